@@ -5,11 +5,13 @@ package auth
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
 	"github.com/nikshitha/linkedin-automation-poc/config"
 	"github.com/nikshitha/linkedin-automation-poc/logger"
 	"github.com/nikshitha/linkedin-automation-poc/stealth"
@@ -43,6 +45,8 @@ type Authenticator struct {
 	page      *rod.Page
 	browser   *rod.Browser
 	isLoggedIn bool
+	lastKeepAlive time.Time // last login, cookie save or keep-alive visit
+	layout    *browser.LayoutMonitor
 }
 
 // NewAuthenticator creates a new authenticator
@@ -65,7 +69,13 @@ func (a *Authenticator) SetPage(page *rod.Page) {
 	a.page = page
 }
 
-// Login performs LinkedIn login with human-like behavior
+// SetLayoutMonitor sets the monitor that fingerprints the login page layout
+func (a *Authenticator) SetLayoutMonitor(m *browser.LayoutMonitor) {
+	a.layout = m
+}
+
+// Login performs LinkedIn login with human-like behavior, retrying the
+// credential flow up to login_attempts times when the form misbehaves
 func (a *Authenticator) Login() error {
 	a.logger.Info("Starting login process")
 
@@ -73,29 +83,58 @@ func (a *Authenticator) Login() error {
 	if a.tryExistingSession() {
 		a.logger.Info("Successfully restored existing session")
 		a.isLoggedIn = true
+		a.lastKeepAlive = time.Now()
 		return nil
 	}
 
-	// Navigate to login page
+	attempts := a.config.LinkedIn.LoginAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			delay := loginRetryDelay(attempt - 1)
+			a.logger.WithFields(map[string]interface{}{
+				"attempt": attempt,
+				"of":      attempts,
+				"delay":   delay.String(),
+			}).WithError(err).Warn("Login attempt failed, retrying")
+			time.Sleep(delay)
+		}
+
+		err = a.attemptLogin()
+		if err == nil || !isRetryableLoginError(err) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("login failed after %d attempts: %w", attempts, err)
+}
+
+// attemptLogin loads the login page and submits the credentials once
+func (a *Authenticator) attemptLogin() error {
+	// Navigate to login page (reloading it on retries)
 	a.logger.Info("Navigating to login page")
-	err := a.page.Navigate(LinkedInLoginURL)
-	if err != nil {
-		return fmt.Errorf("failed to navigate to login page: %w", err)
+	err := browser.NavigateAndWaitReady(a.page, LinkedInLoginURL, a.config.GetReadyTimeout(),
+		"#username", "input[name='session_key']", "#global-nav")
+	// Checkpoints and redirects render none of the login selectors, so only a
+	// login page that never became ready is an error
+	currentURL := a.currentURL()
+	if err != nil && (currentURL == "" || strings.Contains(currentURL, "/login")) {
+		return fmt.Errorf("%w: login page not ready: %v", errLoginFormIncomplete, err)
 	}
 
-	// Wait for page load
 	a.stealth.PageLoadDelay()
-	err = a.page.WaitLoad()
-	if err != nil {
-		return fmt.Errorf("failed to load login page: %w", err)
-	}
-	
-	// Extra wait for dynamic content to load
-	time.Sleep(3 * time.Second)
-	
+
 	// Check if already logged in (redirected to feed)
-	currentURL := a.page.MustInfo().URL
+	currentURL = a.currentURL()
 	a.logger.WithField("url", currentURL).Debug("Current URL after navigation")
+
+	if strings.Contains(currentURL, "/login") {
+		a.layout.Check(a.page, "login")
+	}
 	
 	if strings.Contains(currentURL, "/feed") || strings.Contains(currentURL, "/mynetwork") || strings.Contains(currentURL, "/in/") {
 		a.logger.Info("Already logged in - redirected to LinkedIn")
@@ -109,7 +148,7 @@ func (a *Authenticator) Login() error {
 	_, err = a.page.Timeout(10 * time.Second).Element("#username")
 	if err != nil {
 		// Check again if we got redirected during the wait
-		currentURL = a.page.MustInfo().URL
+		currentURL = a.currentURL()
 		if strings.Contains(currentURL, "/feed") || strings.Contains(currentURL, "/mynetwork") {
 			a.logger.Info("Already logged in - detected after wait")
 			a.isLoggedIn = true
@@ -122,6 +161,9 @@ func (a *Authenticator) Login() error {
 	// Apply fingerprint masking
 	a.stealth.ApplyFingerprintMasking(a.page)
 
+	// Cookie banners and promo dialogs can sit over the form
+	a.dismissLoginInterruptions()
+
 	// Random mouse movement before interaction
 	a.stealth.RandomMouseWander(a.page)
 	a.stealth.ThinkingDelay()
@@ -152,18 +194,22 @@ func (a *Authenticator) Login() error {
 	}
 	
 	if emailField == nil {
-		return fmt.Errorf("failed to find email field - LinkedIn page may not have loaded correctly")
+		return fmt.Errorf("%w: email field not found - LinkedIn page may not have loaded correctly", errLoginFormIncomplete)
 	}
 	
 	a.logger.Debug("Found email field, clicking")
 
-	// Click on email field first - use MustClick for reliability
-	emailField.MustClick()
-	time.Sleep(500 * time.Millisecond)
+	// Click on email field first, then type - use simple input for reliability
+	err = rod.Try(func() {
+		emailField.MustClick()
+		time.Sleep(500 * time.Millisecond)
 
-	// Type email - use simple input for reliability
-	a.logger.Debug("Typing email")
-	emailField.MustSelectAllText().MustInput(a.config.LinkedIn.Email)
+		a.logger.Debug("Typing email")
+		emailField.MustSelectAllText().MustInput(a.config.LinkedIn.Email)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: failed to enter email: %v", errLoginFormIncomplete, err)
+	}
 
 	// Small delay before moving to password
 	time.Sleep(1 * time.Second)
@@ -188,15 +234,20 @@ func (a *Authenticator) Login() error {
 	}
 	
 	if passwordField == nil {
-		return fmt.Errorf("failed to find password field")
+		return fmt.Errorf("%w: password field not found", errLoginFormIncomplete)
 	}
 	
 	a.logger.Debug("Found password field, clicking")
-	passwordField.MustClick()
-	time.Sleep(500 * time.Millisecond)
-	
-	a.logger.Debug("Typing password")
-	passwordField.MustSelectAllText().MustInput(a.config.LinkedIn.Password)
+	err = rod.Try(func() {
+		passwordField.MustClick()
+		time.Sleep(500 * time.Millisecond)
+
+		a.logger.Debug("Typing password")
+		passwordField.MustSelectAllText().MustInput(a.config.LinkedIn.Password)
+	})
+	if err != nil {
+		return fmt.Errorf("%w: failed to enter password: %v", errLoginFormIncomplete, err)
+	}
 
 	// Thinking delay before submitting
 	time.Sleep(1 * time.Second)
@@ -215,11 +266,13 @@ func (a *Authenticator) Login() error {
 	}
 	
 	if loginButton == nil {
-		return fmt.Errorf("failed to find login button")
+		return fmt.Errorf("%w: login button not found", errLoginFormIncomplete)
 	}
 	
 	a.logger.Debug("Found login button, clicking")
-	loginButton.MustClick()
+	if err := rod.Try(func() { loginButton.MustClick() }); err != nil {
+		return fmt.Errorf("%w: failed to click login button: %v", errLoginFormIncomplete, err)
+	}
 
 	// Wait for navigation
 	a.logger.Debug("Waiting for login to complete")
@@ -229,12 +282,41 @@ func (a *Authenticator) Login() error {
 	return a.checkLoginResult()
 }
 
+// currentURL returns the URL of the login page, or "" when the page can't be
+// queried (closed or crashed); callers treat "" as an unknown state
+func (a *Authenticator) currentURL() string {
+	info, err := a.page.Info()
+	if err != nil {
+		a.logger.WithError(err).Warn("Failed to read current page URL")
+		return ""
+	}
+	return info.URL
+}
+
 // checkLoginResult verifies if login was successful and handles errors
 func (a *Authenticator) checkLoginResult() error {
-	currentURL := a.page.MustInfo().URL
+	currentURL := a.currentURL()
 
 	a.logger.WithField("url", currentURL).Debug("Checking login result")
 
+	// A benign "This was me" confirmation can sit between the login form and
+	// the feed; click through it and judge the page it leads to instead
+	if !strings.Contains(currentURL, "/feed") && a.confirmThisWasMe() {
+		currentURL = a.currentURL()
+		a.logger.WithField("url", currentURL).Debug("Re-checking login result after confirmation")
+	}
+
+	// Answer an authenticator-app prompt when the user opted in with a secret;
+	// without one (or if it fails) the 2FA error path below applies
+	if !strings.Contains(currentURL, "/feed") && a.config.LinkedIn.TOTPSecret != "" && a.detect2FA() {
+		if err := a.submitTOTPCode(); err != nil {
+			a.logger.WithError(err).Warn("Failed to answer 2FA prompt automatically")
+		} else {
+			currentURL = a.currentURL()
+			a.logger.WithField("url", currentURL).Debug("Re-checking login result after two-factor verification")
+		}
+	}
+
 	// Check for successful login (redirected to feed)
 	if strings.Contains(currentURL, "/feed") {
 		a.logger.Info("Login successful - redirected to feed")
@@ -288,9 +370,38 @@ func (a *Authenticator) checkLoginResult() error {
 	return ErrLoginFailed
 }
 
+// thisWasMePattern matches the confirm button of the "This was me"
+// interstitial, as a JS regex literal for ElementR
+const thisWasMePattern = `/^\s*(this was me|yes,? (it was|this was|that was) me)\s*$/i`
+
+// confirmThisWasMe detects the one-click "Verify it's you" / "This was me"
+// interstitial and clicks through it. Returns true if it was confirmed.
+func (a *Authenticator) confirmThisWasMe() bool {
+	button, err := a.page.Timeout(2*time.Second).ElementR("button, a[role='button']", thisWasMePattern)
+	if err != nil || button == nil {
+		return false
+	}
+
+	a.logger.SecurityEvent("LOGIN_CONFIRMATION", "\"This was me\" confirmation shown after login, confirming")
+
+	// Read the prompt before confirming, like a person would
+	a.stealth.ThinkingDelay()
+	if err := a.stealth.ClickElement(a.page, button); err != nil {
+		a.logger.WithError(err).Warn("Failed to click login confirmation")
+		return false
+	}
+
+	if err := browser.WaitReady(a.page, a.config.GetReadyTimeout()); err != nil {
+		a.logger.WithError(err).Debug("Page not ready after login confirmation")
+	}
+	a.stealth.PageLoadDelay()
+
+	return true
+}
+
 // handleSecurityCheckpoint handles various security checkpoints
 func (a *Authenticator) handleSecurityCheckpoint() error {
-	currentURL := a.page.MustInfo().URL
+	currentURL := a.currentURL()
 	pageHTML, _ := a.page.HTML()
 
 	// Phone verification
@@ -427,7 +538,7 @@ func (a *Authenticator) IsLoggedIn() bool {
 	a.stealth.PageLoadDelay()
 	time.Sleep(2 * time.Second)
 
-	currentURL := a.page.MustInfo().URL
+	currentURL := a.currentURL()
 
 	// Check if redirected to login
 	if strings.Contains(currentURL, "/login") || strings.Contains(currentURL, "/authwall") {
@@ -467,10 +578,14 @@ func (a *Authenticator) tryExistingSession() bool {
 		return false
 	}
 
-	// Check if cookies are expired
+	// Check if cookies are expired; only restore the configured LinkedIn domains
+	domains := a.config.Storage.CookieDomains
 	validCookies := make([]*storage.SessionCookie, 0)
 	now := time.Now().Unix()
 	for _, cookie := range cookies {
+		if !cookieDomainAllowed(cookie.Domain, domains) {
+			continue
+		}
 		if cookie.Expires == 0 || cookie.Expires > now {
 			validCookies = append(validCookies, cookie)
 		}
@@ -497,7 +612,7 @@ func (a *Authenticator) tryExistingSession() bool {
 			Secure:   cookie.Secure,
 		}})
 		if err != nil {
-			a.logger.WithError(err).Debug("Failed to set cookie")
+			a.logger.WithError(err).WithField("domain", cookie.Domain).Debug("Failed to set cookie")
 		}
 	}
 
@@ -506,12 +621,24 @@ func (a *Authenticator) tryExistingSession() bool {
 	a.stealth.PageLoadDelay()
 	time.Sleep(2 * time.Second)
 
-	return a.IsLoggedIn()
+	if !a.IsLoggedIn() {
+		// Unexpired cookies that don't log in usually mean a domain's set is missing
+		a.logger.WithFields(map[string]interface{}{
+			"cookies":            len(validCookies),
+			"cookie_domains":     cookieDomainCounts(validCookies),
+			"configured_domains": strings.Join(domains, ","),
+		}).Warn("Restored session cookies did not log in")
+		return false
+	}
+	return true
 }
 
 // saveCookies saves the current session cookies
 func (a *Authenticator) saveCookies() error {
-	cookies, err := a.page.Cookies([]string{LinkedInBaseURL})
+	// Auth cookies are spread over .linkedin.com, www.linkedin.com and
+	// API subdomains; capturing only the base URL's set restores a
+	// half-authenticated session
+	cookies, err := a.page.Cookies(cookieURLs(a.config.Storage.CookieDomains))
 	if err != nil {
 		return fmt.Errorf("failed to get cookies: %w", err)
 	}
@@ -529,6 +656,7 @@ func (a *Authenticator) saveCookies() error {
 			Secure:   cookie.Secure,
 		}
 	}
+	storageCookies = dedupeCookies(storageCookies)
 
 	// Save to database
 	if err := a.db.SaveCookies(storageCookies); err != nil {
@@ -541,7 +669,8 @@ func (a *Authenticator) saveCookies() error {
 		return err
 	}
 
-	a.logger.Info("Session cookies saved successfully")
+	a.lastKeepAlive = time.Now()
+	a.logger.WithField("cookie_domains", cookieDomainCounts(storageCookies)).Info("Session cookies saved successfully")
 	return nil
 }
 
@@ -598,7 +727,36 @@ func (a *Authenticator) GetCurrentUser() (map[string]string, error) {
 	}
 
 	// Get profile URL
-	user["profile_url"] = a.page.MustInfo().URL
+	user["profile_url"] = a.currentURL()
 
 	return user, nil
 }
+
+// joinedDatePattern matches the "Joined <Month> <Year>" line in "About this profile"
+var joinedDatePattern = regexp.MustCompile(`Joined\s+(January|February|March|April|May|June|July|August|September|October|November|December)\s+(\d{4})`)
+
+// GetAccountJoinDate scrapes when the logged-in account joined LinkedIn from its own profile
+func (a *Authenticator) GetAccountJoinDate() (time.Time, error) {
+	err := browser.NavigateAndWaitReady(a.page, "https://www.linkedin.com/in/me/", a.config.GetReadyTimeout(),
+		"h1.text-heading-xlarge")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to open own profile: %w", err)
+	}
+	a.stealth.PageLoadDelay()
+
+	html, err := a.page.HTML()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read profile: %w", err)
+	}
+
+	match := joinedDatePattern.FindStringSubmatch(html)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("join date not found on profile")
+	}
+
+	joined, err := time.ParseInLocation("January 2006", match[1]+" "+match[2], time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse join date: %w", err)
+	}
+	return joined, nil
+}
@@ -0,0 +1,23 @@
+// Package auth - Tests for login checkpoint handling
+package auth
+
+import (
+	"testing"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+func TestThisWasMePattern(t *testing.T) {
+	re, err := browser.CompileJSRegex(thisWasMePattern)
+	if err != nil {
+		t.Fatalf("Confirmation pattern won't run in the browser: %v", err)
+	}
+	for _, label := range []string{"This was me", " Yes, it was me ", "yes this was me"} {
+		if !re.MatchString(label) {
+			t.Errorf("Expected %q to confirm the login", label)
+		}
+	}
+	if re.MatchString("This wasn't me") {
+		t.Error("The denial button must not match")
+	}
+}
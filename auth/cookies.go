@@ -0,0 +1,71 @@
+// Package auth - cookies.go handles which LinkedIn domains session cookies
+// are captured from and restored to
+package auth
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+// cookieURLs returns the URLs to capture cookies for, one per configured
+// domain. Cookies set on a parent domain (.linkedin.com) are included for
+// every subdomain URL.
+func cookieURLs(domains []string) []string {
+	urls := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		urls = append(urls, "https://"+strings.TrimPrefix(domain, "."))
+	}
+	return urls
+}
+
+// cookieDomainAllowed reports whether a cookie's domain is one of the
+// configured domains or a subdomain of one
+func cookieDomainAllowed(cookieDomain string, domains []string) bool {
+	cookieDomain = strings.ToLower(strings.TrimPrefix(cookieDomain, "."))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+		if cookieDomain == domain || strings.HasSuffix(cookieDomain, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeCookies drops repeats of the same cookie returned for several URLs
+func dedupeCookies(cookies []*storage.SessionCookie) []*storage.SessionCookie {
+	seen := make(map[string]bool)
+	unique := cookies[:0]
+	for _, cookie := range cookies {
+		key := cookie.Name + "|" + cookie.Domain + "|" + cookie.Path
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, cookie)
+	}
+	return unique
+}
+
+// cookieDomainCounts summarizes how many cookies each domain has, e.g.
+// ".linkedin.com=12 www.linkedin.com=3"
+func cookieDomainCounts(cookies []*storage.SessionCookie) string {
+	counts := make(map[string]int)
+	for _, cookie := range cookies {
+		counts[cookie.Domain]++
+	}
+
+	domains := make([]string, 0, len(counts))
+	for domain := range counts {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+
+	parts := make([]string, len(domains))
+	for i, domain := range domains {
+		parts[i] = fmt.Sprintf("%s=%d", domain, counts[domain])
+	}
+	return strings.Join(parts, " ")
+}
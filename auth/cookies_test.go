@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+func TestCookieDomains(t *testing.T) {
+	domains := []string{"linkedin.com", "www.linkedin.com"}
+
+	urls := cookieURLs([]string{".linkedin.com", "www.linkedin.com"})
+	if len(urls) != 2 || urls[0] != "https://linkedin.com" || urls[1] != "https://www.linkedin.com" {
+		t.Errorf("Unexpected cookie URLs: %v", urls)
+	}
+
+	for domain, want := range map[string]bool{
+		".linkedin.com":     true,
+		"www.linkedin.com":  true,
+		".api.linkedin.com": true,
+		"notlinkedin.com":   false,
+		".example.com":      false,
+	} {
+		if got := cookieDomainAllowed(domain, domains); got != want {
+			t.Errorf("cookieDomainAllowed(%q) = %v, want %v", domain, got, want)
+		}
+	}
+
+	cookies := dedupeCookies([]*storage.SessionCookie{
+		{Name: "li_at", Domain: ".linkedin.com", Path: "/"},
+		{Name: "JSESSIONID", Domain: ".www.linkedin.com", Path: "/"},
+		{Name: "li_at", Domain: ".linkedin.com", Path: "/"},
+	})
+	if len(cookies) != 2 {
+		t.Fatalf("Expected 2 unique cookies, got %d", len(cookies))
+	}
+	if got := cookieDomainCounts(cookies); got != ".linkedin.com=1 .www.linkedin.com=1" {
+		t.Errorf("Unexpected domain summary: %q", got)
+	}
+}
@@ -0,0 +1,83 @@
+// Package auth - health.go handles detection of account warning banners,
+// which LinkedIn shows on the feed before it hard-restricts an account.
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrAccountWarning is returned by CheckAccountHealth when a warning banner is
+// shown and rate_limits.halt_on_account_warning is set
+var ErrAccountWarning = errors.New("account warning banner shown")
+
+// accountWarningPhrases are lowercase fragments of LinkedIn's non-blocking
+// warning banners
+var accountWarningPhrases = []string{
+	"we noticed unusual activity",
+	"we've noticed unusual activity",
+	"we’ve noticed unusual activity",
+	"we noticed some unusual activity",
+	"your account may be restricted",
+	"may result in your account being restricted",
+	"violates our user agreement",
+	"automated activity",
+}
+
+// accountWarningSelector matches the containers LinkedIn shows warnings in:
+// the global alert banner, ARIA alerts and the restriction interstitial.
+// Only their text is scanned, so posts in the feed quoting a warning phrase
+// don't count.
+const accountWarningSelector = ".artdeco-global-alert, [role='alert'], .restricted-account-interstitial, #restricted-account"
+
+// accountWarningTextJS joins the visible text of every element matching the
+// selector passed as its argument
+const accountWarningTextJS = `(selector) => Array.from(document.querySelectorAll(selector)).map(el => el.innerText || "").join("\n")`
+
+// findAccountWarning returns the first warning phrase found in the text of
+// the warning containers, or "" if there is none
+func findAccountWarning(text string) string {
+	lower := strings.ToLower(text)
+	for _, phrase := range accountWarningPhrases {
+		if strings.Contains(lower, phrase) {
+			return phrase
+		}
+	}
+	return ""
+}
+
+// CheckAccountHealth scans the feed for warning banners at session start.
+// A banner is logged as a high-severity security event; when
+// rate_limits.halt_on_account_warning is set, ErrAccountWarning is returned
+// so the run stops before the account gets restricted.
+func (a *Authenticator) CheckAccountHealth() error {
+	if !strings.Contains(a.currentURL(), "/feed") {
+		if err := a.page.Navigate(LinkedInFeedURL); err != nil {
+			a.logger.WithError(err).Warn("Could not open the feed to check account health")
+			return nil
+		}
+		a.stealth.PageLoadDelay()
+	}
+
+	// Banners are rendered after the feed itself
+	time.Sleep(2 * time.Second)
+
+	res, err := a.page.Eval(accountWarningTextJS, accountWarningSelector)
+	if err != nil {
+		a.logger.WithError(err).Warn("Could not read the feed to check account health")
+		return nil
+	}
+
+	phrase := findAccountWarning(res.Value.Str())
+	if phrase == "" {
+		a.logger.Debug("No account warning banner on the feed")
+		return nil
+	}
+
+	a.logger.SecurityAlert("ACCOUNT_WARNING", "Warning banner on the feed: \""+phrase+"\" - the account is at risk of restriction")
+	if a.config.RateLimits.HaltOnAccountWarning {
+		return ErrAccountWarning
+	}
+	return nil
+}
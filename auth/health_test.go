@@ -0,0 +1,20 @@
+package auth
+
+import "testing"
+
+func TestFindAccountWarning(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"We noticed unusual activity on your account", "we noticed unusual activity"},
+		{"Continued use of automated activity may result in your account being restricted.", "may result in your account being restricted"},
+		{"Congrats on the new role!", ""},
+	}
+
+	for _, tt := range tests {
+		if got := findAccountWarning(tt.text); got != tt.want {
+			t.Errorf("findAccountWarning(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Package auth - keepalive.go handles periodic session refreshes during long runs
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+// keepAliveDue reports whether interval has passed since last. A zero
+// interval disables keep-alives; a zero last means the session was never
+// established, so there is nothing to keep alive.
+func keepAliveDue(last time.Time, interval time.Duration, now time.Time) bool {
+	if interval <= 0 || last.IsZero() {
+		return false
+	}
+	return now.Sub(last) >= interval
+}
+
+// KeepAlive revisits the feed once linkedin.keep_alive_minutes have passed
+// since the last login, cookie save or keep-alive, and re-saves the cookies
+// so rotated session tokens are persisted. Runs shorter than the interval
+// never navigate. Returns ErrSessionExpired if the visit lands on a login
+// page, so the caller can re-authenticate, or an error if the feed didn't load.
+func (a *Authenticator) KeepAlive() error {
+	interval := time.Duration(a.config.LinkedIn.KeepAliveMinutes) * time.Minute
+	if !keepAliveDue(a.lastKeepAlive, interval, time.Now()) {
+		return nil
+	}
+
+	a.logger.Debug("Refreshing session cookies")
+	if err := browser.NavigateAndWaitReady(a.page, LinkedInFeedURL, a.config.GetReadyTimeout(), "#global-nav"); err != nil {
+		currentURL := a.currentURL()
+		if strings.Contains(currentURL, "/login") || strings.Contains(currentURL, "/authwall") {
+			a.isLoggedIn = false
+			return ErrSessionExpired
+		}
+		// Try again next interval rather than on every check
+		a.lastKeepAlive = time.Now()
+		return fmt.Errorf("keep-alive visit to the feed failed: %w", err)
+	}
+	a.stealth.PageLoadDelay()
+
+	if err := a.saveCookies(); err != nil {
+		a.logger.WithError(err).Warn("Failed to save cookies after keep-alive")
+		a.lastKeepAlive = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeepAliveDue(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		last     time.Time
+		interval time.Duration
+		want     bool
+	}{
+		{now.Add(-2 * time.Hour), time.Hour, true},
+		{now.Add(-10 * time.Minute), time.Hour, false},
+		{now.Add(-2 * time.Hour), 0, false},
+		{time.Time{}, time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		if got := keepAliveDue(tt.last, tt.interval, now); got != tt.want {
+			t.Errorf("keepAliveDue(%v ago, %v) = %v, want %v", now.Sub(tt.last), tt.interval, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package auth - retry.go handles retrying the login flow after transient form failures
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// errLoginFormIncomplete marks a login attempt that failed because the form
+// didn't render or respond (a field or the submit button was missing);
+// unlike the exported sentinels, it is worth reloading the page and retrying
+var errLoginFormIncomplete = errors.New("login form incomplete")
+
+// loginInterruptionSelectors match dismiss buttons on cookie banners and
+// promo dialogs that can cover the login form
+var loginInterruptionSelectors = []string{
+	`button[action-type="ACCEPT"]`,
+	`.artdeco-global-alert button[aria-label*="Dismiss"]`,
+	`.artdeco-modal button[aria-label="Dismiss"]`,
+	`.artdeco-modal__dismiss`,
+}
+
+// isRetryableLoginError reports whether a failed login attempt may succeed on
+// a fresh page. 2FA, captcha, checkpoints, restrictions and rejected
+// credentials all need a person (or would lock the account) and are final.
+func isRetryableLoginError(err error) bool {
+	return errors.Is(err, errLoginFormIncomplete)
+}
+
+// loginRetryDelay is the wait before the given retry: 5s, doubling up to 40s
+func loginRetryDelay(retry int) time.Duration {
+	if retry < 1 {
+		retry = 1
+	}
+	if retry > 4 {
+		retry = 4
+	}
+	return 5 * time.Second << uint(retry-1)
+}
+
+// dismissLoginInterruptions closes anything sitting over the login form
+func (a *Authenticator) dismissLoginInterruptions() {
+	for _, selector := range loginInterruptionSelectors {
+		button, err := a.page.Timeout(500 * time.Millisecond).Element(selector)
+		if err != nil {
+			continue
+		}
+		if visible, _ := button.Visible(); !visible {
+			continue
+		}
+
+		a.logger.WithField("selector", selector).Debug("Dismissing interruption over login form")
+		if err := a.stealth.ClickElement(a.page, button); err != nil {
+			a.logger.WithError(err).Debug("Failed to dismiss interruption")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestIsRetryableLoginError(t *testing.T) {
+	retryable := fmt.Errorf("%w: password field not found", errLoginFormIncomplete)
+	if !isRetryableLoginError(retryable) {
+		t.Error("A missing form field should be retried")
+	}
+
+	for _, err := range []error{
+		ErrTwoFactorRequired,
+		ErrCaptchaRequired,
+		fmt.Errorf("%w: phone verification required", ErrSecurityCheck),
+		ErrAccountRestricted,
+		ErrLoginFailed,
+	} {
+		if isRetryableLoginError(err) {
+			t.Errorf("%v should not be retried", err)
+		}
+	}
+}
+
+func TestLoginRetryDelay(t *testing.T) {
+	want := []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second, 40 * time.Second}
+	for i, expected := range want {
+		if got := loginRetryDelay(i + 1); got != expected {
+			t.Errorf("Retry %d: expected %v, got %v", i+1, expected, got)
+		}
+	}
+}
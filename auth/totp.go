@@ -0,0 +1,86 @@
+// Package auth - totp.go handles answering authenticator-app 2FA prompts
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+// TOTP parameters used by LinkedIn and authenticator apps (RFC 6238 defaults)
+const (
+	totpStep   = 30 * time.Second
+	totpDigits = 6
+)
+
+// totpCode returns the time-based one-time password for a base32 secret at t
+func totpCode(secret string, t time.Time) (string, error) {
+	normalized := strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "="))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(normalized)
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/int64(totpStep/time.Second)))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation (RFC 4226 section 5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// submitTOTPCode fills the verification input with the current code from the
+// configured secret and submits it
+func (a *Authenticator) submitTOTPCode() error {
+	input, err := a.page.Timeout(5 * time.Second).Element("#input__phone_verification_pin, input[name='pin'], input[autocomplete='one-time-code']")
+	if err != nil {
+		return fmt.Errorf("verification code input not found: %w", err)
+	}
+
+	// Don't start typing a code that expires before it can be submitted
+	if remaining := totpStep - time.Duration(time.Now().Unix()%int64(totpStep/time.Second))*time.Second; remaining < 5*time.Second {
+		time.Sleep(remaining)
+	}
+
+	code, err := totpCode(a.config.LinkedIn.TOTPSecret, time.Now())
+	if err != nil {
+		return err
+	}
+
+	a.logger.SecurityEvent("2FA_TOTP", "Answering two-factor prompt with the configured TOTP secret")
+
+	if err := a.stealth.ClickElement(a.page, input); err != nil {
+		return fmt.Errorf("failed to focus verification input: %w", err)
+	}
+	if err := a.stealth.HumanType(a.page, input, code); err != nil {
+		return fmt.Errorf("failed to type verification code: %w", err)
+	}
+	a.stealth.ActionDelay()
+
+	submit, err := a.page.Timeout(3 * time.Second).Element("#two-step-submit-button, form button[type='submit']")
+	if err != nil {
+		return fmt.Errorf("verification submit button not found: %w", err)
+	}
+	if err := a.stealth.ClickElement(a.page, submit); err != nil {
+		return fmt.Errorf("failed to submit verification code: %w", err)
+	}
+
+	if err := browser.WaitReady(a.page, a.config.GetReadyTimeout()); err != nil {
+		a.logger.WithError(err).Debug("Page not ready after submitting verification code")
+	}
+	a.stealth.PageLoadDelay()
+
+	return nil
+}
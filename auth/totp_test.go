@@ -0,0 +1,42 @@
+// Package auth - Tests for TOTP code generation
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B SHA-1 vectors, secret "12345678901234567890"
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := totpCode(secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("At %d: expected %s, got %s", tt.unix, tt.want, got)
+		}
+	}
+
+	// Authenticator apps show secrets lowercased and grouped
+	got, err := totpCode("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", time.Unix(59, 0))
+	if err != nil || got != "287082" {
+		t.Errorf("Formatted secret should give the same code, got %s (%v)", got, err)
+	}
+
+	if _, err := totpCode("not base32!", time.Now()); err == nil {
+		t.Error("Expected an error for an invalid secret")
+	}
+}
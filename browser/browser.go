@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/nikshitha/linkedin-automation-poc/config"
 	"github.com/nikshitha/linkedin-automation-poc/logger"
@@ -23,6 +25,13 @@ type Browser struct {
 	stealth *stealth.StealthManager
 	browser *rod.Browser
 	page    *rod.Page
+	device  stealth.DeviceMetrics
+
+	// Contents of browser.inject_script_path, run after the masking script
+	injectScript string
+
+	// Called after EnsureAlive relaunches the browser
+	onRelaunch func() error
 }
 
 // NewBrowser creates a new browser instance
@@ -66,6 +75,15 @@ func (b *Browser) Launch() error {
 		Set("metrics-recording-only").
 		Set("safebrowsing-disable-auto-update")
 
+	// Use a system or managed browser build instead of the downloaded Chromium
+	if b.config.Browser.BinaryPath != "" {
+		l = l.Bin(b.config.Browser.BinaryPath)
+	}
+	for _, raw := range b.config.Browser.ExtraFlags {
+		name, values := parseFlag(raw)
+		l = l.Set(flags.Flag(name), values...)
+	}
+
 	// Set user data directory for session persistence
 	if b.config.Browser.UserDataDir != "" {
 		l = l.UserDataDir(b.config.Browser.UserDataDir)
@@ -80,6 +98,22 @@ func (b *Browser) Launch() error {
 		viewportHeight = b.config.Browser.ViewportHeight
 	}
 
+	// Pick this session's identity, or reuse the one the profile was created
+	// with so it looks like the same device every run. Tablets and phones
+	// bring their own screen size.
+	profile := b.stealth.NewFingerprintProfile(b.config.Browser.DeviceProfile, viewportWidth, viewportHeight)
+	if b.config.Stealth.PersistFingerprint && b.config.Browser.UserDataDir != "" {
+		stored, err := b.stealth.LoadOrCreateFingerprintProfile(b.config.Browser.UserDataDir, profile)
+		if err != nil {
+			b.logger.WithError(err).Warn("Failed to persist fingerprint profile, using a one-off identity")
+		} else {
+			profile = stored
+		}
+	}
+	b.stealth.SetFingerprintProfile(profile)
+	b.device = profile.Screen
+	viewportWidth, viewportHeight = b.device.Width, b.device.Height
+
 	// Set window size
 	l = l.Set("window-size", fmt.Sprintf("%d,%d", viewportWidth, viewportHeight))
 
@@ -103,34 +137,71 @@ func (b *Browser) Launch() error {
 
 	b.logger.Info("Browser launched successfully")
 
+	// Load the user's page hooks before the first page is created
+	if b.config.Browser.InjectScriptPath != "" {
+		if err := b.ExecuteScriptFile(b.config.Browser.InjectScriptPath); err != nil {
+			return err
+		}
+	}
+
 	// Create initial page
-	return b.createPage(viewportWidth, viewportHeight)
+	return b.createPage()
+}
+
+// parseFlag splits a command-line flag such as "--proxy-server=host:3128"
+// into its name and values for the launcher
+func parseFlag(raw string) (string, []string) {
+	name, value, found := strings.Cut(strings.TrimLeft(strings.TrimSpace(raw), "-"), "=")
+	if !found {
+		return name, nil
+	}
+	return name, []string{value}
 }
 
 // createPage creates a new page with stealth settings
-func (b *Browser) createPage(width, height int) error {
-	var err error
-	b.page, err = b.browser.Page(proto.TargetCreateTarget{URL: "about:blank"})
+func (b *Browser) createPage() error {
+	page, err := b.browser.Page(proto.TargetCreateTarget{URL: "about:blank"})
 	if err != nil {
 		return fmt.Errorf("failed to create page: %w", err)
 	}
+	b.page = page
+	b.setupPage(page)
+
+	b.logger.Info("Page created with stealth settings")
+	return nil
+}
 
-	// Set viewport
-	err = b.page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
-		Width:             width,
-		Height:            height,
-		DeviceScaleFactor: 1,
-		Mobile:            false,
+// setupPage gives page this session's identity: the device's viewport and
+// metrics, touch emulation, user agent, geolocation and the masking and
+// inject scripts. Every page the run drives goes through it so tabs look
+// like the same device. Failures are logged and leave the page usable.
+func (b *Browser) setupPage(page *rod.Page) {
+	err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             b.device.Width,
+		Height:            b.device.Height,
+		DeviceScaleFactor: b.device.ScaleFactor,
+		Mobile:            b.device.Mobile,
 	})
 	if err != nil {
 		b.logger.WithError(err).Warn("Failed to set viewport")
 	}
 
-	// Set user agent if configured
-	if b.config.Stealth.RandomUserAgent {
-		userAgent := b.stealth.GetRandomUserAgent()
-		err = b.page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
-			UserAgent: userAgent,
+	if b.device.Touch {
+		maxTouchPoints := 5
+		err = proto.EmulationSetTouchEmulationEnabled{Enabled: true, MaxTouchPoints: &maxTouchPoints}.Call(page)
+		if err != nil {
+			b.logger.WithError(err).Warn("Failed to enable touch emulation")
+		}
+	}
+
+	// Set user agent if configured; emulated devices always need a matching one
+	device := b.config.Browser.DeviceProfile
+	if b.config.Stealth.RandomUserAgent || (device != "" && device != stealth.DeviceDesktop) {
+		userAgent := b.stealth.FingerprintProfile().UserAgent
+		err = page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+			UserAgent:         userAgent,
+			Platform:          stealth.NavigatorPlatform(userAgent),
+			UserAgentMetadata: stealth.UserAgentMetadata(userAgent),
 		})
 		if err != nil {
 			b.logger.WithError(err).Warn("Failed to set user agent")
@@ -139,11 +210,13 @@ func (b *Browser) createPage(width, height int) error {
 		}
 	}
 
-	// Apply fingerprint masking on page load
-	b.page.EvalOnNewDocument(b.getStealthScript())
+	if err := b.SetGeolocation(page); err != nil {
+		b.logger.WithError(err).Warn("Failed to set geolocation")
+	}
 
-	b.logger.Info("Page created with stealth settings")
-	return nil
+	// Apply fingerprint masking on page load
+	page.EvalOnNewDocument(b.getStealthScript())
+	b.applyInjectScript(page)
 }
 
 // getStealthScript returns JavaScript to inject for anti-detection
@@ -165,7 +238,7 @@ func (b *Browser) getStealthScript() string {
 
 		// Overwrite the 'languages' property
 		Object.defineProperty(navigator, 'languages', {
-			get: () => ['en-US', 'en']
+			get: () => ` + b.stealth.LanguagesJS() + `
 		});
 
 		// Fix permissions
@@ -215,6 +288,15 @@ func (b *Browser) GetPage() *rod.Page {
 	return b.page
 }
 
+// SetPage makes page the current page, e.g. after a link opened in a new tab,
+// and gives it the same setup as pages created here. The tab's document
+// loaded before the setup, so fingerprint masking is applied to it directly.
+func (b *Browser) SetPage(page *rod.Page) {
+	b.page = page
+	b.setupPage(page)
+	b.stealth.ApplyFingerprintMasking(page)
+}
+
 // GetBrowser returns the browser instance
 func (b *Browser) GetBrowser() *rod.Browser {
 	return b.browser
@@ -224,18 +306,13 @@ func (b *Browser) GetBrowser() *rod.Browser {
 func (b *Browser) Navigate(url string) error {
 	b.logger.BrowserAction("navigate", url)
 
-	err := b.page.Navigate(url)
+	err := NavigateAndWaitReady(b.page, url, b.config.GetReadyTimeout())
 	if err != nil {
-		return fmt.Errorf("navigation failed: %w", err)
+		return err
 	}
 
 	b.stealth.PageLoadDelay()
 
-	err = b.page.WaitLoad()
-	if err != nil {
-		return fmt.Errorf("page load failed: %w", err)
-	}
-
 	// Apply fingerprint masking after navigation
 	b.stealth.ApplyFingerprintMasking(b.page)
 
@@ -264,15 +341,13 @@ func (b *Browser) TakeScreenshot(filename string) error {
 	return nil
 }
 
-// NewTab creates a new tab
+// NewTab creates a new tab set up like the main page
 func (b *Browser) NewTab() (*rod.Page, error) {
 	page, err := b.browser.Page(proto.TargetCreateTarget{URL: "about:blank"})
 	if err != nil {
 		return nil, err
 	}
-
-	// Apply stealth settings to new tab
-	page.EvalOnNewDocument(b.getStealthScript())
+	b.setupPage(page)
 
 	return page, nil
 }
@@ -292,14 +367,50 @@ func (b *Browser) Close() error {
 	return nil
 }
 
+// profileCookieFiles are the cookie stores Chrome keeps in a profile; newer
+// versions moved them under Network/
+var profileCookieFiles = []string{
+	filepath.Join("Default", "Cookies"),
+	filepath.Join("Default", "Cookies-journal"),
+	filepath.Join("Default", "Network", "Cookies"),
+	filepath.Join("Default", "Network", "Cookies-journal"),
+}
+
+// ClearProfileCookies deletes the cookie stores of the Chrome profile in
+// userDataDir so the next launch starts without a session. It returns the
+// files it removed; missing files are not an error. The browser must not be
+// running on that profile.
+func ClearProfileCookies(userDataDir string) ([]string, error) {
+	if userDataDir == "" {
+		return nil, nil
+	}
+	var removed []string
+	for _, name := range profileCookieFiles {
+		path := filepath.Join(userDataDir, name)
+		if err := os.Remove(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
 // WaitForSelector waits for an element to appear
 func (b *Browser) WaitForSelector(selector string, timeout time.Duration) (*rod.Element, error) {
 	return b.page.Timeout(timeout).Element(selector)
 }
 
-// GetCurrentURL returns the current page URL
+// GetCurrentURL returns the current page URL, or "" if the page can't be queried
 func (b *Browser) GetCurrentURL() string {
-	return b.page.MustInfo().URL
+	info, err := b.page.Info()
+	if err != nil {
+		b.logger.WithError(err).Warn("Failed to read current page URL")
+		return ""
+	}
+	return info.URL
 }
 
 // Reload reloads the current page
@@ -0,0 +1,41 @@
+// Package browser - Tests for clearing the profile's cookies
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClearProfileCookies(t *testing.T) {
+	dir := t.TempDir()
+	network := filepath.Join(dir, "Default", "Network")
+	if err := os.MkdirAll(network, 0755); err != nil {
+		t.Fatal(err)
+	}
+	keep := filepath.Join(dir, "Default", "Preferences")
+	for _, path := range []string{filepath.Join(network, "Cookies"), filepath.Join(network, "Cookies-journal"), keep} {
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := ClearProfileCookies(dir)
+	if err != nil {
+		t.Fatalf("ClearProfileCookies failed: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("Expected the two Network cookie files to be removed, got %v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(network, "Cookies")); !os.IsNotExist(err) {
+		t.Error("Cookies file should be gone")
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Errorf("Other profile files should be left alone: %v", err)
+	}
+
+	// Clearing again finds nothing to remove
+	if removed, err := ClearProfileCookies(dir); err != nil || len(removed) != 0 {
+		t.Errorf("Expected nothing to remove on a second run, got %v, %v", removed, err)
+	}
+}
@@ -0,0 +1,56 @@
+// Package browser - enabled.go handles waiting for buttons that start out disabled
+package browser
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// ErrSendButtonDisabled is returned when a Send button never enables, e.g.
+// because the message is too short or the note is invalid; clicking it
+// would no-op and record a false success
+var ErrSendButtonDisabled = errors.New("send button stayed disabled")
+
+// enabledPollInterval is how often a disabled button is re-checked
+const enabledPollInterval = 200 * time.Millisecond
+
+// ElementEnabled reports whether el accepts clicks: it has no disabled
+// attribute and isn't marked aria-disabled
+func ElementEnabled(el *rod.Element) (bool, error) {
+	disabled, err := el.Attribute("disabled")
+	if err != nil {
+		return false, err
+	}
+	ariaDisabled, err := el.Attribute("aria-disabled")
+	if err != nil {
+		return false, err
+	}
+	return !isDisabled(disabled, ariaDisabled), nil
+}
+
+// isDisabled interprets the disabled and aria-disabled attribute values
+// (nil when absent)
+func isDisabled(disabled, ariaDisabled *string) bool {
+	return disabled != nil || (ariaDisabled != nil && *ariaDisabled == "true")
+}
+
+// WaitSendEnabled polls a Send button until it is enabled, returning
+// ErrSendButtonDisabled if it still isn't after timeout
+func WaitSendEnabled(el *rod.Element, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		enabled, err := ElementEnabled(el)
+		if err != nil {
+			return err
+		}
+		if enabled {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrSendButtonDisabled
+		}
+		time.Sleep(enabledPollInterval)
+	}
+}
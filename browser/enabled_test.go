@@ -0,0 +1,22 @@
+// Package browser - Tests for reading a button's disabled state
+package browser
+
+import "testing"
+
+func TestIsDisabled(t *testing.T) {
+	empty, yes, no := "", "true", "false"
+	cases := []struct {
+		disabled, aria *string
+		want           bool
+	}{
+		{nil, nil, false},
+		{&empty, nil, true},
+		{nil, &yes, true},
+		{nil, &no, false},
+	}
+	for i, tc := range cases {
+		if got := isDisabled(tc.disabled, tc.aria); got != tc.want {
+			t.Errorf("Case %d: expected %v, got %v", i, tc.want, got)
+		}
+	}
+}
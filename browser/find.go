@@ -0,0 +1,106 @@
+// Package browser - find.go handles racing selector fallbacks when looking up elements
+package browser
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// FirstElement races selectors on the current page and returns the first visible match
+func (b *Browser) FirstElement(timeout time.Duration, selectors ...string) (*rod.Element, error) {
+	return FirstElement(b.page, timeout, selectors...)
+}
+
+// FirstElement races all selectors concurrently and returns the first visible match,
+// cancelling the remaining lookups. When several selectors are visible at the same
+// time the earliest one in the list wins, so ordered fallbacks keep their priority.
+func FirstElement(pager Pager, timeout time.Duration, selectors ...string) (*rod.Element, error) {
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("no selectors given")
+	}
+
+	page := RodPage(pager)
+	if page == nil {
+		return firstPagerElement(pager, selectors)
+	}
+
+	ctx, cancel := context.WithTimeout(page.GetContext(), timeout)
+	defer cancel()
+
+	type match struct {
+		index int
+		el    *rod.Element
+	}
+
+	found := make(chan match, len(selectors))
+	var wg sync.WaitGroup
+	for i, selector := range selectors {
+		wg.Add(1)
+		go func(i int, selector string) {
+			defer wg.Done()
+			el, err := page.Context(ctx).Element(selector)
+			if err != nil {
+				return
+			}
+			if err := el.WaitVisible(); err != nil {
+				return
+			}
+			found <- match{index: i, el: el}
+		}(i, selector)
+	}
+
+	allDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allDone)
+	}()
+
+	var first match
+	select {
+	case first = <-found:
+	case <-allDone:
+		select {
+		case first = <-found:
+		default:
+			return nil, fmt.Errorf("none of %d selectors matched a visible element within %s", len(selectors), timeout)
+		}
+	}
+	cancel()
+
+	// Prefer a higher-priority selector that became visible at the same time
+	for i := 0; i < first.index; i++ {
+		if el := visibleNow(page, selectors[i]); el != nil {
+			return el, nil
+		}
+	}
+
+	// Detach the element from the cancelled race context
+	return first.el.Context(page.GetContext()), nil
+}
+
+// firstPagerElement looks selectors up in order on a Pager without the rod
+// API behind it, where there is nothing to race or wait on
+func firstPagerElement(pager Pager, selectors []string) (*rod.Element, error) {
+	for _, selector := range selectors {
+		if el, err := pager.Element(selector); err == nil {
+			return el, nil
+		}
+	}
+	return nil, fmt.Errorf("none of %d selectors matched", len(selectors))
+}
+
+// visibleNow returns the element for selector if it is visible right now, without waiting
+func visibleNow(page *rod.Page, selector string) *rod.Element {
+	el, err := page.Sleeper(rod.NotFoundSleeper).Element(selector)
+	if err != nil {
+		return nil
+	}
+	if visible, _ := el.Visible(); !visible {
+		return nil
+	}
+	return el
+}
@@ -0,0 +1,104 @@
+// Package browser - Tests for racing element lookup against a fake page fixture server
+package browser
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// fixturePage renders a fake profile page: #late appears after a delay, #hidden never shows
+const fixturePage = `<html><body>
+<button id="hidden" style="display:none">Connect</button>
+<button id="primary">Connect</button>
+<button id="secondary">Connect</button>
+<script>
+setTimeout(function() {
+	var b = document.createElement('button');
+	b.id = 'late';
+	b.textContent = 'Message';
+	document.body.appendChild(b);
+}, 300);
+</script>
+</body></html>`
+
+// newFixturePage serves fixturePage from a local server and opens it in a headless browser
+func newFixturePage(t *testing.T) *rod.Page {
+	t.Helper()
+
+	path, found := launcher.LookPath()
+	if !found {
+		t.Skip("no Chrome/Chromium binary available")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, fixturePage)
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := launcher.New().Bin(path).Headless(true).Launch()
+	if err != nil {
+		t.Skipf("failed to launch browser: %v", err)
+	}
+
+	b := rod.New().ControlURL(u)
+	if err := b.Connect(); err != nil {
+		t.Fatalf("failed to connect to browser: %v", err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	page, err := b.Page(proto.TargetCreateTarget{URL: server.URL})
+	if err != nil {
+		t.Fatalf("failed to open fixture page: %v", err)
+	}
+	if err := page.WaitLoad(); err != nil {
+		t.Fatalf("fixture page did not load: %v", err)
+	}
+	return page
+}
+
+func TestFirstElement(t *testing.T) {
+	page := newFixturePage(t)
+
+	// Missing and hidden selectors must not hold up the visible match
+	start := time.Now()
+	el, err := FirstElement(page, 5*time.Second, "#missing", "#hidden", "#late")
+	if err != nil {
+		t.Fatalf("FirstElement should find #late: %v", err)
+	}
+	if id, _ := el.Attribute("id"); id == nil || *id != "late" {
+		t.Errorf("Expected #late, got %v", id)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Racing selectors took too long: %s", elapsed)
+	}
+
+	// The returned element must stay usable after the race context is cancelled
+	if _, err := el.Text(); err != nil {
+		t.Errorf("Element should be usable after FirstElement returns: %v", err)
+	}
+
+	// Earlier selectors win when several are visible at once
+	el, err = FirstElement(page, 2*time.Second, "#secondary", "#primary")
+	if err != nil {
+		t.Fatalf("FirstElement should find a button: %v", err)
+	}
+	if id, _ := el.Attribute("id"); id == nil || *id != "secondary" {
+		t.Errorf("Expected #secondary by priority, got %v", id)
+	}
+
+	// Nothing visible returns an error once the timeout expires
+	start = time.Now()
+	if _, err := FirstElement(page, 500*time.Millisecond, "#missing", "#hidden"); err == nil {
+		t.Error("FirstElement should fail when no selector is visible")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("FirstElement should respect its timeout, took %s", elapsed)
+	}
+}
@@ -0,0 +1,163 @@
+// Package browser - fingerprint.go handles DOM layout fingerprints for detecting UI changes
+package browser
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+// skeletonScript collects the distinct parent>tag.class tokens of the page.
+// Text, attributes and generated class names (containing digits) are left
+// out so the result reflects layout, not content.
+const skeletonScript = `() => {
+	const skip = new Set(['SCRIPT', 'STYLE', 'NOSCRIPT', 'TEMPLATE']);
+	const tokens = new Set();
+	const walk = (el, depth, parent) => {
+		if (depth > 15 || skip.has(el.tagName)) return;
+		const tag = el.tagName.toLowerCase();
+		const classes = Array.from(el.classList).filter(c => !/\d/.test(c)).sort();
+		tokens.add(parent + '>' + [tag, ...classes].join('.'));
+		if (tag === 'svg') return;
+		for (const child of el.children) walk(child, depth + 1, tag);
+	};
+	if (document.body) walk(document.body, 0, '');
+	return Array.from(tokens);
+}`
+
+// PageFingerprint returns a layout fingerprint of the current page
+func (b *Browser) PageFingerprint() (string, error) {
+	return PageFingerprint(b.page)
+}
+
+// PageFingerprint computes a 64-bit SimHash over the page's DOM skeleton
+// (tag/class structure, not content). Similar layouts give fingerprints a
+// small Hamming distance apart, see FingerprintDistance.
+func PageFingerprint(page Pager) (string, error) {
+	res, err := page.Eval(skeletonScript)
+	if err != nil {
+		return "", fmt.Errorf("failed to read page skeleton: %w", err)
+	}
+
+	var tokens []string
+	for _, token := range res.Value.Arr() {
+		tokens = append(tokens, token.Str())
+	}
+	if len(tokens) == 0 {
+		return "", fmt.Errorf("page has no content to fingerprint")
+	}
+
+	return simHash(tokens), nil
+}
+
+// simHash folds tokens into a 64-bit similarity hash, hex encoded
+func simHash(tokens []string) string {
+	var weights [64]int
+	for _, token := range tokens {
+		h := fnv.New64a()
+		h.Write([]byte(token))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit := 0; bit < 64; bit++ {
+		if weights[bit] > 0 {
+			hash |= 1 << uint(bit)
+		}
+	}
+	return fmt.Sprintf("%016x", hash)
+}
+
+// FingerprintDistance returns how many of the 64 bits differ between two
+// fingerprints (0 = same layout)
+func FingerprintDistance(a, b string) (int, error) {
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fingerprint %q: %w", a, err)
+	}
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fingerprint %q: %w", b, err)
+	}
+	return bits.OnesCount64(x ^ y), nil
+}
+
+// LayoutMonitor fingerprints key pages once per run and warns when one has
+// drifted from the last stored fingerprint. A nil monitor is a no-op.
+type LayoutMonitor struct {
+	db        *storage.Database
+	logger    *logger.Logger
+	threshold int
+	checked   map[string]bool
+}
+
+// NewLayoutMonitor returns nil when layout checks are disabled
+func NewLayoutMonitor(cfg *config.Config, log *logger.Logger, db *storage.Database) *LayoutMonitor {
+	if !cfg.Browser.LayoutCheck || db == nil {
+		return nil
+	}
+	return &LayoutMonitor{
+		db:        db,
+		logger:    log.WithModule("layout"),
+		threshold: cfg.Browser.LayoutChangeThreshold,
+		checked:   make(map[string]bool),
+	}
+}
+
+// Check fingerprints page under pageKey (login, search, profile, ...) and
+// compares it with the previous run. Failures are logged, never returned.
+func (m *LayoutMonitor) Check(page Pager, pageKey string) {
+	if m == nil || m.checked[pageKey] {
+		return
+	}
+	m.checked[pageKey] = true
+
+	fingerprint, err := PageFingerprint(page)
+	if err != nil {
+		m.logger.WithError(err).Debug("Failed to fingerprint page")
+		return
+	}
+
+	previous, err := m.db.GetLatestPageFingerprint(pageKey)
+	if err != nil {
+		m.logger.WithError(err).Debug("Failed to load previous page fingerprint")
+	}
+
+	if err := m.db.SavePageFingerprint(pageKey, fingerprint); err != nil {
+		m.logger.WithError(err).Debug("Failed to save page fingerprint")
+	}
+
+	if previous == "" {
+		return
+	}
+
+	distance, err := FingerprintDistance(previous, fingerprint)
+	if err != nil {
+		m.logger.WithError(err).Debug("Failed to compare page fingerprints")
+		return
+	}
+
+	fields := map[string]interface{}{
+		"page":     pageKey,
+		"distance": distance,
+		"previous": previous,
+		"current":  fingerprint,
+	}
+	if distance > m.threshold {
+		m.logger.WithFields(fields).Warn("Page layout changed significantly since last run - selectors may break")
+		return
+	}
+	m.logger.WithFields(fields).Debug("Page layout unchanged")
+}
@@ -0,0 +1,47 @@
+// Package browser - Tests for DOM layout fingerprints
+package browser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSimHashDistance(t *testing.T) {
+	var tokens []string
+	for i := 0; i < 200; i++ {
+		tokens = append(tokens, fmt.Sprintf("div>section.card.block-%d", i))
+	}
+
+	base := simHash(tokens)
+	if same := simHash(append([]string{}, tokens...)); same != base {
+		t.Fatalf("Same skeleton should give the same fingerprint: %s vs %s", base, same)
+	}
+
+	// A small change to the skeleton moves the fingerprint a little
+	tweaked := append(append([]string{}, tokens[:195]...), "main>div.new-banner")
+	small, err := FingerprintDistance(base, simHash(tweaked))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A different layout moves it a lot
+	var other []string
+	for i := 0; i < 200; i++ {
+		other = append(other, fmt.Sprintf("ul>li.redesign-%d", i))
+	}
+	large, err := FingerprintDistance(base, simHash(other))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if small >= large {
+		t.Errorf("Small change (%d bits) should differ less than a redesign (%d bits)", small, large)
+	}
+	if small > 10 {
+		t.Errorf("Small change should stay under the default threshold, got %d bits", small)
+	}
+
+	if _, err := FingerprintDistance(base, "not-hex"); err == nil {
+		t.Error("Expected an error for an invalid fingerprint")
+	}
+}
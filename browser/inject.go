@@ -0,0 +1,41 @@
+// Package browser - inject.go handles user-supplied scripts run in every page
+package browser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-rod/rod"
+)
+
+// ExecuteScriptFile reads a JavaScript file and runs it at the start of every
+// document in the current page and in pages and tabs created later, after
+// the built-in masking script. It lets users add their own fingerprinting
+// evasions without editing getStealthScript.
+func (b *Browser) ExecuteScriptFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read inject script: %w", err)
+	}
+
+	b.injectScript = string(data)
+	b.logger.WithField("path", path).Info("Custom inject script loaded")
+
+	if b.page != nil {
+		b.applyInjectScript(b.page)
+	}
+	return nil
+}
+
+// applyInjectScript registers the custom script on page. It is registered
+// separately from the built-in script so a broken custom script can't
+// disable the masking.
+func (b *Browser) applyInjectScript(page *rod.Page) {
+	if strings.TrimSpace(b.injectScript) == "" {
+		return
+	}
+	if _, err := page.EvalOnNewDocument(b.injectScript); err != nil {
+		b.logger.WithError(err).Warn("Failed to inject custom script")
+	}
+}
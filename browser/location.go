@@ -0,0 +1,44 @@
+// Package browser - location.go handles the geolocation and timezone pages report
+package browser
+
+import (
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// geolocationOrigin is granted the geolocation permission so the override is
+// readable without a prompt
+const geolocationOrigin = "https://www.linkedin.com"
+
+// SetGeolocation makes page report browser.geolocation and the timezone from
+// GetBrowserTimezone, so a proxied session's location and clock agree with
+// its exit IP. Settings left unset keep the host's real values.
+func (b *Browser) SetGeolocation(page *rod.Page) error {
+	if geo := b.config.Browser.Geolocation; geo.Enabled {
+		err := proto.BrowserGrantPermissions{
+			Permissions: []proto.BrowserPermissionType{proto.BrowserPermissionTypeGeolocation},
+			Origin:      geolocationOrigin,
+		}.Call(b.browser)
+		if err != nil {
+			return fmt.Errorf("failed to grant geolocation permission: %w", err)
+		}
+
+		err = proto.EmulationSetGeolocationOverride{
+			Latitude:  &geo.Latitude,
+			Longitude: &geo.Longitude,
+			Accuracy:  &geo.Accuracy,
+		}.Call(page)
+		if err != nil {
+			return fmt.Errorf("failed to override geolocation: %w", err)
+		}
+	}
+
+	if tz := b.config.GetBrowserTimezone(); tz != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: tz}).Call(page); err != nil {
+			return fmt.Errorf("failed to override timezone %s: %w", tz, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Package browser - pager.go handles the page abstraction the managers depend on
+package browser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Pager is the subset of page operations the search, connection and
+// messaging managers call directly. *rod.Page satisfies it in production;
+// tests can substitute a fake to exercise orchestration without a browser.
+type Pager interface {
+	Navigate(url string) error
+	Element(selector string) (*rod.Element, error)
+	Elements(selector string) (rod.Elements, error)
+	WaitLoad() error
+	Eval(js string, args ...interface{}) (*proto.RuntimeRemoteObject, error)
+	Info() (*proto.TargetTargetInfo, error)
+	KeyActions() *rod.KeyActions
+}
+
+var _ Pager = (*rod.Page)(nil)
+
+// RodPage returns the *rod.Page behind p, or nil if p is not backed by a real
+// browser page. Helpers that need the full rod API (timeouts, mouse, stealth)
+// use this.
+func RodPage(p Pager) *rod.Page {
+	page, _ := p.(*rod.Page)
+	return page
+}
+
+// ElementWithin waits up to timeout for selector. Pagers without the rod API
+// behind them are looked up once.
+func ElementWithin(p Pager, timeout time.Duration, selector string) (*rod.Element, error) {
+	if page := RodPage(p); page != nil {
+		return page.Timeout(timeout).Element(selector)
+	}
+	return p.Element(selector)
+}
+
+// ElementRWithin waits up to timeout for an element matching selector whose
+// text matches the jsRegex pattern, like rod's ElementR
+func ElementRWithin(p Pager, timeout time.Duration, selector, pattern string) (*rod.Element, error) {
+	if page := RodPage(p); page != nil {
+		return page.Timeout(timeout).ElementR(selector, pattern)
+	}
+
+	re, err := CompileJSRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	elements, err := p.Elements(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, el := range elements {
+		if text, err := el.Text(); err == nil && re.MatchString(text) {
+			return el, nil
+		}
+	}
+	return nil, &rod.ElementNotFoundError{}
+}
+
+// jsRegexLiteral matches a JS regex literal such as /connect/i
+var jsRegexLiteral = regexp.MustCompile(`^/(.+)/([a-z]*)$`)
+
+// goInlineFlags matches Go's inline flag groups such as (?i), which the
+// browser's RegExp rejects as an invalid group
+var goInlineFlags = regexp.MustCompile(`\(\?[imsU-]+[:)]`)
+
+// CompileJSRegex compiles the jsRegex argument of ElementR, a JS literal such
+// as /connect/i or a bare pattern, with Go's regexp. Go-only inline flags are
+// an error: rod hands the pattern to the browser unchanged, where it throws,
+// so it could only ever match against a fake page.
+func CompileJSRegex(pattern string) (*regexp.Regexp, error) {
+	if goInlineFlags.MatchString(pattern) {
+		return nil, fmt.Errorf("pattern %q uses Go inline flags; write it as a JS literal like /.../i", pattern)
+	}
+	m := jsRegexLiteral.FindStringSubmatch(pattern)
+	if m == nil {
+		return regexp.Compile(pattern)
+	}
+	if strings.Contains(m[2], "i") {
+		return regexp.Compile("(?i)" + m[1])
+	}
+	return regexp.Compile(m[1])
+}
+
+// MustCompileJSRegex is like CompileJSRegex but panics if the pattern is
+// invalid, for package-level patterns
+func MustCompileJSRegex(pattern string) *regexp.Regexp {
+	re, err := CompileJSRegex(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// CurrentURL returns the URL p is showing, or "" if it can't be read
+func CurrentURL(p Pager) string {
+	info, err := p.Info()
+	if err != nil {
+		return ""
+	}
+	return info.URL
+}
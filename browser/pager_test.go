@@ -0,0 +1,47 @@
+// Package browser - Tests for running ElementR patterns against fake pages
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod"
+)
+
+// emptyPager is a fake page with no elements
+type emptyPager struct{ Pager }
+
+func (emptyPager) Elements(string) (rod.Elements, error) { return nil, nil }
+
+func TestCompileJSRegex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		match   bool
+	}{
+		{`/^\s*connect\s*$/i`, " Connect ", true},
+		{`/connect/`, "Connect", false},
+		{`send without a note`, "Send without a note", false},
+		{`/(?:got it|ok)/i`, "OK", true},
+	}
+	for _, tt := range tests {
+		re, err := CompileJSRegex(tt.pattern)
+		if err != nil {
+			t.Fatalf("CompileJSRegex(%q): %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.text); got != tt.match {
+			t.Errorf("%q matching %q = %v, want %v", tt.pattern, tt.text, got, tt.match)
+		}
+	}
+}
+
+func TestElementRWithinRejectsGoFlags(t *testing.T) {
+	// The browser's RegExp throws on (?i), so the fake must not accept it either
+	for _, pattern := range []string{`(?i)connect`, `/(?i)connect/`, `(?s:a.b)`} {
+		if _, err := CompileJSRegex(pattern); err == nil {
+			t.Errorf("CompileJSRegex(%q) should reject Go inline flags", pattern)
+		}
+		if _, err := ElementRWithin(emptyPager{}, 0, "button", pattern); err == nil {
+			t.Errorf("ElementRWithin should reject %q on a fake page", pattern)
+		}
+	}
+}
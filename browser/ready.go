@@ -0,0 +1,107 @@
+// Package browser - ready.go handles waiting for LinkedIn pages to finish hydrating
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	// networkIdleWindow is how long the network must stay quiet to count as idle
+	networkIdleWindow = 500 * time.Millisecond
+
+	// minSelectorCheck keeps a final selector check possible when network idle used up the deadline
+	minSelectorCheck = time.Second
+)
+
+// ErrPageNotReady marks a page that failed to navigate, load or render its
+// content in time; usually a slow or dropped connection
+var ErrPageNotReady = errors.New("page not ready")
+
+// WaitReady waits for the current page to load, go network idle and render any of selectors
+func (b *Browser) WaitReady(selectors ...string) error {
+	return WaitReady(b.page, b.config.GetReadyTimeout(), selectors...)
+}
+
+// NavigateAndWaitReady navigates to url and waits for load, network idle and any of selectors.
+// The network-idle lifecycle listener is registered before navigating so the event can't be missed.
+func NavigateAndWaitReady(pager Pager, url string, timeout time.Duration, selectors ...string) error {
+	page := RodPage(pager)
+	if page == nil {
+		if err := pager.Navigate(url); err != nil {
+			return fmt.Errorf("%w: navigation failed: %w", ErrPageNotReady, err)
+		}
+		return waitPagerReady(pager, selectors)
+	}
+
+	deadline := time.Now().Add(timeout)
+	p := page.Timeout(timeout)
+
+	waitIdle := p.WaitNavigation(proto.PageLifecycleEventNameNetworkIdle)
+	if err := p.Navigate(url); err != nil {
+		return fmt.Errorf("%w: navigation failed: %w", ErrPageNotReady, err)
+	}
+
+	return waitLoadIdleAndSelectors(page, p, deadline, waitIdle, selectors)
+}
+
+// WaitReady waits for load, network idle and any of selectors on a page that is already navigating.
+// Network idle is detected from in-flight requests since the lifecycle event may have already fired.
+func WaitReady(pager Pager, timeout time.Duration, selectors ...string) error {
+	page := RodPage(pager)
+	if page == nil {
+		return waitPagerReady(pager, selectors)
+	}
+
+	deadline := time.Now().Add(timeout)
+	p := page.Timeout(timeout)
+
+	waitIdle := p.WaitRequestIdle(networkIdleWindow, nil, nil, nil)
+
+	return waitLoadIdleAndSelectors(page, p, deadline, waitIdle, selectors)
+}
+
+// waitPagerReady is the readiness check for a Pager without the rod API
+// behind it: load, then a single look for any of selectors
+func waitPagerReady(pager Pager, selectors []string) error {
+	if err := pager.WaitLoad(); err != nil {
+		return fmt.Errorf("%w: page load failed: %w", ErrPageNotReady, err)
+	}
+	if len(selectors) == 0 {
+		return nil
+	}
+	if _, err := pager.Element(strings.Join(selectors, ", ")); err != nil {
+		return fmt.Errorf("%w, none of %v found: %w", ErrPageNotReady, selectors, err)
+	}
+	return nil
+}
+
+// waitLoadIdleAndSelectors runs the shared load -> network idle -> selector sequence.
+// Network idle is best effort: if it never settles, the selectors still decide readiness.
+func waitLoadIdleAndSelectors(page, p *rod.Page, deadline time.Time, waitIdle func(), selectors []string) error {
+	if err := p.WaitLoad(); err != nil {
+		return fmt.Errorf("%w: page load failed: %w", ErrPageNotReady, err)
+	}
+
+	waitIdle()
+
+	if len(selectors) == 0 {
+		return nil
+	}
+
+	remaining := time.Until(deadline)
+	if remaining < minSelectorCheck {
+		remaining = minSelectorCheck
+	}
+
+	_, err := page.Timeout(remaining).Element(strings.Join(selectors, ", "))
+	if err != nil {
+		return fmt.Errorf("%w, none of %v found: %w", ErrPageNotReady, selectors, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Package browser - recover.go handles detecting and recovering from browser crashes
+package browser
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// aliveTimeout bounds how long EnsureAlive waits for the browser to answer
+const aliveTimeout = 5 * time.Second
+
+// SetOnRelaunch registers fn to run after EnsureAlive relaunches the browser,
+// typically to rebind the new page and restore the session
+func (b *Browser) SetOnRelaunch(fn func() error) {
+	b.onRelaunch = fn
+}
+
+// EnsureAlive pings the browser and, if it has died (e.g. Chromium was
+// killed for running out of memory), relaunches it and runs the relaunch
+// hook. It returns an error only when recovery fails; a panic from rod on
+// the dead connection is recovered and treated as a failure to answer.
+func (b *Browser) EnsureAlive() error {
+	if b.browser != nil && b.page != nil {
+		var pingErr error
+		if err := rod.Try(func() {
+			_, pingErr = b.browser.Timeout(aliveTimeout).Pages()
+		}); err != nil {
+			pingErr = err
+		}
+		if pingErr == nil {
+			return nil
+		}
+		b.logger.WithError(pingErr).Warn("Browser is not responding, relaunching")
+	}
+
+	// The old process may be gone already; closing just releases what's left
+	if b.browser != nil {
+		_ = rod.Try(func() { b.browser.Close() })
+	}
+	b.browser = nil
+	b.page = nil
+
+	var launchErr error
+	if err := rod.Try(func() { launchErr = b.Launch() }); err != nil {
+		launchErr = err
+	}
+	if launchErr != nil {
+		return fmt.Errorf("failed to relaunch browser: %w", launchErr)
+	}
+
+	if b.onRelaunch != nil {
+		var hookErr error
+		if err := rod.Try(func() { hookErr = b.onRelaunch() }); err != nil {
+			hookErr = err
+		}
+		if hookErr != nil {
+			return fmt.Errorf("failed to restore state after relaunch: %w", hookErr)
+		}
+	}
+
+	b.logger.Info("Browser recovered")
+	return nil
+}
@@ -0,0 +1,167 @@
+// Package browser - screenshot.go handles audit screenshots of sent actions and
+// screenshots of failed ones
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+)
+
+// CaptureActionScreenshot saves an audit screenshot of the page after a successful send.
+// Files are written to <screenshot_dir>/<YYYY-MM-DD>/<slug>_<HHMMSS>_<action>.png and the
+// oldest screenshots are pruned beyond max_screenshots. Returns "" when disabled.
+func CaptureActionScreenshot(page *rod.Page, cfg *config.BrowserConfig, action string) (string, error) {
+	if !cfg.ScreenshotOnAction || page == nil {
+		return "", nil
+	}
+
+	slug := "unknown"
+	if info, err := page.Info(); err == nil {
+		slug = ProfileSlug(info.URL)
+	}
+	return saveScreenshot(page, cfg, slug, action)
+}
+
+// CaptureErrorScreenshot saves a single screenshot after an action failed,
+// named <slug>_<HHMMSS>_<action>_error.png after the target profile (or the
+// current page when profileURL is empty). Returns "" when
+// screenshot_on_error is off or actionErr is nil.
+func CaptureErrorScreenshot(page *rod.Page, cfg *config.BrowserConfig, action, profileURL string, actionErr error) (string, error) {
+	if !cfg.ScreenshotOnError || actionErr == nil || page == nil {
+		return "", nil
+	}
+
+	if profileURL == "" {
+		if info, err := page.Info(); err == nil {
+			profileURL = info.URL
+		}
+	}
+	return saveScreenshot(page, cfg, ProfileSlug(profileURL), action+"_error")
+}
+
+// ScreenshotOnError saves and logs an error screenshot of p when *err is set
+// on return. Defer it with the action's named error once the action has
+// opened its page: an action that fails its pre-checks, e.g. on a rate limit,
+// never left the previous page, and a screenshot of that is misleading.
+// Errors matching one of skip are deliberate skips, not failures, and get no
+// screenshot either.
+func ScreenshotOnError(p Pager, cfg *config.BrowserConfig, log *logger.Logger, action, profileURL string, err *error, skip ...error) {
+	for _, sentinel := range skip {
+		if errors.Is(*err, sentinel) {
+			return
+		}
+	}
+	path, captureErr := CaptureErrorScreenshot(RodPage(p), cfg, action, profileURL, *err)
+	if captureErr != nil {
+		log.WithError(captureErr).Warn("Failed to capture error screenshot")
+	}
+	if path != "" {
+		log.WithField("filename", path).Info("Error screenshot saved")
+	}
+}
+
+// CaptureDebugScreenshot saves a screenshot of the current page regardless
+// of the screenshot settings, for diagnosing a run that is about to stop
+func CaptureDebugScreenshot(page *rod.Page, cfg *config.BrowserConfig, action string) (string, error) {
+	if page == nil {
+		return "", nil
+	}
+
+	slug := "unknown"
+	if info, err := page.Info(); err == nil {
+		slug = ProfileSlug(info.URL)
+	}
+	return saveScreenshot(page, cfg, slug, action)
+}
+
+// saveScreenshot writes a screenshot to today's folder and prunes old ones
+func saveScreenshot(page *rod.Page, cfg *config.BrowserConfig, slug, action string) (string, error) {
+	now := time.Now()
+	dayDir := filepath.Join(cfg.ScreenshotDir, now.Format("2006-01-02"))
+	if err := os.MkdirAll(dayDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create screenshot directory: %w", err)
+	}
+
+	data, err := page.Screenshot(false, nil)
+	if err != nil {
+		return "", fmt.Errorf("screenshot failed: %w", err)
+	}
+
+	filename := filepath.Join(dayDir, fmt.Sprintf("%s_%s_%s.png", slug, now.Format("150405.000"), action))
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to save screenshot: %w", err)
+	}
+
+	if cfg.MaxScreenshots > 0 {
+		if err := pruneScreenshots(cfg.ScreenshotDir, cfg.MaxScreenshots); err != nil {
+			return filename, fmt.Errorf("failed to prune screenshots: %w", err)
+		}
+	}
+
+	return filename, nil
+}
+
+// ProfileSlug extracts the public identifier from a /in/<slug>/ profile URL
+func ProfileSlug(profileURL string) string {
+	parsed, err := url.Parse(profileURL)
+	if err != nil {
+		return "unknown"
+	}
+
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "in" && parts[i+1] != "" {
+			return parts[i+1]
+		}
+	}
+	return "unknown"
+}
+
+// pruneScreenshots deletes the oldest screenshots under dir so at most max remain
+func pruneScreenshots(dir string, max int) error {
+	type shot struct {
+		path    string
+		modTime time.Time
+	}
+
+	var shots []shot
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".png") {
+			shots = append(shots, shot{path: path, modTime: info.ModTime()})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(shots) <= max {
+		return nil
+	}
+
+	sort.Slice(shots, func(i, j int) bool {
+		return shots[i].modTime.Before(shots[j].modTime)
+	})
+
+	for _, s := range shots[:len(shots)-max] {
+		if err := os.Remove(s.path); err != nil {
+			return err
+		}
+		// Drop the day folder once it's empty; Remove fails harmlessly otherwise
+		os.Remove(filepath.Dir(s.path))
+	}
+	return nil
+}
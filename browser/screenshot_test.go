@@ -0,0 +1,64 @@
+// Package browser - Tests for error screenshots
+package browser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+)
+
+// countScreenshots returns how many .png files are under dir
+func countScreenshots(t *testing.T, dir string) int {
+	t.Helper()
+	n := 0
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && filepath.Ext(path) == ".png" {
+			n++
+		}
+		return nil
+	})
+	return n
+}
+
+func TestScreenshotOnError(t *testing.T) {
+	page := newFixturePage(t)
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cfg := &config.BrowserConfig{ScreenshotOnError: true, ScreenshotDir: t.TempDir()}
+	errSkipped := errors.New("skipped")
+
+	capture := func(err error) {
+		defer ScreenshotOnError(page, cfg, log, "connection", "https://www.linkedin.com/in/jane-doe/", &err, errSkipped)
+	}
+
+	capture(nil)
+	if n := countScreenshots(t, cfg.ScreenshotDir); n != 0 {
+		t.Fatalf("A successful action should not be captured, got %d screenshots", n)
+	}
+
+	capture(errSkipped)
+	if n := countScreenshots(t, cfg.ScreenshotDir); n != 0 {
+		t.Fatalf("A skip sentinel should not be captured, got %d screenshots", n)
+	}
+
+	capture(errors.New("connect button not found"))
+	matches, _ := filepath.Glob(filepath.Join(cfg.ScreenshotDir, "*", "jane-doe_*_connection_error.png"))
+	if len(matches) != 1 {
+		t.Fatalf("Expected one error screenshot named after the profile, got %v", matches)
+	}
+}
+
+func TestScreenshotOnErrorWithoutPage(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cfg := &config.BrowserConfig{ScreenshotOnError: true, ScreenshotDir: t.TempDir()}
+
+	// A pager with no browser page behind it has nothing to capture
+	err := errors.New("failed")
+	ScreenshotOnError(nil, cfg, log, "search", "", &err)
+	if n := countScreenshots(t, cfg.ScreenshotDir); n != 0 {
+		t.Errorf("Expected no screenshots without a page, got %d", n)
+	}
+}
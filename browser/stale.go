@@ -0,0 +1,149 @@
+// Package browser - stale.go handles element handles invalidated by a re-render
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+)
+
+// staleElementMessages are the CDP errors returned for a handle whose node or
+// JS context is gone, e.g. after an infinite-scroll list re-rendered
+var staleElementMessages = []string{
+	"Could not find object with given id",
+	"Cannot find context with specified id",
+	"Execution context was destroyed",
+	"Could not find node with given id",
+	"No node with given id found",
+	"Node is detached from document",
+	"Node with given id does not belong to the document",
+}
+
+// IsStaleElement reports whether err means the element handle no longer
+// refers to a live node, as opposed to the node being hidden, covered or
+// never found
+func IsStaleElement(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var objErr *rod.ObjectNotFoundError
+	if errors.As(err, &objErr) {
+		return true
+	}
+
+	var cdpErr *cdp.Error
+	if errors.As(err, &cdpErr) {
+		for _, msg := range staleElementMessages {
+			if strings.HasPrefix(cdpErr.Message, msg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isStale reports whether err from acting on el came from el going stale.
+// A node removed from the DOM keeps its handle but loses its shape, so an
+// invisible-shape error counts when el is no longer connected.
+func isStale(el *rod.Element, err error) bool {
+	if IsStaleElement(err) {
+		return true
+	}
+
+	var shapeErr *rod.InvisibleShapeError
+	if el == nil || !errors.As(err, &shapeErr) {
+		return false
+	}
+	res, evalErr := el.Eval(`() => !this.isConnected`)
+	if evalErr != nil {
+		return IsStaleElement(evalErr)
+	}
+	return res.Value.Bool()
+}
+
+// RetryStale runs action on el. If it fails because el went stale, el is
+// looked up again with refetch and action runs once more on the fresh
+// handle. Any other error, or a failed refetch, returns the original error.
+func RetryStale(el *rod.Element, refetch func() (*rod.Element, error), action func(*rod.Element) error) error {
+	err := action(el)
+	if err == nil || !isStale(el, err) {
+		return err
+	}
+
+	fresh, refetchErr := refetch()
+	if refetchErr != nil {
+		return fmt.Errorf("%w (element went stale and could not be found again: %v)", err, refetchErr)
+	}
+	return action(fresh)
+}
+
+// ElementByAttribute returns the first element matching selector whose attr
+// equals value. It re-finds an element by something that survives a
+// re-render, such as a profile link's href.
+func ElementByAttribute(p Pager, selector, attr, value string) (*rod.Element, error) {
+	if value == "" {
+		return nil, fmt.Errorf("no %s to find %s by", attr, selector)
+	}
+
+	elements, err := p.Elements(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, el := range elements {
+		if v, err := el.Attribute(attr); err == nil && v != nil && *v == value {
+			return el, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s with %s %q", selector, attr, value)
+}
+
+// cardKeysJS reads, for each element matching selector in document order, the
+// first of attrs found on the element itself or on a descendant
+const cardKeysJS = `(selector, attrs) => Array.from(document.querySelectorAll(selector)).map(card => {
+	for (const attr of attrs) {
+		const el = card.hasAttribute(attr) ? card : card.querySelector('[' + attr + ']');
+		if (el) return {attr: attr, value: el.getAttribute(attr)};
+	}
+	return {attr: '', value: ''};
+})`
+
+// CardKey is an attribute value a card in a list can be found again by after
+// the list re-rendered, such as a result URN or a profile link's href
+type CardKey struct {
+	Attr  string `json:"attr"`
+	Value string `json:"value"`
+}
+
+// CardKeys reads a key for every element matching selector in one round
+// trip, in the order Elements returns them. attrs are tried in order; a card
+// carrying none of them gets an empty key. Returns nil if the page can't be
+// read.
+func CardKeys(p Pager, selector string, attrs ...string) []CardKey {
+	res, err := p.Eval(cardKeysJS, selector, attrs)
+	if err != nil || res == nil {
+		return nil
+	}
+	var keys []CardKey
+	if err := res.Value.Unmarshal(&keys); err != nil {
+		return nil
+	}
+	return keys
+}
+
+// RefetchCard finds a card matching selector again by its key: the element
+// carrying the key is looked up with ElementByAttribute, and the card is the
+// closest element around it (or itself) that matches selector.
+func RefetchCard(p Pager, selector string, key CardKey) (*rod.Element, error) {
+	if key.Attr == "" {
+		return nil, fmt.Errorf("no key to find the %s card by", selector)
+	}
+	el, err := ElementByAttribute(p, "["+key.Attr+"]", key.Attr, key.Value)
+	if err != nil {
+		return nil, err
+	}
+	return el.ElementByJS(rod.Eval(`(selector) => this.closest(selector)`, selector))
+}
@@ -0,0 +1,104 @@
+// Package browser - Tests for stale element classification and refetching
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+)
+
+func TestIsStaleElement(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"object gone", cdp.ErrObjNotFound, true},
+		{"context destroyed", fmt.Errorf("failed to click profile link: %w", cdp.ErrCtxDestroyed), true},
+		{"detached node", &cdp.Error{Code: -32000, Message: "Node is detached from document"}, true},
+		{"rod object", &rod.ObjectNotFoundError{}, true},
+		{"not found", &rod.ElementNotFoundError{}, false},
+		{"other cdp", cdp.ErrNodeNotFoundAtPos, false},
+		{"plain", errors.New("timeout"), false},
+	}
+	for _, tt := range tests {
+		if got := IsStaleElement(tt.err); got != tt.want {
+			t.Errorf("%s: IsStaleElement(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryStale(t *testing.T) {
+	refetchErr := errors.New("gone")
+	tests := []struct {
+		name        string
+		errs        []error // returned by successive action calls
+		refetchErr  error
+		wantCalls   int
+		wantRefetch bool
+		wantErr     error
+	}{
+		{"succeeds", []error{nil}, nil, 1, false, nil},
+		{"other error", []error{cdp.ErrNodeNotFoundAtPos}, nil, 1, false, cdp.ErrNodeNotFoundAtPos},
+		{"stale then ok", []error{cdp.ErrObjNotFound, nil}, nil, 2, true, nil},
+		{"stale twice", []error{cdp.ErrObjNotFound, cdp.ErrCtxNotFound}, nil, 2, true, cdp.ErrCtxNotFound},
+		{"refetch fails", []error{cdp.ErrObjNotFound}, refetchErr, 1, true, cdp.ErrObjNotFound},
+	}
+	for _, tt := range tests {
+		calls, refetched := 0, false
+		err := RetryStale(nil, func() (*rod.Element, error) {
+			refetched = true
+			return nil, tt.refetchErr
+		}, func(*rod.Element) error {
+			calls++
+			return tt.errs[calls-1]
+		})
+
+		if calls != tt.wantCalls || refetched != tt.wantRefetch {
+			t.Errorf("%s: %d calls, refetched %v; want %d, %v", tt.name, calls, refetched, tt.wantCalls, tt.wantRefetch)
+		}
+		if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRefetchCardAfterRerender(t *testing.T) {
+	page := newFixturePage(t)
+	list := `<ul>
+<li class="card" data-chameleon-result-urn="urn:li:member:1"><a href="/in/ada/">Ada</a></li>
+<li class="card"><a href="/in/grace/">Grace</a></li>
+</ul>`
+	if err := page.SetDocumentContent(list); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := CardKeys(page, ".card", "data-chameleon-result-urn", "href")
+	want := []CardKey{{"data-chameleon-result-urn", "urn:li:member:1"}, {"href", "/in/grace/"}}
+	if len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] {
+		t.Fatalf("CardKeys = %v, want %v", keys, want)
+	}
+
+	// Re-render the list in the opposite order; the old positions now point
+	// at the other person
+	if _, err := page.Eval(`() => {
+		const ul = document.querySelector('ul');
+		ul.replaceChildren(...Array.from(ul.children).reverse().map(li => li.cloneNode(true)));
+	}`); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, name := range []string{"Ada", "Grace"} {
+		card, err := RefetchCard(page, ".card", keys[i])
+		if err != nil {
+			t.Fatalf("RefetchCard(%v): %v", keys[i], err)
+		}
+		if text, _ := card.Text(); text != name {
+			t.Errorf("RefetchCard(%v) found %q, want %q", keys[i], text, name)
+		}
+	}
+}
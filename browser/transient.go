@@ -0,0 +1,26 @@
+// Package browser - transient.go handles telling network failures from ones retrying won't fix
+package browser
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/go-rod/rod"
+)
+
+// IsTransient reports whether err looks like a slow or dropped connection:
+// a page that never became ready, a timeout, a network error or a failed
+// navigation. Those may pass on a later attempt; a missing button or a
+// profile that was already invited won't.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	var navErr *rod.NavigationError
+	return errors.Is(err, ErrPageNotReady) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &netErr) ||
+		errors.As(err, &navErr)
+}
@@ -0,0 +1,30 @@
+// Package browser - Tests for classifying transient failures
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-rod/rod"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{fmt.Errorf("failed to navigate to profile: %w", fmt.Errorf("%w: page load failed: %w", ErrPageNotReady, errors.New("eof"))), true},
+		{fmt.Errorf("wait: %w", context.DeadlineExceeded), true},
+		{&rod.NavigationError{Reason: "net::ERR_CONNECTION_RESET"}, true},
+		{errors.New("connect button not found"), false},
+		{fmt.Errorf("failed to click: %w", &rod.ElementNotFoundError{}), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
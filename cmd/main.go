@@ -4,13 +4,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/go-rod/rod"
 	"github.com/joho/godotenv"
 	"github.com/nikshitha/linkedin-automation-poc/auth"
 	"github.com/nikshitha/linkedin-automation-poc/browser"
@@ -36,18 +45,184 @@ type Application struct {
 	searcher    *search.Searcher
 	connector   *connection.ConnectionManager
 	messenger   *messaging.MessagingManager
+	tracer      *logger.Tracer
+	pause       *stealth.PauseController
+	watchdog    *stealth.Watchdog // nil when schedule.watchdog_minutes is 0
+
+	// ctx is cancelled when -max-duration elapses
+	ctx       context.Context
+	cancel    context.CancelFunc
+	startedAt time.Time
+
+	// Outcomes of the modes run so far, aggregated by Run
+	result *ApplicationResult
+}
+
+// SearchOutcome is what a search found
+type SearchOutcome struct {
+	Found int `json:"found"`
+}
+
+// ConnectOutcome counts a connect run's requests. Skipped profiles were
+// already invited or filtered out before sending.
+type ConnectOutcome struct {
+	Search  SearchOutcome `json:"search"`
+	Sent    int           `json:"sent"`
+	Failed  int           `json:"failed"`
+	Skipped int           `json:"skipped"`
+}
+
+// MessageOutcome counts a message run's messages. Skipped recipients were
+// messaged too recently.
+type MessageOutcome struct {
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+// WithdrawOutcome counts the invitations a withdraw run took back
+type WithdrawOutcome struct {
+	Withdrawn int `json:"withdrawn"`
+}
+
+// QueueOutcome counts the queued tasks a run worked through
+type QueueOutcome struct {
+	Done   int `json:"done"`
+	Failed int `json:"failed"`
+}
+
+// ApplicationResult aggregates the outcomes of every mode a run executed;
+// full mode adds each cycle's steps into it
+type ApplicationResult struct {
+	Mode     string          `json:"mode"`
+	Cycles   int             `json:"cycles,omitempty"`
+	Search   SearchOutcome   `json:"search"`
+	Connect  ConnectOutcome  `json:"connect"`
+	Message  MessageOutcome  `json:"message"`
+	Withdraw WithdrawOutcome `json:"withdraw"`
+	Queue    QueueOutcome    `json:"queue"`
+	Duration time.Duration   `json:"duration"`
+}
+
+// AddSearch adds a search's outcome
+func (r *ApplicationResult) AddSearch(o SearchOutcome) {
+	r.Search.Found += o.Found
+}
+
+// AddConnect adds a connect run's outcome, including the search it ran
+func (r *ApplicationResult) AddConnect(o ConnectOutcome) {
+	r.AddSearch(o.Search)
+	r.Connect.Sent += o.Sent
+	r.Connect.Failed += o.Failed
+	r.Connect.Skipped += o.Skipped
+}
+
+// AddMessage adds a message run's outcome
+func (r *ApplicationResult) AddMessage(o MessageOutcome) {
+	r.Message.Sent += o.Sent
+	r.Message.Failed += o.Failed
+	r.Message.Skipped += o.Skipped
+}
+
+// AddWithdraw adds a withdraw run's outcome
+func (r *ApplicationResult) AddWithdraw(o WithdrawOutcome) {
+	r.Withdraw.Withdrawn += o.Withdrawn
+}
+
+// AddQueue adds a queue run's outcome
+func (r *ApplicationResult) AddQueue(o QueueOutcome) {
+	r.Queue.Done += o.Done
+	r.Queue.Failed += o.Failed
+}
+
+// Succeeded counts the actions that went through
+func (r *ApplicationResult) Succeeded() int {
+	return r.Connect.Sent + r.Message.Sent + r.Withdraw.Withdrawn + r.Queue.Done
+}
+
+// Failed counts the actions that were attempted and failed
+func (r *ApplicationResult) Failed() int {
+	return r.Connect.Failed + r.Message.Failed + r.Queue.Failed
+}
+
+// exitAllFailed is the exit code when every attempted action failed, which
+// usually means LinkedIn changed its markup
+const exitAllFailed = 2
+
+// exitWatchdog is the exit code when the watchdog stopped a stuck run
+const exitWatchdog = 3
+
+// ExitCode is 0 unless actions were attempted and none succeeded
+func (r *ApplicationResult) ExitCode() int {
+	if r.Failed() > 0 && r.Succeeded() == 0 {
+		return exitAllFailed
+	}
+	return 0
+}
+
+// Fields returns the result as log fields
+func (r *ApplicationResult) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"mode":                r.Mode,
+		"cycles":              r.Cycles,
+		"profiles_found":      r.Search.Found,
+		"connects_sent":       r.Connect.Sent,
+		"connects_failed":     r.Connect.Failed,
+		"connects_skipped":    r.Connect.Skipped,
+		"messages_sent":       r.Message.Sent,
+		"messages_failed":     r.Message.Failed,
+		"messages_skipped":    r.Message.Skipped,
+		"withdrawn":           r.Withdraw.Withdrawn,
+		"queued_tasks_done":   r.Queue.Done,
+		"queued_tasks_failed": r.Queue.Failed,
+		"duration":            r.Duration.Round(time.Second).String(),
+	}
 }
 
 // Command line flags
 var (
-	configPath  = flag.String("config", "config.yaml", "Path to configuration file")
-	mode        = flag.String("mode", "interactive", "Run mode: interactive, search, connect, message, full, demo")
-	searchQuery = flag.String("search", "", "Search query (job title, keywords)")
-	company     = flag.String("company", "", "Company filter for search")
-	location    = flag.String("location", "", "Location filter for search")
-	maxResults  = flag.Int("max-results", 25, "Maximum search results")
-	dryRun      = flag.Bool("dry-run", false, "Dry run mode - no actual actions")
-	verbose     = flag.Bool("verbose", false, "Enable verbose logging")
+	configPath     = flag.String("config", "config.yaml", "Path to configuration file")
+	account        = flag.String("account", "", "Name of the configured account to run as (isolates data under data/<account>/)")
+	mode           = flag.String("mode", "interactive", "Run mode: interactive, search, connect, message, first-degree, full, demo, withdraw, queue")
+	searchQuery    = flag.String("search", "", "Search query (job title, keywords)")
+	searchURL      = flag.String("search-url", "", "Raw LinkedIn search URL to collect results from (overrides -search)")
+	company        = flag.String("company", "", "Company filter for search")
+	location       = flag.String("location", "", "Location filter for search")
+	maxResults     = flag.Int("max-results", 25, "Maximum search results")
+	network        = flag.String("network", "", "Comma-separated connection degrees to search, e.g. 2nd,3rd")
+	resumeSearch   = flag.Bool("resume-search", false, "Continue the search from the page the last run of the same query stopped at")
+	minMutual      = flag.Int("min-mutual", 0, "Skip profiles with fewer than N mutual connections in connect mode")
+	countOnly      = flag.Bool("count", false, "Print how many results the -search query has, then exit")
+	dryRun         = flag.Bool("dry-run", false, "Dry run mode - no actual actions")
+	maxDuration    = flag.Duration("max-duration", 0, "Stop cleanly after this wall-clock time, e.g. 90m (0 = no limit)")
+	verbose        = flag.Bool("verbose", false, "Enable verbose logging")
+	verboseActions = flag.Bool("verbose-actions", false, "Log every planned delay, break and cooldown with its duration and reason")
+	dbCheck        = flag.Bool("db-check", false, "Validate and migrate the database schema, then exit")
+	showStats      = flag.Bool("stats", false, "Print activity and acceptance-time statistics from the database, then exit")
+	initConfig     = flag.Bool("init", false, "Write a documented default config (or validate an existing one), then exit")
+	// Report flags
+	reportPath = flag.String("report", "", "Write a self-contained HTML activity report to this file, then exit")
+	reportFrom = flag.String("report-from", "", "First day of the report, YYYY-MM-DD (default: 29 days before -report-to)")
+	reportTo   = flag.String("report-to", "", "Last day of the report, YYYY-MM-DD (default: today)")
+
+	selfTest    = flag.Bool("selftest", false, "Launch the browser, check the anti-detection layer against a test page and print pass/fail per check, then exit")
+	selfTestURL = flag.String("selftest-url", "", "Page -selftest loads, e.g. a fingerprint echo or bot-detection page (default: a built-in local page)")
+
+	cookiesCmd = flag.String("cookies", "", "Inspect or reset the stored session: show (names, domains, expiry; values masked) or clear, then exit")
+
+	exportPath = flag.String("export", "", "Export data to a CSV file whose name selects the type, e.g. connections.csv, then exit")
+	// Profile tagging flags
+	tagProfile  = flag.String("profile", "", "Profile URL to change with -tag, -untag or -note, then exit")
+	addTag      = flag.String("tag", "", "Tag to add to -profile")
+	removeTag   = flag.String("untag", "", "Tag to remove from -profile")
+	setNote     = flag.String("note", "", "Notes to store on -profile (replaces existing notes)")
+	listTagged  = flag.String("tagged", "", "List stored profiles carrying this tag, then exit")
+	previewNote = flag.String("preview-note", "", "Print the connection note(s) and follow-up message rendered for this stored profile URL, then exit")
+	// Task queue flags
+	profilesFromDB = flag.String("profiles-from-db", "", "In message mode, direct-message stored profiles matching tag:X,company:Y,degree:1st instead of new connections; in first-degree mode, limits the campaign to them")
+
+	enqueue   = flag.String("enqueue", "", "Queue a task as type:profile-url (type is connect, message or view), then exit")
+	enqueueAt = flag.String("enqueue-at", "", "When the queued task becomes due, \"YYYY-MM-DD HH:MM\" local time (default: now)")
 	// Demo mode flags
 	demoName        = flag.String("demo-name", "Shreeya Khatri", "Name to search for in demo mode")
 	demoInstitution = flag.String("demo-institution", "IIIT Sonepat", "Institution filter for demo mode")
@@ -59,13 +234,26 @@ func main() {
 	// Print banner
 	printBanner()
 
+	if _, err := search.ParseNetwork(*network); err != nil {
+		fmt.Printf("Invalid -network: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Load environment variables from .env file
 	if err := godotenv.Load(); err != nil {
 		fmt.Println("Note: No .env file found, using environment variables")
 	}
 
+	if *initConfig {
+		if err := runInit(*configPath, *account); err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
+	cfg, err := config.LoadConfigForAccount(*configPath, *account)
 	if err != nil {
 		fmt.Printf("Failed to load configuration: %v\n", err)
 		fmt.Println("\nPlease ensure you have set LINKEDIN_EMAIL and LINKEDIN_PASSWORD environment variables")
@@ -79,18 +267,104 @@ func main() {
 	}
 
 	// Initialize logger
-	log, err := logger.New(logger.Config{
+	logCfg := logger.Config{
 		Level:      cfg.Logging.Level,
 		Format:     cfg.Logging.Format,
 		OutputFile: cfg.Logging.OutputFile,
-	})
+
+		NarrateDelays: *verboseActions,
+	}
+	if cfg.Logging.Redact {
+		logCfg.RedactFields = cfg.Logging.RedactFields
+		logCfg.RedactHash = cfg.Logging.RedactHash
+	}
+	log, err := logger.New(logCfg)
 	if err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
 
+	if *account != "" {
+		log = log.WithField("account", *account)
+	}
+
+	for _, fix := range cfg.Messaging.TemplateCorrections() {
+		log.Warnf("Corrected template field casing (%s); update config.yaml to silence this", fix)
+	}
+
+	if *dbCheck {
+		if err := runDatabaseCheck(cfg, log); err != nil {
+			log.Errorf("Database check failed: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *showStats {
+		if err := runStats(cfg, log); err != nil {
+			log.Errorf("Failed to read statistics: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *selfTest {
+		passed, err := runSelfTest(cfg, log)
+		if err != nil {
+			log.Errorf("Self-test failed to run: %v", err)
+			os.Exit(1)
+		}
+		if !passed {
+			os.Exit(exitAllFailed)
+		}
+		return
+	}
+
+	if *cookiesCmd != "" {
+		if err := runCookies(cfg, log); err != nil {
+			log.Errorf("Failed to %s cookies: %v", *cookiesCmd, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *exportPath != "" {
+		if err := runExport(cfg, log); err != nil {
+			log.Errorf("Failed to export: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *tagProfile != "" || *listTagged != "" {
+		if err := runTags(cfg, log); err != nil {
+			log.Errorf("Failed to update tags: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *previewNote != "" {
+		if err := runPreviewNote(cfg, log); err != nil {
+			log.Errorf("Failed to preview note: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *enqueue != "" {
+		if err := runEnqueue(cfg, log); err != nil {
+			log.Errorf("Failed to queue task: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	log.Info("LinkedIn Automation PoC starting...")
 	log.Infof("Mode: %s", *mode)
+	if dump, err := cfg.RedactedYAML(); err == nil {
+		log.Infof("Effective configuration (secrets masked):\n%s", dump)
+	}
 
 	// Create application
 	app, err := NewApplication(cfg, log)
@@ -99,18 +373,396 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The report only reads the database; the browser is never launched
+	if *reportPath != "" {
+		from, to, err := reportRange(*reportFrom, *reportTo)
+		if err == nil {
+			err = app.GenerateReport(*reportPath, from, to)
+		}
+		app.Close()
+		if err != nil {
+			log.Errorf("Failed to generate report: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Handle graceful shutdown
 	setupGracefulShutdown(app)
+	app.tracer.DumpOnSignal(cfg.Logging.TraceDir, log)
+	app.pause.ToggleOnSignal()
 
 	// Run the application
-	if err := app.Run(); err != nil {
+	result, err := app.Run()
+	log.WithFields(result.Fields()).Info("Run result")
+	if err != nil {
 		log.Errorf("Application error: %v", err)
+		if path, dumpErr := app.tracer.DumpToDir(cfg.Logging.TraceDir); dumpErr == nil {
+			log.WithField("path", path).Info("Trace dumped")
+		}
 		os.Exit(1)
 	}
 
+	if code := result.ExitCode(); code != 0 {
+		log.Errorf("All %d attempted actions failed", result.Failed())
+		os.Exit(code)
+	}
+
 	log.Info("Application completed successfully")
 }
 
+// runInit scaffolds a documented config file and JSON schema, or validates
+// the config if one already exists
+func runInit(path, accountName string) error {
+	if _, err := os.Stat(path); err == nil {
+		fmt.Printf("Config file %s already exists, validating it...\n", path)
+		if _, err := config.LoadConfigForAccount(path, accountName); err != nil {
+			return fmt.Errorf("invalid configuration: %w\n\nFix the value above in %s (run -init with a new -config path to see the documented defaults)", err, path)
+		}
+		fmt.Println("Configuration is valid.")
+		return nil
+	}
+
+	if err := config.WriteDefaultConfig(path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	schema, err := config.JSONSchema()
+	if err != nil {
+		return fmt.Errorf("failed to build config schema: %w", err)
+	}
+	schemaPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".schema.json"
+	if err := os.WriteFile(schemaPath, schema, 0644); err != nil {
+		return fmt.Errorf("failed to write config schema: %w", err)
+	}
+
+	fmt.Printf("Wrote default configuration to %s (schema: %s)\n", path, schemaPath)
+	fmt.Println("\nNext steps:")
+	fmt.Println("  1. Set LINKEDIN_EMAIL and LINKEDIN_PASSWORD in your environment or a .env file")
+	fmt.Println("  2. Review the search and rate limit settings in the config file")
+	fmt.Println("  3. Run again with -init to validate your changes")
+	return nil
+}
+
+// runDatabaseCheck opens the database (applying pending migrations) and verifies
+// its integrity without launching the browser
+func runDatabaseCheck(cfg *config.Config, log *logger.Logger) error {
+	log.WithField("path", cfg.Storage.DatabasePath).Info("Checking database")
+
+	db, err := storage.NewDatabase(cfg.Storage.DatabasePath, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := db.CheckIntegrity(); err != nil {
+		return err
+	}
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Database OK (schema version %d)", version)
+	return nil
+}
+
+// runStats prints stored activity statistics without starting a browser
+func runStats(cfg *config.Config, log *logger.Logger) error {
+	db, err := storage.NewDatabase(cfg.Storage.DatabasePath, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	logDailyStats(db, cfg, log)
+	if err := logAcceptanceLatency(db, log); err != nil {
+		return err
+	}
+	return logExperimentResults(db, log)
+}
+
+// runSelfTest launches the browser with the full stealth setup, loads the
+// -selftest-url page (or a built-in local one) and prints each check's
+// result. It reports whether every check passed.
+func runSelfTest(cfg *config.Config, log *logger.Logger) (bool, error) {
+	url := *selfTestURL
+	if url == "" {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return false, fmt.Errorf("failed to start local test page: %w", err)
+		}
+		server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, stealth.SelfTestPage)
+		})}
+		go server.Serve(listener)
+		defer server.Close()
+		url = "http://" + listener.Addr().String() + "/"
+	}
+
+	stealthMgr := stealth.NewStealthManager(&cfg.Stealth, log)
+	browserMgr := browser.NewBrowser(cfg, log, stealthMgr)
+	if err := browserMgr.Launch(); err != nil {
+		return false, fmt.Errorf("failed to launch browser: %w", err)
+	}
+	defer browserMgr.Close()
+
+	if err := browserMgr.Navigate(url); err != nil {
+		return false, fmt.Errorf("failed to load %s: %w", url, err)
+	}
+
+	fmt.Printf("Stealth self-test against %s\n", url)
+	passed := true
+	for _, check := range stealthMgr.RunSelfTest(browserMgr.GetPage()) {
+		status := "PASS"
+		if !check.Passed {
+			status = "FAIL"
+			passed = false
+		}
+		fmt.Printf("  %s  %-28s %s\n", status, check.Name, check.Detail)
+	}
+	return passed, nil
+}
+
+// exporters maps -export file names (minus .csv) to the data they write
+var exporters = map[string]func(db *storage.Database, w io.Writer) error{
+	"connections": (*storage.Database).ExportConnectionsCSV,
+}
+
+// runExport writes the CSV export selected by the -export file name
+func runExport(cfg *config.Config, log *logger.Logger) error {
+	name := strings.ToLower(filepath.Base(*exportPath))
+	kind := strings.TrimSuffix(name, ".csv")
+	export, ok := exporters[kind]
+	if !ok || kind == name {
+		return fmt.Errorf("-export must name a CSV export, e.g. connections.csv")
+	}
+
+	db, err := storage.NewDatabase(cfg.Storage.DatabasePath, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	file, err := os.Create(*exportPath)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := export(db, file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	fmt.Printf("Exported %s to %s\n", kind, *exportPath)
+	return nil
+}
+
+// runCookies shows or clears the session cookies stored in the database and
+// the cookies file (clearing also wipes the browser profile's cookie stores),
+// without launching the browser
+func runCookies(cfg *config.Config, log *logger.Logger) error {
+	if *cookiesCmd != "show" && *cookiesCmd != "clear" {
+		return fmt.Errorf("-cookies must be show or clear")
+	}
+
+	db, err := storage.NewDatabase(cfg.Storage.DatabasePath, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *cookiesCmd == "clear" {
+		cleared, err := db.ClearCookies()
+		if err != nil {
+			return err
+		}
+		if err := db.RemoveCookiesFile(cfg.Storage.CookiesPath); err != nil {
+			return err
+		}
+		profileFiles, err := browser.ClearProfileCookies(cfg.Browser.UserDataDir)
+		if err != nil {
+			return fmt.Errorf("failed to clear the browser profile's cookies: %w", err)
+		}
+		fmt.Printf("Cleared %d cookies from the database, removed %s and %d cookie files from %s; the next run logs in again\n",
+			cleared, cfg.Storage.CookiesPath, len(profileFiles), cfg.Browser.UserDataDir)
+		return nil
+	}
+
+	stored, err := db.LoadCookies()
+	if err != nil {
+		return fmt.Errorf("failed to load cookies from the database: %w", err)
+	}
+	fromFile, err := db.LoadCookiesFromFile(cfg.Storage.CookiesPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", cfg.Storage.CookiesPath, err)
+	}
+
+	now := time.Now()
+	for _, source := range []struct {
+		name    string
+		cookies []*storage.SessionCookie
+	}{
+		{"database", stored},
+		{cfg.Storage.CookiesPath, fromFile},
+	} {
+		fmt.Printf("%s: %d cookies\n", source.name, len(source.cookies))
+		for _, c := range source.cookies {
+			fmt.Printf("  %-24s %-22s %-34s value <%d chars>\n", c.Name, c.Domain, formatCookieExpiry(c.Expires, now), len(c.Value))
+		}
+	}
+	return nil
+}
+
+// formatCookieExpiry describes a cookie's expiry (Unix seconds, 0 or less for
+// a session cookie) relative to now
+func formatCookieExpiry(expires int64, now time.Time) string {
+	if expires <= 0 {
+		return "session"
+	}
+	at := time.Unix(expires, 0)
+	if !at.After(now) {
+		return "expired " + at.Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("expires %s (in %s)", at.Format("2006-01-02 15:04"), formatDays(at.Sub(now)))
+}
+
+// formatDays renders a duration in whole days, or hours under a day
+func formatDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// runTags applies -tag, -untag and -note to -profile, or lists -tagged profiles
+func runTags(cfg *config.Config, log *logger.Logger) error {
+	db, err := storage.NewDatabase(cfg.Storage.DatabasePath, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *tagProfile != "" {
+		profileURL := search.CleanProfileURL(*tagProfile)
+		if *addTag == "" && *removeTag == "" && *setNote == "" {
+			return fmt.Errorf("-profile needs -tag, -untag or -note")
+		}
+
+		if *addTag != "" {
+			if err := db.AddTag(profileURL, *addTag); err != nil {
+				return err
+			}
+		}
+		if *removeTag != "" {
+			if err := db.RemoveTag(profileURL, *removeTag); err != nil {
+				return err
+			}
+		}
+		if *setNote != "" {
+			if err := db.SetProfileNotes(profileURL, *setNote); err != nil {
+				return err
+			}
+		}
+
+		tags, err := db.GetTags(profileURL)
+		if err != nil {
+			return err
+		}
+		log.WithFields(map[string]interface{}{
+			"profile_url": profileURL,
+			"tags":        strings.Join(tags, ", "),
+		}).Info("Profile updated")
+	}
+
+	if *listTagged != "" {
+		profiles, err := db.GetProfilesByTag(*listTagged)
+		if err != nil {
+			return err
+		}
+		log.Infof("%d profiles tagged %q", len(profiles), *listTagged)
+		for _, p := range profiles {
+			log.WithFields(map[string]interface{}{
+				"profile_url": p.ProfileURL,
+				"name":        p.Name,
+				"company":     p.Company,
+				"notes":       p.Notes,
+			}).Info("Tagged profile")
+		}
+	}
+
+	return nil
+}
+
+// runPreviewNote renders the connection note variants and follow-up message
+// for a stored profile without launching the browser
+func runPreviewNote(cfg *config.Config, log *logger.Logger) error {
+	db, err := storage.NewDatabase(cfg.Storage.DatabasePath, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	profileURL := search.CleanProfileURL(*previewNote)
+	profile, err := db.GetProfile(profileURL)
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return fmt.Errorf("profile not found: %s (run a search that finds it first)", profileURL)
+	}
+
+	connector := connection.NewConnectionManager(cfg, log, nil, nil, db)
+	notes, err := connector.PreviewNotes(search.ResultFromProfile(profile))
+	if err != nil {
+		return err
+	}
+	messenger := messaging.NewMessagingManager(cfg, log, nil, nil, db)
+	followUp, err := messenger.PreviewFollowUp(profile)
+	if err != nil {
+		return fmt.Errorf("follow-up message: %w", err)
+	}
+
+	fmt.Printf("Profile: %s (%s)\n", profile.Name, profileURL)
+	if missing := emptyProfileFields(profile); len(missing) > 0 {
+		fmt.Printf("Empty fields (templates using them may read oddly): %s\n", strings.Join(missing, ", "))
+	}
+
+	variants := make([]string, 0, len(notes))
+	for id := range notes {
+		variants = append(variants, id)
+	}
+	sort.Strings(variants)
+	for _, id := range variants {
+		fmt.Printf("\nConnection note [%s] (%d chars):\n%s\n", id, len([]rune(notes[id])), notes[id])
+	}
+	fmt.Printf("\nFollow-up message (%d chars):\n%s\n", len([]rune(followUp)), followUp)
+	return nil
+}
+
+// emptyProfileFields lists the template fields a stored profile has no value for
+func emptyProfileFields(p *storage.Profile) []string {
+	fields := []struct{ name, value string }{
+		{"FirstName", p.FirstName},
+		{"LastName", p.LastName},
+		{"Company", p.Company},
+		{"Headline", p.Headline},
+		{"Location", p.Location},
+	}
+
+	var missing []string
+	for _, f := range fields {
+		if strings.TrimSpace(f.value) == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	return missing
+}
+
 // NewApplication creates and initializes a new application instance
 func NewApplication(cfg *config.Config, log *logger.Logger) (*Application, error) {
 	// Initialize database
@@ -124,10 +776,20 @@ func NewApplication(cfg *config.Config, log *logger.Logger) (*Application, error
 
 	// Initialize rate limiter
 	rateLimiter := stealth.NewRateLimiter(&cfg.RateLimits, log)
+	if cfg.Logging.Format != "json" {
+		rateLimiter.SetProgressInterval(time.Duration(cfg.Logging.ProgressIntervalMinutes) * time.Minute)
+	}
 
 	// Initialize scheduler
 	scheduler := stealth.NewScheduler(&cfg.Schedule, log)
 
+	// A fixed seed makes the whole run's randomness reproducible
+	if cfg.Stealth.RandomSeed != 0 {
+		rateLimiter.SetSeed(cfg.Stealth.RandomSeed)
+		scheduler.SetSeed(cfg.Stealth.RandomSeed)
+		log.WithField("seed", cfg.Stealth.RandomSeed).Warn("Using a fixed random seed - behavior repeats across runs")
+	}
+
 	// Initialize browser
 	browserMgr := browser.NewBrowser(cfg, log, stealthMgr)
 
@@ -136,6 +798,9 @@ func NewApplication(cfg *config.Config, log *logger.Logger) (*Application, error
 
 	// Initialize searcher
 	searchMgr := search.NewSearcher(cfg, log, stealthMgr, rateLimiter, db)
+	if err := searchMgr.WarmCache(); err != nil {
+		log.WithError(err).Warn("Failed to warm the dedup cache, checking the database per profile")
+	}
 
 	// Initialize connection manager
 	connMgr := connection.NewConnectionManager(cfg, log, stealthMgr, rateLimiter, db)
@@ -143,7 +808,42 @@ func NewApplication(cfg *config.Config, log *logger.Logger) (*Application, error
 	// Initialize messaging manager
 	msgMgr := messaging.NewMessagingManager(cfg, log, stealthMgr, rateLimiter, db)
 
-	return &Application{
+	// Share one decision tracer across all components
+	tracer := logger.NewTracer(cfg.Logging.TraceBufferSize)
+	stealthMgr.SetTracer(tracer)
+	rateLimiter.SetTracer(tracer)
+	scheduler.SetTracer(tracer)
+	searchMgr.SetTracer(tracer)
+	connMgr.SetTracer(tracer)
+	msgMgr.SetTracer(tracer)
+
+	// Respect limits LinkedIn reported in earlier runs
+	if until, err := db.GetBlockedUntil("connection"); err != nil {
+		log.WithError(err).Warn("Failed to load stored invitation limit")
+	} else {
+		rateLimiter.BlockUntil("connection", until)
+	}
+	if until, err := db.GetBlockedUntil("search"); err != nil {
+		log.WithError(err).Warn("Failed to load stored search limit")
+	} else {
+		rateLimiter.BlockUntil("search", until)
+	}
+	rateLimiter.SetWeeklyConnectionSource(db.GetWeeklyConnectionCount)
+
+	// Warn early when LinkedIn changes the layout of pages we scrape
+	layoutMonitor := browser.NewLayoutMonitor(cfg, log, db)
+	authMgr.SetLayoutMonitor(layoutMonitor)
+	searchMgr.SetLayoutMonitor(layoutMonitor)
+	connMgr.SetLayoutMonitor(layoutMonitor)
+
+	// Operator pause/resume between actions
+	pause := stealth.NewPauseController(&cfg.Schedule, log)
+	pause.SetTracer(tracer)
+	connMgr.SetPauseController(pause)
+	msgMgr.SetPauseController(pause)
+	searchMgr.SetPauseController(pause)
+
+	app := &Application{
 		config:      cfg,
 		logger:      log,
 		browser:     browserMgr,
@@ -155,14 +855,45 @@ func NewApplication(cfg *config.Config, log *logger.Logger) (*Application, error
 		searcher:    searchMgr,
 		connector:   connMgr,
 		messenger:   msgMgr,
-	}, nil
+		tracer:      tracer,
+		pause:       pause,
+	}
+	pause.SetKeepAlive(app.keepSessionWarm)
+
+	app.setupWatchdog()
+
+	return app, nil
 }
 
 // Run executes the application based on the selected mode
-func (app *Application) Run() error {
+func (app *Application) Run() (*ApplicationResult, error) {
+	app.startedAt = time.Now()
+	app.result = &ApplicationResult{Mode: *mode}
+	defer func() { app.result.Duration = time.Since(app.startedAt) }()
+
+	return app.result, app.run()
+}
+
+// run launches the browser, logs in and executes the selected mode
+func (app *Application) run() error {
+	if *maxDuration > 0 {
+		app.ctx, app.cancel = context.WithTimeout(context.Background(), *maxDuration)
+		app.logger.Infof("Run will stop after %s", *maxDuration)
+	} else {
+		app.ctx, app.cancel = context.WithCancel(context.Background())
+	}
+	defer app.cancel()
+
+	app.searcher.SetContext(app.ctx)
+	app.connector.SetContext(app.ctx)
+	app.messenger.SetContext(app.ctx)
+
 	// Check operating hours if scheduling is enabled
 	if app.config.Schedule.Enabled {
-		app.scheduler.WaitForOperatingHours()
+		if !app.waitUnlessDone(app.scheduler.WaitForOperatingHours) {
+			app.logRunSummary()
+			return nil
+		}
 	}
 
 	// Launch browser
@@ -171,13 +902,16 @@ func (app *Application) Run() error {
 	}
 	defer app.Close()
 
-	// Set page references for all managers
-	page := app.browser.GetPage()
-	app.auth.SetBrowser(app.browser.GetBrowser())
-	app.auth.SetPage(page)
-	app.searcher.SetPage(page)
-	app.connector.SetPage(page)
-	app.messenger.SetPage(page)
+	// Set page references for all managers, and again after a crash recovery
+	app.bindPage()
+	app.browser.SetOnRelaunch(func() error {
+		app.bindPage()
+		return app.auth.RefreshSession()
+	})
+	app.connector.SetOnPageChange(func(page *rod.Page) {
+		app.browser.SetPage(page)
+		app.bindPage()
+	})
 
 	// Authenticate
 	app.logger.Info("Authenticating with LinkedIn...")
@@ -186,39 +920,90 @@ func (app *Application) Run() error {
 	}
 	app.logger.Info("Authentication successful!")
 
+	// Look for warning banners before doing anything else
+	if err := app.auth.CheckAccountHealth(); err != nil {
+		return fmt.Errorf("stopping run: %w", err)
+	}
+
 	// Log current user info
 	if user, err := app.auth.GetCurrentUser(); err == nil {
-		app.logger.Infof("Logged in as: %s", user["name"])
+		app.logger.WithField("name", user["name"]).Info("Logged in")
+	}
+
+	app.applyAccountMaturity()
+
+	if *countOnly {
+		return app.runCount()
 	}
 
 	// Show daily stats
 	app.showDailyStats()
 
+	// Interactive mode has no actions to make progress on, so only the
+	// automated modes run under the watchdog
+	if *mode != "interactive" {
+		app.startWatchdog()
+
+		// The browser may have died while waiting on the schedule or the stats
+		if err := app.browser.EnsureAlive(); err != nil {
+			return fmt.Errorf("browser crashed and could not be recovered: %w", err)
+		}
+	}
+
 	// Execute based on mode
 	switch *mode {
 	case "interactive":
 		return app.runInteractiveMode()
 	case "search":
-		return app.runSearchMode()
+		outcome, err := app.runSearchMode()
+		app.result.AddSearch(outcome)
+		return err
 	case "connect":
-		return app.runConnectMode()
+		outcome, err := app.runConnectMode()
+		app.result.AddConnect(outcome)
+		return err
 	case "message":
-		return app.runMessageMode()
+		outcome, err := app.runMessageMode()
+		app.result.AddMessage(outcome)
+		return err
+	case "first-degree":
+		outcome, err := app.runFirstDegreeMode()
+		app.result.AddMessage(outcome)
+		return err
 	case "full":
 		return app.runFullWorkflow()
 	case "demo":
 		return app.runDemoMode()
+	case "withdraw":
+		outcome, err := app.runWithdrawMode()
+		app.result.AddWithdraw(outcome)
+		return err
+	case "queue":
+		outcome, err := app.drainTaskQueue()
+		app.result.AddQueue(outcome)
+		return err
 	default:
 		return fmt.Errorf("unknown mode: %s", *mode)
 	}
 }
 
+// bindPage points every manager at the browser's current page
+func (app *Application) bindPage() {
+	page := app.browser.GetPage()
+	app.auth.SetBrowser(app.browser.GetBrowser())
+	app.auth.SetPage(page)
+	app.searcher.SetPage(page)
+	app.searcher.SetTabOpener(app.browser.NewTab)
+	app.connector.SetPage(page)
+	app.messenger.SetPage(page)
+}
+
 // runDemoMode runs the assignment demo workflow
 // Login → Go to Connections → Filter by institution → Search by name → Open profile
 func (app *Application) runDemoMode() error {
 	app.logger.Info("=== Running Assignment Demo Mode ===")
 	app.logger.Infof("Institution Filter: %s", *demoInstitution)
-	app.logger.Infof("Profile to find: %s", *demoName)
+	app.logger.WithField("name", *demoName).Info("Profile to find")
 
 	// Execute the demo workflow
 	if err := app.connector.NavigateAndOpenProfile(*demoInstitution, *demoName); err != nil {
@@ -230,7 +1015,9 @@ func (app *Application) runDemoMode() error {
 	app.logger.Info("Press Ctrl+C to exit when done viewing.")
 
 	// Keep browser open for user to view
-	select {}
+	<-app.ctx.Done()
+	app.logRunSummary()
+	return nil
 }
 
 // runInteractiveMode runs an interactive session
@@ -239,176 +1026,647 @@ func (app *Application) runInteractiveMode() error {
 	app.logger.Info("Browser is open. You can interact manually or close to exit.")
 	app.logger.Info("Press Ctrl+C to exit")
 
-	// Keep running until interrupted
-	select {}
+	// Keep running until interrupted (or -max-duration elapses)
+	<-app.ctx.Done()
+	app.logRunSummary()
+	return nil
 }
 
 // runSearchMode runs search-only mode
-func (app *Application) runSearchMode() error {
+func (app *Application) runSearchMode() (SearchOutcome, error) {
 	app.logger.Info("Running in search mode")
 
+	if *searchURL != "" {
+		results, err := app.searcher.SearchByURL(*searchURL, *maxResults)
+		if err != nil {
+			return SearchOutcome{}, fmt.Errorf("search failed: %w", err)
+		}
+		app.enrichResults(results)
+		app.saveSearchResults(results)
+		return SearchOutcome{Found: len(results)}, nil
+	}
+
+	params := app.searchParams()
+	if params.JobTitle == "" {
+		return SearchOutcome{}, fmt.Errorf("no search query provided (use -search flag or set in config)")
+	}
+
+	results, err := app.searcher.Search(params)
+	if err != nil {
+		return SearchOutcome{}, fmt.Errorf("search failed: %w", err)
+	}
+
+	app.enrichResults(results)
+	app.saveSearchResults(results)
+	return SearchOutcome{Found: len(results)}, nil
+}
+
+// enrichResults opens each result for its full details when
+// search.enrich_workers is set; profiles that fail keep their card details
+func (app *Application) enrichResults(results []*search.SearchResult) {
+	if app.config.Search.EnrichWorkers == 0 {
+		return
+	}
+	app.searcher.EnrichProfiles(results, app.config.Search.EnrichWorkers)
+}
+
+// runCount prints the total number of results for the search query
+func (app *Application) runCount() error {
+	if *searchURL != "" {
+		return fmt.Errorf("-count works with -search and its filters, not -search-url")
+	}
+	params := app.searchParams()
+	if params.JobTitle == "" {
+		return fmt.Errorf("no search query provided (use -search flag or set in config)")
+	}
+
+	count, err := app.searcher.CountResults(params)
+	if err != nil {
+		return fmt.Errorf("failed to count results: %w", err)
+	}
+	fmt.Printf("%d results for %q\n", count, params.JobTitle)
+	return nil
+}
+
+// searchParams builds search parameters from flags and config defaults
+func (app *Application) searchParams() search.SearchParams {
 	query := *searchQuery
 	if query == "" {
 		query = app.config.Search.DefaultJobTitle
 	}
 
-	if query == "" {
-		return fmt.Errorf("no search query provided (use -search flag or set in config)")
-	}
+	// Validated at startup
+	networkFilter, _ := search.ParseNetwork(*network)
 
-	params := search.SearchParams{
+	return search.SearchParams{
 		JobTitle:   query,
 		Company:    *company,
 		Location:   *location,
 		Keywords:   app.config.Search.Keywords,
+		Network:    networkFilter,
 		MaxResults: *maxResults,
+		Resume:     *resumeSearch,
 	}
+}
 
-	results, err := app.searcher.Search(params)
-	if err != nil {
-		return fmt.Errorf("search failed: %w", err)
-	}
-
+// saveSearchResults logs and persists collected search results
+func (app *Application) saveSearchResults(results []*search.SearchResult) {
 	app.logger.Infof("Found %d profiles", len(results))
 
 	// Save profiles to database
 	for _, result := range results {
 		app.searcher.SaveProfile(result)
-		app.logger.Infof("  - %s (%s) - %s", result.Name, result.Connection, result.ProfileURL)
+		app.logger.WithFields(map[string]interface{}{
+			"name":        result.Name,
+			"degree":      result.Connection,
+			"score":       result.Score,
+			"profile_url": result.ProfileURL,
+		}).Info("Found profile")
 	}
-
-	return nil
 }
 
 // runConnectMode runs connection-only mode
-func (app *Application) runConnectMode() error {
+func (app *Application) runConnectMode() (ConnectOutcome, error) {
 	app.logger.Info("Running in connect mode")
 
-	// First, do a search
-	if err := app.runSearchMode(); err != nil {
-		return err
+	// First, do a search. Past LinkedIn's monthly search limit, work through
+	// the profiles already saved instead.
+	var outcome ConnectOutcome
+	searched, err := app.runSearchMode()
+	outcome.Search = searched
+	if err != nil {
+		if !errors.Is(err, search.ErrSearchLimitReached) {
+			return outcome, err
+		}
+		app.logger.WithError(err).Warn("Search unavailable, continuing with saved profiles")
 	}
 
 	// Get profiles that haven't been connected
 	profiles, err := app.db.GetAllProfiles()
 	if err != nil {
-		return fmt.Errorf("failed to get profiles: %w", err)
+		return outcome, fmt.Errorf("failed to get profiles: %w", err)
 	}
 
-	var toConnect []*search.SearchResult
+	var candidates []*search.SearchResult
 	for _, p := range profiles {
-		hasSent, _ := app.db.HasSentConnectionRequest(p.ProfileURL)
+		candidates = append(candidates, search.ResultFromProfile(p))
+	}
+	total := len(candidates)
+
+	// Filter before previewing so the preview counts only profiles the run
+	// could actually send to
+	if *minMutual > 0 {
+		before := len(candidates)
+		candidates = search.FilterByMinMutualConnections(candidates, *minMutual)
+		app.logger.Infof("Skipped %d profiles with fewer than %d mutual connections", before-len(candidates), *minMutual)
+	}
+
+	var incomplete int
+	candidates, incomplete = search.FilterIncomplete(candidates, app.config.Search.SkipMissingHeadline, app.config.Search.SkipMissingCompany)
+	if incomplete > 0 {
+		app.logger.Infof("Skipped %d profiles missing a headline or company", incomplete)
+	}
+
+	app.logBulkPreview(app.connector.PreviewBulk(candidates))
+
+	var toConnect []*search.SearchResult
+	for _, candidate := range candidates {
+		// Any earlier request counts, so declined and withdrawn profiles are never retried
+		hasSent, _ := app.db.HasSentConnectionRequest(candidate.ProfileURL)
 		if !hasSent {
-			toConnect = append(toConnect, &search.SearchResult{
-				ProfileURL: p.ProfileURL,
-				Name:       p.Name,
-				FirstName:  p.FirstName,
-				LastName:   p.LastName,
-				Headline:   p.Headline,
-				Company:    p.Company,
-				Location:   p.Location,
-				Connection: p.ConnectionDegree,
-			})
+			toConnect = append(toConnect, candidate)
 		}
 	}
+	toConnect = app.connector.FilterByDegree(toConnect)
+
+	app.searcher.RankResults(toConnect, app.searchParams())
+	outcome.Skipped = total - len(toConnect)
 
 	if len(toConnect) == 0 {
 		app.logger.Info("No new profiles to connect with")
-		return nil
+		return outcome, nil
 	}
 
 	app.logger.Infof("Sending connection requests to %d profiles", len(toConnect))
 
 	if *dryRun {
 		app.logger.Info("Dry run mode - skipping actual connections")
-		return nil
+		return outcome, nil
 	}
 
-	sent, failed, err := app.connector.SendBulkConnectionRequests(toConnect, "")
+	outcome.Sent, outcome.Failed, err = app.connector.SendBulkConnectionRequests(toConnect, "")
 	if err != nil {
-		return err
+		return outcome, err
 	}
 
-	app.logger.Infof("Connection requests: %d sent, %d failed", sent, failed)
-	return nil
+	app.logger.Infof("Connection requests: %d sent, %d failed", outcome.Sent, outcome.Failed)
+	return outcome, nil
+}
+
+// logBulkPreview reports how many requests a connect run is expected to send
+func (app *Application) logBulkPreview(preview connection.BulkPreview) {
+	fields := map[string]interface{}{
+		"profiles":          preview.Total,
+		"already_sent":      preview.AlreadySent,
+		"already_connected": preview.AlreadyConnected,
+		"wrong_degree":      preview.WrongDegree,
+		"eligible":          preview.Eligible,
+		"remaining_today":   preview.RemainingToday,
+		"will_send":         preview.WillSend,
+	}
+	if !preview.BlockedUntil.IsZero() {
+		fields["blocked_until"] = preview.BlockedUntil.Format("2006-01-02 15:04")
+	}
+	app.logger.WithFields(fields).Infof("Connect preview: %d of %d profiles will be sent a request", preview.WillSend, preview.Total)
 }
 
 // runMessageMode runs messaging-only mode
-func (app *Application) runMessageMode() error {
+func (app *Application) runMessageMode() (MessageOutcome, error) {
 	app.logger.Info("Running in message mode")
 
+	if *profilesFromDB != "" {
+		return app.messageProfilesFromDB()
+	}
+
 	if *dryRun {
 		app.logger.Info("Dry run mode - skipping actual messages")
-		return nil
+		return MessageOutcome{}, nil
+	}
+
+	return app.processNewConnections()
+}
+
+// processNewConnections follows up with newly accepted connections
+func (app *Application) processNewConnections() (MessageOutcome, error) {
+	sent, failed, err := app.messenger.ProcessNewConnectionsWorkflow()
+	return MessageOutcome{Sent: sent, Failed: failed}, err
+}
+
+// messageProfilesFromDB sends the direct message template to the stored
+// profiles matching -profiles-from-db
+func (app *Application) messageProfilesFromDB() (MessageOutcome, error) {
+	filter, err := storage.ParseProfileFilter(*profilesFromDB)
+	if err != nil {
+		return MessageOutcome{}, fmt.Errorf("invalid -profiles-from-db: %w", err)
+	}
+
+	profiles, err := app.db.GetProfiles(filter)
+	if err != nil {
+		return MessageOutcome{}, err
+	}
+	app.logger.Infof("Found %d stored profiles matching %q", len(profiles), *profilesFromDB)
+
+	if *dryRun {
+		app.logger.Infof("Dry run mode - would message up to %d of them", len(profiles))
+		return MessageOutcome{}, nil
+	}
+
+	var outcome MessageOutcome
+	outcome.Sent, outcome.Failed, outcome.Skipped, err = app.messenger.SendBulkDirectMessages(profiles)
+	return outcome, err
+}
+
+// runFirstDegreeMode direct-messages the 1st-degree connections among the
+// stored profiles (those matching -profiles-from-db when set), skipping
+// everyone else
+func (app *Application) runFirstDegreeMode() (MessageOutcome, error) {
+	app.logger.Info("Running in first-degree messaging mode")
+
+	// Without -profiles-from-db every stored profile is a candidate
+	var filter storage.ProfileFilter
+	if *profilesFromDB != "" {
+		parsed, err := storage.ParseProfileFilter(*profilesFromDB)
+		if err != nil {
+			return MessageOutcome{}, fmt.Errorf("invalid -profiles-from-db: %w", err)
+		}
+		filter = parsed
+	}
+	stored, err := app.db.GetProfiles(filter)
+	if err != nil {
+		return MessageOutcome{}, err
+	}
+
+	profiles := make([]*search.SearchResult, len(stored))
+	for i, p := range stored {
+		profiles[i] = search.ResultFromProfile(p)
+	}
+	app.logger.Infof("Checking %d stored profiles for 1st-degree connections", len(profiles))
+
+	if *dryRun {
+		app.logger.Info("Dry run mode - skipping actual messages")
+		return MessageOutcome{}, nil
+	}
+
+	var outcome MessageOutcome
+	outcome.Sent, outcome.Failed, outcome.Skipped, err = app.messenger.ProcessFirstDegreeMessaging(profiles)
+	return outcome, err
+}
+
+// runWithdrawMode withdraws all pending requests to the -company company
+func (app *Application) runWithdrawMode() (WithdrawOutcome, error) {
+	if *company == "" {
+		return WithdrawOutcome{}, fmt.Errorf("withdraw mode requires -company")
+	}
+	app.logger.WithField("company", *company).Info("Running in withdraw mode")
+
+	if *dryRun {
+		requests, err := app.db.GetPendingConnectionRequestsByCompany(*company)
+		if err != nil {
+			return WithdrawOutcome{}, err
+		}
+		app.logger.Infof("Dry run mode - would withdraw %d pending requests", len(requests))
+		return WithdrawOutcome{}, nil
 	}
 
-	return app.messenger.ProcessNewConnectionsWorkflow()
+	withdrawn, err := app.connector.WithdrawPendingByCompany(*company)
+	return WithdrawOutcome{Withdrawn: withdrawn}, err
 }
 
 // runFullWorkflow runs the complete automation workflow
 func (app *Application) runFullWorkflow() error {
 	app.logger.Info("Running full workflow")
 	sessionStart := time.Now()
+	var runErr error
 
 	for {
+		if !app.pause.WaitWhilePaused(app.ctx) {
+			break
+		}
+
 		// Check if we should take a break
 		if app.scheduler.ShouldTakeBreak(sessionStart) {
-			app.scheduler.TakeBreak()
+			if !app.waitUnlessDone(app.scheduler.TakeBreak) {
+				break
+			}
 			sessionStart = time.Now()
 		}
 
 		// Check operating hours
 		if !app.scheduler.IsWithinOperatingHours() {
 			app.logger.Info("Outside operating hours, waiting...")
-			app.scheduler.WaitForOperatingHours()
-		}
-
-		// 1. Check for newly accepted connections and send follow-ups
-		app.logger.Info("Step 1: Processing new connections...")
-		if err := app.messenger.ProcessNewConnectionsWorkflow(); err != nil {
-			app.logger.WithError(err).Warn("Failed to process new connections")
+			if !app.waitUnlessDone(app.scheduler.WaitForOperatingHours) {
+				break
+			}
 		}
 
-		// 2. Search for new profiles
-		app.logger.Info("Step 2: Searching for new profiles...")
-		if err := app.runSearchMode(); err != nil {
-			app.logger.WithError(err).Warn("Search failed")
+		// Keep long runs from expiring mid-session
+		if err := app.auth.KeepAlive(); errors.Is(err, auth.ErrSessionExpired) {
+			app.logger.Warn("Session expired, logging in again")
+			if err := app.auth.RefreshSession(); err != nil {
+				runErr = fmt.Errorf("session expired and re-authentication failed: %w", err)
+				break
+			}
+		} else if err != nil {
+			// A slow feed isn't worth failing the run over
+			app.logger.WithError(err).Warn("Keep-alive failed")
 		}
 
-		// 3. Send connection requests
-		if app.connector.GetRemainingConnections() > 0 {
-			app.logger.Info("Step 3: Sending connection requests...")
-			if err := app.runConnectMode(); err != nil {
-				app.logger.WithError(err).Warn("Failed to send connections")
+		// Run the cycle's steps, possibly in a shuffled order
+		stopped := false
+		for i, step := range app.stealth.OrderSteps(app.prioritizedWorkflowSteps()) {
+			app.logger.Infof("Step %d: %s...", i+1, step.Name)
+			if err := app.browser.EnsureAlive(); err != nil {
+				runErr = fmt.Errorf("browser crashed and could not be recovered: %w", err)
+				stopped = true
+				break
+			}
+			if err := step.Run(); errors.Is(err, search.ErrSearchLimitReached) {
+				app.logger.WithError(err).Infof("Skipping %s until the search limit resets", step.Name)
+			} else if err != nil {
+				app.logger.WithError(err).Warnf("Step %s failed", step.Name)
+			}
+			if !app.pause.WaitWhilePaused(app.ctx) {
+				stopped = true
+				break
 			}
-		} else {
-			app.logger.Info("Daily connection limit reached")
 		}
+		if stopped {
+			break
+		}
+		app.result.Cycles++
+		app.watchdog.Reset()
 
 		// Show stats
 		app.showDailyStats()
 
 		// Cooldown before next cycle
 		app.logger.Info("Workflow cycle complete. Starting cooldown...")
-		app.rateLimiter.EnforceCooldown()
+		if !app.waitUnlessDone(app.rateLimiter.EnforceCooldown) {
+			break
+		}
+	}
+
+	app.logRunSummary()
+	return runErr
+}
+
+// workflowSteps returns the steps of one workflow cycle. Connecting depends
+// on the search having run first; the other steps are independent.
+func (app *Application) workflowSteps() []stealth.WorkflowStep {
+	return []stealth.WorkflowStep{
+		{
+			// Tasks queued with -enqueue
+			Name: "run queued tasks",
+			Run: func() error {
+				outcome, err := app.drainTaskQueue()
+				app.result.AddQueue(outcome)
+				return err
+			},
+		},
+		{
+			// Check for newly accepted connections and send follow-ups
+			Name: "process new connections",
+			Run: func() error {
+				outcome, err := app.processNewConnections()
+				app.result.AddMessage(outcome)
+				return err
+			},
+		},
+		{
+			// Never re-invite people who declined; needs acceptances recorded first
+			Name:  "detect declined invitations",
+			After: []string{"process new connections"},
+			Run: func() error {
+				_, err := app.connector.DetectDeclines()
+				return err
+			},
+		},
+		{
+			Name: "search for new profiles",
+			Run: func() error {
+				outcome, err := app.runSearchMode()
+				app.result.AddSearch(outcome)
+				return err
+			},
+		},
+		{
+			Name:  "send connection requests",
+			After: []string{"search for new profiles"},
+			Run: func() error {
+				if app.connector.GetRemainingConnections() <= 0 {
+					app.logger.Info("Daily connection limit reached")
+					return nil
+				}
+				outcome, err := app.runConnectMode()
+				// The search step already counted what was found
+				outcome.Search = SearchOutcome{}
+				app.result.AddConnect(outcome)
+				return err
+			},
+		},
 	}
 }
 
+// Workflow step groups reordered by rate_limits.low_budget_priority
+var (
+	messageSteps    = []string{"process new connections"}
+	connectionSteps = []string{"search for new profiles", "send connection requests"}
+)
+
+// prioritizedWorkflowSteps returns the cycle's steps, moving the preferred
+// group first when both daily budgets are nearly spent so the remaining
+// budget goes to the higher-value actions
+func (app *Application) prioritizedWorkflowSteps() []stealth.WorkflowStep {
+	steps := app.workflowSteps()
+
+	var first, then []string
+	switch app.rateLimiter.LowBudgetPriority() {
+	case "messages":
+		first, then = messageSteps, connectionSteps
+	case "connections":
+		first, then = connectionSteps, messageSteps
+	default:
+		return steps
+	}
+
+	app.logger.WithFields(map[string]interface{}{
+		"remaining_connections": app.rateLimiter.GetRemainingActions("connection"),
+		"remaining_messages":    app.rateLimiter.GetRemainingActions("message"),
+		"first":                 first,
+	}).Info("Daily budgets are low, prioritizing steps")
+	return prioritizeSteps(steps, first, then)
+}
+
+// prioritizeSteps moves the steps named in first ahead of the others, keeping
+// their relative order, and makes each step named in then wait on all of
+// first so shuffling can't undo the priority
+func prioritizeSteps(steps []stealth.WorkflowStep, first, then []string) []stealth.WorkflowStep {
+	inFirst := make(map[string]bool, len(first))
+	for _, name := range first {
+		inFirst[name] = true
+	}
+	inThen := make(map[string]bool, len(then))
+	for _, name := range then {
+		inThen[name] = true
+	}
+
+	var head, tail []stealth.WorkflowStep
+	for _, step := range steps {
+		if inThen[step.Name] {
+			step.After = append(append([]string(nil), step.After...), first...)
+		}
+		if inFirst[step.Name] {
+			head = append(head, step)
+		} else {
+			tail = append(tail, step)
+		}
+	}
+	return append(head, tail...)
+}
+
+// keepSessionWarm browses the feed briefly so a paused session stays active
+func (app *Application) keepSessionWarm() {
+	page := app.browser.GetPage()
+	if page == nil {
+		return
+	}
+
+	err := browser.NavigateAndWaitReady(page, auth.LinkedInFeedURL, app.config.GetReadyTimeout(),
+		".scaffold-layout__main", "main")
+	if err != nil {
+		app.logger.WithError(err).Debug("Feed not ready during keep-alive")
+		return
+	}
+	app.stealth.PageLoadDelay()
+
+	for i := 0; i < 3; i++ {
+		app.stealth.HumanScroll(page, "down", 400)
+		app.stealth.ThinkingDelay()
+	}
+	app.stealth.RandomMouseWander(page)
+}
+
+// waitUnlessDone runs a blocking wait (break, cooldown, schedule) and returns
+// false if the run context finishes first
+func (app *Application) waitUnlessDone(wait func()) bool {
+	defer app.watchdog.Suspend()()
+
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-app.ctx.Done():
+		return false
+	}
+}
+
+// logRunSummary reports why the run stopped along with today's activity
+func (app *Application) logRunSummary() {
+	elapsed := time.Since(app.startedAt).Round(time.Second)
+	if errors.Is(app.ctx.Err(), context.DeadlineExceeded) {
+		app.logger.Infof("Max duration %s reached after %s, stopping", *maxDuration, elapsed)
+	} else {
+		app.logger.Infof("Run finished after %s", elapsed)
+	}
+	app.showDailyStats()
+}
+
+// applyAccountMaturity determines the account's age and applies the warmup ramp
+// to the rate limiter. Without a known age the configured limits are used as-is.
+func (app *Application) applyAccountMaturity() {
+	maturity := &app.config.RateLimits.AccountMaturity
+	if !maturity.Enabled {
+		return
+	}
+
+	created, ok, err := maturity.CreatedDate()
+	if err != nil {
+		app.logger.WithError(err).Warn("Invalid account_created, skipping warmup ramp")
+		return
+	}
+	if !ok {
+		created, err = app.auth.GetAccountJoinDate()
+		if err != nil {
+			app.logger.WithError(err).Warn("Could not detect account age; set rate_limits.account_maturity.account_created to enable the warmup ramp")
+			return
+		}
+		app.logger.WithField("account_created", created.Format("2006-01-02")).
+			Info("Detected the account's join date; set rate_limits.account_maturity.account_created to skip the profile visit on later runs")
+	}
+
+	ageDays := int(time.Since(created).Hours() / 24)
+	app.rateLimiter.SetAccountAge(ageDays)
+}
+
 // showDailyStats displays today's activity statistics
 func (app *Application) showDailyStats() {
-	stats, err := app.db.GetTodayStats()
+	logDailyStats(app.db, app.config, app.logger)
+}
+
+// logDailyStats logs today's activity against the configured limits
+func logDailyStats(db *storage.Database, cfg *config.Config, log *logger.Logger) {
+	stats, err := db.GetTodayStats()
 	if err != nil {
-		app.logger.WithError(err).Warn("Failed to get daily stats")
+		log.WithError(err).Warn("Failed to get daily stats")
 		return
 	}
 
-	app.logger.Info("=== Today's Activity ===")
-	app.logger.Infof("  Connections Sent: %d / %d", stats.ConnectionsSent, app.config.RateLimits.MaxConnectionsPerDay)
-	app.logger.Infof("  Connections Accepted: %d", stats.ConnectionsAccepted)
-	app.logger.Infof("  Messages Sent: %d / %d", stats.MessagesSent, app.config.RateLimits.MaxMessagesPerDay)
-	app.logger.Infof("  Profiles Viewed: %d / %d", stats.ProfilesViewed, app.config.RateLimits.MaxProfileViewsPerDay)
-	app.logger.Infof("  Searches: %d", stats.SearchesPerformed)
-	app.logger.Info("========================")
+	log.Info("=== Today's Activity ===")
+	log.Infof("  Connections Sent: %d / %d", stats.ConnectionsSent, cfg.RateLimits.MaxConnectionsPerDay)
+	if weekly, err := db.GetWeeklyConnectionCount(); err != nil {
+		log.WithError(err).Warn("Failed to get weekly connection count")
+	} else if cfg.RateLimits.MaxConnectionsPerWeek > 0 {
+		log.Infof("  Connections This Week: %d / %d", weekly, cfg.RateLimits.MaxConnectionsPerWeek)
+	} else {
+		log.Infof("  Connections This Week: %d", weekly)
+	}
+	log.Infof("  Connections Accepted: %d", stats.ConnectionsAccepted)
+	log.Infof("  Messages Sent: %d / %d", stats.MessagesSent, cfg.RateLimits.MaxMessagesPerDay)
+	log.Infof("  Profiles Viewed: %d / %d (+%d while connecting/messaging)", stats.ProfilesViewed, cfg.RateLimits.MaxProfileViewsPerDay, stats.IncidentalProfileViews)
+	log.Infof("  Searches: %d", stats.SearchesPerformed)
+	log.Info("========================")
+}
+
+// logAcceptanceLatency logs how long accepted invitations took to be accepted
+func logAcceptanceLatency(db *storage.Database, log *logger.Logger) error {
+	latency, err := db.GetAcceptanceLatencyStats()
+	if err != nil {
+		return err
+	}
+
+	log.Info("=== Acceptance Time ===")
+	if latency.Count == 0 {
+		log.Info("  No accepted invitations yet")
+	} else {
+		log.Infof("  Accepted Invitations: %d", latency.Count)
+		log.Infof("  Median: %.1f days", latency.Median)
+		log.Infof("  Mean: %.1f days", latency.Mean)
+		log.Infof("  90th Percentile: %.1f days", latency.P90)
+		log.Infof("  Range: %.1f - %.1f days", latency.Min, latency.Max)
+	}
+	log.Info("=======================")
+	return nil
+}
+
+// logExperimentResults logs the acceptance rate of each connection note variant
+func logExperimentResults(db *storage.Database, log *logger.Logger) error {
+	results, err := db.GetExperimentResults()
+	if err != nil {
+		return err
+	}
+
+	log.Info("=== Note Experiments ===")
+	if len(results) == 0 {
+		log.Info("  No requests recorded with a note variant yet")
+	}
+
+	variants := make([]string, 0, len(results))
+	for variant := range results {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+
+	for _, variant := range variants {
+		stats := results[variant]
+		log.Infof("  %s: %d/%d accepted (%.1f%%)", variant, stats.Accepted, stats.Sent, stats.AcceptanceRate*100)
+	}
+	log.Info("========================")
+	return nil
 }
 
 // Close cleans up application resources
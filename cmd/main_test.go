@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/connection"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+)
+
+func TestApplicationResult(t *testing.T) {
+	result := &ApplicationResult{Mode: "full"}
+	result.AddConnect(ConnectOutcome{Search: SearchOutcome{Found: 10}, Sent: 3, Failed: 1, Skipped: 6})
+	result.AddSearch(SearchOutcome{Found: 5})
+	result.AddMessage(MessageOutcome{Sent: 2, Skipped: 1})
+	result.AddQueue(QueueOutcome{Done: 1, Failed: 2})
+
+	if result.Search.Found != 15 {
+		t.Errorf("Expected the connect run's search to be counted, got %d found", result.Search.Found)
+	}
+	if result.Succeeded() != 6 || result.Failed() != 3 {
+		t.Errorf("Expected 6 succeeded and 3 failed, got %d and %d", result.Succeeded(), result.Failed())
+	}
+	if code := result.ExitCode(); code != 0 {
+		t.Errorf("Partial success should exit 0, got %d", code)
+	}
+
+	failed := &ApplicationResult{Mode: "connect"}
+	failed.AddConnect(ConnectOutcome{Failed: 4})
+	if code := failed.ExitCode(); code != exitAllFailed {
+		t.Errorf("Expected exit code %d when every action failed, got %d", exitAllFailed, code)
+	}
+
+	if code := (&ApplicationResult{Mode: "search"}).ExitCode(); code != 0 {
+		t.Errorf("A run with no actions should exit 0, got %d", code)
+	}
+}
+
+func TestPrioritizeSteps(t *testing.T) {
+	steps := []stealth.WorkflowStep{
+		{Name: "run queued tasks"},
+		{Name: "process new connections"},
+		{Name: "search for new profiles"},
+		{Name: "send connection requests", After: []string{"search for new profiles"}},
+	}
+
+	got := prioritizeSteps(steps, connectionSteps, messageSteps)
+	var names []string
+	for _, step := range got {
+		names = append(names, step.Name)
+	}
+	want := []string{"search for new profiles", "send connection requests", "run queued tasks", "process new connections"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected order %v, got %v", want, names)
+	}
+
+	if after := got[3].After; !reflect.DeepEqual(after, connectionSteps) {
+		t.Errorf("Expected messaging to wait on %v, got %v", connectionSteps, after)
+	}
+	if after := got[1].After; !reflect.DeepEqual(after, []string{"search for new profiles"}) {
+		t.Errorf("Connect step dependencies should be unchanged, got %v", after)
+	}
+	if len(steps[1].After) != 0 {
+		t.Error("prioritizeSteps must not modify the input steps")
+	}
+}
+
+func TestFormatCookieExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		expires int64
+		want    string
+	}{
+		{0, "session"},
+		{-1, "session"},
+		{now.Add(-time.Minute).Unix(), "expired 2026-10-16 11:59"},
+		{now.Add(5 * time.Hour).Unix(), "expires 2026-10-16 17:00 (in 5h)"},
+		{now.Add(72*time.Hour + time.Minute).Unix(), "expires 2026-10-19 12:01 (in 3d)"},
+	}
+	for _, tt := range tests {
+		if got := formatCookieExpiry(tt.expires, now); got != tt.want {
+			t.Errorf("formatCookieExpiry(%d) = %q, want %q", tt.expires, got, tt.want)
+		}
+	}
+}
+
+func TestRetryableTaskError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{connection.ErrAlreadySent, false},
+		{errors.New("connect button not found"), false},
+		{connection.ErrRateLimited, true},
+		{fmt.Errorf("send failed: %w", browser.ErrPageNotReady), true},
+		{fmt.Errorf("%w: %d sent to %s today", connection.ErrCompanyLimitReached, 3, "Acme"), true},
+	}
+	for _, tt := range tests {
+		if got := retryableTaskError(tt.err); got != tt.want {
+			t.Errorf("retryableTaskError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
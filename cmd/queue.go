@@ -0,0 +1,163 @@
+// Package main - queue.go handles the persistent task queue
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/connection"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+	"github.com/nikshitha/linkedin-automation-poc/search"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+// runEnqueue adds the -enqueue task to the persistent task queue
+func runEnqueue(cfg *config.Config, log *logger.Logger) error {
+	taskType, profileURL, ok := strings.Cut(*enqueue, ":")
+	if !ok || !storage.IsTaskType(taskType) {
+		return fmt.Errorf("-enqueue must be connect:<url>, message:<url> or view:<url>")
+	}
+
+	due := time.Now()
+	if *enqueueAt != "" {
+		t, err := time.ParseInLocation("2006-01-02 15:04", *enqueueAt, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid -enqueue-at (want YYYY-MM-DD HH:MM): %w", err)
+		}
+		due = t
+	}
+
+	db, err := storage.NewDatabase(cfg.Storage.DatabasePath, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	profileURL = search.CleanProfileURL(profileURL)
+	id, err := db.EnqueueTask(taskType, profileURL, due)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Queued task %d: %s %s (due %s)\n", id, taskType, profileURL, due.Format("2006-01-02 15:04"))
+	return nil
+}
+
+// taskRetryDelay is how long a failed queued task waits before its next attempt
+const taskRetryDelay = time.Hour
+
+// retryableTaskError reports whether a queued task that failed with err may
+// succeed later: the page or network failed, or a limit that resets was
+// reached. Anything else, like a request already sent or a missing button,
+// fails the task for good.
+func retryableTaskError(err error) bool {
+	return browser.IsTransient(err) ||
+		errors.Is(err, stealth.ErrRateLimited) ||
+		errors.Is(err, connection.ErrInvitationLimit) ||
+		errors.Is(err, connection.ErrCompanyLimitReached)
+}
+
+// taskActions maps queued task types to the rate-limited action they perform
+var taskActions = map[string]string{
+	storage.TaskConnect: "connection",
+	storage.TaskMessage: "message",
+	storage.TaskView:    "profile_view",
+}
+
+// drainTaskQueue runs due tasks from the persistent queue until none are due,
+// every task type is rate limited, or the run stops. Tasks that can't run yet
+// stay queued for the next run.
+func (app *Application) drainTaskQueue() (QueueOutcome, error) {
+	if *dryRun {
+		pending, err := app.db.CountTasks(storage.TaskPending)
+		if err != nil {
+			return QueueOutcome{}, err
+		}
+		app.logger.Infof("Dry run mode - %d queued tasks left untouched", pending)
+		return QueueOutcome{}, nil
+	}
+
+	done, failed := 0, 0
+	for app.pause.WaitWhilePaused(app.ctx) {
+		var runnable []string
+		for taskType, action := range taskActions {
+			if app.rateLimiter.CanPerformAction(action) {
+				runnable = append(runnable, taskType)
+			}
+		}
+		if len(runnable) == 0 {
+			app.logger.Info("Rate limits reached, leaving remaining tasks queued")
+			break
+		}
+
+		task, err := app.db.NextDueTask(runnable...)
+		if err != nil {
+			return QueueOutcome{Done: done, Failed: failed}, err
+		}
+		if task == nil {
+			break
+		}
+
+		if err := app.browser.EnsureAlive(); err != nil {
+			return QueueOutcome{Done: done, Failed: failed}, fmt.Errorf("browser crashed and could not be recovered: %w", err)
+		}
+		if err := app.runTask(task); err != nil {
+			app.logger.WithError(err).WithFields(map[string]interface{}{
+				"task_id":   task.ID,
+				"task_type": task.TaskType,
+				"target":    task.TargetURL,
+				"attempt":   task.Attempts + 1,
+			}).Warn("Queued task failed")
+			app.rateLimiter.RecordFailure(task.TaskType)
+			record := app.db.AbandonTask(task.ID, err.Error())
+			if retryableTaskError(err) {
+				record = app.db.FailTask(task.ID, err.Error(), taskRetryDelay)
+			}
+			if record != nil {
+				return QueueOutcome{Done: done, Failed: failed}, record
+			}
+			failed++
+		} else {
+			if err := app.db.CompleteTask(task.ID); err != nil {
+				return QueueOutcome{Done: done, Failed: failed}, err
+			}
+			done++
+		}
+
+		// The in-flight task has finished; don't wait out delays past the deadline
+		if app.ctx.Err() != nil {
+			continue
+		}
+		app.stealth.ThinkingDelay()
+		app.stealth.MaybeSimulateTabBlur(app.browser.GetPage())
+		app.rateLimiter.WaitForNextAction()
+	}
+
+	if done+failed > 0 {
+		app.logger.Infof("Queued tasks: %d done, %d failed", done, failed)
+	}
+	return QueueOutcome{Done: done, Failed: failed}, nil
+}
+
+// runTask performs one queued task
+func (app *Application) runTask(task *storage.Task) error {
+	switch task.TaskType {
+	case storage.TaskConnect:
+		result := &search.SearchResult{ProfileURL: task.TargetURL}
+		if profile, err := app.db.GetProfile(task.TargetURL); err == nil && profile != nil {
+			result = search.ResultFromProfile(profile)
+		}
+		return app.connector.SendConnectionRequest(result, "")
+	case storage.TaskMessage:
+		_, err := app.messenger.SendTemplatedDirectMessage(task.TargetURL)
+		return err
+	case storage.TaskView:
+		return app.searcher.ViewProfile(task.TargetURL)
+	default:
+		return fmt.Errorf("unknown task type: %s", task.TaskType)
+	}
+}
@@ -0,0 +1,260 @@
+// Package main - report.go handles the HTML activity report
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+// reportDays is the default length of an HTML report
+const reportDays = 30
+
+// reportRange parses -report-from and -report-to, defaulting to the last
+// reportDays days
+func reportRange(fromFlag, toFlag string) (time.Time, time.Time, error) {
+	to := time.Now()
+	if toFlag != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", toFlag, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid -report-to: %w", err)
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -(reportDays - 1))
+	if fromFlag != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", fromFlag, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid -report-from: %w", err)
+		}
+		from = parsed
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("-report-from must not be after -report-to")
+	}
+	return from, to, nil
+}
+
+// reportData is everything the HTML report template renders
+type reportData struct {
+	From, To       string
+	GeneratedAt    string
+	Days           []*storage.DailyStats
+	Totals         storage.DailyStats
+	Sent           int
+	Accepted       int
+	AcceptanceRate float64
+	Recent         []reportConnection
+	Chart          reportChart
+}
+
+// reportConnection is one recently accepted connection in the report
+type reportConnection struct {
+	Name       string
+	ProfileURL string
+	AcceptedAt string
+}
+
+// reportChart is an inline SVG bar chart of connections sent per day
+type reportChart struct {
+	Width, Height int
+	Max           int
+	Bars          []reportBar
+}
+
+// reportBar is one day's bar in the report chart
+type reportBar struct {
+	X, Y, Width, Height int
+	Label               string
+	Value               int
+}
+
+// Report chart dimensions in pixels
+const (
+	chartHeight   = 160
+	chartBarWidth = 18
+	chartGap      = 4
+)
+
+// recentConnectionsInReport caps the recently accepted connections listed
+const recentConnectionsInReport = 20
+
+// GenerateReport writes a self-contained HTML activity report covering from
+// through to (inclusive): daily stats, acceptance rate, recently accepted
+// connections and a bar chart of requests sent. It only reads the database.
+func (app *Application) GenerateReport(path string, from, to time.Time) error {
+	days, err := app.db.GetDailyStatsRange(from, to)
+	if err != nil {
+		return err
+	}
+
+	data := reportData{
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		GeneratedAt: time.Now().Format("2006-01-02 15:04"),
+		Days:        days,
+	}
+	for _, day := range days {
+		data.Totals.ConnectionsSent += day.ConnectionsSent
+		data.Totals.ConnectionsAccepted += day.ConnectionsAccepted
+		data.Totals.MessagesSent += day.MessagesSent
+		data.Totals.ProfilesViewed += day.ProfilesViewed
+		data.Totals.SearchesPerformed += day.SearchesPerformed
+	}
+
+	// Acceptance rate of the requests sent in the range
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+	requests, err := app.db.GetConnectionRequests(storage.ConnectionFilter{Since: start, Until: end})
+	if err != nil {
+		return err
+	}
+	data.Sent = len(requests)
+	for _, req := range requests {
+		if req.Status == "accepted" {
+			data.Accepted++
+		}
+	}
+	if data.Sent > 0 {
+		data.AcceptanceRate = float64(data.Accepted) / float64(data.Sent) * 100
+	}
+
+	accepted, err := app.db.GetConnectionRequests(storage.ConnectionFilter{
+		Status:  "accepted",
+		OrderBy: "accepted_at",
+		Limit:   recentConnectionsInReport,
+	})
+	if err != nil {
+		return err
+	}
+	for _, req := range accepted {
+		recent := reportConnection{ProfileURL: req.ProfileURL, Name: req.ProfileURL}
+		if profile, err := app.db.GetProfile(req.ProfileURL); err == nil && profile != nil && profile.Name != "" {
+			recent.Name = profile.Name
+		}
+		if req.AcceptedAt != nil {
+			recent.AcceptedAt = req.AcceptedAt.Format("2006-01-02")
+		}
+		data.Recent = append(data.Recent, recent)
+	}
+
+	data.Chart = buildReportChart(days)
+
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
+	app.logger.WithFields(map[string]interface{}{
+		"path": path,
+		"from": data.From,
+		"to":   data.To,
+	}).Info("Report written")
+	return nil
+}
+
+// buildReportChart lays out one bar per day scaled to the busiest day
+func buildReportChart(days []*storage.DailyStats) reportChart {
+	chart := reportChart{
+		Width:  len(days)*(chartBarWidth+chartGap) + chartGap,
+		Height: chartHeight,
+	}
+	for _, day := range days {
+		if day.ConnectionsSent > chart.Max {
+			chart.Max = day.ConnectionsSent
+		}
+	}
+
+	for i, day := range days {
+		height := 0
+		if chart.Max > 0 {
+			height = day.ConnectionsSent * (chartHeight - 20) / chart.Max
+		}
+		chart.Bars = append(chart.Bars, reportBar{
+			X:      chartGap + i*(chartBarWidth+chartGap),
+			Y:      chartHeight - height,
+			Width:  chartBarWidth,
+			Height: height,
+			Label:  day.Date,
+			Value:  day.ConnectionsSent,
+		})
+	}
+	return chart
+}
+
+// reportTemplate renders the HTML report; styles and chart are inline so the
+// file can be shared on its own
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>LinkedIn activity report {{.From}} to {{.To}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1d2226; }
+h1 { font-size: 1.5em; }
+.summary { display: flex; gap: 1em; flex-wrap: wrap; margin: 1em 0 2em; }
+.card { border: 1px solid #ddd; border-radius: 8px; padding: 0.8em 1.2em; min-width: 8em; }
+.card .value { font-size: 1.6em; font-weight: 600; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border-bottom: 1px solid #eee; padding: 0.3em 0.8em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+svg rect { fill: #0a66c2; }
+.muted { color: #666; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>LinkedIn activity report</h1>
+<p class="muted">{{.From}} to {{.To}} &middot; generated {{.GeneratedAt}}</p>
+
+<div class="summary">
+  <div class="card"><div class="value">{{.Totals.ConnectionsSent}}</div>requests sent</div>
+  <div class="card"><div class="value">{{.Accepted}} / {{.Sent}}</div>accepted</div>
+  <div class="card"><div class="value">{{printf "%.1f" .AcceptanceRate}}%</div>acceptance rate</div>
+  <div class="card"><div class="value">{{.Totals.MessagesSent}}</div>messages sent</div>
+  <div class="card"><div class="value">{{.Totals.ProfilesViewed}}</div>profiles viewed</div>
+  <div class="card"><div class="value">{{.Totals.SearchesPerformed}}</div>searches</div>
+</div>
+
+<h2>Connection requests per day</h2>
+<svg width="{{.Chart.Width}}" height="{{.Chart.Height}}" role="img" aria-label="Connection requests sent per day">
+{{- range .Chart.Bars}}
+  <rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"><title>{{.Label}}: {{.Value}}</title></rect>
+{{- end}}
+</svg>
+<p class="muted">Busiest day: {{.Chart.Max}} requests</p>
+
+<h2>Daily activity</h2>
+<table>
+  <tr><th>Date</th><th>Requests</th><th>Accepted</th><th>Messages</th><th>Profile views</th><th>Searches</th></tr>
+  {{- range .Days}}
+  <tr><td>{{.Date}}</td><td>{{.ConnectionsSent}}</td><td>{{.ConnectionsAccepted}}</td><td>{{.MessagesSent}}</td><td>{{.ProfilesViewed}}</td><td>{{.SearchesPerformed}}</td></tr>
+  {{- end}}
+</table>
+
+<h2>Recently accepted connections</h2>
+{{- if .Recent}}
+<table>
+  <tr><th>Name</th><th>Accepted</th></tr>
+  {{- range .Recent}}
+  <tr><td><a href="{{.ProfileURL}}">{{.Name}}</a></td><td>{{.AcceptedAt}}</td></tr>
+  {{- end}}
+</table>
+{{- else}}
+<p class="muted">No accepted connections yet.</p>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,77 @@
+// Package main - watchdog.go handles stopping runs that stall
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+)
+
+// setupWatchdog creates the watchdog when schedule.watchdog_minutes is set and
+// lets the components that make progress reset it
+func (app *Application) setupWatchdog() {
+	if app.config.Schedule.WatchdogMinutes <= 0 {
+		return
+	}
+	app.watchdog = stealth.NewWatchdog(time.Duration(app.config.Schedule.WatchdogMinutes) * time.Minute)
+	app.rateLimiter.SetWatchdog(app.watchdog)
+	app.pause.SetWatchdog(app.watchdog)
+	app.connector.SetWatchdog(app.watchdog)
+}
+
+// startWatchdog restarts the watchdog window and watches the run until the
+// application context ends
+func (app *Application) startWatchdog() {
+	app.watchdog.Reset()
+	go app.watchdog.Run(app.ctx, app.onWatchdogStall)
+}
+
+// watchdogStallTimeout bounds the screenshot, logout and cleanup the
+// watchdog does before exiting, since the page that stalled may not respond
+const watchdogStallTimeout = 30 * time.Second
+
+// onWatchdogStall stops a run that made no progress within the watchdog
+// window: it saves a screenshot of where it got stuck, logs out so the
+// session isn't left open, and exits with exitWatchdog for a supervisor.
+// The cleanup gets watchdogStallTimeout; after that it exits regardless.
+func (app *Application) onWatchdogStall(idle time.Duration) {
+	app.logger.WithField("idle", idle.Round(time.Second).String()).Warn("No successful action within the watchdog window, logging out")
+
+	ctx, cancel := context.WithTimeout(context.Background(), watchdogStallTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		page := app.browser.GetPage()
+		if page != nil {
+			page = page.Context(ctx)
+		}
+		if path, err := browser.CaptureDebugScreenshot(page, &app.config.Browser, "watchdog"); err != nil {
+			app.logger.WithError(err).Warn("Failed to capture watchdog screenshot")
+		} else if path != "" {
+			app.logger.WithField("path", path).Info("Watchdog screenshot saved")
+		}
+		if path, err := app.tracer.DumpToDir(app.config.Logging.TraceDir); err == nil {
+			app.logger.WithField("path", path).Info("Trace dumped")
+		}
+
+		if page != nil {
+			app.auth.SetPage(page)
+			if err := app.auth.Logout(); err != nil {
+				app.logger.WithError(err).Warn("Failed to log out")
+			}
+		}
+		app.Close()
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		app.logger.WithField("timeout", watchdogStallTimeout.String()).Warn("Watchdog cleanup timed out, exiting anyway")
+	}
+	os.Exit(exitWatchdog)
+}
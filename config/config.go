@@ -4,8 +4,12 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -39,12 +43,35 @@ type Config struct {
 
 	// Activity scheduling
 	Schedule ScheduleConfig `yaml:"schedule"`
+
+	// Additional LinkedIn accounts selectable with -account
+	Accounts []AccountConfig `yaml:"accounts"`
 }
 
 // LinkedInConfig holds LinkedIn-specific settings
 type LinkedInConfig struct {
 	Email    string `yaml:"email"`
 	Password string `yaml:"password"`
+
+	// Base32 authenticator-app secret; answers 2FA prompts when set
+	TOTPSecret string `yaml:"totp_secret"`
+
+	// Tries of the whole login flow when the form fails to render or respond
+	LoginAttempts int `yaml:"login_attempts"`
+
+	// Minutes between session keep-alive visits to the feed during long
+	// runs, re-saving rotated cookies; 0 disables
+	KeepAliveMinutes int `yaml:"keep_alive_minutes"`
+}
+
+// AccountConfig holds settings for one of several managed LinkedIn accounts.
+// Each account gets its own database, cookies file, and browser profile under DataDir.
+type AccountConfig struct {
+	Name     string `yaml:"name"`
+	Email    string `yaml:"email"`
+	Password string `yaml:"password"`
+	TOTPSecret string `yaml:"totp_secret"`
+	DataDir  string `yaml:"data_dir"` // defaults to ./data/<name>
 }
 
 // BrowserConfig holds browser automation settings
@@ -53,8 +80,43 @@ type BrowserConfig struct {
 	UserDataDir    string `yaml:"user_data_dir"`
 	SlowMotion     int    `yaml:"slow_motion_ms"`
 	Timeout        int    `yaml:"timeout_seconds"`
+	ReadyTimeout   int    `yaml:"ready_timeout_seconds"`
+	SendEnabledTimeout int `yaml:"send_enabled_timeout_seconds"` // max wait for a disabled Send button to enable
 	ViewportWidth  int    `yaml:"viewport_width"`
 	ViewportHeight int    `yaml:"viewport_height"`
+	DeviceProfile  string `yaml:"device_profile"` // desktop, tablet or mobile
+
+	// Custom browser build and command-line flags
+	BinaryPath string   `yaml:"binary_path"` // empty = auto-downloaded Chromium
+	ExtraFlags []string `yaml:"extra_flags"` // e.g. "--proxy-server=http://host:3128"
+
+	// JavaScript file run in every page after the built-in masking script
+	InjectScriptPath string `yaml:"inject_script_path"`
+
+	// Audit screenshots taken after every successful send
+	ScreenshotOnAction bool   `yaml:"screenshot_on_action"`
+	ScreenshotDir      string `yaml:"screenshot_dir"`
+	MaxScreenshots     int    `yaml:"max_screenshots"` // oldest pruned beyond this; 0 = unlimited
+
+	// One screenshot when a connect, message or search action fails
+	ScreenshotOnError bool `yaml:"screenshot_on_error"`
+
+	// Warn when key pages' DOM layout drifts from the previous run
+	LayoutCheck           bool `yaml:"layout_check"`
+	LayoutChangeThreshold int  `yaml:"layout_change_threshold"` // differing fingerprint bits (of 64) that count as a change
+
+	// Location and timezone the page reports; keep them consistent with
+	// the proxy in extra_flags so they don't contradict the exit IP
+	Geolocation GeolocationConfig `yaml:"geolocation"`
+	Timezone    string            `yaml:"timezone"` // IANA name; empty = schedule.timezone, "Local" = no override
+}
+
+// GeolocationConfig holds the position reported to navigator.geolocation
+type GeolocationConfig struct {
+	Enabled   bool    `yaml:"enabled"`
+	Latitude  float64 `yaml:"latitude"`
+	Longitude float64 `yaml:"longitude"`
+	Accuracy  float64 `yaml:"accuracy"` // meters
 }
 
 // StealthConfig holds anti-detection settings
@@ -64,11 +126,14 @@ type StealthConfig struct {
 	MouseSpeedMax      float64 `yaml:"mouse_speed_max"`
 	MouseOvershoot     bool    `yaml:"mouse_overshoot"`
 	MouseMicroCorrect  bool    `yaml:"mouse_micro_corrections"`
+	ClickAreaPercent   int     `yaml:"click_area_percent"` // central share of an element hovers and clicks land in; 0 = dead center
 
 	// Typing settings
 	TypingDelayMin     int  `yaml:"typing_delay_min_ms"`
 	TypingDelayMax     int  `yaml:"typing_delay_max_ms"`
 	TypingMistakeRate  float64 `yaml:"typing_mistake_rate"`
+	TypingCorrectionVariance float64 `yaml:"typing_correction_variance"`
+	TypingProfile      string  `yaml:"typing_profile"` // fast, average or hunt-and-peck; empty uses the settings above
 
 	// Scrolling settings
 	ScrollSpeedMin     int  `yaml:"scroll_speed_min"`
@@ -81,44 +146,141 @@ type StealthConfig struct {
 	PageLoadWaitMin    int  `yaml:"page_load_wait_min_ms"`
 	PageLoadWaitMax    int  `yaml:"page_load_wait_max_ms"`
 
+	// Reading time on profiles, scaled by visible text length from the
+	// minimum for an empty page to the maximum at ReadingMaxChars
+	ReadingDelayMin int `yaml:"reading_delay_min_ms"`
+	ReadingDelayMax int `yaml:"reading_delay_max_ms"`
+	ReadingMaxChars int `yaml:"reading_max_chars"`
+
 	// Fingerprint masking
 	RandomizeViewport  bool    `yaml:"randomize_viewport"`
 	DisableWebdriver   bool    `yaml:"disable_webdriver"`
 	RandomUserAgent    bool    `yaml:"random_user_agent"`
+	PersistFingerprint bool    `yaml:"persist_fingerprint"` // reuse one identity per user data dir
+
+	// Vary the order of independent workflow steps each cycle
+	ShuffleWorkflowSteps bool `yaml:"shuffle_workflow_steps"`
+
+	// Chance of switching away from the tab (blur + hidden) between actions
+	TabBlurChance float64 `yaml:"tab_blur_chance"`
+
+	// Fixed seed for reproducible behavior when debugging (0 = time-based)
+	RandomSeed int64 `yaml:"random_seed"`
 }
 
 // RateLimitConfig holds rate limiting settings
 type RateLimitConfig struct {
 	MaxConnectionsPerDay    int `yaml:"max_connections_per_day"`
+	MaxConnectionsPerWeek   int `yaml:"max_connections_per_week"` // trailing 7 days; 0 disables
+	MaxConnectionsPerCompanyPerDay int `yaml:"max_connections_per_company_per_day"` // 0 disables
 	MaxMessagesPerDay       int `yaml:"max_messages_per_day"`
 	MaxProfileViewsPerDay   int `yaml:"max_profile_views_per_day"`
 	MaxSearchesPerHour      int `yaml:"max_searches_per_hour"`
 	CooldownMinutes         int `yaml:"cooldown_minutes"`
+	HourlyCooldownMinutes   map[int]int `yaml:"hourly_cooldown_minutes"` // hour of day (0-23) -> cooldown; other hours use cooldown_minutes
 	MinDelayBetweenActions  int `yaml:"min_delay_between_actions_ms"`
 	MaxDelayBetweenActions  int `yaml:"max_delay_between_actions_ms"`
+
+	// Token bucket letting a few actions run back to back before the normal
+	// spacing applies; one token returns every burst_refill_seconds
+	BurstSize          int `yaml:"burst_size"` // 0 disables
+	BurstRefillSeconds int `yaml:"burst_refill_seconds"`
+
+	// Back off when bulk operations keep failing (e.g. a broken selector)
+	FailureCooldownAfter   int `yaml:"failure_cooldown_after"`   // consecutive failures before cooling down; 0 disables
+	FailureCooldownMinutes int `yaml:"failure_cooldown_minutes"` // first cooldown, doubled for each further failure
+	FailureAbortAfter      int `yaml:"failure_abort_after"`      // consecutive failures that abort the batch; 0 disables
+
+	// Stop the run when the feed shows an "unusual activity" warning banner
+	HaltOnAccountWarning bool `yaml:"halt_on_account_warning"`
+
+	// When both remaining connection and message budgets are at or below
+	// low_budget_threshold, run the low_budget_priority steps first
+	LowBudgetThreshold int    `yaml:"low_budget_threshold"` // 0 disables
+	LowBudgetPriority  string `yaml:"low_budget_priority"`  // messages or connections
+
+	// Warmup ramp for new accounts
+	AccountMaturity AccountMaturityConfig `yaml:"account_maturity"`
+}
+
+// AccountMaturityConfig scales rate limits up over a new account's first weeks
+type AccountMaturityConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	AccountCreated string `yaml:"account_created"` // YYYY-MM-DD; scraped from the profile when empty
+	MinAgeDays     int    `yaml:"min_age_days"`    // no connection requests before this age
+	WarmupDays     int    `yaml:"warmup_days"`     // age at which the configured limits fully apply
+	StartPercent   int    `yaml:"start_percent"`   // share of the limits allowed at min_age_days
+}
+
+// CreatedDate parses AccountCreated; ok is false when it isn't set
+func (a *AccountMaturityConfig) CreatedDate() (created time.Time, ok bool, err error) {
+	if a.AccountCreated == "" {
+		return time.Time{}, false, nil
+	}
+	created, err = time.ParseInLocation("2006-01-02", a.AccountCreated, time.Local)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid account_created %q (expected YYYY-MM-DD): %w", a.AccountCreated, err)
+	}
+	return created, true, nil
 }
 
 // SearchConfig holds search-related settings
 type SearchConfig struct {
-	DefaultJobTitle    string   `yaml:"default_job_title"`
-	DefaultCompany     string   `yaml:"default_company"`
-	DefaultLocation    string   `yaml:"default_location"`
-	Keywords           []string `yaml:"keywords"`
-	MaxResultsPerSearch int     `yaml:"max_results_per_search"`
+	DefaultJobTitle         string        `yaml:"default_job_title"`
+	DefaultCompany          string        `yaml:"default_company"`
+	DefaultLocation         string        `yaml:"default_location"`
+	Keywords                []string      `yaml:"keywords"`
+	MaxResultsPerSearch     int           `yaml:"max_results_per_search"`
+	MaxPagesPerSearch       int           `yaml:"max_pages_per_search"`       // 0 = only the built-in safety cap
+	SortByMutualConnections bool          `yaml:"sort_by_mutual_connections"` // highest mutual count first
+	ConnectDegrees          []string      `yaml:"connect_degrees"`            // degrees eligible for invites (empty = all)
+	SkipMissingHeadline     bool          `yaml:"skip_missing_headline"`      // leave out profiles with no headline
+	SkipMissingCompany      bool          `yaml:"skip_missing_company"`       // leave out profiles with no company
+	EnrichWorkers           int           `yaml:"enrich_workers"`             // tabs that open search results for full details; 0 = off
+	DeclineAfterDays        int           `yaml:"decline_after_days"`         // unanswered invites gone from the sent list count as declined after this; 0 = only explicit states
+	Scoring                 ScoringConfig `yaml:"scoring"`
+}
+
+// ScoringConfig weights the 0-100 relevance score given to search results.
+// Weights are relative to each other; a weight of 0 ignores that signal.
+type ScoringConfig struct {
+	Enabled         bool `yaml:"enabled"` // sort by score (overrides sort_by_mutual_connections)
+	KeywordWeight   int  `yaml:"keyword_weight"`
+	MutualWeight    int  `yaml:"mutual_weight"`
+	DegreeWeight    int  `yaml:"degree_weight"`
+	MutualCap       int  `yaml:"mutual_cap"` // mutual count that earns the full mutual score
 }
 
 // MessagingConfig holds messaging settings
 type MessagingConfig struct {
 	ConnectionNoteTemplate  string `yaml:"connection_note_template"`
 	FollowUpMessageTemplate string `yaml:"follow_up_message_template"`
+	DirectMessageTemplate   string `yaml:"direct_message_template"`
 	MaxNoteLength           int    `yaml:"max_note_length"`
+	SendNotePercentage      int    `yaml:"send_note_percentage"` // share of requests sent with a note (0-100)
 	MaxMessageLength        int    `yaml:"max_message_length"`
+	UseRecentActivity       bool   `yaml:"use_recent_activity"` // scrape the latest post for {{.RecentActivity}} (slower)
+	RecentMessageDays       int    `yaml:"recent_message_days"` // skip database recipients messaged this recently (0 = no guard)
+
+	// Note variants picked at random per request for A/B testing; when
+	// empty, connection_note_template is used as the "default" variant
+	ConnectionNoteVariants []NoteVariant `yaml:"connection_note_variants"`
+
+	// Casing fixes applied by NormalizeTemplates
+	templateCorrections []string
+}
+
+// NoteVariant is one connection note template in an A/B experiment
+type NoteVariant struct {
+	ID       string `yaml:"id"`
+	Template string `yaml:"template"`
 }
 
 // StorageConfig holds data persistence settings
 type StorageConfig struct {
 	DatabasePath   string `yaml:"database_path"`
 	CookiesPath    string `yaml:"cookies_path"`
+	CookieDomains  []string `yaml:"cookie_domains"` // LinkedIn domains whose cookies are saved and restored
 	BackupEnabled  bool   `yaml:"backup_enabled"`
 	BackupInterval int    `yaml:"backup_interval_hours"`
 }
@@ -130,6 +292,18 @@ type LoggingConfig struct {
 	OutputFile string `yaml:"output_file"`
 	MaxSizeMB  int    `yaml:"max_size_mb"`
 	MaxBackups int    `yaml:"max_backups"`
+
+	// Decision trace kept in memory and dumped on error or SIGUSR2
+	TraceBufferSize int    `yaml:"trace_buffer_size"` // 0 disables tracing
+	TraceDir        string `yaml:"trace_dir"`
+
+	// One-line session progress against today's caps; text format only
+	ProgressIntervalMinutes int `yaml:"progress_interval_minutes"` // 0 disables
+
+	// Mask sensitive field values so logs can be shared
+	Redact       bool     `yaml:"redact"`
+	RedactFields []string `yaml:"redact_fields"`
+	RedactHash   bool     `yaml:"redact_hash"` // short hash instead of [REDACTED]
 }
 
 // ScheduleConfig holds activity scheduling settings
@@ -142,6 +316,17 @@ type ScheduleConfig struct {
 	BreakMinMax    int    `yaml:"break_max_minutes"`
 	SessionMaxMin  int    `yaml:"session_max_minutes"`
 	Timezone       string `yaml:"timezone"`
+
+	// Random delay of 0..N minutes before activity starts, so cron launches vary
+	StartJitterMinutes int `yaml:"start_jitter_minutes"`
+
+	// Pause between actions while this file exists (or after SIGUSR1)
+	PauseFile             string `yaml:"pause_file"`
+	PauseKeepAliveMinutes int    `yaml:"pause_keep_alive_minutes"` // light feed browsing interval while paused; 0 disables
+
+	// Log out and exit when no action succeeds for this long (waits,
+	// breaks and pauses excluded), so a stuck run doesn't hold the session open
+	WatchdogMinutes int `yaml:"watchdog_minutes"` // 0 disables
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -150,23 +335,36 @@ func DefaultConfig() *Config {
 		LinkedIn: LinkedInConfig{
 			Email:    "",
 			Password: "",
+			LoginAttempts: 3,
+			KeepAliveMinutes: 60,
 		},
 		Browser: BrowserConfig{
 			Headless:       false,
 			UserDataDir:    "./data/browser",
 			SlowMotion:     0,
 			Timeout:        30,
+			ReadyTimeout:   15,
+			SendEnabledTimeout: 5,
 			ViewportWidth:  1366,
 			ViewportHeight: 768,
+			DeviceProfile:  "desktop",
+			ScreenshotOnAction: false,
+			ScreenshotDir:      "./data/screenshots",
+			MaxScreenshots:     500,
+			LayoutCheck:           true,
+			LayoutChangeThreshold: 10,
+			Geolocation:           GeolocationConfig{Accuracy: 100},
 		},
 		Stealth: StealthConfig{
 			MouseSpeedMin:      0.5,
 			MouseSpeedMax:      2.0,
 			MouseOvershoot:     true,
 			MouseMicroCorrect:  true,
+			ClickAreaPercent:   60,
 			TypingDelayMin:     50,
 			TypingDelayMax:     200,
 			TypingMistakeRate:  0.02,
+			TypingCorrectionVariance: 0.3,
 			ScrollSpeedMin:     100,
 			ScrollSpeedMax:     400,
 			ScrollBackChance:   0.15,
@@ -174,18 +372,39 @@ func DefaultConfig() *Config {
 			ActionDelayMax:     2000,
 			PageLoadWaitMin:    1000,
 			PageLoadWaitMax:    3000,
+			ReadingDelayMin:    1500,
+			ReadingDelayMax:    15000,
+			ReadingMaxChars:    8000,
 			RandomizeViewport:  true,
 			DisableWebdriver:   true,
 			RandomUserAgent:    true,
+			PersistFingerprint: true,
+			ShuffleWorkflowSteps: true,
+			TabBlurChance:        0.1,
 		},
 		RateLimits: RateLimitConfig{
 			MaxConnectionsPerDay:   25,
+			MaxConnectionsPerWeek:  100,
+			MaxConnectionsPerCompanyPerDay: 5,
 			MaxMessagesPerDay:      50,
 			MaxProfileViewsPerDay:  100,
 			MaxSearchesPerHour:     10,
 			CooldownMinutes:        5,
 			MinDelayBetweenActions: 2000,
 			MaxDelayBetweenActions: 5000,
+			BurstSize:              0,
+			BurstRefillSeconds:     120,
+			FailureCooldownAfter:   3,
+			FailureCooldownMinutes: 5,
+			FailureAbortAfter:      6,
+			LowBudgetThreshold:     5,
+			LowBudgetPriority:      "messages",
+			AccountMaturity: AccountMaturityConfig{
+				Enabled:      false,
+				MinAgeDays:   7,
+				WarmupDays:   30,
+				StartPercent: 20,
+			},
 		},
 		Search: SearchConfig{
 			DefaultJobTitle:     "",
@@ -193,16 +412,30 @@ func DefaultConfig() *Config {
 			DefaultLocation:     "",
 			Keywords:            []string{},
 			MaxResultsPerSearch: 25,
+			MaxPagesPerSearch:   5,
+			DeclineAfterDays:    21,
+			SortByMutualConnections: true,
+			Scoring: ScoringConfig{
+				Enabled:       true,
+				KeywordWeight: 50,
+				MutualWeight:  30,
+				DegreeWeight:  20,
+				MutualCap:     20,
+			},
 		},
 		Messaging: MessagingConfig{
 			ConnectionNoteTemplate:  "Hi {{.FirstName}}, I came across your profile and would love to connect!",
 			FollowUpMessageTemplate: "Thanks for connecting, {{.FirstName}}! I'd love to learn more about your work at {{.Company}}.",
+			DirectMessageTemplate:   "Hi {{.FirstName}}, hope all is well at {{.Company}}! Would love to catch up sometime.",
 			MaxNoteLength:           300,
+			SendNotePercentage:      100,
 			MaxMessageLength:        8000,
+			RecentMessageDays:       30,
 		},
 		Storage: StorageConfig{
 			DatabasePath:   "./data/linkedin_automation.db",
 			CookiesPath:    "./data/cookies.json",
+			CookieDomains:  []string{"linkedin.com", "www.linkedin.com", "api.linkedin.com"},
 			BackupEnabled:  true,
 			BackupInterval: 24,
 		},
@@ -212,6 +445,13 @@ func DefaultConfig() *Config {
 			OutputFile: "./logs/automation.log",
 			MaxSizeMB:  100,
 			MaxBackups: 5,
+			TraceBufferSize: 2000,
+			TraceDir:        "./logs/traces",
+			ProgressIntervalMinutes: 10,
+			RedactFields: []string{
+				"profile_url", "recipient_url", "url", "profile", "name", "person",
+				"email", "note", "card_text", "link_text",
+			},
 		},
 		Schedule: ScheduleConfig{
 			Enabled:       true,
@@ -222,12 +462,23 @@ func DefaultConfig() *Config {
 			BreakMinMax:   15,
 			SessionMaxMin: 120,
 			Timezone:      "Local",
+			StartJitterMinutes:    15,
+			PauseFile:             "./control/paused",
+			PauseKeepAliveMinutes: 10,
+			WatchdogMinutes:       30,
 		},
 	}
 }
 
 // LoadConfig loads configuration from a YAML file and applies environment variable overrides
 func LoadConfig(configPath string) (*Config, error) {
+	return LoadConfigForAccount(configPath, "")
+}
+
+// LoadConfigForAccount loads configuration like LoadConfig and then applies the
+// named account's credentials and isolated data paths. An empty name keeps the
+// top-level linkedin/storage/browser settings.
+func LoadConfigForAccount(configPath string, account string) (*Config, error) {
 	config := DefaultConfig()
 
 	// Try to load from file if it exists
@@ -248,6 +499,16 @@ func LoadConfig(configPath string) (*Config, error) {
 	// Apply environment variable overrides
 	config.applyEnvOverrides()
 
+	// Fix {{.firstname}}-style casing mistakes before validation rejects them
+	config.Messaging.NormalizeTemplates()
+
+	// Select account after env overrides so per-account paths always win
+	if account != "" {
+		if err := config.ApplyAccount(account); err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate configuration
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
@@ -265,6 +526,9 @@ func (c *Config) applyEnvOverrides() {
 	if password := os.Getenv("LINKEDIN_PASSWORD"); password != "" {
 		c.LinkedIn.Password = password
 	}
+	if secret := os.Getenv("LINKEDIN_TOTP_SECRET"); secret != "" {
+		c.LinkedIn.TOTPSecret = secret
+	}
 
 	// Browser settings
 	if headless := os.Getenv("BROWSER_HEADLESS"); headless != "" {
@@ -309,15 +573,167 @@ func (c *Config) Validate() error {
 	if c.LinkedIn.Password == "" {
 		return fmt.Errorf("LinkedIn password is required (set LINKEDIN_PASSWORD env var or in config)")
 	}
+	if c.LinkedIn.LoginAttempts < 1 {
+		return fmt.Errorf("login_attempts must be at least 1")
+	}
+	if c.LinkedIn.KeepAliveMinutes < 0 {
+		return fmt.Errorf("keep_alive_minutes must not be negative")
+	}
+
+	// Validate reading delay
+	if c.Stealth.ReadingDelayMin < 0 || c.Stealth.ReadingDelayMax < c.Stealth.ReadingDelayMin {
+		return fmt.Errorf("reading delay requires 0 <= reading_delay_min_ms <= reading_delay_max_ms")
+	}
+	if c.Stealth.ReadingMaxChars <= 0 {
+		return fmt.Errorf("reading_max_chars must be positive")
+	}
 
 	// Validate rate limits
 	if c.RateLimits.MaxConnectionsPerDay < 0 || c.RateLimits.MaxConnectionsPerDay > 100 {
 		return fmt.Errorf("max_connections_per_day must be between 0 and 100")
 	}
+	if c.RateLimits.MaxConnectionsPerWeek < 0 || c.RateLimits.MaxConnectionsPerWeek > 500 {
+		return fmt.Errorf("max_connections_per_week must be between 0 (no weekly cap) and 500")
+	}
+	if c.RateLimits.MaxConnectionsPerCompanyPerDay < 0 {
+		return fmt.Errorf("max_connections_per_company_per_day cannot be negative")
+	}
 	if c.RateLimits.MaxMessagesPerDay < 0 || c.RateLimits.MaxMessagesPerDay > 150 {
 		return fmt.Errorf("max_messages_per_day must be between 0 and 150")
 	}
 
+	for hour, minutes := range c.RateLimits.HourlyCooldownMinutes {
+		if hour < 0 || hour > 23 {
+			return fmt.Errorf("hourly_cooldown_minutes hour %d must be between 0 and 23", hour)
+		}
+		if minutes < 0 {
+			return fmt.Errorf("hourly_cooldown_minutes for hour %d must not be negative", hour)
+		}
+	}
+
+	if c.RateLimits.FailureCooldownAfter < 0 || c.RateLimits.FailureCooldownMinutes < 0 || c.RateLimits.FailureAbortAfter < 0 {
+		return fmt.Errorf("failure cooldown settings must not be negative")
+	}
+
+	if c.RateLimits.BurstSize < 0 {
+		return fmt.Errorf("burst_size must be 0 (disabled) or positive")
+	}
+	if c.RateLimits.BurstSize > 0 && c.RateLimits.BurstRefillSeconds <= 0 {
+		return fmt.Errorf("burst_refill_seconds must be positive when burst_size is set")
+	}
+
+	if c.RateLimits.LowBudgetThreshold < 0 {
+		return fmt.Errorf("low_budget_threshold must not be negative")
+	}
+	switch c.RateLimits.LowBudgetPriority {
+	case "", "messages", "connections":
+	default:
+		return fmt.Errorf("low_budget_priority must be messages or connections")
+	}
+
+	// Validate account maturity ramp
+	maturity := c.RateLimits.AccountMaturity
+	if maturity.MinAgeDays < 0 || maturity.WarmupDays < maturity.MinAgeDays {
+		return fmt.Errorf("account_maturity requires 0 <= min_age_days <= warmup_days")
+	}
+	if maturity.StartPercent < 0 || maturity.StartPercent > 100 {
+		return fmt.Errorf("account_maturity start_percent must be between 0 and 100")
+	}
+	if _, _, err := maturity.CreatedDate(); err != nil {
+		return err
+	}
+
+	if c.Search.MaxPagesPerSearch < 0 {
+		return fmt.Errorf("max_pages_per_search must be 0 (no cap) or positive")
+	}
+	if c.Search.EnrichWorkers < 0 || c.Search.EnrichWorkers > 4 {
+		return fmt.Errorf("enrich_workers must be between 0 (off) and 4")
+	}
+	if c.Search.DeclineAfterDays < 0 {
+		return fmt.Errorf("decline_after_days must be 0 (explicit declines only) or positive")
+	}
+	for _, degree := range c.Search.ConnectDegrees {
+		switch strings.ToLower(strings.TrimSpace(degree)) {
+		case "1st", "2nd", "3rd", "3rd+":
+		default:
+			return fmt.Errorf("connect_degrees: unknown connection degree %q (use 1st, 2nd or 3rd+)", degree)
+		}
+	}
+
+	// Validate search scoring
+	scoring := c.Search.Scoring
+	if scoring.KeywordWeight < 0 || scoring.MutualWeight < 0 || scoring.DegreeWeight < 0 || scoring.MutualCap < 0 {
+		return fmt.Errorf("search scoring weights and mutual_cap must not be negative")
+	}
+
+	// Validate messaging
+	if c.Messaging.SendNotePercentage < 0 || c.Messaging.SendNotePercentage > 100 {
+		return fmt.Errorf("send_note_percentage must be between 0 and 100")
+	}
+	if c.Messaging.RecentMessageDays < 0 {
+		return fmt.Errorf("recent_message_days cannot be negative")
+	}
+
+	if len(c.Storage.CookieDomains) == 0 {
+		return fmt.Errorf("cookie_domains must list at least one domain")
+	}
+	for _, domain := range c.Storage.CookieDomains {
+		if strings.TrimPrefix(domain, ".") == "" || strings.Contains(domain, "/") {
+			return fmt.Errorf("cookie_domains entry %q must be a bare domain such as www.linkedin.com", domain)
+		}
+	}
+
+	if c.Browser.MaxScreenshots < 0 {
+		return fmt.Errorf("max_screenshots must be 0 (unlimited) or positive")
+	}
+	if c.Browser.LayoutChangeThreshold < 0 || c.Browser.LayoutChangeThreshold > 64 {
+		return fmt.Errorf("layout_change_threshold must be between 0 and 64")
+	}
+	if geo := c.Browser.Geolocation; geo.Enabled {
+		if geo.Latitude < -90 || geo.Latitude > 90 || geo.Longitude < -180 || geo.Longitude > 180 {
+			return fmt.Errorf("geolocation latitude must be between -90 and 90 and longitude between -180 and 180")
+		}
+		if geo.Accuracy <= 0 {
+			return fmt.Errorf("geolocation accuracy must be positive")
+		}
+	}
+	if tz := c.Browser.Timezone; tz != "" && tz != "Local" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("browser timezone: %w", err)
+		}
+	}
+	if c.Stealth.ClickAreaPercent < 0 || c.Stealth.ClickAreaPercent > 100 {
+		return fmt.Errorf("click_area_percent must be between 0 and 100")
+	}
+	if c.Stealth.TabBlurChance < 0 || c.Stealth.TabBlurChance > 1 {
+		return fmt.Errorf("tab_blur_chance must be between 0 and 1")
+	}
+	switch c.Stealth.TypingProfile {
+	case "", "fast", "average", "hunt-and-peck":
+	default:
+		return fmt.Errorf("typing_profile must be fast, average or hunt-and-peck")
+	}
+	switch c.Browser.DeviceProfile {
+	case "", "desktop", "tablet", "mobile":
+	default:
+		return fmt.Errorf("device_profile must be desktop, tablet or mobile")
+	}
+	if c.Browser.BinaryPath != "" {
+		if err := checkExecutable(c.Browser.BinaryPath); err != nil {
+			return fmt.Errorf("browser binary_path: %w", err)
+		}
+	}
+	for _, flag := range c.Browser.ExtraFlags {
+		if strings.TrimLeft(strings.TrimSpace(flag), "-") == "" {
+			return fmt.Errorf("browser extra_flags contains an empty flag")
+		}
+	}
+	if c.Browser.InjectScriptPath != "" {
+		if err := checkReadable(c.Browser.InjectScriptPath); err != nil {
+			return fmt.Errorf("browser inject_script_path: %w", err)
+		}
+	}
+
 	// Validate schedule
 	if c.Schedule.StartHour < 0 || c.Schedule.StartHour > 23 {
 		return fmt.Errorf("start_hour must be between 0 and 23")
@@ -325,12 +741,178 @@ func (c *Config) Validate() error {
 	if c.Schedule.EndHour < 0 || c.Schedule.EndHour > 23 {
 		return fmt.Errorf("end_hour must be between 0 and 23")
 	}
+	if c.Schedule.StartJitterMinutes < 0 {
+		return fmt.Errorf("start_jitter_minutes must be 0 (disabled) or positive")
+	}
+	if c.Schedule.PauseKeepAliveMinutes < 0 {
+		return fmt.Errorf("pause_keep_alive_minutes must be 0 (disabled) or positive")
+	}
+	if c.Schedule.WatchdogMinutes < 0 {
+		return fmt.Errorf("watchdog_minutes must be 0 (disabled) or positive")
+	}
+
+	// Validate accounts
+	seenAccounts := make(map[string]bool)
+	for _, account := range c.Accounts {
+		if account.Name == "" {
+			return fmt.Errorf("account name is required")
+		}
+		if strings.ContainsAny(account.Name, `/\`) || account.Name == "." || account.Name == ".." {
+			return fmt.Errorf("invalid account name: %s", account.Name)
+		}
+		if seenAccounts[account.Name] {
+			return fmt.Errorf("duplicate account name: %s", account.Name)
+		}
+		seenAccounts[account.Name] = true
+	}
+
+	// Validate message templates
+	if err := c.Messaging.ValidateTemplates(); err != nil {
+		return err
+	}
 
 	// Validate logging level
 	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
 	if !validLevels[c.Logging.Level] {
 		return fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", c.Logging.Level)
 	}
+	if c.Logging.ProgressIntervalMinutes < 0 {
+		return fmt.Errorf("progress_interval_minutes cannot be negative")
+	}
+
+	return nil
+}
+
+// ApplyAccount switches the configuration to the named account, replacing the
+// credentials (when set on the account) and isolating the database, cookies,
+// and browser user-data-dir under the account's data directory.
+func (c *Config) ApplyAccount(name string) error {
+	var account *AccountConfig
+	for i := range c.Accounts {
+		if c.Accounts[i].Name == name {
+			account = &c.Accounts[i]
+			break
+		}
+	}
+	if account == nil {
+		return fmt.Errorf("account %q not found in config", name)
+	}
+
+	if account.Email != "" {
+		c.LinkedIn.Email = account.Email
+	}
+	if account.Password != "" {
+		c.LinkedIn.Password = account.Password
+	}
+	if account.TOTPSecret != "" {
+		c.LinkedIn.TOTPSecret = account.TOTPSecret
+	}
+
+	dataDir := account.DataDir
+	if dataDir == "" {
+		dataDir = filepath.Join("data", account.Name)
+	}
+
+	c.Storage.DatabasePath = filepath.Join(dataDir, "linkedin_automation.db")
+	c.Storage.CookiesPath = filepath.Join(dataDir, "cookies.json")
+	c.Browser.UserDataDir = filepath.Join(dataDir, "browser")
+	c.Browser.ScreenshotDir = filepath.Join(dataDir, "screenshots")
+
+	return nil
+}
+
+// noteTemplateData mirrors connection.TemplateData for template validation
+type noteTemplateData struct {
+	FirstName  string
+	LastName   string
+	FullName   string
+	Company    string
+	Headline   string
+	Location   string
+	Connection string
+
+	RecentActivity string
+}
+
+// messageTemplateData mirrors messaging.MessageTemplateData for template validation
+type messageTemplateData struct {
+	FirstName string
+	LastName  string
+	FullName  string
+	Company   string
+	Headline  string
+	Location  string
+	DaysSince int
+
+	MutualConnectionName string
+}
+
+// Sample data templates are checked against
+var (
+	sampleNoteData = noteTemplateData{
+		FirstName: "Jane", LastName: "Doe", FullName: "Jane Doe", Company: "Acme",
+		Headline: "Engineer at Acme", Location: "Remote", Connection: "2nd",
+		RecentActivity: "Why we moved our build to Bazel",
+	}
+	sampleMessageData = messageTemplateData{
+		FirstName: "Jane", LastName: "Doe", FullName: "Jane Doe", Company: "Acme",
+		Headline: "Engineer at Acme", Location: "Remote", DaysSince: 1,
+		MutualConnectionName: "John Roe",
+	}
+)
+
+// ValidateTemplates parses and executes the configured templates against sample
+// data so that syntax errors and unknown fields surface at startup instead of on
+// the first send.
+func (m *MessagingConfig) ValidateTemplates() error {
+	note, message := sampleNoteData, sampleMessageData
+
+	if err := checkTemplate("connection_note_template", m.ConnectionNoteTemplate, note); err != nil {
+		return err
+	}
+	if err := checkTemplate("follow_up_message_template", m.FollowUpMessageTemplate, message); err != nil {
+		return err
+	}
+	if err := checkTemplate("direct_message_template", m.DirectMessageTemplate, message); err != nil {
+		return err
+	}
+
+	seenVariants := make(map[string]bool)
+	for _, variant := range m.ConnectionNoteVariants {
+		if variant.ID == "" || variant.Template == "" {
+			return fmt.Errorf("connection_note_variants entries need an id and a template")
+		}
+		if seenVariants[variant.ID] {
+			return fmt.Errorf("duplicate connection note variant id: %s", variant.ID)
+		}
+		seenVariants[variant.ID] = true
+
+		if err := checkTemplate("connection note variant "+variant.ID, variant.Template, note); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkTemplate parses and executes a single template against sample data
+func checkTemplate(name, text string, data interface{}) error {
+	if text == "" {
+		return nil
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	if err := checkTemplateFields(name, tmpl, data); err != nil {
+		return err
+	}
+
+	if err := tmpl.Execute(io.Discard, data); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
 
 	return nil
 }
@@ -340,16 +922,63 @@ func (c *Config) GetTimeout() time.Duration {
 	return time.Duration(c.Browser.Timeout) * time.Second
 }
 
-// SaveConfig saves the current configuration to a YAML file
-func (c *Config) SaveConfig(configPath string) error {
-	data, err := yaml.Marshal(c)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+// GetBrowserTimezone returns the IANA timezone the page should report:
+// browser.timezone, else schedule.timezone, or "" when it should stay the
+// host's own
+func (c *Config) GetBrowserTimezone() string {
+	tz := c.Browser.Timezone
+	if tz == "" {
+		tz = c.Schedule.Timezone
 	}
+	if tz == "Local" {
+		return ""
+	}
+	return tz
+}
+
+// GetReadyTimeout returns the page readiness timeout, falling back to the browser timeout
+func (c *Config) GetReadyTimeout() time.Duration {
+	if c.Browser.ReadyTimeout <= 0 {
+		return c.GetTimeout()
+	}
+	return time.Duration(c.Browser.ReadyTimeout) * time.Second
+}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+// GetSendEnabledTimeout returns how long to wait for a Send button to enable
+func (c *Config) GetSendEnabledTimeout() time.Duration {
+	if c.Browser.SendEnabledTimeout <= 0 {
+		return 5 * time.Second
 	}
+	return time.Duration(c.Browser.SendEnabledTimeout) * time.Second
+}
 
+// checkReadable reports whether path is an existing file that can be read
+func checkReadable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot use %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a file", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// checkExecutable reports whether path is an existing, executable file
+func checkExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("cannot use %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a browser executable", path)
+	}
+	if info.Mode()&0111 == 0 {
+		return fmt.Errorf("%s is not executable", path)
+	}
 	return nil
 }
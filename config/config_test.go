@@ -3,6 +3,9 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -140,3 +143,306 @@ func TestLoadConfigNonExistent(t *testing.T) {
 		t.Error("Should have default timeout")
 	}
 }
+
+func TestApplyAccount(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LinkedIn.Email = "default@example.com"
+	cfg.LinkedIn.Password = "default"
+	cfg.Accounts = []AccountConfig{
+		{Name: "primary", Email: "primary@example.com", Password: "primary"},
+		{Name: "agency", DataDir: "custom/agency"},
+	}
+
+	if err := cfg.ApplyAccount("primary"); err != nil {
+		t.Fatalf("ApplyAccount should succeed: %v", err)
+	}
+
+	if cfg.LinkedIn.Email != "primary@example.com" {
+		t.Errorf("Email should come from account, got %s", cfg.LinkedIn.Email)
+	}
+
+	expectedDB := filepath.Join("data", "primary", "linkedin_automation.db")
+	if cfg.Storage.DatabasePath != expectedDB {
+		t.Errorf("Expected database path %s, got %s", expectedDB, cfg.Storage.DatabasePath)
+	}
+
+	if cfg.Browser.UserDataDir != filepath.Join("data", "primary", "browser") {
+		t.Errorf("User data dir should be isolated per account, got %s", cfg.Browser.UserDataDir)
+	}
+
+	// Custom data dir, credentials fall back to current values
+	if err := cfg.ApplyAccount("agency"); err != nil {
+		t.Fatalf("ApplyAccount should succeed: %v", err)
+	}
+
+	if cfg.Storage.CookiesPath != filepath.Join("custom", "agency", "cookies.json") {
+		t.Errorf("Cookies path should use account data dir, got %s", cfg.Storage.CookiesPath)
+	}
+
+	if err := cfg.ApplyAccount("missing"); err == nil {
+		t.Error("ApplyAccount should fail for unknown account")
+	}
+}
+
+func TestAccountValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LinkedIn.Email = "test@example.com"
+	cfg.LinkedIn.Password = "password123"
+
+	cfg.Accounts = []AccountConfig{{Name: "a"}, {Name: "a"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validation should fail with duplicate account names")
+	}
+
+	cfg.Accounts = []AccountConfig{{Name: "../escape"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validation should fail with path separators in account name")
+	}
+}
+
+func TestBrowserBinaryValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LinkedIn.Email = "test@example.com"
+	cfg.LinkedIn.Password = "password123"
+	dir := t.TempDir()
+
+	cfg.Browser.BinaryPath = filepath.Join(dir, "missing-chrome")
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validation should fail for a missing browser binary")
+	}
+
+	plain := filepath.Join(dir, "chrome.txt")
+	os.WriteFile(plain, []byte("not a browser"), 0644)
+	cfg.Browser.BinaryPath = plain
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validation should fail for a non-executable browser binary")
+	}
+
+	exe := filepath.Join(dir, "chrome")
+	os.WriteFile(exe, []byte("#!/bin/sh\n"), 0755)
+	cfg.Browser.BinaryPath = exe
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Executable browser binary should be accepted: %v", err)
+	}
+}
+
+func TestInjectScriptValidation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LinkedIn.Email = "test@example.com"
+	cfg.LinkedIn.Password = "password123"
+	dir := t.TempDir()
+
+	cfg.Browser.InjectScriptPath = filepath.Join(dir, "missing.js")
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validation should fail for a missing inject script")
+	}
+
+	cfg.Browser.InjectScriptPath = dir
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validation should fail when the inject script is a directory")
+	}
+
+	script := filepath.Join(dir, "hooks.js")
+	os.WriteFile(script, []byte("window.hooked = true;"), 0644)
+	cfg.Browser.InjectScriptPath = script
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Readable inject script should be accepted: %v", err)
+	}
+}
+
+func TestBrowserLocation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LinkedIn.Email = "test@example.com"
+	cfg.LinkedIn.Password = "password123"
+
+	if tz := cfg.GetBrowserTimezone(); tz != "" {
+		t.Errorf("Default config should keep the host timezone, got %q", tz)
+	}
+	cfg.Schedule.Timezone = "Europe/Berlin"
+	if tz := cfg.GetBrowserTimezone(); tz != "Europe/Berlin" {
+		t.Errorf("Expected the schedule timezone, got %q", tz)
+	}
+	cfg.Browser.Timezone = "America/New_York"
+	if tz := cfg.GetBrowserTimezone(); tz != "America/New_York" {
+		t.Errorf("browser.timezone should win over the schedule, got %q", tz)
+	}
+
+	cfg.Browser.Timezone = "Mars/Olympus_Mons"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validation should fail for an unknown timezone")
+	}
+	cfg.Browser.Timezone = ""
+
+	cfg.Browser.Geolocation = GeolocationConfig{Enabled: true, Latitude: 40.71, Longitude: -74.01, Accuracy: 50}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Valid geolocation should be accepted: %v", err)
+	}
+	cfg.Browser.Geolocation.Latitude = 91
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validation should fail for an out-of-range latitude")
+	}
+}
+
+func TestValidateTemplates(t *testing.T) {
+	cfg := DefaultConfig()
+
+	if err := cfg.Messaging.ValidateTemplates(); err != nil {
+		t.Errorf("Default templates should be valid: %v", err)
+	}
+
+	// Syntax error
+	cfg.Messaging.ConnectionNoteTemplate = "Hi {{.FirstName"
+	err := cfg.Messaging.ValidateTemplates()
+	if err == nil || !strings.Contains(err.Error(), "connection_note_template") {
+		t.Errorf("Expected connection_note_template parse error, got %v", err)
+	}
+	cfg.Messaging.ConnectionNoteTemplate = "Hi {{.FirstName}}" // Reset
+
+	// Unknown field
+	cfg.Messaging.FollowUpMessageTemplate = "Hi {{.Nickname}}"
+	err = cfg.Messaging.ValidateTemplates()
+	if err == nil || !strings.Contains(err.Error(), "follow_up_message_template") {
+		t.Errorf("Expected follow_up_message_template exec error, got %v", err)
+	}
+
+	// DaysSince is only available to follow-up messages
+	cfg.Messaging.FollowUpMessageTemplate = "It's been {{.DaysSince}} days"
+	if err := cfg.Messaging.ValidateTemplates(); err != nil {
+		t.Errorf("DaysSince should be valid in follow-up template: %v", err)
+	}
+}
+
+func TestTemplateFieldChecks(t *testing.T) {
+	// Valid references, including inside if blocks and via $
+	cfg := DefaultConfig()
+	cfg.Messaging.ConnectionNoteTemplate = "Hi {{.FirstName}}{{if $.Company}} at {{.Company}}{{end}}"
+	cfg.Messaging.NormalizeTemplates()
+	if fixes := cfg.Messaging.TemplateCorrections(); len(fixes) != 0 {
+		t.Errorf("Expected no corrections for a valid template, got %v", fixes)
+	}
+	if err := cfg.Messaging.ValidateTemplates(); err != nil {
+		t.Errorf("Expected valid template, got %v", err)
+	}
+
+	// Wrong case is corrected
+	cfg.Messaging.ConnectionNoteTemplate = "Hi {{.firstname}}, {{ .FIRSTNAME }} at {{.company}}. Say hi to .firstname"
+	cfg.Messaging.NormalizeTemplates()
+	want := "Hi {{.FirstName}}, {{ .FirstName }} at {{.Company}}. Say hi to .firstname"
+	if cfg.Messaging.ConnectionNoteTemplate != want {
+		t.Errorf("Expected %q, got %q", want, cfg.Messaging.ConnectionNoteTemplate)
+	}
+	if fixes := cfg.Messaging.TemplateCorrections(); len(fixes) != 3 || !strings.Contains(fixes[0], ".firstname -> .FirstName") {
+		t.Errorf("Unexpected corrections: %v", fixes)
+	}
+	if err := cfg.Messaging.ValidateTemplates(); err != nil {
+		t.Errorf("Corrected template should be valid: %v", err)
+	}
+
+	// Misspelled field suggests the closest match and lists valid fields
+	cfg.Messaging.ConnectionNoteTemplate = "Hi {{.FristName}}"
+	cfg.Messaging.NormalizeTemplates()
+	err := cfg.Messaging.ValidateTemplates()
+	if err == nil || !strings.Contains(err.Error(), "did you mean .FirstName") || !strings.Contains(err.Error(), "RecentActivity") {
+		t.Errorf("Expected a suggestion and the valid fields, got %v", err)
+	}
+
+	// Unknown field: no suggestion, DaysSince isn't available to notes
+	cfg.Messaging.ConnectionNoteTemplate = "It's been {{.DaysSince}} days"
+	err = cfg.Messaging.ValidateTemplates()
+	if err == nil || !strings.Contains(err.Error(), "unknown field .DaysSince") || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("Expected unknown field error without suggestion, got %v", err)
+	}
+}
+
+func TestWriteDefaultConfig(t *testing.T) {
+	os.Setenv("LINKEDIN_EMAIL", "test@test.com")
+	os.Setenv("LINKEDIN_PASSWORD", "password")
+	defer func() {
+		os.Unsetenv("LINKEDIN_EMAIL")
+		os.Unsetenv("LINKEDIN_PASSWORD")
+	}()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := WriteDefaultConfig(path); err != nil {
+		t.Fatalf("WriteDefaultConfig failed: %v", err)
+	}
+	if err := WriteDefaultConfig(path); err == nil {
+		t.Error("WriteDefaultConfig should refuse to overwrite an existing file")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "# Rate limiting") ||
+		!strings.Contains(string(data), "# Connection requests per day") {
+		t.Error("Generated config should contain field documentation")
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Generated config should load: %v", err)
+	}
+	if cfg.RateLimits.MaxConnectionsPerDay != DefaultConfig().RateLimits.MaxConnectionsPerDay {
+		t.Error("Generated config should round-trip default values")
+	}
+}
+
+func TestFieldDocsCoverConfig(t *testing.T) {
+	schema := schemaFor(reflect.TypeOf(Config{}), "")
+
+	var check func(node map[string]interface{}, prefix string)
+	check = func(node map[string]interface{}, prefix string) {
+		properties, _ := node["properties"].(map[string]interface{})
+		for name, child := range properties {
+			path := name
+			if prefix != "" {
+				path = prefix + "." + name
+			}
+			if _, ok := fieldDocs[path]; !ok {
+				t.Errorf("Config key %q has no entry in fieldDocs", path)
+			}
+			check(child.(map[string]interface{}), path)
+		}
+	}
+	check(schema, "")
+
+	if _, err := JSONSchema(); err != nil {
+		t.Errorf("JSONSchema failed: %v", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LinkedIn.Email = "jane@example.com"
+	cfg.LinkedIn.Password = "hunter22"
+	cfg.LinkedIn.TOTPSecret = "JBSWY3DPEHPK3PXP"
+	cfg.Accounts = []AccountConfig{{Name: "alt", Email: "alt@example.com", Password: "s3same!"}}
+
+	redacted := cfg.Redacted()
+	if redacted.LinkedIn.Email != "***om" {
+		t.Errorf("Expected email to keep its last 2 chars, got %q", redacted.LinkedIn.Email)
+	}
+	if redacted.LinkedIn.Password != "<8 chars>" || redacted.LinkedIn.TOTPSecret != "<16 chars>" {
+		t.Errorf("Secrets not masked: %q %q", redacted.LinkedIn.Password, redacted.LinkedIn.TOTPSecret)
+	}
+	if redacted.Accounts[0].Password != "<7 chars>" || redacted.Accounts[0].Name != "alt" {
+		t.Errorf("Account not redacted correctly: %+v", redacted.Accounts[0])
+	}
+	if cfg.LinkedIn.Password != "hunter22" || cfg.Accounts[0].Password != "s3same!" {
+		t.Error("Redacted must not modify the original config")
+	}
+
+	dump, err := cfg.RedactedYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hunter22", "JBSWY3DPEHPK3PXP", "jane@", "s3same!"} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("Dump leaks %q", secret)
+		}
+	}
+	if !strings.Contains(dump, "max_connections_per_day") {
+		t.Error("Dump should include the rate limits")
+	}
+}
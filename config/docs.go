@@ -0,0 +1,298 @@
+// Package config - docs.go handles writing documented config files and a JSON schema
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configHeader is written at the top of generated config files
+const configHeader = `LinkedIn Automation PoC - Configuration File
+Generated with -init. Every key is documented; edit values as needed.
+Credentials are best set via LINKEDIN_EMAIL / LINKEDIN_PASSWORD (or a .env file).`
+
+// fieldDocs documents every config key by its dotted YAML path. Sections and
+// lists get a comment above them, scalar keys a trailing comment. Keep in sync with Config.
+var fieldDocs = map[string]string{
+	"linkedin":                    "LinkedIn credentials (can also be set via environment variables)",
+	"linkedin.email":              "Set via LINKEDIN_EMAIL env var",
+	"linkedin.password":           "Set via LINKEDIN_PASSWORD env var",
+	"linkedin.totp_secret":        "Authenticator-app 2FA secret; set via LINKEDIN_TOTP_SECRET env var (empty = stop at 2FA prompts)",
+	"linkedin.login_attempts":     "Tries of the login flow when the form fails to load or a field or button is missing; 2FA, captcha, checkpoints and wrong credentials are never retried",
+	"linkedin.keep_alive_minutes": "During long runs, revisit the feed this often and re-save rotated session cookies; runs shorter than this never navigate for it (0 disables)",
+
+	"browser":                              "Browser configuration",
+	"browser.headless":                     "Run browser in headless mode",
+	"browser.user_data_dir":                "Store browser data for session persistence",
+	"browser.slow_motion_ms":               "Add delay between browser actions (for debugging)",
+	"browser.timeout_seconds":              "Default timeout for browser operations",
+	"browser.ready_timeout_seconds":        "Max wait for a page to load, go network idle and render content",
+	"browser.send_enabled_timeout_seconds": "Max wait for a disabled Send button to enable before the send fails instead of clicking a dead button",
+	"browser.viewport_width":               "Window width in pixels (ignored when stealth.randomize_viewport is on)",
+	"browser.viewport_height":              "Window height in pixels (ignored when stealth.randomize_viewport is on)",
+	"browser.device_profile":               "Emulated device: desktop, tablet or mobile (sets screen, touch and a matching user agent)",
+	"browser.binary_path":                  "Chrome/Chromium executable to launch instead of the auto-downloaded Chromium",
+	"browser.extra_flags":                  "Extra browser command-line flags, e.g. --proxy-server=http://host:3128",
+	"browser.inject_script_path":           "JavaScript file run at the start of every page after the built-in masking (custom evasions)",
+	"browser.screenshot_on_action":         "Save a screenshot after every sent connection request / message (audit trail)",
+	"browser.screenshot_dir":               "Screenshots go in per-day folders: <dir>/YYYY-MM-DD/<slug>_<time>_<action>.png",
+	"browser.max_screenshots":              "Prune the oldest screenshots beyond this count (0 = unlimited)",
+	"browser.screenshot_on_error":          "Save one screenshot (<slug>_<time>_<action>_error.png) when a connect, message or search action fails",
+	"browser.layout_check":                 "Fingerprint the login, search and profile page layouts each run and warn when they change",
+	"browser.layout_change_threshold":      "Differing fingerprint bits (of 64) that count as a significant layout change",
+	"browser.geolocation":                  "Position reported to navigator.geolocation; match it to your proxy's exit location",
+	"browser.geolocation.enabled":          "Override the reported position",
+	"browser.geolocation.latitude":         "Reported latitude (-90 to 90)",
+	"browser.geolocation.longitude":        "Reported longitude (-180 to 180)",
+	"browser.geolocation.accuracy":         "Reported accuracy in meters",
+	"browser.timezone":                     "IANA timezone the page reports, e.g. America/New_York; keep it consistent with the proxy (empty = schedule.timezone, \"Local\" = no override)",
+
+	"stealth":                            "Stealth/Anti-detection settings",
+	"stealth.mouse_speed_min":            "Slowest mouse movement speed multiplier",
+	"stealth.mouse_speed_max":            "Fastest mouse movement speed multiplier",
+	"stealth.mouse_overshoot":            "Occasionally overshoot the target and correct back",
+	"stealth.mouse_micro_corrections":    "Small jitters near the end of a mouse movement",
+	"stealth.click_area_percent":         "Central share (width and height) of a button that hovers and clicks land in at random; 0 = dead center",
+	"stealth.typing_delay_min_ms":        "Shortest delay between keystrokes",
+	"stealth.typing_delay_max_ms":        "Longest delay between keystrokes",
+	"stealth.typing_mistake_rate":        "Chance of a typo per character (0.02 = 2%)",
+	"stealth.typing_correction_variance": "Chance a typo is noticed late (rarely left uncorrected)",
+	"stealth.typing_profile":             "Typing cadence: fast, average or hunt-and-peck (overrides the delay range and mistake rate; empty uses them)",
+	"stealth.scroll_speed_min":           "Smallest scroll step in pixels",
+	"stealth.scroll_speed_max":           "Largest scroll step in pixels",
+	"stealth.scroll_back_chance":         "Chance to scroll back a little after scrolling (0.15 = 15%)",
+	"stealth.action_delay_min_ms":        "Shortest pause between actions",
+	"stealth.action_delay_max_ms":        "Longest pause between actions",
+	"stealth.page_load_wait_min_ms":      "Shortest pause after a page loads",
+	"stealth.page_load_wait_max_ms":      "Longest pause after a page loads",
+	"stealth.reading_delay_min_ms":       "Shortest time spent reading a profile",
+	"stealth.reading_delay_max_ms":       "Longest time spent reading a profile",
+	"stealth.reading_max_chars":          "Visible text length that gets reading_delay_max_ms; shorter profiles dwell proportionally less",
+	"stealth.randomize_viewport":         "Pick a common screen size at launch",
+	"stealth.disable_webdriver":          "Hide the navigator.webdriver flag",
+	"stealth.random_user_agent":          "Pick a realistic user agent at launch",
+	"stealth.persist_fingerprint":        "Keep the user agent, screen, CPU cores and languages picked for a browser profile in <user_data_dir>/fingerprint.json and reuse them every run",
+	"stealth.shuffle_workflow_steps":     "Vary the order of independent workflow steps each cycle (search still precedes connect)",
+	"stealth.tab_blur_chance":            "Chance of briefly switching away from the tab between actions (0.1 = 10%); sessions that never lose focus look automated",
+	"stealth.random_seed":                "Fixed seed that makes delays, mouse paths and note selection reproducible; 0 = time-based",
+
+	"rate_limits":                                     "Rate limiting",
+	"rate_limits.max_connections_per_day":             "Connection requests per day (0-100)",
+	"rate_limits.max_connections_per_week":            "Connection requests over the trailing 7 days (0 = no weekly cap); LinkedIn enforces a weekly invitation limit",
+	"rate_limits.max_connections_per_company_per_day": "Connection requests per day to people at the same company, so outreach doesn't look coordinated (0 = no cap)",
+	"rate_limits.max_messages_per_day":                "Messages per day (0-150)",
+	"rate_limits.max_profile_views_per_day":           "Profile visits per day",
+	"rate_limits.max_searches_per_hour":               "Searches per hour",
+	"rate_limits.cooldown_minutes":                    "Pause between full workflow cycles",
+	"rate_limits.hourly_cooldown_minutes":             "Cooldown by hour of day, e.g. {12: 45, 13: 30, 10: 3}; hours not listed use cooldown_minutes",
+	"rate_limits.min_delay_between_actions_ms":        "Shortest gap between rate-limited actions",
+	"rate_limits.max_delay_between_actions_ms":        "Longest gap between rate-limited actions",
+	"rate_limits.burst_size":                          "Up to this many actions may run back to back (0.5-1.5s apart) before the delays above apply; humans cluster activity (0 disables)",
+	"rate_limits.burst_refill_seconds":                "One burst action is regained every this many seconds",
+	"rate_limits.failure_cooldown_after":              "Consecutive throttled or network failures in a batch before cooling down (0 disables)",
+	"rate_limits.failure_cooldown_minutes":            "First cooldown after repeated failures; doubles with each further failure",
+	"rate_limits.failure_abort_after":                 "Consecutive throttled or network failures that abort the batch (0 disables)",
+	"rate_limits.halt_on_account_warning":             "Stop the run when the feed shows an account warning banner (\"We noticed unusual activity\")",
+	"rate_limits.low_budget_threshold":                "When both remaining connections and messages for today are at or below this, reorder the workflow (0 disables)",
+	"rate_limits.low_budget_priority":                 "Steps that run first when budgets are low: messages (follow up accepted connections) or connections (new invites)",
+
+	"rate_limits.account_maturity":                 "Warmup ramp for new accounts: limits scale from start_percent at min_age_days up to the values above at warmup_days",
+	"rate_limits.account_maturity.enabled":         "Apply the warmup ramp (off by default; without account_created each run visits your profile to read the join date)",
+	"rate_limits.account_maturity.account_created": `YYYY-MM-DD; when empty the "Joined" date is scraped from your profile`,
+	"rate_limits.account_maturity.min_age_days":    "No connection requests before the account is this old",
+	"rate_limits.account_maturity.warmup_days":     "Full limits apply from this age",
+	"rate_limits.account_maturity.start_percent":   "Share of limits allowed once min_age_days is reached",
+
+	"search":                            "Search configuration",
+	"search.default_job_title":          "Used when -search is not given",
+	"search.default_company":            "Used when -company is not given",
+	"search.default_location":           "Used when -location is not given",
+	"search.keywords":                   "Extra keywords added to every search",
+	"search.max_results_per_search":     "Stop collecting after this many profiles",
+	"search.max_pages_per_search":       "Stop paginating after this many result pages (0 = only the built-in safety cap)",
+	"search.sort_by_mutual_connections": "Process profiles with more mutual connections first (they accept more often)",
+	"search.connect_degrees":            "Only send invites to profiles of these degrees, e.g. [2nd] (empty = everyone)",
+	"search.skip_missing_headline":      "Leave profiles with an empty headline out of search results and connect runs (often inactive accounts)",
+	"search.skip_missing_company":       "Leave profiles with no company out of search results and connect runs",
+	"search.enrich_workers":             "Tabs that visit search results in parallel to fill in headline, company and location (0 = off); each visit counts as a profile view",
+	"search.decline_after_days":         "Treat a pending invite that left the sent invitations list this many days after sending as declined; declined profiles are never invited again (0 = only invites LinkedIn marks ignored/withdrawn)",
+
+	"search.scoring":                "Relevance score (0-100) per result; weights are relative, 0 ignores a signal",
+	"search.scoring.enabled":        "Process the best-scoring profiles first (overrides sort_by_mutual_connections)",
+	"search.scoring.keyword_weight": "Job title / keyword / company matches in the headline",
+	"search.scoring.mutual_weight":  "Mutual connection count",
+	"search.scoring.degree_weight":  "Connection degree (2nd scores above 3rd+)",
+	"search.scoring.mutual_cap":     "Mutual connections that earn the full mutual score",
+
+	"messaging":                            "Messaging configuration (templates use {{.FirstName}}, {{.Company}}, ...)",
+	"messaging.connection_note_template":   "Note sent with connection requests",
+	"messaging.connection_note_variants":   "A/B test notes: list of {id, template} picked at random per request (overrides connection_note_template); compare acceptance with -stats",
+	"messaging.follow_up_message_template": "Message sent after a request is accepted",
+	"messaging.direct_message_template":    "Message sent to existing connections in message mode",
+	"messaging.max_note_length":            "Upper bound; a smaller maxlength on LinkedIn's note field (e.g. free accounts) wins",
+	"messaging.send_note_percentage":       "% of requests sent with a note; the rest go out as bare invitations",
+	"messaging.max_message_length":         "Longer messages are truncated",
+	"messaging.use_recent_activity":        "Scroll to the profile's Activity section and expose their latest post as {{.RecentActivity}} in connection notes (slower)",
+	"messaging.recent_message_days":        "With -profiles-from-db, skip anyone sent a message within this many days (0 = message everyone matched)",
+
+	"storage":                       "Storage configuration",
+	"storage.database_path":         "SQLite database file",
+	"storage.cookies_path":          "Saved session cookies",
+	"storage.cookie_domains":        "LinkedIn domains whose cookies are saved and restored (subdomains included)",
+	"storage.backup_enabled":        "Periodically back up the database",
+	"storage.backup_interval_hours": "Hours between database backups",
+
+	"logging":                           "Logging configuration",
+	"logging.level":                     "debug, info, warn, error",
+	"logging.format":                    "text or json",
+	"logging.output_file":               "Log file (also printed to the console)",
+	"logging.max_size_mb":               "Rotate the log file at this size",
+	"logging.max_backups":               "Rotated log files to keep",
+	"logging.trace_buffer_size":         "Recent decisions (rate-limit checks, skips, delays) kept for debugging; 0 disables",
+	"logging.progress_interval_minutes": "At most this often, log a one-line summary of this session's connects, messages and failures against today's caps; 0 disables, and it is off with the json format",
+	"logging.trace_dir":                 "Trace is dumped here on error or when sent SIGUSR2",
+	"logging.redact":                    "Mask the values of redact_fields, and profile URLs and emails in messages and errors, so logs can be shared",
+	"logging.redact_fields":             "Field names to mask when redact is on",
+	"logging.redact_hash":               "Replace values with a short hash instead of [REDACTED], so entries stay correlatable",
+
+	"schedule":                          "Activity scheduling",
+	"schedule.enabled":                  "Only run within operating hours",
+	"schedule.start_hour":               "First hour of activity (0-23)",
+	"schedule.end_hour":                 "Activity stops at this hour (0-23)",
+	"schedule.work_days_only":           "Only Mon-Fri",
+	"schedule.break_min_minutes":        "Shortest break",
+	"schedule.break_max_minutes":        "Longest break",
+	"schedule.session_max_minutes":      "Take a break at the latest after this long",
+	"schedule.timezone":                 "Timezone for operating hours",
+	"schedule.start_jitter_minutes":     "Wait a random 0..N minutes once operating hours are reached, so cron launches don't start at the same minute (0 disables)",
+	"schedule.pause_file":               "Pause before the next action while this file exists (kill -USR1 <pid> also toggles pause)",
+	"schedule.pause_keep_alive_minutes": "Browse the feed this often while paused to keep the session warm (0 disables)",
+	"schedule.watchdog_minutes":         "Log out and exit with code 3 when no action succeeds for this long, not counting cooldowns, breaks or pauses (0 disables)",
+
+	"accounts": "Multiple accounts (select one with -account <name>); each gets an isolated database, cookies file, and browser profile under data_dir (defaults to ./data/<name>)",
+}
+
+// SaveConfig saves the current configuration to a YAML file, documenting every key
+func (c *Config) SaveConfig(configPath string) error {
+	var doc yaml.Node
+	if err := doc.Encode(c); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	annotateNode(&doc, "")
+
+	var buf bytes.Buffer
+	for _, line := range strings.Split(configHeader, "\n") {
+		buf.WriteString("# " + line + "\n")
+	}
+	buf.WriteString("\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	encoder.Close()
+
+	if err := os.WriteFile(configPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteDefaultConfig writes a fully documented default config to configPath.
+// It refuses to overwrite an existing file.
+func WriteDefaultConfig(configPath string) error {
+	if _, err := os.Stat(configPath); err == nil {
+		return fmt.Errorf("%s already exists", configPath)
+	}
+	return DefaultConfig().SaveConfig(configPath)
+}
+
+// annotateNode attaches fieldDocs comments to the mapping keys under node
+func annotateNode(node *yaml.Node, prefix string) {
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			annotateNode(child, prefix)
+		}
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+
+		if doc, ok := fieldDocs[path]; ok {
+			if value.Kind != yaml.ScalarNode {
+				key.HeadComment = doc
+			} else {
+				key.LineComment = doc
+			}
+		}
+		annotateNode(value, path)
+	}
+}
+
+// JSONSchema returns a JSON schema describing the config file, for editor validation
+func JSONSchema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "LinkedIn Automation PoC configuration"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaFor builds the schema for a config type, using fieldDocs as descriptions
+func schemaFor(t reflect.Type, path string) map[string]interface{} {
+	schema := map[string]interface{}{}
+	if doc, ok := fieldDocs[path]; ok {
+		schema["description"] = doc
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			properties[name] = schemaFor(field.Type, fieldPath)
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["additionalProperties"] = false
+	case reflect.Slice:
+		schema["type"] = "array"
+		schema["items"] = schemaFor(t.Elem(), "")
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = schemaFor(t.Elem(), "")
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int64:
+		schema["type"] = "integer"
+	case reflect.Float64:
+		schema["type"] = "number"
+	case reflect.String:
+		schema["type"] = "string"
+	}
+
+	return schema
+}
@@ -0,0 +1,58 @@
+// Package config - redact.go handles masking credentials for logging the effective configuration
+package config
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted returns a copy of the configuration that is safe to log: email
+// addresses keep only their last two characters, and passwords and TOTP
+// secrets are replaced by their length. Slices and maps other than the
+// accounts are shared with c, so treat the copy as read-only.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.LinkedIn.Email = maskIdentity(c.LinkedIn.Email)
+	redacted.LinkedIn.Password = maskSecret(c.LinkedIn.Password)
+	redacted.LinkedIn.TOTPSecret = maskSecret(c.LinkedIn.TOTPSecret)
+
+	redacted.Accounts = make([]AccountConfig, len(c.Accounts))
+	for i, account := range c.Accounts {
+		account.Email = maskIdentity(account.Email)
+		account.Password = maskSecret(account.Password)
+		account.TOTPSecret = maskSecret(account.TOTPSecret)
+		redacted.Accounts[i] = account
+	}
+	return &redacted
+}
+
+// RedactedYAML renders Redacted as YAML for the startup log
+func (c *Config) RedactedYAML() (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return "", fmt.Errorf("failed to encode config: %w", err)
+	}
+	encoder.Close()
+	return buf.String(), nil
+}
+
+// maskSecret hides a secret entirely, keeping only its length
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf("<%d chars>", len([]rune(value)))
+}
+
+// maskIdentity hides all but the last two characters of a login
+func maskIdentity(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 2 {
+		return maskSecret(value)
+	}
+	return "***" + string(runes[len(runes)-2:])
+}
@@ -0,0 +1,205 @@
+// Package config - templates.go handles checking and normalizing the field
+// references in message templates
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateFields returns the field names a template can use on data
+func templateFields(data interface{}) []string {
+	t := reflect.TypeOf(data)
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fields = append(fields, t.Field(i).Name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// templateRefs returns the top-level fields a template references, e.g.
+// "FirstName" for {{.FirstName}} or {{if $.FirstName}}. References inside
+// range/with bodies are skipped because dot is rebound there.
+func templateRefs(tmpl *template.Template) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			refs = append(refs, name)
+		}
+	}
+
+	var walkPipe func(p *parse.PipeNode)
+	walkPipe = func(p *parse.PipeNode) {
+		if p == nil {
+			return
+		}
+		for _, cmd := range p.Cmds {
+			for _, arg := range cmd.Args {
+				switch a := arg.(type) {
+				case *parse.FieldNode:
+					add(a.Ident[0])
+				case *parse.VariableNode:
+					if a.Ident[0] == "$" && len(a.Ident) > 1 {
+						add(a.Ident[1])
+					}
+				case *parse.PipeNode:
+					walkPipe(a)
+				}
+			}
+		}
+	}
+
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.ActionNode:
+			walkPipe(n.Pipe)
+		case *parse.IfNode:
+			walkPipe(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walkPipe(n.Pipe)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walkPipe(n.Pipe)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walkPipe(n.Pipe)
+		}
+	}
+
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root)
+	}
+	return refs
+}
+
+// checkTemplateFields reports the first field reference that data doesn't
+// have, suggesting the closest valid field and listing all of them
+func checkTemplateFields(name string, tmpl *template.Template, data interface{}) error {
+	fields := templateFields(data)
+	valid := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		valid[field] = true
+	}
+
+	for _, ref := range templateRefs(tmpl) {
+		if valid[ref] {
+			continue
+		}
+		hint := ""
+		if suggestion := closestField(ref, fields); suggestion != "" {
+			hint = fmt.Sprintf(" (did you mean .%s?)", suggestion)
+		}
+		return fmt.Errorf("invalid %s: unknown field .%s%s; valid fields: %s",
+			name, ref, hint, strings.Join(fields, ", "))
+	}
+	return nil
+}
+
+// closestField returns the field within edit distance 2 of ref, ignoring
+// case, or "" if there is none
+func closestField(ref string, fields []string) string {
+	best, bestDist := "", 3
+	for _, field := range fields {
+		if d := editDistance(strings.ToLower(ref), strings.ToLower(field)); d < bestDist {
+			best, bestDist = field, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// templateActionPattern matches a {{ ... }} action
+var templateActionPattern = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+
+// normalizeTemplate rewrites field references that only differ in case from
+// a valid field, e.g. {{.firstname}} to {{.FirstName}}. It returns the new
+// text and a description of each correction.
+func normalizeTemplate(text string, data interface{}) (string, []string) {
+	tmpl, err := template.New("").Parse(text)
+	if err != nil {
+		// Syntax errors are reported by ValidateTemplates
+		return text, nil
+	}
+
+	fields := templateFields(data)
+	var fixes []string
+	for _, ref := range templateRefs(tmpl) {
+		for _, field := range fields {
+			if ref == field || !strings.EqualFold(ref, field) {
+				continue
+			}
+			refPattern := regexp.MustCompile(`(^|[^\w.])\.` + regexp.QuoteMeta(ref) + `\b`)
+			text = templateActionPattern.ReplaceAllStringFunc(text, func(action string) string {
+				return refPattern.ReplaceAllString(action, "${1}."+field)
+			})
+			fixes = append(fixes, fmt.Sprintf(".%s -> .%s", ref, field))
+		}
+	}
+	return text, fixes
+}
+
+// NormalizeTemplates corrects field references whose case is wrong, such as
+// {{.firstname}}, in every configured template. The corrections are
+// available from TemplateCorrections.
+func (m *MessagingConfig) NormalizeTemplates() {
+	m.templateCorrections = nil
+	fix := func(name string, text *string, data interface{}) {
+		fixed, fixes := normalizeTemplate(*text, data)
+		*text = fixed
+		for _, f := range fixes {
+			m.templateCorrections = append(m.templateCorrections, name+": "+f)
+		}
+	}
+
+	fix("connection_note_template", &m.ConnectionNoteTemplate, sampleNoteData)
+	fix("follow_up_message_template", &m.FollowUpMessageTemplate, sampleMessageData)
+	fix("direct_message_template", &m.DirectMessageTemplate, sampleMessageData)
+	for i := range m.ConnectionNoteVariants {
+		variant := &m.ConnectionNoteVariants[i]
+		fix("connection note variant "+variant.ID, &variant.Template, sampleNoteData)
+	}
+}
+
+// TemplateCorrections lists the corrections made by NormalizeTemplates, e.g.
+// "connection_note_template: .firstname -> .FirstName"
+func (m *MessagingConfig) TemplateCorrections() []string {
+	return m.templateCorrections
+}
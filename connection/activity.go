@@ -0,0 +1,77 @@
+// Package connection - activity.go handles finding a profile's latest post to mention in notes
+package connection
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+// maxRecentActivityLength keeps the post snippet short enough to quote in a 300-character note
+const maxRecentActivityLength = 80
+
+// activityPostSelectors match the text of the newest post in the profile's Activity section
+var activityPostSelectors = []string{
+	"section:has(#content_collections) .update-components-text",
+	"section:has(#content_collections) .feed-shared-inline-show-more-text",
+	"section:has(#content_collections) span.break-words",
+}
+
+// scrapeRecentActivity scrolls the open profile down to its Activity section
+// and returns a short snippet of the latest post, or "" if there is none.
+// The page is scrolled back to the top card afterwards.
+func (c *ConnectionManager) scrapeRecentActivity() string {
+	scrolled := 0
+	defer func() {
+		if scrolled > 0 {
+			c.stealth.HumanScroll(c.rodPage(), "up", scrolled)
+			c.stealth.ActionDelay()
+		}
+	}()
+
+	// The section is lazy-loaded further down the profile
+	for i := 0; i < 4; i++ {
+		el, err := browser.FirstElement(c.pager, time.Second, activityPostSelectors...)
+		if err == nil {
+			text, err := el.Text()
+			if err != nil {
+				return ""
+			}
+			activity := parseRecentActivity(text)
+			if activity != "" {
+				c.logger.WithField("recent_activity", activity).Debug("Found recent activity")
+			}
+			return activity
+		}
+
+		c.stealth.HumanScroll(c.rodPage(), "down", 500)
+		scrolled += 500
+		c.stealth.ActionDelay()
+	}
+
+	c.logger.Debug("No recent activity found on profile")
+	return ""
+}
+
+// parseRecentActivity reduces a post to its first line, cut at a word
+// boundary to maxRecentActivityLength characters
+func parseRecentActivity(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) <= maxRecentActivityLength {
+			return line
+		}
+
+		cut := string([]rune(line)[:maxRecentActivityLength])
+		if i := strings.LastIndex(cut, " "); i > 0 {
+			cut = cut[:i]
+		}
+		return strings.TrimRight(cut, " ,.;:-") + "..."
+	}
+	return ""
+}
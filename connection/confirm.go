@@ -0,0 +1,54 @@
+// Package connection - confirm.go handles positive confirmation that an invitation was sent
+package connection
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnconfirmedSend is returned when the send click produced no success
+// indicator, so the request is not recorded as sent
+var ErrUnconfirmedSend = errors.New("connection request not confirmed")
+
+// confirmSendTimeout bounds the wait for a success indicator after sending
+const confirmSendTimeout = 5 * time.Second
+
+// Elements that show an invitation went out
+const (
+	pendingButtonSelector = "button[aria-label^='Pending'], button[aria-label*='withdraw invitation' i]"
+	sentToastSelector     = ".artdeco-toast-item, [role='alert']"
+)
+
+// Text of the same indicators, as JS regex literals for ElementR
+const (
+	// pendingButtonPattern matches the profile action button once an invitation is pending
+	pendingButtonPattern = `/^\s*pending\s*$/i`
+
+	// invitationSentPattern matches LinkedIn's "invitation sent" toast text
+	invitationSentPattern = `/invitation (was )?sent|your invitation to .+ was sent/i`
+)
+
+// confirmSent waits for a positive sign that the invitation went out: the
+// Connect button turning into "Pending" or an invitation-sent toast.
+// It returns ErrUnconfirmedSend if neither appears in time.
+func (c *ConnectionManager) confirmSent() error {
+	page := c.rodPage()
+	if page == nil {
+		c.tracer.Record("connection", "unconfirmed_send", nil)
+		return ErrUnconfirmedSend
+	}
+
+	el, err := page.Timeout(confirmSendTimeout).Race().
+		Element(pendingButtonSelector).
+		ElementR("main button", pendingButtonPattern).
+		ElementR(sentToastSelector, invitationSentPattern).
+		Do()
+	if err != nil {
+		c.tracer.Record("connection", "unconfirmed_send", nil)
+		return ErrUnconfirmedSend
+	}
+
+	text, _ := el.Text()
+	c.logger.WithField("indicator", text).Debug("Invitation confirmed")
+	return nil
+}
@@ -4,12 +4,18 @@ package connection
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
 	"github.com/nikshitha/linkedin-automation-poc/config"
 	"github.com/nikshitha/linkedin-automation-poc/logger"
 	"github.com/nikshitha/linkedin-automation-poc/search"
@@ -17,6 +23,21 @@ import (
 	"github.com/nikshitha/linkedin-automation-poc/storage"
 )
 
+// ErrTooManyFailures is returned when a bulk run is aborted after too many
+// consecutive failures (see rate_limits.failure_abort_after)
+var ErrTooManyFailures = errors.New("too many consecutive failures")
+
+// ErrCompanyLimitReached is returned when today's requests to the profile's
+// company reached rate_limits.max_connections_per_company_per_day
+var ErrCompanyLimitReached = errors.New("daily connection limit for company reached")
+
+// ErrAlreadySent is returned when a request was sent to the profile before
+var ErrAlreadySent = errors.New("connection request already sent")
+
+// ErrRateLimited is returned when the connection rate limit leaves no room
+// for another request
+var ErrRateLimited = fmt.Errorf("connection %w", stealth.ErrRateLimited)
+
 // ConnectionManager handles connection request operations
 type ConnectionManager struct {
 	config      *config.Config
@@ -24,7 +45,16 @@ type ConnectionManager struct {
 	stealth     *stealth.StealthManager
 	rateLimiter *stealth.RateLimiter
 	db          *storage.Database
-	page        *rod.Page
+	pager       browser.Pager
+	rand        *rand.Rand
+	ctx         context.Context
+	tracer      *logger.Tracer
+	layout      *browser.LayoutMonitor
+	pause       *stealth.PauseController
+	watchdog    *stealth.Watchdog
+
+	// Called when a click moves the session to a new tab
+	onPageChange func(page *rod.Page)
 }
 
 // NewConnectionManager creates a new connection manager
@@ -35,12 +65,45 @@ func NewConnectionManager(cfg *config.Config, log *logger.Logger, s *stealth.Ste
 		stealth:     s,
 		rateLimiter: rl,
 		db:          db,
+		rand:        stealth.NewRand(cfg.Stealth.RandomSeed, stealth.StreamConnection),
+		ctx:         context.Background(),
 	}
 }
 
 // SetPage sets the page instance
-func (c *ConnectionManager) SetPage(page *rod.Page) {
-	c.page = page
+func (c *ConnectionManager) SetPage(page browser.Pager) {
+	c.pager = page
+}
+
+// rodPage returns the browser page behind the pager for mouse, keyboard and
+// screenshot work, or nil when a fake pager is set
+func (c *ConnectionManager) rodPage() *rod.Page {
+	return browser.RodPage(c.pager)
+}
+
+// SetTracer sets the decision tracer
+func (c *ConnectionManager) SetTracer(t *logger.Tracer) {
+	c.tracer = t
+}
+
+// SetLayoutMonitor sets the monitor that fingerprints the profile page layout
+func (c *ConnectionManager) SetLayoutMonitor(m *browser.LayoutMonitor) {
+	c.layout = m
+}
+
+// SetPauseController sets the controller checked before each bulk request
+func (c *ConnectionManager) SetPauseController(p *stealth.PauseController) {
+	c.pause = p
+}
+
+// SetWatchdog sets the watchdog suspended during failure cooldowns
+func (c *ConnectionManager) SetWatchdog(w *stealth.Watchdog) {
+	c.watchdog = w
+}
+
+// SetContext sets the run context; bulk operations stop between requests once it is done
+func (c *ConnectionManager) SetContext(ctx context.Context) {
+	c.ctx = ctx
 }
 
 // TemplateData holds data for personalizing connection notes
@@ -52,10 +115,14 @@ type TemplateData struct {
 	Headline   string
 	Location   string
 	Connection string
+
+	// Snippet of their latest post; empty unless messaging.use_recent_activity
+	// is on and one was found, so guard it with {{if .RecentActivity}}
+	RecentActivity string
 }
 
 // SendConnectionRequest sends a connection request to a profile
-func (c *ConnectionManager) SendConnectionRequest(profile *search.SearchResult, customNote string) error {
+func (c *ConnectionManager) SendConnectionRequest(profile *search.SearchResult, customNote string) (err error) {
 	c.logger.WithFields(map[string]interface{}{
 		"profile_url": profile.ProfileURL,
 		"name":        profile.Name,
@@ -64,7 +131,7 @@ func (c *ConnectionManager) SendConnectionRequest(profile *search.SearchResult,
 	// Check rate limits
 	if !c.rateLimiter.CanPerformAction("connection") {
 		remaining := c.rateLimiter.GetRemainingActions("connection")
-		return fmt.Errorf("connection rate limit reached (remaining: %d)", remaining)
+		return fmt.Errorf("%w (remaining: %d)", ErrRateLimited, remaining)
 	}
 
 	// Check if already sent
@@ -74,62 +141,129 @@ func (c *ConnectionManager) SendConnectionRequest(profile *search.SearchResult,
 	}
 	if hasSent {
 		c.logger.Warn("Connection request already sent to this profile")
-		return fmt.Errorf("connection request already sent to %s", profile.ProfileURL)
+		c.tracer.Record("connection", "dedup_skip", map[string]interface{}{"profile_url": profile.ProfileURL})
+		return fmt.Errorf("%w to %s", ErrAlreadySent, profile.ProfileURL)
 	}
 
+	// Don't invite a whole company's staff in one day
+	if err := c.checkCompanyLimit(profile); err != nil {
+		return err
+	}
+
+	defer browser.ScreenshotOnError(c.pager, &c.config.Browser, c.logger, "connection", profile.ProfileURL, &err)
+
 	// Navigate to profile
 	err = c.navigateToProfile(profile.ProfileURL)
 	if err != nil {
 		return fmt.Errorf("failed to navigate to profile: %w", err)
 	}
 
-	// Record profile view
-	c.rateLimiter.RecordAction("profile_view")
-	c.db.IncrementProfileViews()
+	// Record profile view; it's part of the connect, so it doesn't use up the profile view cap
+	c.rateLimiter.RecordProfileView(stealth.ViewIncidental)
+	c.db.IncrementProfileViews(stealth.ViewIncidental)
 
-	// Random behavior on profile page
-	c.stealth.RandomMouseWander(c.page)
-	c.stealth.ThinkingDelay()
+	// Random behavior on profile page; dwell in proportion to its content
+	c.stealth.RandomMouseWander(c.rodPage())
+	c.stealth.ReadingDelay(c.rodPage())
 
 	// Scroll down to simulate reading profile
-	c.stealth.HumanScroll(c.page, "down", 300)
+	c.stealth.HumanScroll(c.rodPage(), "down", 300)
 	c.stealth.ActionDelay()
 
+	// Look at their latest post before the invite dialog covers the profile
+	recentActivity := ""
+	if c.config.Messaging.UseRecentActivity && customNote == "" {
+		recentActivity = c.scrapeRecentActivity()
+	}
+
 	// Find and click Connect button
 	err = c.clickConnectButton()
 	if err != nil {
 		return fmt.Errorf("failed to click connect button: %w", err)
 	}
 
-	// Generate personalized note if not provided
+	// LinkedIn shows its limit notice instead of the invite dialog
+	if err := c.checkInvitationLimit(); err != nil {
+		return err
+	}
+
+	// Many note-less invites (often 3rd-degree) go out on the Connect click itself
+	directSend := c.detectInviteFlow() == inviteDirect
+
+	// Generate personalized note if not provided. A configurable share of
+	// template-based requests go out as bare invitations for A/B testing.
+	// Template-based requests record their variant for experiment results.
+	// Direct sends had no choice of note, so they stay out of the experiment.
 	note := customNote
-	if note == "" {
-		note, err = c.generatePersonalizedNote(profile)
-		if err != nil {
-			c.logger.WithError(err).Warn("Failed to generate personalized note, sending without note")
+	variant := ""
+	if directSend {
+		if note != "" {
+			c.logger.Warn("Invitation was sent without a dialog, custom note not added")
 			note = ""
 		}
+	} else if note == "" {
+		variant = NoNoteVariant
+		if c.shouldAttachNote() {
+			note, variant, err = c.generatePersonalizedNote(profile, recentActivity)
+			if err != nil || note == "" {
+				if err != nil {
+					c.logger.WithError(err).Warn("Failed to generate personalized note, sending without note")
+				}
+				note, variant = "", NoNoteVariant
+			}
+		}
 	}
 
 	// Send with note if applicable
-	if note != "" {
+	switch {
+	case directSend:
+		c.logger.Info("Connect click sent the invitation directly, no send button to click")
+		c.captureSendScreenshot("connection")
+	case note != "":
 		err = c.addConnectionNote(note)
 		if err != nil {
 			c.logger.WithError(err).Warn("Failed to add note, sending without note")
+			note = ""
+			if variant != "" {
+				variant = NoNoteVariant
+			}
 		}
-	}
 
-	// Click Send button
-	err = c.clickSendButton()
+		if errors.Is(err, errNoteUnavailable) {
+			// The dialog only offers sending a bare invitation
+			err = c.clickSendWithoutNoteButton()
+		} else {
+			err = c.clickSendButton()
+		}
+	default:
+		err = c.clickSendWithoutNoteButton()
+	}
+	if limitErr := c.checkInvitationLimit(); limitErr != nil {
+		return limitErr
+	}
 	if err != nil {
 		return fmt.Errorf("failed to send connection request: %w", err)
 	}
 
+	// Only record the request once LinkedIn shows it as sent
+	if err := c.confirmSent(); err != nil {
+		c.logger.WithField("profile_url", profile.ProfileURL).Warn("No confirmation after sending, not recording the request")
+		return err
+	}
+
+	c.tracer.Record("connection", "sent", map[string]interface{}{
+		"profile_url": profile.ProfileURL,
+		"direct":      directSend,
+		"has_note":    note != "",
+		"note_length": len(note),
+		"variant":     variant,
+	})
+
 	// Record the connection request
 	c.rateLimiter.RecordAction("connection")
 
 	// Save to database
-	c.saveConnectionRequest(profile, note)
+	c.saveConnectionRequest(profile, note, variant)
 
 	c.logger.ConnectionRequest(profile.ProfileURL, "sent", note)
 
@@ -143,25 +277,17 @@ func (c *ConnectionManager) SendConnectionRequest(profile *search.SearchResult,
 func (c *ConnectionManager) navigateToProfile(profileURL string) error {
 	c.logger.WithField("url", profileURL).Debug("Navigating to profile")
 
-	err := c.page.Navigate(profileURL)
+	err := browser.NavigateAndWaitReady(c.pager, profileURL, c.config.GetReadyTimeout(),
+		".pv-top-card", ".profile-background-image", ".scaffold-layout__main")
 	if err != nil {
-		return err
+		return fmt.Errorf("profile content not loaded: %w", err)
 	}
 
 	c.stealth.PageLoadDelay()
-	err = c.page.WaitLoad()
-	if err != nil {
-		return err
-	}
+	c.layout.Check(c.pager, "profile")
 
 	// Apply fingerprint masking
-	c.stealth.ApplyFingerprintMasking(c.page)
-
-	// Wait for profile content
-	_, err = c.page.Timeout(10 * time.Second).Element(".pv-top-card, .profile-background-image, .scaffold-layout__main")
-	if err != nil {
-		return fmt.Errorf("profile content not loaded: %w", err)
-	}
+	c.stealth.ApplyFingerprintMasking(c.rodPage())
 
 	return nil
 }
@@ -185,62 +311,85 @@ func (c *ConnectionManager) clickConnectButton() error {
 		"button.pv-s-profile-actions__overflow-toggle", // More button
 	}
 
-	var connectButton *rod.Element
-	var err error
+	connectButton, err := browser.FirstElement(c.pager, 5*time.Second, connectSelectors...)
 
-	for _, selector := range connectSelectors {
-		connectButton, err = c.page.Timeout(3 * time.Second).Element(selector)
-		if err == nil && connectButton != nil {
-			// Verify it's visible and clickable
-			visible, _ := connectButton.Visible()
-			if visible {
-				break
-			}
-		}
-		connectButton = nil
+	// The primary action is often Follow or Message with Connect only in the
+	// More dropdown, so a non-Connect button doesn't mean we're connected yet
+	primaryState := "none"
+	if err == nil {
+		primaryState = primaryButtonState(connectButton)
 	}
 
-	// If not found, try the More button dropdown
-	if connectButton == nil {
-		err = c.tryMoreButtonDropdown()
+	c.tracer.Record("connection", "connect_button", map[string]interface{}{
+		"found_on_profile": err == nil,
+		"primary_state":    primaryState,
+	})
+
+	if primaryState == "connect" {
+		// Human-like click
+		err = c.stealth.ClickElement(c.rodPage(), connectButton)
 		if err != nil {
-			return fmt.Errorf("connect button not found: %w", err)
+			return fmt.Errorf("failed to click connect button: %w", err)
 		}
+
+		c.stealth.ActionDelay()
 		return nil
 	}
 
-	// Check button text to ensure it's Connect (not Connected or Pending)
-	buttonText, _ := connectButton.Text()
-	buttonText = strings.ToLower(strings.TrimSpace(buttonText))
-	
-	if strings.Contains(buttonText, "pending") {
-		return fmt.Errorf("connection request already pending")
+	c.logger.WithField("primary_button", primaryState).Debug("Primary button is not Connect, checking More dropdown")
+
+	moreErr := c.tryMoreButtonDropdown()
+	if moreErr == nil {
+		return nil
 	}
-	if strings.Contains(buttonText, "connected") || strings.Contains(buttonText, "message") {
+
+	// Neither location offers Connect
+	switch primaryState {
+	case "pending":
+		return fmt.Errorf("connection request already pending")
+	case "connected":
 		return fmt.Errorf("already connected to this profile")
+	default:
+		return fmt.Errorf("connect button not found: %w", moreErr)
 	}
+}
 
-	// Human-like click
-	err = c.stealth.ClickElement(c.page, connectButton)
-	if err != nil {
-		return fmt.Errorf("failed to click connect button: %w", err)
+// primaryButtonState classifies a profile action button as connect, pending,
+// connected (Message / Connected) or other (Follow, More, ...)
+func primaryButtonState(button *rod.Element) string {
+	buttonText, _ := button.Text()
+	label := strings.ToLower(strings.TrimSpace(buttonText))
+	if ariaLabel, err := button.Attribute("aria-label"); err == nil && ariaLabel != nil {
+		label += " " + strings.ToLower(*ariaLabel)
+	}
+
+	switch {
+	case strings.Contains(label, "pending"):
+		return "pending"
+	case strings.Contains(label, "connected") || strings.Contains(label, "message"):
+		return "connected"
+	case strings.Contains(label, "connect"):
+		return "connect"
+	default:
+		return "other"
 	}
-
-	c.stealth.ActionDelay()
-	return nil
 }
 
+// dropdownConnectPattern matches the Connect item of the More dropdown, as a
+// JS regex literal for ElementR
+const dropdownConnectPattern = `/^\s*connect\s*$/i`
+
 // tryMoreButtonDropdown tries to find Connect in the More dropdown
 func (c *ConnectionManager) tryMoreButtonDropdown() error {
 	c.logger.Debug("Trying More button dropdown")
 
 	// Find and click More button
-	moreButton, err := c.page.Timeout(3 * time.Second).Element("button[aria-label='More actions'], button.artdeco-dropdown__trigger")
+	moreButton, err := browser.ElementWithin(c.pager, 3*time.Second, "button[aria-label='More actions'], button.artdeco-dropdown__trigger")
 	if err != nil {
 		return fmt.Errorf("more button not found: %w", err)
 	}
 
-	err = c.stealth.ClickElement(c.page, moreButton)
+	err = c.stealth.ClickElement(c.rodPage(), moreButton)
 	if err != nil {
 		return err
 	}
@@ -248,12 +397,14 @@ func (c *ConnectionManager) tryMoreButtonDropdown() error {
 	c.stealth.ActionDelay()
 
 	// Find Connect in dropdown
-	dropdownConnect, err := c.page.Timeout(3 * time.Second).Element("div.artdeco-dropdown__content button:has-text('Connect'), li.artdeco-dropdown__item:has-text('Connect')")
+	dropdownConnect, err := browser.ElementRWithin(c.pager, 3*time.Second, "button, div[role=button]", dropdownConnectPattern)
 	if err != nil {
+		// Close the dropdown again before giving up
+		c.pager.KeyActions().Type(input.Escape).Do()
 		return fmt.Errorf("connect option not found in dropdown: %w", err)
 	}
 
-	err = c.stealth.ClickElement(c.page, dropdownConnect)
+	err = c.stealth.ClickElement(c.rodPage(), dropdownConnect)
 	if err != nil {
 		return err
 	}
@@ -269,14 +420,14 @@ func (c *ConnectionManager) addConnectionNote(note string) error {
 	time.Sleep(500 * time.Millisecond)
 
 	// Look for "Add a note" button
-	addNoteButton, err := c.page.Timeout(5 * time.Second).Element("button[aria-label*='Add a note'], button:has-text('Add a note')")
+	addNoteButton, err := browser.ElementWithin(c.pager, 5*time.Second, "button[aria-label*='Add a note'], button:has-text('Add a note')")
 	if err != nil {
 		// Note might not be available for this connection type
 		c.logger.Debug("Add note button not found, may not be available")
-		return nil
+		return errNoteUnavailable
 	}
 
-	err = c.stealth.ClickElement(c.page, addNoteButton)
+	err = c.stealth.ClickElement(c.rodPage(), addNoteButton)
 	if err != nil {
 		return fmt.Errorf("failed to click add note button: %w", err)
 	}
@@ -284,69 +435,135 @@ func (c *ConnectionManager) addConnectionNote(note string) error {
 	c.stealth.ActionDelay()
 
 	// Find note textarea
-	noteTextarea, err := c.page.Timeout(5 * time.Second).Element("textarea[name='message'], textarea#custom-message, textarea.connect-button-send-invite__custom-message")
+	noteTextarea, err := browser.ElementWithin(c.pager, 5*time.Second, "textarea[name='message'], textarea#custom-message, textarea.connect-button-send-invite__custom-message")
 	if err != nil {
 		// Try alternative selectors
-		noteTextarea, err = c.page.Element("textarea")
+		noteTextarea, err = c.pager.Element("textarea")
 		if err != nil {
 			return fmt.Errorf("note textarea not found: %w", err)
 		}
 	}
 
-	// Truncate note if too long
-	maxLength := c.config.Messaging.MaxNoteLength
-	if len(note) > maxLength {
-		note = note[:maxLength-3] + "..."
-		c.logger.Warnf("Note truncated to %d characters", maxLength)
+	// Truncate note to the smallest of our limit, the field's maxlength and
+	// its character counter before typing
+	maxLength := c.noteLimit(noteTextarea)
+	for attempt := 1; ; attempt++ {
+		if truncated := truncateNote(note, maxLength); truncated != note {
+			note = truncated
+			c.logger.Warnf("Note truncated to %d characters", maxLength)
+		}
+
+		// Clear any existing text
+		noteTextarea.SelectAllText()
+
+		// Type the note with human-like behavior
+		err = c.stealth.HumanType(c.rodPage(), noteTextarea, note)
+		if err != nil {
+			return fmt.Errorf("failed to type note: %w", err)
+		}
+
+		c.stealth.ActionDelay()
+
+		// An over-long note leaves Send disabled and the request silently unsent
+		if c.noteSendEnabled() {
+			return nil
+		}
+		if attempt == maxNoteTypingAttempts {
+			// Clear the field so the invitation can still go out without a note
+			noteTextarea.SelectAllText()
+			c.pager.KeyActions().Type(input.Backspace).Do()
+			return fmt.Errorf("send button still disabled after shortening the note %d times", attempt)
+		}
+
+		used, limit, ok := c.readNoteCounter()
+		if !ok {
+			used, limit = maxLength, maxLength
+		}
+		maxLength = shortenedNoteLimit(maxLength, used, limit)
+		c.logger.WithFields(map[string]interface{}{
+			"counter_used":  used,
+			"counter_limit": limit,
+			"next_limit":    maxLength,
+		}).Warn("Send disabled after typing the note, shortening and retyping")
+		c.tracer.Record("connection", "note_retyped", map[string]interface{}{
+			"attempt":    attempt,
+			"next_limit": maxLength,
+		})
 	}
+}
 
-	// Clear any existing text
-	noteTextarea.SelectAllText()
-	
-	// Type the note with human-like behavior
-	err = c.stealth.HumanType(c.page, noteTextarea, note)
-	if err != nil {
-		return fmt.Errorf("failed to type note: %w", err)
+// noteLimit returns the effective note length limit. Free accounts get a shorter
+// limit than MaxNoteLength, which LinkedIn exposes as the textarea's maxlength
+// and in the character counter under it.
+func (c *ConnectionManager) noteLimit(noteTextarea *rod.Element) int {
+	limit := c.config.Messaging.MaxNoteLength
+
+	// The live counter ("0/300") shows the limit even without a maxlength
+	domLimit := 0
+	if _, counterLimit, ok := c.readNoteCounter(); ok {
+		domLimit = counterLimit
 	}
 
-	c.stealth.ActionDelay()
-	return nil
+	if attr, err := noteTextarea.Attribute("maxlength"); err == nil && attr != nil {
+		if maxlength, err := strconv.Atoi(strings.TrimSpace(*attr)); err == nil && maxlength > 0 && (domLimit == 0 || maxlength < domLimit) {
+			domLimit = maxlength
+		}
+	}
+
+	if domLimit <= 0 {
+		c.logger.WithField("limit", limit).Debug("Note field has no maxlength or counter, using configured limit")
+		return limit
+	}
+
+	if domLimit < limit {
+		limit = domLimit
+	}
+
+	c.logger.WithFields(map[string]interface{}{
+		"dom_maxlength":   domLimit,
+		"config_limit":    c.config.Messaging.MaxNoteLength,
+		"effective_limit": limit,
+	}).Info("Discovered note length limit")
+	c.tracer.Record("connection", "note_limit", map[string]interface{}{
+		"dom_maxlength":   domLimit,
+		"effective_limit": limit,
+	})
+
+	return limit
+}
+
+// truncateNote shortens note to at most limit characters, cutting at the last
+// word boundary and appending "..." rather than splitting a word or an emoji
+func truncateNote(note string, limit int) string {
+	return stealth.TruncateText(note, limit)
 }
 
 // clickSendButton clicks the Send button to submit the connection request
 func (c *ConnectionManager) clickSendButton() error {
 	c.logger.Debug("Clicking send button")
 
-	// Various selectors for Send button
+	// Various selectors for Send button; disabled ones are matched too and
+	// waited on below rather than skipped
 	sendSelectors := []string{
-		"button[aria-label*='Send']:not([disabled])",
-		"button.artdeco-button--primary:has-text('Send'):not([disabled])",
-		"button[aria-label*='invitation']:not([disabled])",
+		"button[aria-label*='Send']",
+		"button.artdeco-button--primary:has-text('Send')",
+		"button[aria-label*='invitation']",
 		"button.ml1:has-text('Send')",
 		"button:has-text('Send invitation')",
 		"button:has-text('Send now')",
 	}
 
-	var sendButton *rod.Element
-	var err error
-
-	for _, selector := range sendSelectors {
-		sendButton, err = c.page.Timeout(3 * time.Second).Element(selector)
-		if err == nil && sendButton != nil {
-			visible, _ := sendButton.Visible()
-			if visible {
-				break
-			}
-		}
-		sendButton = nil
+	sendButton, err := browser.FirstElement(c.pager, 5*time.Second, sendSelectors...)
+	if err != nil {
+		return fmt.Errorf("send button not found: %w", err)
 	}
 
-	if sendButton == nil {
-		return fmt.Errorf("send button not found")
+	if err := browser.WaitSendEnabled(sendButton, c.config.GetSendEnabledTimeout()); err != nil {
+		return err
 	}
 
 	// Human-like click
-	err = c.stealth.ClickElement(c.page, sendButton)
+	err = c.stealth.ClickElement(c.rodPage(), sendButton)
 	if err != nil {
 		return fmt.Errorf("failed to click send button: %w", err)
 	}
@@ -355,29 +572,131 @@ func (c *ConnectionManager) clickSendButton() error {
 	time.Sleep(time.Second)
 
 	// Check for success (modal closes)
-	_, modalErr := c.page.Timeout(3 * time.Second).Element(".send-invite, .artdeco-modal--layer-default")
+	_, modalErr := browser.ElementWithin(c.pager, 3*time.Second, ".send-invite, .artdeco-modal--layer-default")
 	if modalErr != nil {
-		// Modal closed, likely success
+		// Modal closed; confirmSent checks that the invitation went out
+		c.captureSendScreenshot("connection")
 		return nil
 	}
 
 	// Check for error messages
-	errorEl, err := c.page.Timeout(2 * time.Second).Element(".artdeco-inline-feedback--error, .form-error")
+	errorEl, err := browser.ElementWithin(c.pager, 2*time.Second, ".artdeco-inline-feedback--error, .form-error")
 	if err == nil && errorEl != nil {
 		errorText, _ := errorEl.Text()
 		return fmt.Errorf("connection request failed: %s", errorText)
 	}
 
+	c.captureSendScreenshot("connection")
 	return nil
 }
 
-// generatePersonalizedNote generates a personalized connection note using templates
-func (c *ConnectionManager) generatePersonalizedNote(profile *search.SearchResult) (string, error) {
-	templateStr := c.config.Messaging.ConnectionNoteTemplate
+// captureSendScreenshot saves an audit screenshot when Browser.ScreenshotOnAction is enabled
+func (c *ConnectionManager) captureSendScreenshot(action string) {
+	path, err := browser.CaptureActionScreenshot(c.rodPage(), &c.config.Browser, action)
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to capture audit screenshot")
+	}
+	if path != "" {
+		c.logger.WithField("filename", path).Debug("Audit screenshot saved")
+	}
+}
+
+// clickSendWithoutNoteButton sends the invitation directly from the Connect modal
+// without adding a note, falling back to the regular Send button
+func (c *ConnectionManager) clickSendWithoutNoteButton() error {
+	c.logger.Debug("Sending invitation without note")
+
+	sendButton, err := browser.ElementWithin(c.pager, 3*time.Second, "button[aria-label='Send without a note']")
+	if err != nil {
+		sendButton, err = browser.ElementRWithin(c.pager, 2*time.Second, "button", "/send without a note/i")
+		if err != nil {
+			c.logger.Debug("Send without note button not found, using regular send button")
+			return c.clickSendButton()
+		}
+	}
+
+	err = c.stealth.ClickElement(c.rodPage(), sendButton)
+	if err != nil {
+		return fmt.Errorf("failed to click send without note button: %w", err)
+	}
+
+	// Wait for confirmation
+	time.Sleep(time.Second)
+
+	c.captureSendScreenshot("connection")
+	return nil
+}
+
+// shouldAttachNote decides whether a template note is attached to this request
+// based on Messaging.SendNotePercentage
+func (c *ConnectionManager) shouldAttachNote() bool {
+	percentage := c.config.Messaging.SendNotePercentage
+	if percentage >= 100 {
+		return true
+	}
+	if percentage <= 0 {
+		return false
+	}
+
+	attach := c.rand.Intn(100) < percentage
+	c.tracer.Record("connection", "note_decision", map[string]interface{}{
+		"percentage": percentage,
+		"attach":     attach,
+	})
+	return attach
+}
+
+// NoNoteVariant is the experiment variant recorded for bare invitations
+const NoNoteVariant = "no_note"
+
+// pickNoteVariant chooses the note template for a request: a random entry of
+// Messaging.ConnectionNoteVariants, or ConnectionNoteTemplate as "default"
+func (c *ConnectionManager) pickNoteVariant() (string, string) {
+	variants := c.config.Messaging.ConnectionNoteVariants
+	if len(variants) == 0 {
+		return "default", c.config.Messaging.ConnectionNoteTemplate
+	}
+
+	variant := variants[c.rand.Intn(len(variants))]
+	return variant.ID, variant.Template
+}
+
+// generatePersonalizedNote generates a personalized connection note using
+// templates and returns it with the ID of the variant used
+func (c *ConnectionManager) generatePersonalizedNote(profile *search.SearchResult, recentActivity string) (string, string, error) {
+	variant, templateStr := c.pickNoteVariant()
 	if templateStr == "" {
-		return "", nil
+		return "", "", nil
 	}
 
+	note, err := c.renderNote(templateStr, profile, recentActivity)
+	if err != nil {
+		return "", "", err
+	}
+	return note, variant, nil
+}
+
+// PreviewNotes renders every connection note variant for a profile without
+// sending anything, keyed by variant ID ("default" without variants)
+func (c *ConnectionManager) PreviewNotes(profile *search.SearchResult) (map[string]string, error) {
+	variants := c.config.Messaging.ConnectionNoteVariants
+	if len(variants) == 0 {
+		variants = []config.NoteVariant{{ID: "default", Template: c.config.Messaging.ConnectionNoteTemplate}}
+	}
+
+	notes := make(map[string]string, len(variants))
+	for _, variant := range variants {
+		note, err := c.renderNote(variant.Template, profile, "")
+		if err != nil {
+			return nil, fmt.Errorf("variant %s: %w", variant.ID, err)
+		}
+		notes[variant.ID] = note
+	}
+	return notes, nil
+}
+
+// renderNote fills a note template from a profile, truncated to the maximum note length
+func (c *ConnectionManager) renderNote(templateStr string, profile *search.SearchResult, recentActivity string) (string, error) {
 	// Prepare template data
 	data := TemplateData{
 		FirstName:  profile.FirstName,
@@ -387,6 +706,8 @@ func (c *ConnectionManager) generatePersonalizedNote(profile *search.SearchResul
 		Headline:   profile.Headline,
 		Location:   profile.Location,
 		Connection: profile.Connection,
+
+		RecentActivity: recentActivity,
 	}
 
 	// Handle empty first name
@@ -409,26 +730,16 @@ func (c *ConnectionManager) generatePersonalizedNote(profile *search.SearchResul
 	note := buf.String()
 
 	// Ensure note doesn't exceed max length
-	if len(note) > c.config.Messaging.MaxNoteLength {
-		note = note[:c.config.Messaging.MaxNoteLength]
-	}
+	note = truncateNote(note, c.config.Messaging.MaxNoteLength)
 
 	return note, nil
 }
 
-// saveConnectionRequest saves the connection request to the database
-func (c *ConnectionManager) saveConnectionRequest(profile *search.SearchResult, note string) error {
+// saveConnectionRequest saves the connection request to the database, with
+// the note variant used when it is part of an experiment
+func (c *ConnectionManager) saveConnectionRequest(profile *search.SearchResult, note, variant string) error {
 	// First save the profile
-	profileID, err := c.db.SaveProfile(&storage.Profile{
-		ProfileURL:       profile.ProfileURL,
-		Name:             profile.Name,
-		FirstName:        profile.FirstName,
-		LastName:         profile.LastName,
-		Headline:         profile.Headline,
-		Company:          profile.Company,
-		Location:         profile.Location,
-		ConnectionDegree: profile.Connection,
-	})
+	profileID, err := c.db.SaveProfile(profile.ToProfile())
 	if err != nil {
 		c.logger.WithError(err).Warn("Failed to save profile")
 	}
@@ -438,35 +749,149 @@ func (c *ConnectionManager) saveConnectionRequest(profile *search.SearchResult,
 		ProfileID:  profileID,
 		ProfileURL: profile.ProfileURL,
 		Note:       note,
+		HasNote:    note != "",
 		Status:     "pending",
 	}
 
-	_, err = c.db.SaveConnectionRequest(request)
-	return err
+	requestID, err := c.db.SaveConnectionRequest(request)
+	if err != nil {
+		return err
+	}
+
+	if variant != "" {
+		if err := c.db.RecordExperimentVariant(requestID, profile.ProfileURL, variant); err != nil {
+			c.logger.WithError(err).Warn("Failed to record note variant")
+		}
+	}
+	return nil
+}
+
+// checkCompanyLimit returns ErrCompanyLimitReached when today's requests to
+// the profile's company have reached max_connections_per_company_per_day
+func (c *ConnectionManager) checkCompanyLimit(profile *search.SearchResult) error {
+	limit := c.config.RateLimits.MaxConnectionsPerCompanyPerDay
+	if limit <= 0 {
+		return nil
+	}
+
+	company := profile.Company
+	if company == "" {
+		if stored, err := c.db.GetProfile(profile.ProfileURL); err == nil && stored != nil {
+			company = stored.Company
+		}
+	}
+	if strings.TrimSpace(company) == "" {
+		return nil
+	}
+
+	sent, err := c.db.GetTodayConnectionCountByCompany(company)
+	if err != nil {
+		c.logger.WithError(err).Warn("Failed to count today's requests to company")
+		return nil
+	}
+	if sent < limit {
+		return nil
+	}
+
+	c.tracer.Record("connection", "company_limit_skip", map[string]interface{}{
+		"profile_url": profile.ProfileURL,
+		"company":     company,
+		"sent_today":  sent,
+	})
+	return fmt.Errorf("%w: %d sent to %s today", ErrCompanyLimitReached, sent, company)
+}
+
+// countsTowardBackoff reports whether a failed request should add to the
+// consecutive failure backoff: only throttling and network failures do.
+// A profile without a Connect button says nothing about the next one.
+func countsTowardBackoff(err error) bool {
+	return errors.Is(err, ErrRateLimited) || browser.IsTransient(err)
 }
 
 // SendBulkConnectionRequests sends connection requests to multiple profiles
 func (c *ConnectionManager) SendBulkConnectionRequests(profiles []*search.SearchResult, customNote string) (int, int, error) {
 	sent := 0
 	failed := 0
+	consecutiveFailures := 0
 
 	for _, profile := range profiles {
+		// Blocks while paused; resumes with this profile
+		if !c.pause.WaitWhilePaused(c.ctx) {
+			c.logger.Info("Run stopped, ending bulk connection requests")
+			break
+		}
+
 		// Check rate limits before each request
 		if !c.rateLimiter.CanPerformAction("connection") {
 			c.logger.Warn("Rate limit reached, stopping bulk connection requests")
 			break
 		}
 
+		// Don't navigate to profiles we can't (or don't want to) invite
+		if ok, reason := c.degreeAllowed(profile); !ok {
+			c.logSkippedDegree(profile, reason)
+			continue
+		}
+
 		err := c.SendConnectionRequest(profile, customNote)
+		if errors.Is(err, ErrCompanyLimitReached) || errors.Is(err, ErrAlreadySent) {
+			// Not a failure; other companies and profiles can still be invited
+			c.logger.WithError(err).WithField("profile", profile.ProfileURL).Info("Skipping profile")
+			continue
+		}
+		if errors.Is(err, ErrInvitationLimit) {
+			c.logger.WithError(err).Warn("LinkedIn invitation limit reached, stopping bulk connection requests")
+			failed++
+			break
+		}
 		if err != nil {
 			c.logger.WithError(err).WithField("profile", profile.ProfileURL).Warn("Failed to send connection request")
+			c.tracer.Record("connection", "failed", map[string]interface{}{
+				"profile_url": profile.ProfileURL,
+				"error":       err.Error(),
+			})
+			c.rateLimiter.RecordFailure("connection")
 			failed++
+			if countsTowardBackoff(err) {
+				consecutiveFailures++
+
+				// A run of throttled or dropped requests means LinkedIn or the
+				// network is pushing back; slow down instead of bursting through
+				// profiles, and give up eventually
+				cooldown, abort := stealth.FailureBackoff(c.config.RateLimits, consecutiveFailures)
+				if abort {
+					c.logger.WithField("consecutive_failures", consecutiveFailures).Error("Too many consecutive failures, aborting bulk connection requests")
+					c.tracer.Record("connection", "failure_abort", map[string]interface{}{"consecutive_failures": consecutiveFailures})
+					return sent, failed, fmt.Errorf("%w: %d in a row", ErrTooManyFailures, consecutiveFailures)
+				}
+				if cooldown > 0 {
+					c.logger.WithFields(map[string]interface{}{
+						"consecutive_failures": consecutiveFailures,
+						"cooldown":             cooldown.String(),
+					}).Warn("Repeated failures, cooling down")
+					c.tracer.Record("connection", "failure_cooldown", map[string]interface{}{
+						"consecutive_failures": consecutiveFailures,
+						"duration_min":         cooldown.Minutes(),
+					})
+					resume := c.watchdog.Suspend()
+					c.sleepUnlessDone(cooldown)
+					resume()
+					continue
+				}
+			}
 		} else {
 			sent++
+			consecutiveFailures = 0
+		}
+
+		// The in-flight request has finished; don't wait out delays past the deadline
+		if c.ctx.Err() != nil {
+			continue
 		}
 
 		// Natural delay between requests
 		c.stealth.ThinkingDelay()
+		c.stealth.MaybeSimulateTabBlur(c.rodPage())
 		c.rateLimiter.WaitForNextAction()
 	}
 
@@ -474,12 +899,29 @@ func (c *ConnectionManager) SendBulkConnectionRequests(profiles []*search.Search
 	return sent, failed, nil
 }
 
+// sleepUnlessDone sleeps for d, returning early if the run context ends
+func (c *ConnectionManager) sleepUnlessDone(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-c.ctx.Done():
+	}
+}
+
 // GetRemainingConnections returns how many more connections can be sent today
 func (c *ConnectionManager) GetRemainingConnections() int {
 	return c.rateLimiter.GetRemainingActions("connection")
 }
 
-// WithdrawConnectionRequest withdraws a pending connection request
+// withdrawButtonPattern matches the Withdraw button of the pending menu, as a
+// JS regex literal for ElementR
+const withdrawButtonPattern = `/^\s*withdraw\s*$/i`
+
+// WithdrawConnectionRequest withdraws a pending connection request. Opening
+// the profile is charged as an explicit profile view; a profile that fails
+// to load isn't.
 func (c *ConnectionManager) WithdrawConnectionRequest(profileURL string) error {
 	c.logger.WithField("profile_url", profileURL).Info("Withdrawing connection request")
 
@@ -488,16 +930,21 @@ func (c *ConnectionManager) WithdrawConnectionRequest(profileURL string) error {
 	if err != nil {
 		return err
 	}
+	c.rateLimiter.RecordProfileView(stealth.ViewExplicit)
+	c.db.IncrementProfileViews(stealth.ViewExplicit)
 
 	c.stealth.ThinkingDelay()
 
 	// Find Pending button
-	pendingButton, err := c.page.Timeout(5 * time.Second).Element("button:has-text('Pending'), button[aria-label*='Pending']")
+	pendingButton, err := browser.ElementWithin(c.pager, 5*time.Second, pendingButtonSelector)
+	if err != nil {
+		pendingButton, err = browser.ElementRWithin(c.pager, 2*time.Second, "main button", pendingButtonPattern)
+	}
 	if err != nil {
 		return fmt.Errorf("pending button not found - may not have a pending request")
 	}
 
-	err = c.stealth.ClickElement(c.page, pendingButton)
+	err = c.stealth.ClickElement(c.rodPage(), pendingButton)
 	if err != nil {
 		return err
 	}
@@ -505,12 +952,15 @@ func (c *ConnectionManager) WithdrawConnectionRequest(profileURL string) error {
 	c.stealth.ActionDelay()
 
 	// Click Withdraw
-	withdrawButton, err := c.page.Timeout(3 * time.Second).Element("button:has-text('Withdraw'), button[aria-label*='Withdraw']")
+	withdrawButton, err := browser.ElementWithin(c.pager, 3*time.Second, "button[aria-label*='Withdraw']")
+	if err != nil {
+		withdrawButton, err = browser.ElementRWithin(c.pager, 2*time.Second, "button", withdrawButtonPattern)
+	}
 	if err != nil {
 		return fmt.Errorf("withdraw button not found")
 	}
 
-	err = c.stealth.ClickElement(c.page, withdrawButton)
+	err = c.stealth.ClickElement(c.rodPage(), withdrawButton)
 	if err != nil {
 		return err
 	}
@@ -521,3 +971,53 @@ func (c *ConnectionManager) WithdrawConnectionRequest(profileURL string) error {
 	c.logger.Info("Connection request withdrawn")
 	return nil
 }
+
+// WithdrawPendingByCompany withdraws every pending request to profiles at
+// company (matched case-insensitively against the stored profile) and
+// returns how many were withdrawn
+func (c *ConnectionManager) WithdrawPendingByCompany(company string) (int, error) {
+	requests, err := c.db.GetPendingConnectionRequestsByCompany(company)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load pending requests: %w", err)
+	}
+
+	c.logger.WithFields(map[string]interface{}{
+		"company": company,
+		"pending": len(requests),
+	}).Info("Withdrawing pending requests to company")
+
+	withdrawn := 0
+	for _, request := range requests {
+		if !c.pause.WaitWhilePaused(c.ctx) {
+			c.logger.Info("Run stopped, ending withdrawals")
+			break
+		}
+
+		// Each withdrawal opens the profile
+		if !c.rateLimiter.CanPerformAction("profile_view") {
+			c.logger.Warn("Profile view rate limit reached, stopping withdrawals")
+			break
+		}
+
+		err := c.WithdrawConnectionRequest(request.ProfileURL)
+		if err != nil {
+			c.logger.WithError(err).WithField("profile_url", request.ProfileURL).Warn("Failed to withdraw connection request")
+			continue
+		}
+
+		withdrawn++
+		c.tracer.Record("connection", "withdrawn", map[string]interface{}{
+			"profile_url": request.ProfileURL,
+			"company":     company,
+		})
+
+		if c.ctx.Err() != nil {
+			continue
+		}
+		c.stealth.ThinkingDelay()
+		c.rateLimiter.WaitForNextAction()
+	}
+
+	c.logger.Infof("Withdrew %d of %d pending requests to %s", withdrawn, len(requests), company)
+	return withdrawn, nil
+}
@@ -0,0 +1,316 @@
+// Package connection - Tests for connection request handling
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+	"github.com/nikshitha/linkedin-automation-poc/search"
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+func TestShouldAttachNote(t *testing.T) {
+	cfg := config.DefaultConfig()
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cm := NewConnectionManager(cfg, log, nil, nil, nil)
+
+	// Boundaries are deterministic
+	cfg.Messaging.SendNotePercentage = 100
+	for i := 0; i < 100; i++ {
+		if !cm.shouldAttachNote() {
+			t.Fatal("Note should always be attached at 100%")
+		}
+	}
+
+	cfg.Messaging.SendNotePercentage = 0
+	for i := 0; i < 100; i++ {
+		if cm.shouldAttachNote() {
+			t.Fatal("Note should never be attached at 0%")
+		}
+	}
+
+	// Ratio should approximate the configured percentage
+	cfg.Messaging.SendNotePercentage = 30
+	runs := 10000
+	attached := 0
+	for i := 0; i < runs; i++ {
+		if cm.shouldAttachNote() {
+			attached++
+		}
+	}
+
+	ratio := float64(attached) / float64(runs)
+	if math.Abs(ratio-0.30) > 0.03 {
+		t.Errorf("Expected note ratio near 0.30, got %.3f", ratio)
+	}
+}
+
+func TestTruncateNote(t *testing.T) {
+	short := "Hi Jane, would love to connect!"
+	if got := truncateNote(short, 300); got != short {
+		t.Errorf("Short note should be unchanged, got %q", got)
+	}
+
+	note := "Hi Jane, I came across your profile and was impressed by your work"
+	got := truncateNote(note, 30)
+	if len([]rune(got)) > 30 {
+		t.Errorf("Truncated note exceeds limit: %q (%d)", got, len([]rune(got)))
+	}
+	if got != "Hi Jane, I came across your..." {
+		t.Errorf("Note should be cut at a word boundary, got %q", got)
+	}
+
+	// Trailing punctuation before the ellipsis is dropped
+	if got := truncateNote("Hello there, friend of mine", 18); got != "Hello there..." {
+		t.Errorf("Expected trailing comma to be trimmed, got %q", got)
+	}
+}
+
+func TestParseNoteCounter(t *testing.T) {
+	cases := []struct {
+		text        string
+		used, limit int
+		ok          bool
+	}{
+		{"0/300", 0, 300, true},
+		{"212 / 200", 212, 200, true},
+		{"45 of 200 characters", 45, 200, true},
+		{"Add a note", 0, 0, false},
+		{"5/0", 0, 0, false},
+	}
+	for _, tc := range cases {
+		used, limit, ok := parseNoteCounter(tc.text)
+		if used != tc.used || limit != tc.limit || ok != tc.ok {
+			t.Errorf("parseNoteCounter(%q) = %d, %d, %v; want %d, %d, %v", tc.text, used, limit, ok, tc.used, tc.limit, tc.ok)
+		}
+	}
+
+	// The counter ran 12 past the limit: cut the overflow plus a margin
+	if got := shortenedNoteLimit(200, 212, 200); got != 178 {
+		t.Errorf("Expected 178, got %d", got)
+	}
+	// No overflow visible: shave 10%
+	if got := shortenedNoteLimit(200, 200, 200); got != 180 {
+		t.Errorf("Expected 180, got %d", got)
+	}
+}
+
+func TestParseInviteResumeDate(t *testing.T) {
+	now := time.Date(2025, time.October, 15, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		text string
+		want time.Time
+		ok   bool
+	}{
+		{"You've reached the weekly invitation limit. You can invite more people on Monday, Oct 20.", time.Date(2025, time.October, 20, 0, 0, 0, 0, time.Local), true},
+		{"You can invite more people on October 20, 2025", time.Date(2025, time.October, 20, 0, 0, 0, 0, time.Local), true},
+		{"You can invite more people on 3 Jan", time.Date(2026, time.January, 3, 0, 0, 0, 0, time.Local), true},
+		{"You can invite more people on 10/21/2025 once your limit resets", time.Date(2025, time.October, 21, 0, 0, 0, 0, time.Local), true},
+		{"You've reached the weekly invitation limit", time.Time{}, false},
+		{"You can invite more people on a later date", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseInviteResumeDate(tt.text, now)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseInviteResumeDate(%q) = %v, %v; want %v, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGeneratePersonalizedNoteVariants(t *testing.T) {
+	cfg := config.DefaultConfig()
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cm := NewConnectionManager(cfg, log, nil, nil, nil)
+	profile := &search.SearchResult{FirstName: "Jane", Company: "Acme"}
+
+	// Without variants the single template is the default variant
+	note, variant, err := cm.generatePersonalizedNote(profile, "")
+	if err != nil || variant != "default" || !strings.Contains(note, "Jane") {
+		t.Fatalf("Expected default variant note, got %q %q (%v)", note, variant, err)
+	}
+
+	cfg.Messaging.ConnectionNoteVariants = []config.NoteVariant{
+		{ID: "short", Template: "Hi {{.FirstName}}!"},
+		{ID: "company", Template: "Hi {{.FirstName}}, fellow {{.Company}} fan here."},
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		note, variant, err := cm.generatePersonalizedNote(profile, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch variant {
+		case "short":
+			if note != "Hi Jane!" {
+				t.Fatalf("Unexpected short note %q", note)
+			}
+		case "company":
+			if !strings.Contains(note, "Acme") {
+				t.Fatalf("Unexpected company note %q", note)
+			}
+		default:
+			t.Fatalf("Unexpected variant %q", variant)
+		}
+		seen[variant] = true
+	}
+	if len(seen) != 2 {
+		t.Error("Both variants should be picked")
+	}
+}
+
+func TestSendConfirmationPatterns(t *testing.T) {
+	invitationSentPattern := browser.MustCompileJSRegex(invitationSentPattern)
+	pendingButtonPattern := browser.MustCompileJSRegex(pendingButtonPattern)
+
+	for _, text := range []string{"Invitation sent", "Your invitation to Jane Doe was sent.", "invitation was sent"} {
+		if !invitationSentPattern.MatchString(text) {
+			t.Errorf("Expected %q to confirm the send", text)
+		}
+	}
+	for _, text := range []string{"Something went wrong", "Invitation limit reached", "Connect"} {
+		if invitationSentPattern.MatchString(text) {
+			t.Errorf("Did not expect %q to confirm the send", text)
+		}
+	}
+
+	if !pendingButtonPattern.MatchString(" Pending ") || pendingButtonPattern.MatchString("Pending invitations") {
+		t.Error("Pending pattern should match only the bare button label")
+	}
+}
+
+func TestInviteFlowPatterns(t *testing.T) {
+	// detectInviteFlow races these in the browser, which rejects Go-only syntax
+	direct := map[string]string{
+		pendingButtonPattern:  "Pending",
+		invitationSentPattern: "Invitation sent",
+	}
+	for pattern, text := range direct {
+		re, err := browser.CompileJSRegex(pattern)
+		if err != nil {
+			t.Fatalf("Invite flow pattern %q won't run in the browser: %v", pattern, err)
+		}
+		if !re.MatchString(text) {
+			t.Errorf("Expected %q to show a direct send", text)
+		}
+	}
+}
+
+func TestLimitDismissPattern(t *testing.T) {
+	re, err := browser.CompileJSRegex(limitDismissPattern)
+	if err != nil {
+		t.Fatalf("Dismiss pattern won't run in the browser: %v", err)
+	}
+	if !re.MatchString(" Got it ") || !re.MatchString("OK") {
+		t.Error("Expected the dismiss button labels to match")
+	}
+	if re.MatchString("Look for more people") {
+		t.Error("Only a whole dismiss label should match")
+	}
+}
+
+func TestDropdownConnectPattern(t *testing.T) {
+	re, err := browser.CompileJSRegex(dropdownConnectPattern)
+	if err != nil {
+		t.Fatalf("Dropdown pattern won't run in the browser: %v", err)
+	}
+	if !re.MatchString(" Connect ") {
+		t.Error("Expected the Connect item to match")
+	}
+	if re.MatchString("Remove connection") {
+		t.Error("Other dropdown items should not match")
+	}
+}
+
+func TestRecentActivityNote(t *testing.T) {
+	long := "Excited to share that our team just shipped the new streaming ingestion pipeline after eight months of work\nMore below"
+	if got := parseRecentActivity(long); got != "Excited to share that our team just shipped the new streaming ingestion..." {
+		t.Errorf("parseRecentActivity cut long post to %q", got)
+	}
+	if got := parseRecentActivity("\n  Hiring   two engineers  \nDM me"); got != "Hiring two engineers" {
+		t.Errorf("parseRecentActivity(first line) = %q", got)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Messaging.ConnectionNoteTemplate = `Hi {{.FirstName}}{{if .RecentActivity}}, enjoyed "{{.RecentActivity}}"{{end}}!`
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cm := NewConnectionManager(cfg, log, nil, nil, nil)
+	profile := &search.SearchResult{FirstName: "Jane"}
+
+	note, _, err := cm.generatePersonalizedNote(profile, "Hiring two engineers")
+	if err != nil || note != `Hi Jane, enjoyed "Hiring two engineers"!` {
+		t.Errorf("Expected the post in the note, got %q (%v)", note, err)
+	}
+	note, _, err = cm.generatePersonalizedNote(profile, "")
+	if err != nil || note != "Hi Jane!" {
+		t.Errorf("Expected the conditional to drop the post, got %q (%v)", note, err)
+	}
+}
+
+func TestPreviewNotes(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Messaging.ConnectionNoteVariants = []config.NoteVariant{
+		{ID: "short", Template: "Hi {{.FirstName}}!"},
+		{ID: "company", Template: "Hi {{.FirstName}}, I admire your work at {{.Company}}."},
+	}
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cm := NewConnectionManager(cfg, log, nil, nil, nil)
+
+	notes, err := cm.PreviewNotes(&search.SearchResult{FirstName: "Jane"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notes["short"] != "Hi Jane!" || notes["company"] != "Hi Jane, I admire your work at ." {
+		t.Errorf("Expected every variant rendered as sent, got %v", notes)
+	}
+}
+
+func TestDeclinedRequests(t *testing.T) {
+	now := time.Now()
+	old, recent := now.AddDate(0, 0, -30), now.AddDate(0, 0, -2)
+	pending := []*storage.ConnectionRequest{
+		{ProfileURL: "https://www.linkedin.com/in/ignored?miniProfileUrn=x", SentAt: recent},
+		{ProfileURL: "https://www.linkedin.com/in/gone/", SentAt: old},
+		{ProfileURL: "https://www.linkedin.com/in/gone-recently/", SentAt: recent},
+		{ProfileURL: "https://www.linkedin.com/in/listed/", SentAt: old},
+	}
+	stillPending := map[string]bool{search.CleanProfileURL("https://www.linkedin.com/in/listed"): true}
+	shown := []string{search.CleanProfileURL("https://www.linkedin.com/in/ignored/")}
+
+	got := declinedRequests(pending, stillPending, shown, true, 14, now)
+	want := []string{pending[0].ProfileURL, pending[1].ProfileURL}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected the stored URLs of the ignored and long-gone requests, got %v", got)
+	}
+
+	// An incomplete list only trusts what the cards show
+	got = declinedRequests(pending, stillPending, shown, false, 14, now)
+	if len(got) != 1 || got[0] != pending[0].ProfileURL {
+		t.Errorf("Expected only the ignored request without the whole list, got %v", got)
+	}
+}
+
+func TestCountsTowardBackoff(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("%w (remaining: 0)", ErrRateLimited), true},
+		{fmt.Errorf("failed to navigate to profile: %w", fmt.Errorf("%w: navigation failed", browser.ErrPageNotReady)), true},
+		{fmt.Errorf("%w to https://www.linkedin.com/in/a", ErrAlreadySent), false},
+		{errors.New("failed to click connect button: not found"), false},
+	}
+	for _, tt := range tests {
+		if got := countsTowardBackoff(tt.err); got != tt.want {
+			t.Errorf("countsTowardBackoff(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
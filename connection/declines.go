@@ -0,0 +1,191 @@
+// Package connection - declines.go handles detecting invitations that were declined or ignored
+package connection
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/search"
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+// LinkedInSentInvitationsURL lists the invitations still awaiting a response
+const LinkedInSentInvitationsURL = "https://www.linkedin.com/mynetwork/invitation-manager/sent/"
+
+// sentInvitationsEmptySelector matches the notice LinkedIn shows when no
+// sent invitations are pending
+const sentInvitationsEmptySelector = ".mn-invitation-manager__no-invites, .artdeco-empty-state"
+
+// declinedStatePattern matches sent invitation cards LinkedIn marks as no longer pending
+var declinedStatePattern = regexp.MustCompile(`(?i)\b(ignored|declined|withdrawn)\b`)
+
+// DetectDeclines marks pending requests as declined so they are never
+// targeted again. A request counts as declined when its card on the sent
+// invitations page shows an ignored/withdrawn state, or when it has left the
+// page (and wasn't accepted) more than search.decline_after_days after it was
+// sent. Run it after accepted connections were recorded so acceptances aren't
+// mistaken for declines. Returns how many requests were marked.
+func (c *ConnectionManager) DetectDeclines() (int, error) {
+	pending, err := c.db.GetPendingConnectionRequests()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending requests: %w", err)
+	}
+	if len(pending) == 0 {
+		c.logger.Debug("No pending connection requests to check for declines")
+		return 0, nil
+	}
+
+	err = browser.NavigateAndWaitReady(c.pager, LinkedInSentInvitationsURL, c.config.GetReadyTimeout(),
+		".invitation-card", ".mn-invitation-list", "main")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open sent invitations: %w", err)
+	}
+	c.stealth.PageLoadDelay()
+
+	stillPending, shownDeclined, complete := c.scrapeSentInvitations()
+	declined := declinedRequests(pending, stillPending, shownDeclined, complete, c.config.Search.DeclineAfterDays, time.Now())
+
+	marked, err := c.db.MarkConnectionsDeclined(declined)
+	if err != nil {
+		return 0, err
+	}
+
+	c.logger.WithFields(map[string]interface{}{
+		"pending":  len(pending),
+		"declined": marked,
+	}).Info("Checked sent invitations for declines")
+	c.tracer.Record("connection", "declines_detected", map[string]interface{}{"count": marked})
+	return marked, nil
+}
+
+// declinedRequests returns the stored profile URLs of the pending requests
+// to mark declined. Cards carry cleaned URLs, so requests are matched on
+// their cleaned URL and reported as stored. A request shown as ignored or
+// withdrawn is declined; one missing from the list is declined only when
+// the whole list was read and it was sent more than afterDays ago.
+func declinedRequests(pending []*storage.ConnectionRequest, stillPending map[string]bool, shownDeclined []string, complete bool, afterDays int, now time.Time) []string {
+	shown := make(map[string]bool, len(shownDeclined))
+	for _, url := range shownDeclined {
+		shown[search.CleanProfileURL(url)] = true
+	}
+
+	olderThan := now.AddDate(0, 0, -afterDays)
+	var declined []string
+	for _, request := range pending {
+		url := search.CleanProfileURL(request.ProfileURL)
+		switch {
+		case shown[url]:
+			declined = append(declined, request.ProfileURL)
+		case complete && afterDays > 0 && !stillPending[url] && request.SentAt.Before(olderThan):
+			// Only trust an invitation's absence when the whole list was loaded
+			declined = append(declined, request.ProfileURL)
+		}
+	}
+	return declined
+}
+
+// maxSentInvitationPages caps how many pages of sent invitations are read;
+// a list longer than that is treated as incomplete
+const maxSentInvitationPages = 50
+
+// Selectors for the sent invitations list and its pagination
+const (
+	sentInvitationCardSelector  = ".invitation-card, li.mn-invitation-list__item"
+	sentInvitationsNextSelector = "button.artdeco-pagination__button--next:not([disabled]), button[aria-label='Next']:not([disabled])"
+)
+
+// scrapeSentInvitations walks every page of the sent invitations list and
+// returns the cleaned profile URLs still pending, those shown as
+// ignored/withdrawn, and whether the end of the list was reached
+func (c *ConnectionManager) scrapeSentInvitations() (map[string]bool, []string, bool) {
+	stillPending := make(map[string]bool)
+	var declined []string
+
+	for page := 1; page <= maxSentInvitationPages; page++ {
+		pageComplete := c.scrapeSentInvitationsPage(stillPending, &declined)
+		if !pageComplete {
+			return stillPending, declined, false
+		}
+
+		next, err := browser.ElementWithin(c.pager, 2*time.Second, sentInvitationsNextSelector)
+		if err != nil {
+			return stillPending, declined, true
+		}
+		if enabled, err := browser.ElementEnabled(next); err != nil || !enabled {
+			return stillPending, declined, true
+		}
+		if err := c.stealth.ClickElement(c.rodPage(), next); err != nil {
+			c.logger.WithError(err).Warn("Failed to open the next page of sent invitations")
+			return stillPending, declined, false
+		}
+		if err := browser.WaitReady(c.pager, c.config.GetReadyTimeout(), sentInvitationCardSelector); err != nil {
+			c.logger.WithError(err).Warn("Next page of sent invitations did not load")
+			return stillPending, declined, false
+		}
+		c.stealth.PageLoadDelay()
+	}
+
+	// More pages than we read; older invitations weren't seen
+	c.logger.WithField("pages", maxSentInvitationPages).Warn("Sent invitations list longer than the page limit")
+	return stillPending, declined, false
+}
+
+// scrapeSentInvitationsPage scrolls the current page of sent invitations
+// until no new cards appear, then adds its cards to stillPending or
+// declined. It reports whether the whole page was loaded.
+func (c *ConnectionManager) scrapeSentInvitationsPage(stillPending map[string]bool, declined *[]string) bool {
+	complete := false
+	lastCount := -1
+	for i := 0; i < 20; i++ {
+		cards, err := c.pager.Elements(sentInvitationCardSelector)
+		if err != nil {
+			break
+		}
+		if len(cards) == lastCount {
+			// No cards only means an empty list when LinkedIn says so; a
+			// missed selector or a new layout must not turn every old
+			// invitation into a decline
+			complete = len(cards) > 0 || c.sentInvitationsEmpty()
+			break
+		}
+		lastCount = len(cards)
+
+		c.stealth.HumanScroll(c.rodPage(), "down", 600)
+		c.stealth.ActionDelay()
+	}
+
+	cards, err := c.pager.Elements(sentInvitationCardSelector)
+	if err != nil {
+		return false
+	}
+
+	for _, card := range cards {
+		link, err := card.Element("a[href*='/in/']")
+		if err != nil {
+			continue
+		}
+		href, err := link.Attribute("href")
+		if err != nil || href == nil {
+			continue
+		}
+		url := search.CleanProfileURL(*href)
+
+		text, _ := card.Text()
+		if declinedStatePattern.MatchString(text) {
+			*declined = append(*declined, url)
+			continue
+		}
+		stillPending[url] = true
+	}
+
+	return complete
+}
+
+// sentInvitationsEmpty reports whether the sent invitations page shows its
+// empty-state notice
+func (c *ConnectionManager) sentInvitationsEmpty() bool {
+	_, err := browser.ElementWithin(c.pager, time.Second, sentInvitationsEmptySelector)
+	return err == nil
+}
@@ -0,0 +1,55 @@
+// Package connection - degree.go handles restricting invites to configured connection degrees
+package connection
+
+import (
+	"fmt"
+
+	"github.com/nikshitha/linkedin-automation-poc/search"
+)
+
+// degreeAllowed reports whether a profile's stored connection degree is in
+// search.connect_degrees, and why not when it isn't. With no degrees
+// configured every profile is allowed.
+func (c *ConnectionManager) degreeAllowed(profile *search.SearchResult) (bool, string) {
+	allowed := c.config.Search.ConnectDegrees
+	if len(allowed) == 0 {
+		return true, ""
+	}
+
+	degree := search.NormalizeDegree(profile.Connection)
+	if degree == "" {
+		return false, "connection degree unknown"
+	}
+	for _, a := range allowed {
+		if search.NormalizeDegree(a) == degree {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("%s degree not in connect_degrees", degree)
+}
+
+// logSkippedDegree logs and traces a profile left out by degreeAllowed
+func (c *ConnectionManager) logSkippedDegree(profile *search.SearchResult, reason string) {
+	c.logger.WithFields(map[string]interface{}{
+		"profile_url": profile.ProfileURL,
+		"degree":      profile.Connection,
+	}).Infof("Skipping profile: %s", reason)
+	c.tracer.Record("connection", "degree_skip", map[string]interface{}{
+		"profile_url": profile.ProfileURL,
+		"reason":      reason,
+	})
+}
+
+// FilterByDegree drops profiles whose connection degree isn't eligible for
+// an invite under search.connect_degrees, logging each skip
+func (c *ConnectionManager) FilterByDegree(profiles []*search.SearchResult) []*search.SearchResult {
+	var eligible []*search.SearchResult
+	for _, profile := range profiles {
+		if ok, reason := c.degreeAllowed(profile); !ok {
+			c.logSkippedDegree(profile, reason)
+			continue
+		}
+		eligible = append(eligible, profile)
+	}
+	return eligible
+}
@@ -0,0 +1,57 @@
+// Package connection - inviteflow.go handles telling the invite dialog apart
+// from invitations sent by the Connect click itself
+package connection
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// Invite flows after clicking Connect
+const (
+	inviteModal  = "modal"  // the invite dialog opened and needs a send click
+	inviteDirect = "direct" // the click sent the invitation, no dialog
+)
+
+// inviteFlowTimeout bounds the wait for the invite dialog or a sent indicator
+const inviteFlowTimeout = 5 * time.Second
+
+// inviteDialogSelector matches the invite dialog opened by Connect
+const inviteDialogSelector = ".send-invite, .artdeco-modal[role='dialog'], div[role='dialog'][aria-labelledby*='send-invite']"
+
+// errNoteUnavailable is returned by addConnectionNote when the invite dialog
+// has no "Add a note" option, as for many 3rd-degree profiles
+var errNoteUnavailable = errors.New("add note not available for this profile")
+
+// detectInviteFlow tells whether the Connect click opened the invite dialog or
+// already sent the invitation. LinkedIn sends many note-less invites (often
+// to 3rd-degree profiles) straight away, leaving no send button to click.
+// When neither shows up in time the dialog flow is assumed.
+func (c *ConnectionManager) detectInviteFlow() string {
+	flow := inviteModal
+	direct := func(*rod.Element) error {
+		flow = inviteDirect
+		return nil
+	}
+
+	page := c.rodPage()
+	if page == nil {
+		return flow
+	}
+
+	_, err := page.Timeout(inviteFlowTimeout).Race().
+		Element(inviteDialogSelector).
+		Element(pendingButtonSelector).Handle(direct).
+		ElementR("main button", pendingButtonPattern).Handle(direct).
+		ElementR(sentToastSelector, invitationSentPattern).Handle(direct).
+		Do()
+	if err != nil {
+		c.logger.Debug("Neither invite dialog nor sent indicator appeared, assuming dialog")
+		flow = inviteModal
+	}
+
+	c.tracer.Record("connection", "invite_flow", map[string]interface{}{"flow": flow})
+	return flow
+}
@@ -0,0 +1,77 @@
+// Package connection - limits.go handles LinkedIn's own invitation limit notices
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/go-rod/rod/lib/input"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+)
+
+// ErrInvitationLimit is returned when LinkedIn reports that no more
+// invitations can be sent for now
+var ErrInvitationLimit = errors.New("LinkedIn invitation limit reached")
+
+var (
+	// invitationLimitPattern matches the weekly/daily limit modal text
+	invitationLimitPattern = regexp.MustCompile(`(?i)invitation limit|invite more people|too many (pending )?invitations`)
+
+	// resumeDatePattern captures the text following "...invite more people on"
+	resumeDatePattern = regexp.MustCompile(`(?i)invite more(?: people| connections)? (?:on|after) ([^.!\n]+)`)
+)
+
+// limitDismissPattern matches the limit modal's dismiss button, as a JS regex
+// literal for ElementR
+const limitDismissPattern = `/^\s*(got it|ok|dismiss|close)\s*$/i`
+
+// invitationLimitFallback is how long to block when the notice has no date
+const invitationLimitFallback = 24 * time.Hour
+
+// checkInvitationLimit looks for LinkedIn's invitation limit modal. When it is
+// shown, connection requests are blocked until the date it names (persisted so
+// later runs respect it) and ErrInvitationLimit is returned.
+func (c *ConnectionManager) checkInvitationLimit() error {
+	dialog, err := browser.ElementWithin(c.pager, time.Second, "div[role='dialog'], div.artdeco-modal, div.ip-fuse-limit-alert")
+	if err != nil {
+		return nil
+	}
+
+	text, err := dialog.Text()
+	if err != nil || !invitationLimitPattern.MatchString(text) {
+		return nil
+	}
+
+	until, ok := parseInviteResumeDate(text, time.Now())
+	if !ok {
+		until = time.Now().Add(invitationLimitFallback)
+		c.logger.WithField("text", text).Warn("Invitation limit reached but no resume date found, blocking for 24h")
+	}
+
+	c.rateLimiter.BlockUntil("connection", until)
+	if err := c.db.SaveBlockedUntil("connection", until); err != nil {
+		c.logger.WithError(err).Warn("Failed to persist invitation limit")
+	}
+
+	// Dismiss the modal
+	if button, err := dialog.ElementR("button", limitDismissPattern); err == nil {
+		c.stealth.ClickElement(c.rodPage(), button)
+	} else {
+		c.pager.KeyActions().Type(input.Escape).Do()
+	}
+
+	return fmt.Errorf("%w until %s", ErrInvitationLimit, until.Format("2006-01-02"))
+}
+
+// parseInviteResumeDate extracts the date from text such as "You can invite
+// more people on Monday, Oct 20"
+func parseInviteResumeDate(text string, now time.Time) (time.Time, bool) {
+	match := resumeDatePattern.FindStringSubmatch(text)
+	if match == nil {
+		return time.Time{}, false
+	}
+	return stealth.ParseLimitDate(match[1], now)
+}
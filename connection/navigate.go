@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/input"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
 )
 
 // ConnectionsPageURL is the URL for the connections page
@@ -29,7 +30,7 @@ func (c *ConnectionManager) NavigateAndOpenProfile(institution string, personNam
 
 	// Step 2: Wait for page to load and perform human-like actions
 	c.stealth.PageLoadDelay()
-	c.stealth.RandomMouseWander(c.page)
+	c.stealth.RandomMouseWander(c.rodPage())
 	c.stealth.ThinkingDelay()
 
 	// Step 3: Search for the person with institution context
@@ -39,7 +40,7 @@ func (c *ConnectionManager) NavigateAndOpenProfile(institution string, personNam
 
 	// Step 4: Wait for results with human-like behavior
 	c.stealth.ThinkingDelay()
-	c.stealth.HumanScroll(c.page, "down", 100)
+	c.stealth.HumanScroll(c.rodPage(), "down", 100)
 
 	// Step 5: Find and click on the profile
 	if err := c.findAndClickProfile(personName, institution); err != nil {
@@ -55,23 +56,11 @@ func (c *ConnectionManager) navigateToConnectionsPage() error {
 	c.logger.Info("Navigating to Connections page")
 
 	// First try direct URL navigation
-	err := c.page.Navigate(ConnectionsPageURL)
-	if err != nil {
-		return fmt.Errorf("failed to navigate: %w", err)
-	}
-
-	// Wait for page to load
-	c.stealth.PageLoadDelay()
-	err = c.page.WaitLoad()
-	if err != nil {
-		c.logger.WithError(err).Warn("Page load wait failed, continuing anyway")
-	}
-
-	// Additional wait for dynamic content
-	time.Sleep(2 * time.Second)
+	err := browser.NavigateAndWaitReady(c.pager, ConnectionsPageURL, c.config.GetReadyTimeout(),
+		".mn-connection-card", "li.mn-connection-card", ".scaffold-finite-scroll__content")
 
 	// Verify we're on the connections page
-	currentURL := c.page.MustInfo().URL
+	currentURL := browser.CurrentURL(c.pager)
 	c.logger.WithField("url", currentURL).Debug("Current URL")
 
 	if !strings.Contains(currentURL, "connections") && !strings.Contains(currentURL, "mynetwork") {
@@ -79,6 +68,11 @@ func (c *ConnectionManager) navigateToConnectionsPage() error {
 		c.logger.Debug("Not on connections page, trying to navigate via My Network")
 		return c.navigateViaMyNetwork()
 	}
+	if err != nil {
+		return fmt.Errorf("connections page not ready: %w", err)
+	}
+
+	c.stealth.PageLoadDelay()
 
 	c.logger.Info("Successfully navigated to Connections page")
 	return nil
@@ -96,21 +90,13 @@ func (c *ConnectionManager) navigateViaMyNetwork() error {
 		`.global-nav__primary-link[href*="mynetwork"]`,
 	}
 
-	var myNetworkLink *rod.Element
-	var err error
-	for _, selector := range myNetworkSelectors {
-		myNetworkLink, err = c.page.Timeout(5 * time.Second).Element(selector)
-		if err == nil && myNetworkLink != nil {
-			break
-		}
-	}
-
-	if myNetworkLink == nil {
-		return fmt.Errorf("could not find My Network link")
+	myNetworkLink, err := browser.FirstElement(c.pager, 5*time.Second, myNetworkSelectors...)
+	if err != nil {
+		return fmt.Errorf("could not find My Network link: %w", err)
 	}
 
 	// Human-like hover and click
-	c.stealth.HoverElement(c.page, myNetworkLink)
+	c.stealth.HoverElement(c.rodPage(), myNetworkLink)
 	c.stealth.ActionDelay()
 	myNetworkLink.MustClick()
 
@@ -124,16 +110,9 @@ func (c *ConnectionManager) navigateViaMyNetwork() error {
 		`.mn-community-summary__link`,
 	}
 
-	var connectionsLink *rod.Element
-	for _, selector := range connectionsSelectors {
-		connectionsLink, err = c.page.Timeout(5 * time.Second).Element(selector)
-		if err == nil && connectionsLink != nil {
-			break
-		}
-	}
-
-	if connectionsLink != nil {
-		c.stealth.HoverElement(c.page, connectionsLink)
+	connectionsLink, err := browser.FirstElement(c.pager, 5*time.Second, connectionsSelectors...)
+	if err == nil {
+		c.stealth.HoverElement(c.rodPage(), connectionsLink)
 		c.stealth.ActionDelay()
 		connectionsLink.MustClick()
 		c.stealth.PageLoadDelay()
@@ -167,24 +146,11 @@ func (c *ConnectionManager) searchViaGlobalSearch(personName string) error {
 		`#global-nav-typeahead input`,
 	}
 
-	var searchInput *rod.Element
-	var err error
-	for _, selector := range globalSearchSelectors {
-		c.logger.WithField("selector", selector).Debug("Trying global search selector")
-		searchInput, err = c.page.Timeout(10 * time.Second).Element(selector)
-		if err == nil && searchInput != nil {
-			visible, _ := searchInput.Visible()
-			if visible {
-				c.logger.WithField("selector", selector).Info("Found global search input")
-				break
-			}
-		}
-		searchInput = nil
-	}
-
-	if searchInput == nil {
-		return fmt.Errorf("could not find global search input after trying all selectors")
+	searchInput, err := browser.FirstElement(c.pager, 10*time.Second, globalSearchSelectors...)
+	if err != nil {
+		return fmt.Errorf("could not find global search input after trying all selectors: %w", err)
 	}
+	c.logger.Info("Found global search input")
 
 	// Click and focus the search input
 	c.logger.Debug("Clicking search input")
@@ -194,7 +160,7 @@ func (c *ConnectionManager) searchViaGlobalSearch(personName string) error {
 	
 	// Type the person's name
 	c.logger.WithField("query", personName).Debug("Typing search query")
-	err = c.stealth.HumanType(c.page, searchInput, personName)
+	err = c.stealth.HumanType(c.rodPage(), searchInput, personName)
 	if err != nil {
 		c.logger.Debug("Human typing failed, using direct input")
 		searchInput.MustInput(personName)
@@ -204,7 +170,7 @@ func (c *ConnectionManager) searchViaGlobalSearch(personName string) error {
 	
 	// Press Enter to search
 	c.logger.Debug("Pressing Enter to search")
-	c.page.Keyboard.Press(input.Enter)
+	c.pager.KeyActions().Type(input.Enter).Do()
 
 	// Wait for search results to load
 	c.logger.Debug("Waiting for search results")
@@ -222,15 +188,11 @@ func (c *ConnectionManager) searchViaGlobalSearch(personName string) error {
 		`[data-test-filter-button="People"]`,
 	}
 
-	for _, selector := range peopleFilterSelectors {
-		filter, err := c.page.Timeout(3 * time.Second).Element(selector)
-		if err == nil && filter != nil {
-			c.logger.WithField("selector", selector).Debug("Found People filter, clicking")
-			c.stealth.HoverElement(c.page, filter)
-			filter.MustClick()
-			c.stealth.PageLoadDelay()
-			break
-		}
+	if filter, err := browser.FirstElement(c.pager, 3*time.Second, peopleFilterSelectors...); err == nil {
+		c.logger.Debug("Found People filter, clicking")
+		c.stealth.HoverElement(c.rodPage(), filter)
+		filter.MustClick()
+		c.stealth.PageLoadDelay()
 	}
 
 	return nil
@@ -247,7 +209,7 @@ func (c *ConnectionManager) findAndClickProfile(personName string, institution s
 	time.Sleep(2 * time.Second)
 
 	// Scroll to load results
-	c.stealth.HumanScroll(c.page, "down", 200)
+	c.stealth.HumanScroll(c.rodPage(), "down", 200)
 	c.stealth.ActionDelay()
 
 	// Look for profile cards with the person's name
@@ -262,10 +224,12 @@ func (c *ConnectionManager) findAndClickProfile(personName string, institution s
 	}
 
 	var profileCards []*rod.Element
+	var cardSelector string
 	for _, selector := range profileCardSelectors {
-		cards, err := c.page.Elements(selector)
+		cards, err := c.pager.Elements(selector)
 		if err == nil && len(cards) > 0 {
 			profileCards = cards
+			cardSelector = selector
 			c.logger.WithField("count", len(cards)).Debug("Found profile cards")
 			break
 		}
@@ -275,13 +239,30 @@ func (c *ConnectionManager) findAndClickProfile(personName string, institution s
 		return fmt.Errorf("no profile cards found")
 	}
 
+	// Read each card's profile link before anything re-renders the list
+	keys := browser.CardKeys(c.pager, cardSelector, "href")
+
 	// Find the matching profile
 	nameLower := strings.ToLower(personName)
 	nameParts := strings.Fields(nameLower)
 
-	for _, card := range profileCards {
-		// Get the card text content
-		text, err := card.Text()
+	for i, card := range profileCards {
+		// Get the card text content, finding the card again by its link if
+		// the list re-rendered since it was listed
+		var text string
+		err := browser.RetryStale(card, func() (*rod.Element, error) {
+			c.tracer.Record("connection", "stale_refetch", map[string]interface{}{"card": i})
+			if i >= len(keys) {
+				return nil, fmt.Errorf("profile card %d was not keyed when listed", i)
+			}
+			return browser.RefetchCard(c.pager, cardSelector, keys[i])
+		}, func(el *rod.Element) error {
+			var textErr error
+			if text, textErr = el.Text(); textErr == nil {
+				card = el
+			}
+			return textErr
+		})
 		if err != nil {
 			continue
 		}
@@ -309,19 +290,17 @@ func (c *ConnectionManager) findAndClickProfile(personName string, institution s
 				link = card
 			}
 
-			// Human-like hover before click
-			c.stealth.HoverElement(c.page, link)
-			c.stealth.ThinkingDelay()
-
-			// Click to open profile
-			link.MustClick()
+			// Hover, then click to open the profile, following it if it opens in a new tab
+			if err := c.hoverAndClickProfile(link); err != nil {
+				return err
+			}
 
 			// Wait for profile page to load
 			c.stealth.PageLoadDelay()
 			time.Sleep(2 * time.Second)
 
 			// Verify we're on a profile page
-			currentURL := c.page.MustInfo().URL
+			currentURL := browser.CurrentURL(c.pager)
 			c.logger.WithField("url", currentURL).Info("Navigated to profile")
 
 			if strings.Contains(currentURL, "/in/") {
@@ -341,9 +320,9 @@ func (c *ConnectionManager) findAndClickProfile(personName string, institution s
 		}
 
 		if link != nil {
-			c.stealth.HoverElement(c.page, link)
-			c.stealth.ThinkingDelay()
-			link.MustClick()
+			if err := c.hoverAndClickProfile(link); err != nil {
+				return err
+			}
 			c.stealth.PageLoadDelay()
 			return nil
 		}
@@ -351,15 +330,15 @@ func (c *ConnectionManager) findAndClickProfile(personName string, institution s
 
 	// Try alternative approach - find any profile link on the page
 	c.logger.Warn("No profile cards found, trying alternative approach")
-	profileLinks, err := c.page.Elements(`a[href*="/in/"]`)
+	profileLinks, err := c.pager.Elements(`a[href*="/in/"]`)
 	if err == nil && len(profileLinks) > 0 {
 		for _, link := range profileLinks {
 			text, _ := link.Text()
 			if text != "" && strings.Contains(strings.ToLower(text), strings.ToLower(personName)) {
 				c.logger.WithField("link_text", text).Info("Found profile link")
-				c.stealth.HoverElement(c.page, link)
-				c.stealth.ThinkingDelay()
-				link.MustClick()
+				if err := c.hoverAndClickProfile(link); err != nil {
+					return err
+				}
 				c.stealth.PageLoadDelay()
 				return nil
 			}
@@ -367,10 +346,9 @@ func (c *ConnectionManager) findAndClickProfile(personName string, institution s
 		
 		// Just click the first profile link if nothing matches
 		c.logger.Warn("Clicking first available profile link")
-		firstLink := profileLinks[0]
-		c.stealth.HoverElement(c.page, firstLink)
-		c.stealth.ThinkingDelay()
-		firstLink.MustClick()
+		if err := c.hoverAndClickProfile(profileLinks[0]); err != nil {
+			return err
+		}
 		c.stealth.PageLoadDelay()
 		return nil
 	}
@@ -378,6 +356,31 @@ func (c *ConnectionManager) findAndClickProfile(personName string, institution s
 	return fmt.Errorf("profile not found for: %s", personName)
 }
 
+// hoverAndClickProfile hovers over link, pauses and clicks it. Scrolling can
+// re-render the result list after link was found; if link went stale, it is
+// found again by its href and the hover and click are retried once.
+func (c *ConnectionManager) hoverAndClickProfile(link *rod.Element) error {
+	href := ""
+	if v, err := link.Attribute("href"); err == nil && v != nil {
+		href = *v
+	}
+
+	refetch := func() (*rod.Element, error) {
+		c.logger.WithField("href", href).Debug("Profile link went stale, finding it again")
+		c.tracer.Record("connection", "stale_refetch", map[string]interface{}{"href": href})
+		return browser.ElementByAttribute(c.pager, `a[href*="/in/"]`, "href", href)
+	}
+
+	return browser.RetryStale(link, refetch, func(el *rod.Element) error {
+		// Other hover failures aren't worth aborting the click for
+		if err := c.stealth.HoverElement(c.rodPage(), el); browser.IsStaleElement(err) {
+			return err
+		}
+		c.stealth.ThinkingDelay()
+		return c.clickProfileLink(el)
+	})
+}
+
 // min returns the minimum of two integers
 func min(a, b int) int {
 	if a < b {
@@ -0,0 +1,98 @@
+// Package connection - newtab.go handles profile links that open in a new tab
+package connection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+// clickNavigationTimeout bounds how long a click is watched for the profile
+// to start opening, in place or in a new tab
+const clickNavigationTimeout = 3 * time.Second
+
+// SetOnPageChange registers fn to run when a click moves the session to a
+// new tab, so the other managers can be pointed at it too
+func (c *ConnectionManager) SetOnPageChange(fn func(page *rod.Page)) {
+	c.onPageChange = fn
+}
+
+// clickProfileLink clicks link and waits for the profile to load. If the
+// click opened it in a new tab (e.g. target=_blank), it switches to that tab
+// and closes the stale one.
+func (c *ConnectionManager) clickProfileLink(link *rod.Element) error {
+	current := c.rodPage()
+	if current == nil {
+		return fmt.Errorf("no browser behind the page")
+	}
+
+	// Listen before clicking so neither outcome can be missed
+	ctx, cancel := context.WithTimeout(context.Background(), clickNavigationTimeout)
+	defer cancel()
+	var opened proto.TargetTargetID
+	waitTab := current.Browser().Context(ctx).EachEvent(func(e *proto.TargetTargetCreated) bool {
+		if e.TargetInfo.Type != proto.TargetTargetInfoTypePage || e.TargetInfo.OpenerID != current.TargetID {
+			return false
+		}
+		opened = e.TargetInfo.TargetID
+		return true
+	})
+	waitInPlace := current.Context(ctx).EachEvent(func(e *proto.PageFrameNavigated) bool {
+		return e.Frame.ParentID == ""
+	}, func(e *proto.PageNavigatedWithinDocument) bool {
+		return true
+	})
+
+	if err := link.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return fmt.Errorf("failed to click profile link: %w", err)
+	}
+
+	// Whichever comes first ends the other; a timeout ends both
+	done := make(chan struct{}, 2)
+	go func() { waitTab(); done <- struct{}{} }()
+	go func() { waitInPlace(); done <- struct{}{} }()
+	<-done
+	cancel()
+	<-done
+
+	if opened != "" {
+		return c.switchToTab(opened)
+	}
+	return browser.WaitReady(c.pager, c.config.GetReadyTimeout())
+}
+
+// switchToTab makes the tab with the given target the current page
+func (c *ConnectionManager) switchToTab(id proto.TargetTargetID) error {
+	stale := c.rodPage()
+	page, err := stale.Browser().PageFromTarget(id)
+	if err != nil {
+		return fmt.Errorf("failed to attach to new tab: %w", err)
+	}
+	if err := browser.WaitReady(page, c.config.GetReadyTimeout()); err != nil {
+		return fmt.Errorf("new tab failed to load: %w", err)
+	}
+	if _, err := page.Activate(); err != nil {
+		c.logger.WithError(err).Debug("Failed to activate new tab")
+	}
+
+	c.pager = page
+	if err := stale.Close(); err != nil {
+		c.logger.WithError(err).Debug("Failed to close previous tab")
+	}
+
+	url := ""
+	if info, err := page.Info(); err == nil {
+		url = info.URL
+	}
+	c.logger.WithField("url", url).Info("Profile opened in a new tab, switched to it")
+	c.tracer.Record("connection", "tab_switch", map[string]interface{}{"url": url})
+
+	if c.onPageChange != nil {
+		c.onPageChange(page)
+	}
+	return nil
+}
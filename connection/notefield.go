@@ -0,0 +1,77 @@
+// Package connection - notefield.go handles fitting the note to the invite dialog's character limit
+package connection
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+// noteCounterSelector matches the live "12/300" counter under the note field
+const noteCounterSelector = ".connect-button-send-invite__custom-message-counter, .send-invite [class*='counter'], div[role='dialog'] [class*='char-count']"
+
+// noteSendButtonSelector matches the dialog's Send button, enabled or not
+const noteSendButtonSelector = "div[role='dialog'] button[aria-label*='Send'], .send-invite button.artdeco-button--primary"
+
+// maxNoteTypingAttempts bounds how often an over-long note is shortened and retyped
+const maxNoteTypingAttempts = 3
+
+// noteCounterPattern matches "12/300", "12 / 300" and "12 of 300"
+var noteCounterPattern = regexp.MustCompile(`(\d+)\s*(?:/|of)\s*(\d+)`)
+
+// parseNoteCounter extracts the used and maximum character counts from the
+// note field's counter text
+func parseNoteCounter(text string) (used, limit int, ok bool) {
+	match := noteCounterPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, 0, false
+	}
+	used, _ = strconv.Atoi(match[1])
+	limit, _ = strconv.Atoi(match[2])
+	if limit <= 0 {
+		return 0, 0, false
+	}
+	return used, limit, true
+}
+
+// readNoteCounter reads the note field's character counter, if it shows one
+func (c *ConnectionManager) readNoteCounter() (used, limit int, ok bool) {
+	counter, err := browser.ElementWithin(c.pager, time.Second, noteCounterSelector)
+	if err != nil {
+		return 0, 0, false
+	}
+	text, err := counter.Text()
+	if err != nil {
+		return 0, 0, false
+	}
+	return parseNoteCounter(text)
+}
+
+// noteSendEnabled reports whether the dialog's Send button accepts clicks;
+// an over-long note leaves it disabled. Without a recognizable button the
+// send step decides.
+func (c *ConnectionManager) noteSendEnabled() bool {
+	button, err := browser.ElementWithin(c.pager, 2*time.Second, noteSendButtonSelector)
+	if err != nil {
+		return true
+	}
+	enabled, err := browser.ElementEnabled(button)
+	return err != nil || enabled
+}
+
+// shortenedNoteLimit returns the limit for the next typing attempt after the
+// counter showed used characters against limit. The counter can run ahead of
+// our rune count (emoji and line breaks count double), so cut the overflow
+// plus a margin.
+func shortenedNoteLimit(current, used, limit int) int {
+	next := current - (used - limit) - 10
+	if used <= limit {
+		next = current - current/10
+	}
+	if next < 0 {
+		next = 0
+	}
+	return next
+}
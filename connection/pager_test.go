@@ -0,0 +1,219 @@
+// Package connection - Tests for manager orchestration against a fake page
+package connection
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+	"github.com/nikshitha/linkedin-automation-poc/search"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+// fakePager records page calls without a browser. Lookups succeed for
+// selectors containing one of present; the element itself is a placeholder.
+type fakePager struct {
+	calls     int
+	present   []string
+	navigated []string
+}
+
+func (f *fakePager) Navigate(url string) error {
+	f.calls++
+	f.navigated = append(f.navigated, url)
+	return nil
+}
+func (f *fakePager) Element(selector string) (*rod.Element, error) {
+	f.calls++
+	for _, s := range f.present {
+		if strings.Contains(selector, s) {
+			return &rod.Element{}, nil
+		}
+	}
+	return nil, &rod.ElementNotFoundError{}
+}
+func (f *fakePager) Elements(selector string) (rod.Elements, error) { f.calls++; return nil, nil }
+func (f *fakePager) WaitLoad() error                                { f.calls++; return nil }
+func (f *fakePager) Eval(js string, args ...interface{}) (*proto.RuntimeRemoteObject, error) {
+	f.calls++
+	return nil, nil
+}
+func (f *fakePager) Info() (*proto.TargetTargetInfo, error) {
+	f.calls++
+	return &proto.TargetTargetInfo{}, nil
+}
+func (f *fakePager) KeyActions() *rod.KeyActions { f.calls++; return nil }
+
+func TestSendConnectionRequestSkipsBeforeTouchingPage(t *testing.T) {
+	cfg := config.DefaultConfig()
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	sentURL := "https://www.linkedin.com/in/sent/"
+	if _, err := db.SaveConnectionRequest(&storage.ConnectionRequest{ProfileURL: sentURL, Status: "pending"}); err != nil {
+		t.Fatal(err)
+	}
+
+	before, _ := db.GetTodayConnectionCount()
+
+	page := &fakePager{}
+	rl := stealth.NewRateLimiter(&cfg.RateLimits, log)
+	cm := NewConnectionManager(cfg, log, nil, rl, db)
+	cm.SetPage(page)
+
+	if err := cm.SendConnectionRequest(&search.SearchResult{ProfileURL: sentURL}, ""); err == nil {
+		t.Error("Expected an error for an already-contacted profile")
+	}
+	if page.calls != 0 {
+		t.Errorf("Dedup should skip before touching the page, got %d calls", page.calls)
+	}
+
+	cfg.RateLimits.MaxConnectionsPerDay = 0
+	if err := cm.SendConnectionRequest(&search.SearchResult{ProfileURL: "https://www.linkedin.com/in/new/"}, ""); err == nil {
+		t.Error("Expected an error once the daily limit is reached")
+	}
+	if page.calls != 0 {
+		t.Errorf("Rate limit should stop before touching the page, got %d calls", page.calls)
+	}
+	if n, _ := db.GetTodayConnectionCount(); n != before {
+		t.Errorf("Skipped requests must not be recorded, got %d want %d", n, before)
+	}
+}
+
+func TestSendConnectionRequestCompanyLimit(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RateLimits.MaxConnectionsPerCompanyPerDay = 2
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, url := range []string{"https://www.linkedin.com/in/a/", "https://www.linkedin.com/in/b/"} {
+		db.SaveProfile(&storage.Profile{ProfileURL: url, Company: "Acme Corp"})
+		db.SaveConnectionRequest(&storage.ConnectionRequest{ProfileURL: url, Status: "pending"})
+	}
+	if n, _ := db.GetTodayConnectionCountByCompany(" acme corp "); n != 2 {
+		t.Fatalf("Expected 2 requests to Acme today, got %d", n)
+	}
+
+	page := &fakePager{}
+	rl := stealth.NewRateLimiter(&cfg.RateLimits, log)
+	cm := NewConnectionManager(cfg, log, nil, rl, db)
+	cm.SetPage(page)
+
+	err = cm.SendConnectionRequest(&search.SearchResult{ProfileURL: "https://www.linkedin.com/in/c/", Company: "ACME CORP"}, "")
+	if !errors.Is(err, ErrCompanyLimitReached) {
+		t.Errorf("Expected ErrCompanyLimitReached, got %v", err)
+	}
+	if page.calls != 0 {
+		t.Errorf("Company limit should skip before touching the page, got %d calls", page.calls)
+	}
+}
+
+func TestSendConnectionRequestDrivesFakePage(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Stealth.ActionDelayMin, cfg.Stealth.ActionDelayMax = 0, 0
+	cfg.Stealth.PageLoadWaitMin, cfg.Stealth.PageLoadWaitMax = 0, 0
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// The profile loads, but it offers no Connect button anywhere
+	page := &fakePager{present: []string{".pv-top-card"}}
+	rl := stealth.NewRateLimiter(&cfg.RateLimits, log)
+	cm := NewConnectionManager(cfg, log, stealth.NewStealthManager(&cfg.Stealth, log), rl, db)
+	cm.SetPage(page)
+
+	profileURL := "https://www.linkedin.com/in/no-connect/"
+	err = cm.SendConnectionRequest(&search.SearchResult{ProfileURL: profileURL}, "")
+	if err == nil || !strings.Contains(err.Error(), "connect button not found") {
+		t.Fatalf("Expected the missing Connect button to fail the request, got %v", err)
+	}
+
+	if len(page.navigated) != 1 || page.navigated[0] != profileURL {
+		t.Errorf("Expected one navigation to %s, got %v", profileURL, page.navigated)
+	}
+	stats, err := db.GetTodayStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.IncidentalProfileViews != 1 || stats.ProfilesViewed != 0 {
+		t.Errorf("Expected one incidental view and no explicit ones, got %d and %d", stats.IncidentalProfileViews, stats.ProfilesViewed)
+	}
+	if sent, _ := db.HasSentConnectionRequest(profileURL); sent {
+		t.Error("A request that was never sent must not be recorded")
+	}
+}
+
+func TestScrapeSentInvitationsWithoutCards(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Stealth.ActionDelayMin, cfg.Stealth.ActionDelayMax = 0, 0
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	cm := NewConnectionManager(cfg, log, stealth.NewStealthManager(&cfg.Stealth, log), nil, nil)
+
+	// No cards and no empty-state notice: the selector may simply have missed
+	cm.SetPage(&fakePager{})
+	if _, _, complete := cm.scrapeSentInvitations(); complete {
+		t.Error("A list with no cards and no empty-state notice must not count as complete")
+	}
+
+	cm.SetPage(&fakePager{present: []string{".artdeco-empty-state"}})
+	if _, _, complete := cm.scrapeSentInvitations(); !complete {
+		t.Error("An explicitly empty list should count as complete")
+	}
+}
+
+func TestWithdrawPendingByCompanyChargesOpenedProfilesOnly(t *testing.T) {
+	cfg := config.DefaultConfig()
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, url := range []string{"https://www.linkedin.com/in/a/", "https://www.linkedin.com/in/b/"} {
+		db.SaveProfile(&storage.Profile{ProfileURL: url, Company: "Acme Corp"})
+		db.SaveConnectionRequest(&storage.ConnectionRequest{ProfileURL: url, Status: "pending"})
+	}
+
+	// Neither profile loads, so neither visit counts as a profile view
+	page := &fakePager{}
+	rl := stealth.NewRateLimiter(&cfg.RateLimits, log)
+	cm := NewConnectionManager(cfg, log, stealth.NewStealthManager(&cfg.Stealth, log), rl, db)
+	cm.SetPage(page)
+
+	withdrawn, err := cm.WithdrawPendingByCompany("acme corp")
+	if err != nil || withdrawn != 0 {
+		t.Fatalf("Expected nothing withdrawn, got %d (%v)", withdrawn, err)
+	}
+	if len(page.navigated) != 2 {
+		t.Errorf("Expected both profiles to be tried, got %v", page.navigated)
+	}
+	if stats, _ := db.GetTodayStats(); stats.ProfilesViewed != 0 {
+		t.Errorf("Profiles that never loaded must not be charged, got %d views", stats.ProfilesViewed)
+	}
+	if remaining := rl.GetRemainingActions("profile_view"); remaining != cfg.RateLimits.MaxProfileViewsPerDay {
+		t.Errorf("Expected the full view budget left, got %d", remaining)
+	}
+}
@@ -0,0 +1,61 @@
+// Package connection - preview.go handles estimating a bulk run before it starts
+package connection
+
+import (
+	"strings"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/search"
+)
+
+// BulkPreview summarizes what SendBulkConnectionRequests would do with a set
+// of profiles given today's usage
+type BulkPreview struct {
+	Total            int       // profiles passed in
+	AlreadySent      int       // skipped: a request was sent before
+	AlreadyConnected int       // skipped: already a 1st-degree connection
+	WrongDegree      int       // skipped: degree not in search.connect_degrees
+	Eligible         int       // could be sent a request
+	RemainingToday   int       // requests left under today's limit
+	WillSend         int       // requests the run would send
+	BlockedUntil     time.Time // set while LinkedIn's invitation limit applies
+}
+
+// PreviewBulk counts how many of profiles a bulk run would actually contact,
+// using only the database and rate limiter (no navigation)
+func (c *ConnectionManager) PreviewBulk(profiles []*search.SearchResult) BulkPreview {
+	preview := BulkPreview{Total: len(profiles)}
+
+	for _, profile := range profiles {
+		if strings.HasPrefix(strings.TrimSpace(profile.Connection), "1st") {
+			preview.AlreadyConnected++
+			continue
+		}
+
+		if ok, _ := c.degreeAllowed(profile); !ok {
+			preview.WrongDegree++
+			continue
+		}
+
+		hasSent, err := c.db.HasSentConnectionRequest(profile.ProfileURL)
+		if err != nil {
+			c.logger.WithError(err).Warn("Failed to check existing connection request")
+		}
+		if hasSent {
+			preview.AlreadySent++
+			continue
+		}
+
+		preview.Eligible++
+	}
+
+	preview.RemainingToday = c.rateLimiter.GetRemainingActions("connection")
+	preview.BlockedUntil = c.rateLimiter.BlockedUntil("connection")
+
+	preview.WillSend = preview.Eligible
+	if preview.RemainingToday < preview.WillSend {
+		preview.WillSend = preview.RemainingToday
+	}
+
+	return preview
+}
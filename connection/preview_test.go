@@ -0,0 +1,70 @@
+// Package connection - Tests for bulk run previews
+package connection
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+	"github.com/nikshitha/linkedin-automation-poc/search"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+func TestPreviewBulk(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RateLimits.MaxConnectionsPerDay = 2
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.SaveConnectionRequest(&storage.ConnectionRequest{ProfileURL: "https://www.linkedin.com/in/sent/", Status: "pending"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rl := stealth.NewRateLimiter(&cfg.RateLimits, log)
+	cm := NewConnectionManager(cfg, log, nil, rl, db)
+
+	profiles := []*search.SearchResult{
+		{ProfileURL: "https://www.linkedin.com/in/sent/", Connection: "2nd"},
+		{ProfileURL: "https://www.linkedin.com/in/friend/", Connection: "1st"},
+		{ProfileURL: "https://www.linkedin.com/in/a/", Connection: "2nd"},
+		{ProfileURL: "https://www.linkedin.com/in/b/", Connection: "3rd+"},
+		{ProfileURL: "https://www.linkedin.com/in/c/"},
+	}
+
+	preview := cm.PreviewBulk(profiles)
+	if preview.Total != 5 || preview.AlreadySent != 1 || preview.AlreadyConnected != 1 || preview.Eligible != 3 {
+		t.Errorf("Unexpected counts: %+v", preview)
+	}
+	if preview.RemainingToday != 2 || preview.WillSend != 2 {
+		t.Errorf("Today's limit should cap the run at 2: %+v", preview)
+	}
+}
+
+func TestFilterByDegree(t *testing.T) {
+	cfg := config.DefaultConfig()
+	log, _ := logger.New(logger.Config{Level: "error"})
+	profiles := []*search.SearchResult{
+		{ProfileURL: "https://www.linkedin.com/in/friend/", Connection: "1st"},
+		{ProfileURL: "https://www.linkedin.com/in/a/", Connection: "• 2nd"},
+		{ProfileURL: "https://www.linkedin.com/in/b/", Connection: "3rd+"},
+		{ProfileURL: "https://www.linkedin.com/in/c/"},
+	}
+
+	cm := NewConnectionManager(cfg, log, nil, nil, nil)
+	if got := cm.FilterByDegree(profiles); len(got) != 4 {
+		t.Errorf("Expected no filtering without connect_degrees, kept %d", len(got))
+	}
+
+	cfg.Search.ConnectDegrees = []string{"2nd"}
+	got := cm.FilterByDegree(profiles)
+	if len(got) != 1 || got[0].ProfileURL != "https://www.linkedin.com/in/a/" {
+		t.Errorf("Expected only the 2nd-degree profile, got %d", len(got))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -14,6 +15,9 @@ import (
 type Logger struct {
 	*logrus.Logger
 	fields logrus.Fields
+
+	// narrateDelays logs every deliberate wait at info level
+	narrateDelays bool
 }
 
 // Config holds logger configuration
@@ -21,6 +25,16 @@ type Config struct {
 	Level      string
 	Format     string
 	OutputFile string
+
+	// RedactFields lists field names whose values are masked in all output
+	// (e.g. profile_url, note, email); empty disables redaction
+	RedactFields []string
+	// RedactHash replaces values with a short hash instead of [REDACTED]
+	RedactHash bool
+
+	// NarrateDelays logs each planned stealth/rate-limit wait and its reason
+	// at info level instead of debug
+	NarrateDelays bool
 }
 
 // New creates a new logger instance with the given configuration
@@ -52,6 +66,8 @@ func New(cfg Config) (*Logger, error) {
 		})
 	}
 
+	log.SetFormatter(newRedactingFormatter(log.Formatter, cfg.RedactFields, cfg.RedactHash))
+
 	// Set up output
 	writers := []io.Writer{os.Stdout}
 
@@ -73,8 +89,9 @@ func New(cfg Config) (*Logger, error) {
 	log.SetOutput(io.MultiWriter(writers...))
 
 	return &Logger{
-		Logger: log,
-		fields: make(logrus.Fields),
+		Logger:        log,
+		fields:        make(logrus.Fields),
+		narrateDelays: cfg.NarrateDelays,
 	}, nil
 }
 
@@ -87,8 +104,9 @@ func (l *Logger) WithField(key string, value interface{}) *Logger {
 	newFields[key] = value
 
 	return &Logger{
-		Logger: l.Logger,
-		fields: newFields,
+		Logger:        l.Logger,
+		fields:        newFields,
+		narrateDelays: l.narrateDelays,
 	}
 }
 
@@ -103,8 +121,9 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	}
 
 	return &Logger{
-		Logger: l.Logger,
-		fields: newFields,
+		Logger:        l.Logger,
+		fields:        newFields,
+		narrateDelays: l.narrateDelays,
 	}
 }
 
@@ -173,6 +192,20 @@ func (l *Logger) StealthAction(action string, details map[string]interface{}) {
 	l.WithFields(fields).Debug("Stealth action performed")
 }
 
+// Delay logs a wait that is about to start and why, so deliberate pauses can
+// be told apart from hangs. It logs at info level when NarrateDelays is set.
+func (l *Logger) Delay(reason string, d time.Duration) {
+	entry := l.WithFields(map[string]interface{}{
+		"delay_reason": reason,
+		"duration":     d.Round(time.Millisecond).String(),
+	})
+	if l.narrateDelays {
+		entry.Infof("Waiting %s (%s)", d.Round(time.Millisecond), reason)
+		return
+	}
+	entry.Debug("Waiting")
+}
+
 // BrowserAction logs a browser action
 func (l *Logger) BrowserAction(action string, url string) {
 	l.WithFields(map[string]interface{}{
@@ -215,3 +248,13 @@ func (l *Logger) SecurityEvent(eventType string, details string) {
 		"details":        details,
 	}).Warn("Security event detected")
 }
+
+// SecurityAlert logs a high-severity security event, e.g. a sign the account
+// is about to be restricted
+func (l *Logger) SecurityAlert(eventType string, details string) {
+	l.WithFields(map[string]interface{}{
+		"security_event": eventType,
+		"details":        details,
+		"severity":       "high",
+	}).Error("Security event detected")
+}
@@ -0,0 +1,83 @@
+// Package logger - redact.go handles masking sensitive field values in log output
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RedactedValue replaces field values when redaction masks instead of hashing
+const RedactedValue = "[REDACTED]"
+
+// sensitiveTextPattern matches profile URLs and email addresses that end up
+// in free text, e.g. a message or a wrapped error naming the profile
+var sensitiveTextPattern = regexp.MustCompile(`(?i)(https?://)?([a-z0-9-]+\.)?linkedin\.com/in/[^\s"'<>,;)]+|[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}`)
+
+// redactingFormatter wraps a formatter and replaces the values of configured
+// fields before the entry is written, so no output path sees the raw value
+type redactingFormatter struct {
+	next   logrus.Formatter
+	fields map[string]bool
+	hash   bool
+}
+
+// newRedactingFormatter returns next unchanged when there is nothing to redact
+func newRedactingFormatter(next logrus.Formatter, fields []string, hash bool) logrus.Formatter {
+	if len(fields) == 0 {
+		return next
+	}
+
+	set := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		set[field] = true
+	}
+
+	return &redactingFormatter{next: next, fields: set, hash: hash}
+}
+
+// Format redacts a copy of the entry and delegates to the wrapped formatter.
+// Configured fields are masked whole; the message and every other string or
+// error field have profile URLs and email addresses masked in place, since
+// those leak through formatted messages and wrapped errors.
+func (f *redactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		if f.fields[key] {
+			data[key] = f.redact(value)
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			value = f.redactText(v)
+		case error:
+			value = f.redactText(v.Error())
+		}
+		data[key] = value
+	}
+
+	clone := *entry
+	clone.Data = data
+	clone.Message = f.redactText(entry.Message)
+	return f.next.Format(&clone)
+}
+
+// redactText masks the profile URLs and email addresses in s
+func (f *redactingFormatter) redactText(s string) string {
+	return sensitiveTextPattern.ReplaceAllStringFunc(s, func(match string) string {
+		return f.redact(match)
+	})
+}
+
+// redact masks a value, or replaces it with a short stable hash so entries
+// about the same profile can still be correlated
+func (f *redactingFormatter) redact(value interface{}) string {
+	if !f.hash {
+		return RedactedValue
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprint(value)))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,83 @@
+// Package logger - Tests for log redaction
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRedactedLoggerNeverEmitsRawValue(t *testing.T) {
+	const profileURL = "https://www.linkedin.com/in/secret-target/"
+	const note = "Hi Jane, loved your talk"
+
+	for _, format := range []string{"text", "json"} {
+		for _, hash := range []bool{false, true} {
+			log, err := New(Config{
+				Level:        "debug",
+				Format:       format,
+				RedactFields: []string{"profile_url", "recipient_url", "note"},
+				RedactHash:   hash,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			log.SetOutput(&buf)
+
+			log.WithField("profile_url", profileURL).Info("Visiting profile")
+			log.WithFields(map[string]interface{}{"note": note, "status": "sent"}).Debug("Note chosen")
+			log.ConnectionRequest(profileURL, "sent", note)
+			log.Message(profileURL, "sent", "follow_up")
+
+			out := buf.String()
+			if strings.Contains(out, "secret-target") || strings.Contains(out, "loved your talk") {
+				t.Errorf("format=%s hash=%v: raw value leaked:\n%s", format, hash, out)
+			}
+			if !strings.Contains(out, "sent") {
+				t.Errorf("format=%s hash=%v: non-redacted fields should be kept:\n%s", format, hash, out)
+			}
+
+			marker := RedactedValue
+			if hash {
+				marker = "sha256:"
+			}
+			if strings.Count(out, marker) < 4 {
+				t.Errorf("format=%s hash=%v: expected redaction markers:\n%s", format, hash, out)
+			}
+		}
+	}
+}
+
+func TestUnredactedLoggerKeepsValues(t *testing.T) {
+	log, _ := New(Config{Level: "info", Format: "json"})
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	log.WithField("profile_url", "https://www.linkedin.com/in/someone/").Info("Visiting profile")
+	if !strings.Contains(buf.String(), "someone") {
+		t.Errorf("Values should be logged when redaction is off: %s", buf.String())
+	}
+}
+
+func TestRedactedLoggerScrubsMessagesAndErrors(t *testing.T) {
+	log, _ := New(Config{Level: "info", Format: "json", RedactFields: []string{"profile_url", "name"}})
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+
+	log.WithError(fmt.Errorf("connection request already sent to %s", "https://www.linkedin.com/in/secret-target/")).
+		Warn("Failed to connect")
+	log.Infof("Visiting linkedin.com/in/other-target?trk=x for jane.doe@example.com")
+	log.WithField("name", "Jane Doe").Info("Logged in")
+
+	out := buf.String()
+	for _, raw := range []string{"secret-target", "other-target", "jane.doe@example.com", "Jane Doe"} {
+		if strings.Contains(out, raw) {
+			t.Errorf("%q leaked:\n%s", raw, out)
+		}
+	}
+	if !strings.Contains(out, "connection request already sent to") || !strings.Contains(out, "Visiting") {
+		t.Errorf("The rest of the message and error should be kept:\n%s", out)
+	}
+}
@@ -0,0 +1,112 @@
+// Package logger - tracer.go handles the replayable decision trace.
+// Unlike the general log, the trace keeps every decision (rate-limit checks,
+// dedup skips, selectors, delays slept) in memory so it can be dumped later.
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TraceEvent is a single recorded decision
+type TraceEvent struct {
+	Time   time.Time              `json:"time"`
+	Module string                 `json:"module"`
+	Event  string                 `json:"event"`
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Tracer records decisions into a fixed-size ring buffer.
+// A nil *Tracer is valid and records nothing.
+type Tracer struct {
+	mu     sync.Mutex
+	events []TraceEvent
+	next   int
+	full   bool
+}
+
+// NewTracer creates a tracer holding the most recent capacity events.
+// Returns nil (tracing disabled) when capacity is not positive.
+func NewTracer(capacity int) *Tracer {
+	if capacity <= 0 {
+		return nil
+	}
+	return &Tracer{events: make([]TraceEvent, capacity)}
+}
+
+// Record adds an event, overwriting the oldest one when the buffer is full
+func (t *Tracer) Record(module, event string, fields map[string]interface{}) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events[t.next] = TraceEvent{
+		Time:   time.Now(),
+		Module: module,
+		Event:  event,
+		Fields: fields,
+	}
+	t.next = (t.next + 1) % len(t.events)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+// Events returns the buffered events in chronological order
+func (t *Tracer) Events() []TraceEvent {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.full {
+		return append([]TraceEvent(nil), t.events[:t.next]...)
+	}
+
+	events := make([]TraceEvent, 0, len(t.events))
+	events = append(events, t.events[t.next:]...)
+	return append(events, t.events[:t.next]...)
+}
+
+// Dump writes the buffered events to path as JSON lines
+func (t *Tracer) Dump(path string) error {
+	if t == nil {
+		return fmt.Errorf("tracing is disabled")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create trace directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create trace file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, event := range t.Events() {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to write trace: %w", err)
+		}
+	}
+	return nil
+}
+
+// DumpToDir writes the trace to a timestamped file in dir and returns its path
+func (t *Tracer) DumpToDir(dir string) (string, error) {
+	path := filepath.Join(dir, fmt.Sprintf("trace-%s.jsonl", time.Now().Format("20060102-150405")))
+	if err := t.Dump(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
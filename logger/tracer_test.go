@@ -0,0 +1,70 @@
+// Package logger - Tests for the decision tracer
+package logger
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTracerRingBuffer(t *testing.T) {
+	tracer := NewTracer(3)
+	for _, event := range []string{"a", "b", "c", "d", "e"} {
+		tracer.Record("test", event, nil)
+	}
+
+	events := tracer.Events()
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 buffered events, got %d", len(events))
+	}
+
+	// Oldest events are overwritten, order stays chronological
+	for i, want := range []string{"c", "d", "e"} {
+		if events[i].Event != want {
+			t.Errorf("Event %d: expected %s, got %s", i, want, events[i].Event)
+		}
+	}
+}
+
+func TestTracerDump(t *testing.T) {
+	tracer := NewTracer(10)
+	tracer.Record("rate_limiter", "check", map[string]interface{}{"allowed": true})
+	tracer.Record("stealth", "delay", map[string]interface{}{"duration_ms": 120})
+
+	path, err := tracer.DumpToDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("DumpToDir failed: %v", err)
+	}
+	if filepath.Ext(path) != ".jsonl" {
+		t.Errorf("Expected a .jsonl trace file, got %s", path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open trace: %v", err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("Expected 2 trace lines, got %d", lines)
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	// Disabled tracing must be safe to call everywhere
+	tracer := NewTracer(0)
+	tracer.Record("test", "ignored", nil)
+
+	if events := tracer.Events(); len(events) != 0 {
+		t.Errorf("Nil tracer should have no events, got %d", len(events))
+	}
+	if err := tracer.Dump(filepath.Join(t.TempDir(), "trace.jsonl")); err == nil {
+		t.Error("Dumping a disabled tracer should fail")
+	}
+}
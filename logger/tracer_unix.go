@@ -0,0 +1,31 @@
+//go:build !windows
+
+// Package logger - tracer_unix.go handles dumping the trace on SIGUSR2
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// DumpOnSignal dumps the trace to dir every time the process receives SIGUSR2
+func (t *Tracer) DumpOnSignal(dir string, log *Logger) {
+	if t == nil {
+		return
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGUSR2)
+
+	go func() {
+		for range sigChan {
+			path, err := t.DumpToDir(dir)
+			if err != nil {
+				log.WithError(err).Warn("Failed to dump trace")
+				continue
+			}
+			log.WithField("path", path).Info("Trace dumped")
+		}
+	}()
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+// Package logger - tracer_windows.go stubs signal dumping, which needs SIGUSR2
+package logger
+
+// DumpOnSignal is unavailable on Windows; the trace is still dumped on error
+func (t *Tracer) DumpOnSignal(dir string, log *Logger) {
+	if t == nil {
+		return
+	}
+	log.Debug("Trace dump on signal is not supported on Windows")
+}
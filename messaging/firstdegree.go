@@ -0,0 +1,160 @@
+// Package messaging - firstdegree.go handles messaging campaigns limited to 1st-degree connections
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nikshitha/linkedin-automation-poc/search"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+// profileDegreeSelector matches the connection degree badge on a profile's top card
+const profileDegreeSelector = ".pv-top-card .dist-value, .pv-top-card .distance-badge, .pv-top-card--list .dist-value"
+
+// errNotFirstDegree marks a profile the first-degree campaign skips
+var errNotFirstDegree = errors.New("not a 1st-degree connection")
+
+// readProfileDegree returns the normalized degree shown on the open profile,
+// or "" if the badge isn't there
+func (m *MessagingManager) readProfileDegree() string {
+	el, err := m.pager.Element(profileDegreeSelector)
+	if err != nil || el == nil {
+		return ""
+	}
+	text, err := el.Text()
+	if err != nil {
+		return ""
+	}
+	return search.NormalizeDegree(strings.TrimSpace(text))
+}
+
+// firstDegreeSkipReason decides from the stored degree and the one shown on
+// the profile whether a profile can be messaged directly. The profile page
+// wins when it shows a degree; an unknown degree is never assumed to be 1st.
+func firstDegreeSkipReason(stored, shown string) string {
+	degree := search.NormalizeDegree(shown)
+	if degree == "" {
+		degree = search.NormalizeDegree(stored)
+	}
+	switch degree {
+	case "1st":
+		return ""
+	case "":
+		return "connection degree unknown"
+	default:
+		return fmt.Sprintf("%s degree, not a connection", degree)
+	}
+}
+
+// degreeCheckViewType returns how opening a profile with the stored degree is
+// charged. A stored 1st degree means the visit is for sending the message;
+// anything else opens the profile to check its degree, which is a real
+// profile view and counts against max_profile_views_per_day.
+func degreeCheckViewType(stored string) string {
+	if search.NormalizeDegree(stored) == "1st" {
+		return stealth.ViewIncidental
+	}
+	return stealth.ViewExplicit
+}
+
+// ProcessFirstDegreeMessaging sends the direct message template to the
+// 1st-degree connections among profiles. Each profile is opened and skipped
+// unless the profile page confirms a 1st-degree connection; the stored degree
+// dates from the search and misses anyone who accepted since. Profiles not
+// stored as 1st-degree are opened as explicit profile views and skipped once
+// the view cap is reached. Anyone messaged within recent_message_days is
+// skipped as well.
+func (m *MessagingManager) ProcessFirstDegreeMessaging(profiles []*search.SearchResult) (sent, failed, skipped int, err error) {
+	for _, profile := range profiles {
+		// Blocks while paused; resumes with this profile
+		if !m.pause.WaitWhilePaused(m.ctx) {
+			m.logger.Info("Run stopped, ending first-degree messages")
+			break
+		}
+
+		if !m.rateLimiter.CanPerformAction("message") {
+			m.logger.Warn("Rate limit reached, stopping first-degree messages")
+			break
+		}
+
+		profileURL := m.cleanProfileURL(profile.ProfileURL)
+
+		if m.messagedRecently(profileURL) {
+			m.logger.WithField("profile", profileURL).Debug("Messaged recently, skipping")
+			skipped++
+			continue
+		}
+
+		viewType := degreeCheckViewType(profile.Connection)
+		if viewType == stealth.ViewExplicit && !m.rateLimiter.CanPerformAction(stealth.ProfileViewAction(viewType)) {
+			m.logFirstDegreeSkip(profileURL, "profile view limit reached, degree not checked")
+			skipped++
+			continue
+		}
+
+		// The degree is checked once the profile is open, before composing
+		var skipReason string
+		_, err := m.sendDirectMessage(profileURL, viewType, func() (string, error) {
+			if skipReason = firstDegreeSkipReason(profile.Connection, m.readProfileDegree()); skipReason != "" {
+				return "", errNotFirstDegree
+			}
+			return m.generateDirectMessage(profileFromResult(profile), m.scrapeMutualConnectionName())
+		})
+		switch {
+		case errors.Is(err, errNotFirstDegree):
+			m.logFirstDegreeSkip(profileURL, skipReason)
+			skipped++
+		case err != nil:
+			m.logger.WithError(err).WithField("profile", profileURL).Warn("Failed to send direct message")
+			m.tracer.Record("messaging", "failed", map[string]interface{}{
+				"profile_url": profileURL,
+				"error":       err.Error(),
+			})
+			m.rateLimiter.RecordFailure("message")
+			failed++
+		default:
+			m.tracer.Record("messaging", "sent", map[string]interface{}{"profile_url": profileURL})
+			sent++
+		}
+
+		// The in-flight message has finished; don't wait out delays past the deadline
+		if m.ctx.Err() != nil {
+			continue
+		}
+
+		m.stealth.ThinkingDelay()
+		m.stealth.MaybeSimulateTabBlur(m.rodPage())
+		m.rateLimiter.WaitForNextAction()
+	}
+
+	m.logger.Infof("First-degree messages: %d sent, %d failed, %d skipped", sent, failed, skipped)
+	return sent, failed, skipped, nil
+}
+
+// logFirstDegreeSkip logs and traces a profile the first-degree campaign left out
+func (m *MessagingManager) logFirstDegreeSkip(profileURL, reason string) {
+	m.logger.WithField("profile_url", profileURL).Infof("Skipping profile: %s", reason)
+	m.tracer.Record("messaging", "degree_skip", map[string]interface{}{
+		"profile_url": profileURL,
+		"reason":      reason,
+	})
+}
+
+// profileFromResult converts a search result into the profile the message
+// template is rendered from
+func profileFromResult(result *search.SearchResult) *storage.Profile {
+	return &storage.Profile{
+		ProfileURL:        result.ProfileURL,
+		Name:              result.Name,
+		FirstName:         result.FirstName,
+		LastName:          result.LastName,
+		Headline:          result.Headline,
+		Company:           result.Company,
+		Location:          result.Location,
+		ConnectionDegree:  result.Connection,
+		MutualConnections: result.MutualConns,
+	}
+}
@@ -0,0 +1,40 @@
+// Package messaging - Tests for first-degree campaign decisions
+package messaging
+
+import (
+	"testing"
+
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+)
+
+func TestFirstDegreeSkipReason(t *testing.T) {
+	tests := []struct {
+		stored, shown string
+		skip          bool
+	}{
+		{"1st", "", false},
+		{"", "· 1st", false},
+		{"2nd", "1st", false}, // connected since the profile was stored
+		{"1st", "2nd", true},
+		{"3rd+", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		reason := firstDegreeSkipReason(tt.stored, tt.shown)
+		if (reason != "") != tt.skip {
+			t.Errorf("firstDegreeSkipReason(%q, %q) = %q, want skip %v", tt.stored, tt.shown, reason, tt.skip)
+		}
+	}
+}
+
+func TestDegreeCheckViewType(t *testing.T) {
+	if got := degreeCheckViewType("· 1st"); got != stealth.ViewIncidental {
+		t.Errorf("A stored 1st-degree profile is opened to message it, got %q", got)
+	}
+	for _, stored := range []string{"2nd", "3rd+", ""} {
+		if got := degreeCheckViewType(stored); got != stealth.ViewExplicit {
+			t.Errorf("Opening a %q profile to check its degree should be an explicit view, got %q", stored, got)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package messaging - message_request.go handles messages LinkedIn queues for approval
+package messaging
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+// SendStatus describes what happened to a message after Send was clicked
+type SendStatus string
+
+const (
+	// SendStatusSent means the message was delivered to the conversation
+	SendStatusSent SendStatus = "sent"
+	// SendStatusPendingApproval means LinkedIn queued the message as a
+	// message request that the recipient has to accept first
+	SendStatusPendingApproval SendStatus = "pending_approval"
+	// SendStatusUnknown means Send was clicked but the conversation couldn't
+	// be checked for the message-request notice
+	SendStatusUnknown SendStatus = "unknown"
+)
+
+// messageRequestPattern matches the notice shown when a message to a
+// non-connection is held for the recipient's approval, as a JS regex literal
+// for ElementR
+const messageRequestPattern = `/your message is pending|message request (sent|pending)|pending (their )?(approval|acceptance)|delivered (once|when|if) .* accept/i`
+
+// messageRequestSelectors are the containers the pending notice appears in
+const messageRequestSelectors = ".msg-overlay-conversation-bubble, .msg-convo-wrapper, .msg-s-message-list-container, .artdeco-toast-item"
+
+// detectSendStatus checks the conversation for the message-request notice
+// after sending. No notice before the timeout means delivered; a lookup that
+// failed any other way leaves the status unknown.
+func (m *MessagingManager) detectSendStatus() SendStatus {
+	_, err := browser.ElementRWithin(m.pager, 2*time.Second, messageRequestSelectors, messageRequestPattern)
+	var notFound *rod.ElementNotFoundError
+	switch {
+	case err == nil:
+		return SendStatusPendingApproval
+	case errors.Is(err, context.DeadlineExceeded) || errors.As(err, &notFound):
+		return SendStatusSent
+	default:
+		m.logger.WithError(err).Warn("Could not check whether the message was queued as a message request")
+		return SendStatusUnknown
+	}
+}
@@ -0,0 +1,23 @@
+// Package messaging - Tests for detecting messages queued as message requests
+package messaging
+
+import (
+	"testing"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+func TestMessageRequestPattern(t *testing.T) {
+	re, err := browser.CompileJSRegex(messageRequestPattern)
+	if err != nil {
+		t.Fatalf("Message request pattern won't run in the browser: %v", err)
+	}
+	for _, text := range []string{"Your message is pending", "Message request sent", "It will be delivered once Jane accepts"} {
+		if !re.MatchString(text) {
+			t.Errorf("Expected %q to mark a message request", text)
+		}
+	}
+	if re.MatchString("Sent just now") {
+		t.Error("A delivered message should not match")
+	}
+}
@@ -4,12 +4,14 @@ package messaging
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
 	"github.com/nikshitha/linkedin-automation-poc/config"
 	"github.com/nikshitha/linkedin-automation-poc/logger"
 	"github.com/nikshitha/linkedin-automation-poc/stealth"
@@ -30,7 +32,10 @@ type MessagingManager struct {
 	stealth     *stealth.StealthManager
 	rateLimiter *stealth.RateLimiter
 	db          *storage.Database
-	page        *rod.Page
+	pager       browser.Pager
+	ctx         context.Context
+	tracer      *logger.Tracer
+	pause       *stealth.PauseController
 }
 
 // NewMessagingManager creates a new messaging manager
@@ -41,12 +46,34 @@ func NewMessagingManager(cfg *config.Config, log *logger.Logger, s *stealth.Stea
 		stealth:     s,
 		rateLimiter: rl,
 		db:          db,
+		ctx:         context.Background(),
 	}
 }
 
 // SetPage sets the page instance
-func (m *MessagingManager) SetPage(page *rod.Page) {
-	m.page = page
+func (m *MessagingManager) SetPage(page browser.Pager) {
+	m.pager = page
+}
+
+// rodPage returns the browser page behind the pager for mouse, keyboard and
+// screenshot work, or nil when a fake pager is set
+func (m *MessagingManager) rodPage() *rod.Page {
+	return browser.RodPage(m.pager)
+}
+
+// SetTracer sets the decision tracer
+func (m *MessagingManager) SetTracer(t *logger.Tracer) {
+	m.tracer = t
+}
+
+// SetContext sets the run context; bulk operations stop between messages once it is done
+func (m *MessagingManager) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// SetPauseController sets the controller checked before each bulk message
+func (m *MessagingManager) SetPauseController(p *stealth.PauseController) {
+	m.pause = p
 }
 
 // MessageTemplateData holds data for message personalization
@@ -58,6 +85,10 @@ type MessageTemplateData struct {
 	Headline   string
 	Location   string
 	DaysSince  int
+
+	// First shared connection named on their profile; empty when there is none,
+	// so templates should guard it: {{if .MutualConnectionName}}...{{end}}
+	MutualConnectionName string
 }
 
 // AcceptedConnection represents a newly accepted connection
@@ -69,6 +100,9 @@ type AcceptedConnection struct {
 	Headline   string
 	Company    string
 	AcceptedAt time.Time
+
+	// Filled in from their profile just before the follow-up is written
+	MutualConnectionName string
 }
 
 // CheckNewlyAcceptedConnections checks for newly accepted connection requests
@@ -81,23 +115,23 @@ func (m *MessagingManager) CheckNewlyAcceptedConnections() ([]*AcceptedConnectio
 		return nil, fmt.Errorf("failed to get pending requests: %w", err)
 	}
 
-	if len(pendingRequests) == 0 {
-		m.logger.Debug("No pending connection requests to check")
-		return nil, nil
-	}
-
 	m.logger.Infof("Checking %d pending requests", len(pendingRequests))
 
+	pendingByURL := make(map[string]*storage.ConnectionRequest, len(pendingRequests))
+	for _, request := range pendingRequests {
+		pendingByURL[m.cleanProfileURL(request.ProfileURL)] = request
+	}
+
 	var newlyAccepted []*AcceptedConnection
 
 	// Navigate to connections page
-	err = m.page.Navigate(LinkedInConnectionsURL)
+	err = browser.NavigateAndWaitReady(m.pager, LinkedInConnectionsURL, m.config.GetReadyTimeout())
 	if err != nil {
 		return nil, fmt.Errorf("failed to navigate to connections: %w", err)
 	}
 
 	m.stealth.PageLoadDelay()
-	m.stealth.ApplyFingerprintMasking(m.page)
+	m.stealth.ApplyFingerprintMasking(m.rodPage())
 
 	// Get list of current 1st-degree connections
 	connections, err := m.getRecentConnections()
@@ -105,33 +139,65 @@ func (m *MessagingManager) CheckNewlyAcceptedConnections() ([]*AcceptedConnectio
 		m.logger.WithError(err).Warn("Failed to get recent connections")
 	}
 
-	// Check each pending request
-	for _, request := range pendingRequests {
-		// Check if this profile is now in connections
-		if m.isInConnections(request.ProfileURL, connections) {
-			m.logger.WithField("profile_url", request.ProfileURL).Info("Connection accepted!")
-
-			// Update status in database
-			m.db.UpdateConnectionStatus(request.ProfileURL, "accepted")
-
-			// Get profile details
-			profile, _ := m.db.GetProfile(request.ProfileURL)
-
-			accepted := &AcceptedConnection{
-				ProfileURL: request.ProfileURL,
-				AcceptedAt: time.Now(),
-			}
-
-			if profile != nil {
-				accepted.Name = profile.Name
-				accepted.FirstName = profile.FirstName
-				accepted.LastName = profile.LastName
-				accepted.Headline = profile.Headline
-				accepted.Company = profile.Company
-			}
-
-			newlyAccepted = append(newlyAccepted, accepted)
+	// Only connections that weren't in the last snapshot can be new acceptances
+	added, err := m.db.DiffConnections(connections)
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to diff connections snapshot, checking every connection")
+		added = connections
+	}
+	m.logger.WithField("count", len(added)).Debug("New 1st-degree connections since last snapshot")
+
+	// Map each new connection back to the request we sent them. A connection
+	// that couldn't be checked or recorded is left out of the snapshot so the
+	// next check sees it as new again.
+	unprocessed := make(map[string]bool)
+	for _, profileURL := range added {
+		request, err := m.findUnacceptedRequest(profileURL, pendingByURL)
+		if err != nil {
+			m.logger.WithError(err).WithField("profile_url", profileURL).Warn("Failed to look up connection request")
+			unprocessed[profileURL] = true
+			continue
+		}
+		if request == nil {
+			continue
+		}
+
+		// Update status in database
+		if err := m.db.UpdateConnectionStatus(request.ProfileURL, "accepted"); err != nil {
+			m.logger.WithError(err).WithField("profile_url", request.ProfileURL).Warn("Failed to record accepted connection")
+			unprocessed[profileURL] = true
+			continue
+		}
+
+		m.logger.WithField("profile_url", request.ProfileURL).Info("Connection accepted!")
+
+		// Get profile details
+		profile, _ := m.db.GetProfile(request.ProfileURL)
+
+		accepted := &AcceptedConnection{
+			ProfileURL: request.ProfileURL,
+			AcceptedAt: time.Now(),
 		}
+
+		if profile != nil {
+			accepted.Name = profile.Name
+			accepted.FirstName = profile.FirstName
+			accepted.LastName = profile.LastName
+			accepted.Headline = profile.Headline
+			accepted.Company = profile.Company
+		}
+
+		newlyAccepted = append(newlyAccepted, accepted)
+	}
+
+	snapshot := make([]string, 0, len(connections))
+	for _, profileURL := range connections {
+		if !unprocessed[profileURL] {
+			snapshot = append(snapshot, profileURL)
+		}
+	}
+	if err := m.db.SaveConnectionsSnapshot(snapshot); err != nil {
+		m.logger.WithError(err).Warn("Failed to save connections snapshot")
 	}
 
 	m.logger.Infof("Found %d newly accepted connections", len(newlyAccepted))
@@ -143,19 +209,19 @@ func (m *MessagingManager) getRecentConnections() ([]string, error) {
 	var connections []string
 
 	// Wait for connections list
-	_, err := m.page.Timeout(10 * time.Second).Element(".mn-connection-card, .mn-connections, .scaffold-finite-scroll__content")
+	_, err := browser.ElementWithin(m.pager, 10*time.Second, ".mn-connection-card, .mn-connections, .scaffold-finite-scroll__content")
 	if err != nil {
 		return nil, err
 	}
 
 	// Scroll to load more connections
 	for i := 0; i < 3; i++ {
-		m.stealth.HumanScroll(m.page, "down", 400)
+		m.stealth.HumanScroll(m.rodPage(), "down", 400)
 		time.Sleep(500 * time.Millisecond)
 	}
 
 	// Get connection profile URLs
-	links, err := m.page.Elements("a[href*='/in/'].mn-connection-card__link, a.ember-view[href*='/in/']")
+	links, err := m.pager.Elements("a[href*='/in/'].mn-connection-card__link, a.ember-view[href*='/in/']")
 	if err != nil {
 		return nil, err
 	}
@@ -170,15 +236,23 @@ func (m *MessagingManager) getRecentConnections() ([]string, error) {
 	return connections, nil
 }
 
-// isInConnections checks if a profile URL is in the connections list
-func (m *MessagingManager) isInConnections(profileURL string, connections []string) bool {
-	cleanURL := m.cleanProfileURL(profileURL)
-	for _, conn := range connections {
-		if m.cleanProfileURL(conn) == cleanURL {
-			return true
-		}
+// findUnacceptedRequest returns the connection request sent to a new
+// connection, or nil if we never invited them or already recorded the
+// acceptance. Requests missing from the pending list (e.g. marked withdrawn
+// or declined too early) are looked up directly.
+func (m *MessagingManager) findUnacceptedRequest(profileURL string, pendingByURL map[string]*storage.ConnectionRequest) (*storage.ConnectionRequest, error) {
+	if request, ok := pendingByURL[m.cleanProfileURL(profileURL)]; ok {
+		return request, nil
 	}
-	return false
+
+	requests, err := m.db.GetConnectionRequests(storage.ConnectionFilter{ProfileURL: profileURL, Limit: 1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up connection request: %w", err)
+	}
+	if len(requests) == 0 || requests[0].Status == "accepted" {
+		return nil, nil
+	}
+	return requests[0], nil
 }
 
 // cleanProfileURL cleans a LinkedIn profile URL for comparison
@@ -192,7 +266,7 @@ func (m *MessagingManager) cleanProfileURL(url string) string {
 }
 
 // SendFollowUpMessage sends a follow-up message to an accepted connection
-func (m *MessagingManager) SendFollowUpMessage(connection *AcceptedConnection, customMessage string) error {
+func (m *MessagingManager) SendFollowUpMessage(connection *AcceptedConnection, customMessage string) (err error) {
 	m.logger.WithFields(map[string]interface{}{
 		"profile_url": connection.ProfileURL,
 		"name":        connection.Name,
@@ -200,7 +274,7 @@ func (m *MessagingManager) SendFollowUpMessage(connection *AcceptedConnection, c
 
 	// Check rate limits
 	if !m.rateLimiter.CanPerformAction("message") {
-		return fmt.Errorf("message rate limit reached")
+		return fmt.Errorf("message %w", stealth.ErrRateLimited)
 	}
 
 	// Check if already sent follow-up
@@ -212,21 +286,25 @@ func (m *MessagingManager) SendFollowUpMessage(connection *AcceptedConnection, c
 		return fmt.Errorf("follow-up message already sent to %s", connection.ProfileURL)
 	}
 
-	// Generate message if not provided
+	defer browser.ScreenshotOnError(m.pager, &m.config.Browser, m.logger, "message", connection.ProfileURL, &err)
+
+	// Navigate to profile
+	err = m.navigateToProfile(connection.ProfileURL, stealth.ViewIncidental)
+	if err != nil {
+		return fmt.Errorf("failed to navigate to profile: %w", err)
+	}
+
+	// Generate message if not provided, mentioning a shared connection
+	// shown on their profile
 	message := customMessage
 	if message == "" {
+		connection.MutualConnectionName = m.scrapeMutualConnectionName()
 		message, err = m.generateFollowUpMessage(connection)
 		if err != nil {
 			return fmt.Errorf("failed to generate message: %w", err)
 		}
 	}
 
-	// Navigate to profile
-	err = m.navigateToProfile(connection.ProfileURL)
-	if err != nil {
-		return fmt.Errorf("failed to navigate to profile: %w", err)
-	}
-
 	m.stealth.ThinkingDelay()
 
 	// Click Message button
@@ -236,7 +314,7 @@ func (m *MessagingManager) SendFollowUpMessage(connection *AcceptedConnection, c
 	}
 
 	// Type and send message
-	err = m.typeAndSendMessage(message)
+	status, err := m.typeAndSendMessage(message)
 	if err != nil {
 		return fmt.Errorf("failed to send message: %w", err)
 	}
@@ -245,26 +323,41 @@ func (m *MessagingManager) SendFollowUpMessage(connection *AcceptedConnection, c
 	m.rateLimiter.RecordAction("message")
 
 	// Save to database
-	m.saveMessage(connection.ProfileURL, message, "follow_up")
-
-	m.logger.Message(connection.ProfileURL, "sent", "follow_up")
+	m.recordSend(connection.ProfileURL, message, "follow_up", status)
 
 	return nil
 }
 
-// SendDirectMessage sends a direct message to a connection
-func (m *MessagingManager) SendDirectMessage(profileURL string, message string) error {
+// SendDirectMessage sends a direct message to a profile. The returned status
+// is SendStatusPendingApproval when LinkedIn queued the message as a message
+// request instead of delivering it; that is not an error.
+func (m *MessagingManager) SendDirectMessage(profileURL string, message string) (SendStatus, error) {
+	return m.sendDirectMessage(profileURL, stealth.ViewIncidental, func() (string, error) {
+		return message, nil
+	})
+}
+
+// sendDirectMessage opens the profile, charged as viewType, writes the
+// message with compose once the profile is showing, and sends it
+func (m *MessagingManager) sendDirectMessage(profileURL, viewType string, compose func() (string, error)) (status SendStatus, err error) {
 	m.logger.WithField("profile_url", profileURL).Info("Sending direct message")
 
 	// Check rate limits
 	if !m.rateLimiter.CanPerformAction("message") {
-		return fmt.Errorf("message rate limit reached")
+		return "", fmt.Errorf("message %w", stealth.ErrRateLimited)
 	}
 
+	defer browser.ScreenshotOnError(m.pager, &m.config.Browser, m.logger, "message", profileURL, &err, errNotFirstDegree)
+
 	// Navigate to profile
-	err := m.navigateToProfile(profileURL)
+	err = m.navigateToProfile(profileURL, viewType)
 	if err != nil {
-		return fmt.Errorf("failed to navigate to profile: %w", err)
+		return "", fmt.Errorf("failed to navigate to profile: %w", err)
+	}
+
+	message, err := compose()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate message: %w", err)
 	}
 
 	m.stealth.ThinkingDelay()
@@ -272,42 +365,74 @@ func (m *MessagingManager) SendDirectMessage(profileURL string, message string)
 	// Click Message button
 	err = m.clickMessageButton()
 	if err != nil {
-		return fmt.Errorf("failed to click message button: %w", err)
+		return "", fmt.Errorf("failed to click message button: %w", err)
 	}
 
 	// Type and send message
-	err = m.typeAndSendMessage(message)
+	status, err = m.typeAndSendMessage(message)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return "", fmt.Errorf("failed to send message: %w", err)
 	}
 
 	// Record action
 	m.rateLimiter.RecordAction("message")
 
 	// Save to database
-	m.saveMessage(profileURL, message, "direct")
+	m.recordSend(profileURL, message, "direct", status)
+
+	return status, nil
+}
 
-	m.logger.Message(profileURL, "sent", "direct")
+// recordSend saves a sent message and logs it. Messages LinkedIn queued for
+// approval are stored as message requests so they aren't counted as delivered.
+func (m *MessagingManager) recordSend(profileURL, message, messageType string, status SendStatus) {
+	if status == SendStatusPendingApproval {
+		m.logger.WithField("profile_url", profileURL).Info("Message queued as a message request pending approval")
+		m.tracer.Record("messaging", "message_request", map[string]interface{}{
+			"profile_url":  profileURL,
+			"message_type": messageType,
+		})
+		messageType = "message_request"
+	}
 
-	return nil
+	m.saveMessage(profileURL, message, messageType, string(status))
+	m.logger.Message(profileURL, string(status), messageType)
 }
 
-// navigateToProfile navigates to a profile page
-func (m *MessagingManager) navigateToProfile(profileURL string) error {
-	err := m.page.Navigate(profileURL)
+// SendTemplatedDirectMessage sends the configured direct message template,
+// personalized from the stored profile, to an existing connection
+func (m *MessagingManager) SendTemplatedDirectMessage(profileURL string) (SendStatus, error) {
+	profileURL = m.cleanProfileURL(profileURL)
+
+	profile, err := m.db.GetProfile(profileURL)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to load profile: %w", err)
+	}
+	if profile == nil {
+		return "", fmt.Errorf("profile not found in database: %s", profileURL)
 	}
 
-	m.stealth.PageLoadDelay()
-	m.stealth.ApplyFingerprintMasking(m.page)
+	return m.sendDirectMessage(profileURL, stealth.ViewIncidental, func() (string, error) {
+		return m.generateDirectMessage(profile, m.scrapeMutualConnectionName())
+	})
+}
 
-	// Wait for profile to load
-	_, err = m.page.Timeout(10 * time.Second).Element(".pv-top-card, .scaffold-layout__main")
+// navigateToProfile navigates to a profile page and charges the visit as
+// viewType. Opening a profile to message it is incidental and doesn't use up
+// the profile view cap; opening one just to look at it is explicit.
+func (m *MessagingManager) navigateToProfile(profileURL, viewType string) error {
+	err := browser.NavigateAndWaitReady(m.pager, profileURL, m.config.GetReadyTimeout(),
+		".pv-top-card", ".scaffold-layout__main")
 	if err != nil {
 		return fmt.Errorf("profile not loaded: %w", err)
 	}
 
+	m.rateLimiter.RecordProfileView(viewType)
+	m.db.IncrementProfileViews(viewType)
+
+	m.stealth.PageLoadDelay()
+	m.stealth.ApplyFingerprintMasking(m.rodPage())
+
 	return nil
 }
 
@@ -322,25 +447,12 @@ func (m *MessagingManager) clickMessageButton() error {
 		"button:has-text('Message')",
 	}
 
-	var messageButton *rod.Element
-	var err error
-
-	for _, selector := range messageSelectors {
-		messageButton, err = m.page.Timeout(3 * time.Second).Element(selector)
-		if err == nil && messageButton != nil {
-			visible, _ := messageButton.Visible()
-			if visible {
-				break
-			}
-		}
-		messageButton = nil
-	}
-
-	if messageButton == nil {
-		return fmt.Errorf("message button not found - may not be connected")
+	messageButton, err := browser.FirstElement(m.pager, 5*time.Second, messageSelectors...)
+	if err != nil {
+		return fmt.Errorf("message button not found - may not be connected: %w", err)
 	}
 
-	err = m.stealth.ClickElement(m.page, messageButton)
+	err = m.stealth.ClickElement(m.rodPage(), messageButton)
 	if err != nil {
 		return err
 	}
@@ -351,67 +463,87 @@ func (m *MessagingManager) clickMessageButton() error {
 	return nil
 }
 
-// typeAndSendMessage types a message and sends it
-func (m *MessagingManager) typeAndSendMessage(message string) error {
+// typeAndSendMessage types a message, sends it and reports whether it was
+// delivered or queued as a message request
+func (m *MessagingManager) typeAndSendMessage(message string) (SendStatus, error) {
 	// Wait for message input
-	messageInput, err := m.page.Timeout(10 * time.Second).Element(".msg-form__contenteditable, .msg-form__msg-content-container--scrollable div[contenteditable='true'], textarea.msg-form__textarea")
+	messageInput, err := browser.ElementWithin(m.pager, 10*time.Second, ".msg-form__contenteditable, .msg-form__msg-content-container--scrollable div[contenteditable='true'], textarea.msg-form__textarea")
 	if err != nil {
-		return fmt.Errorf("message input not found: %w", err)
+		return "", fmt.Errorf("message input not found: %w", err)
 	}
 
 	// Click on input to focus
-	err = m.stealth.ClickElement(m.page, messageInput)
+	err = m.stealth.ClickElement(m.rodPage(), messageInput)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	m.stealth.ActionDelay()
 
 	// Truncate message if too long
 	maxLength := m.config.Messaging.MaxMessageLength
-	if len(message) > maxLength {
-		message = message[:maxLength-3] + "..."
+	if truncated := stealth.TruncateText(message, maxLength); truncated != message {
+		message = truncated
 		m.logger.Warnf("Message truncated to %d characters", maxLength)
 	}
 
 	// Type the message with human-like behavior
-	err = m.stealth.HumanType(m.page, messageInput, message)
+	err = m.stealth.HumanType(m.rodPage(), messageInput, message)
 	if err != nil {
-		return fmt.Errorf("failed to type message: %w", err)
+		return "", fmt.Errorf("failed to type message: %w", err)
 	}
 
 	m.stealth.ThinkingDelay()
 
 	// Find and click send button
-	sendButton, err := m.page.Timeout(5 * time.Second).Element("button.msg-form__send-button:not([disabled]), button[type='submit'].msg-form__send-button")
+	sendButton, err := browser.ElementWithin(m.pager, 5*time.Second, "button.msg-form__send-button, button[type='submit'].msg-form__send-button")
 	if err != nil {
 		// Try alternative selector
-		sendButton, err = m.page.Element("button.msg-form__send-button, button[aria-label='Send']")
+		sendButton, err = m.pager.Element("button[aria-label='Send']")
 		if err != nil {
-			return fmt.Errorf("send button not found: %w", err)
+			return "", fmt.Errorf("send button not found: %w", err)
 		}
 	}
 
-	// Wait for button to be enabled
-	time.Sleep(500 * time.Millisecond)
+	// Wait for button to be enabled; a dead click would record a false success
+	if err := browser.WaitSendEnabled(sendButton, m.config.GetSendEnabledTimeout()); err != nil {
+		return "", err
+	}
 
-	err = m.stealth.ClickElement(m.page, sendButton)
+	err = m.stealth.ClickElement(m.rodPage(), sendButton)
 	if err != nil {
-		return fmt.Errorf("failed to click send: %w", err)
+		return "", fmt.Errorf("failed to click send: %w", err)
 	}
 
 	// Wait for message to be sent
 	time.Sleep(time.Second)
 
+	// Messages to non-connections may be held as a message request
+	status := m.detectSendStatus()
+
+	// Capture the sent message before the window closes
+	m.captureSendScreenshot("message")
+
 	// Close message window
 	m.closeMessageWindow()
 
-	return nil
+	return status, nil
+}
+
+// captureSendScreenshot saves an audit screenshot when Browser.ScreenshotOnAction is enabled
+func (m *MessagingManager) captureSendScreenshot(action string) {
+	path, err := browser.CaptureActionScreenshot(m.rodPage(), &m.config.Browser, action)
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to capture audit screenshot")
+	}
+	if path != "" {
+		m.logger.WithField("filename", path).Debug("Audit screenshot saved")
+	}
 }
 
 // closeMessageWindow closes the messaging window/popup
 func (m *MessagingManager) closeMessageWindow() {
-	closeButton, err := m.page.Timeout(2 * time.Second).Element("button.msg-overlay-bubble-header__control--close, button[aria-label='Close your conversation']")
+	closeButton, err := browser.ElementWithin(m.pager, 2*time.Second, "button.msg-overlay-bubble-header__control--close, button[aria-label='Close your conversation']")
 	if err == nil && closeButton != nil {
 		closeButton.MustClick()
 	}
@@ -431,8 +563,61 @@ func (m *MessagingManager) generateFollowUpMessage(connection *AcceptedConnectio
 		Company:    connection.Company,
 		Headline:   connection.Headline,
 		DaysSince:  int(time.Since(connection.AcceptedAt).Hours() / 24),
+
+		MutualConnectionName: connection.MutualConnectionName,
+	}
+
+	return renderMessage(templateStr, data)
+}
+
+// PreviewFollowUp renders the follow-up message a stored profile would get
+// if they accepted today, without sending anything
+func (m *MessagingManager) PreviewFollowUp(profile *storage.Profile) (string, error) {
+	return m.generateFollowUpMessage(&AcceptedConnection{
+		ProfileURL: profile.ProfileURL,
+		Name:       profile.Name,
+		FirstName:  profile.FirstName,
+		LastName:   profile.LastName,
+		Headline:   profile.Headline,
+		Company:    profile.Company,
+		AcceptedAt: time.Now(),
+	})
+}
+
+// generateDirectMessage fills the direct message template from a stored profile
+// and the mutual connection shown on their profile page
+func (m *MessagingManager) generateDirectMessage(profile *storage.Profile, mutualName string) (string, error) {
+	templateStr := m.config.Messaging.DirectMessageTemplate
+	if templateStr == "" {
+		templateStr = "Hi {{.FirstName}}, hope you're doing well!"
+	}
+
+	data := MessageTemplateData{
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		FullName:  profile.Name,
+		Company:   profile.Company,
+		Headline:  profile.Headline,
+		Location:  profile.Location,
+
+		MutualConnectionName: mutualName,
+	}
+
+	// Days since they accepted, when we sent the invitation
+	requests, err := m.db.GetConnectionRequests(storage.ConnectionFilter{
+		Status:     "accepted",
+		ProfileURL: profile.ProfileURL,
+		Limit:      1,
+	})
+	if err == nil && len(requests) > 0 && requests[0].AcceptedAt != nil {
+		data.DaysSince = int(time.Since(*requests[0].AcceptedAt).Hours() / 24)
 	}
 
+	return renderMessage(templateStr, data)
+}
+
+// renderMessage executes a message template, defaulting an empty first name
+func renderMessage(templateStr string, data MessageTemplateData) (string, error) {
 	// Handle empty first name
 	if data.FirstName == "" {
 		if data.FullName != "" {
@@ -457,7 +642,7 @@ func (m *MessagingManager) generateFollowUpMessage(connection *AcceptedConnectio
 }
 
 // saveMessage saves a sent message to the database
-func (m *MessagingManager) saveMessage(profileURL, content, messageType string) {
+func (m *MessagingManager) saveMessage(profileURL, content, messageType, status string) {
 	// Get profile ID
 	profile, _ := m.db.GetProfile(profileURL)
 	var profileID int64
@@ -471,6 +656,7 @@ func (m *MessagingManager) saveMessage(profileURL, content, messageType string)
 		Content:     content,
 		Template:    m.config.Messaging.FollowUpMessageTemplate,
 		MessageType: messageType,
+		Status:      status,
 	}
 
 	m.db.SaveMessage(message)
@@ -482,6 +668,12 @@ func (m *MessagingManager) SendBulkFollowUpMessages(connections []*AcceptedConne
 	failed := 0
 
 	for _, conn := range connections {
+		// Blocks while paused; resumes with this connection
+		if !m.pause.WaitWhilePaused(m.ctx) {
+			m.logger.Info("Run stopped, ending bulk messages")
+			break
+		}
+
 		// Check rate limits
 		if !m.rateLimiter.CanPerformAction("message") {
 			m.logger.Warn("Rate limit reached, stopping bulk messages")
@@ -491,13 +683,25 @@ func (m *MessagingManager) SendBulkFollowUpMessages(connections []*AcceptedConne
 		err := m.SendFollowUpMessage(conn, customMessage)
 		if err != nil {
 			m.logger.WithError(err).WithField("profile", conn.ProfileURL).Warn("Failed to send follow-up")
+			m.tracer.Record("messaging", "failed", map[string]interface{}{
+				"profile_url": conn.ProfileURL,
+				"error":       err.Error(),
+			})
+			m.rateLimiter.RecordFailure("message")
 			failed++
 		} else {
+			m.tracer.Record("messaging", "sent", map[string]interface{}{"profile_url": conn.ProfileURL})
 			sent++
 		}
 
+		// The in-flight message has finished; don't wait out delays past the deadline
+		if m.ctx.Err() != nil {
+			continue
+		}
+
 		// Natural delay between messages
 		m.stealth.ThinkingDelay()
+		m.stealth.MaybeSimulateTabBlur(m.rodPage())
 		m.rateLimiter.WaitForNextAction()
 	}
 
@@ -505,34 +709,100 @@ func (m *MessagingManager) SendBulkFollowUpMessages(connections []*AcceptedConne
 	return sent, failed, nil
 }
 
+// SendBulkDirectMessages sends the direct message template to stored
+// profiles, skipping anyone messaged within recent_message_days
+func (m *MessagingManager) SendBulkDirectMessages(profiles []*storage.Profile) (sent, failed, skipped int, err error) {
+	for _, profile := range profiles {
+		// Blocks while paused; resumes with this profile
+		if !m.pause.WaitWhilePaused(m.ctx) {
+			m.logger.Info("Run stopped, ending bulk messages")
+			break
+		}
+
+		// Check rate limits
+		if !m.rateLimiter.CanPerformAction("message") {
+			m.logger.Warn("Rate limit reached, stopping bulk messages")
+			break
+		}
+
+		if m.messagedRecently(profile.ProfileURL) {
+			m.logger.WithField("profile", profile.ProfileURL).Debug("Messaged recently, skipping")
+			skipped++
+			continue
+		}
+
+		if _, err := m.SendTemplatedDirectMessage(profile.ProfileURL); err != nil {
+			m.logger.WithError(err).WithField("profile", profile.ProfileURL).Warn("Failed to send direct message")
+			m.tracer.Record("messaging", "failed", map[string]interface{}{
+				"profile_url": profile.ProfileURL,
+				"error":       err.Error(),
+			})
+			m.rateLimiter.RecordFailure("message")
+			failed++
+		} else {
+			m.tracer.Record("messaging", "sent", map[string]interface{}{"profile_url": profile.ProfileURL})
+			sent++
+		}
+
+		// The in-flight message has finished; don't wait out delays past the deadline
+		if m.ctx.Err() != nil {
+			continue
+		}
+
+		// Natural delay between messages
+		m.stealth.ThinkingDelay()
+		m.stealth.MaybeSimulateTabBlur(m.rodPage())
+		m.rateLimiter.WaitForNextAction()
+	}
+
+	m.logger.Infof("Bulk direct messages: %d sent, %d failed, %d skipped as recently messaged", sent, failed, skipped)
+	return sent, failed, skipped, nil
+}
+
+// messagedRecently reports whether the profile was messaged within
+// recent_message_days; lookup errors count as recent to avoid double sends
+func (m *MessagingManager) messagedRecently(profileURL string) bool {
+	days := m.config.Messaging.RecentMessageDays
+	if days <= 0 {
+		return false
+	}
+	recent, err := m.db.HasMessagedSince(m.cleanProfileURL(profileURL), time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		m.logger.WithError(err).Warn("Failed to check message history")
+		return true
+	}
+	return recent
+}
+
 // GetRemainingMessages returns how many more messages can be sent today
 func (m *MessagingManager) GetRemainingMessages() int {
 	return m.rateLimiter.GetRemainingActions("message")
 }
 
-// ProcessNewConnectionsWorkflow checks for new connections and sends follow-ups
-func (m *MessagingManager) ProcessNewConnectionsWorkflow() error {
+// ProcessNewConnectionsWorkflow checks for new connections and sends
+// follow-ups, returning how many were sent and failed
+func (m *MessagingManager) ProcessNewConnectionsWorkflow() (sent, failed int, err error) {
 	m.logger.Info("Processing new connections workflow")
 
 	// Check for newly accepted connections
 	newConnections, err := m.CheckNewlyAcceptedConnections()
 	if err != nil {
-		return fmt.Errorf("failed to check new connections: %w", err)
+		return 0, 0, fmt.Errorf("failed to check new connections: %w", err)
 	}
 
 	if len(newConnections) == 0 {
 		m.logger.Info("No new connections to process")
-		return nil
+		return 0, 0, nil
 	}
 
 	m.logger.Infof("Found %d new connections to follow up with", len(newConnections))
 
 	// Send follow-up messages
-	sent, failed, err := m.SendBulkFollowUpMessages(newConnections, "")
+	sent, failed, err = m.SendBulkFollowUpMessages(newConnections, "")
 	if err != nil {
-		return err
+		return sent, failed, err
 	}
 
 	m.logger.Infof("Workflow complete: %d messages sent, %d failed", sent, failed)
-	return nil
+	return sent, failed, nil
 }
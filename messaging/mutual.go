@@ -0,0 +1,58 @@
+// Package messaging - mutual.go handles finding a shared connection to mention in messages
+package messaging
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+// mutualInsightSelectors match the "X and N others you both know" insight on a profile
+var mutualInsightSelectors = []string{
+	".pv-top-card a[href*='facetConnectionOf']",
+	"a[href*='facetConnectionOf']",
+	".member-insights__reason",
+}
+
+// mutualNameEnd marks where the first mutual connection's name ends, as in
+// "Jane Doe, John Roe and 3 others" or "Jane Doe is a mutual connection"
+var mutualNameEnd = regexp.MustCompile(`(?i),|\s+and\s+|\s+is\s+a\s+mutual`)
+
+// scrapeMutualConnectionName reads the first shared connection's name from the
+// profile that is currently open, or "" if none is shown
+func (m *MessagingManager) scrapeMutualConnectionName() string {
+	el, err := browser.FirstElement(m.pager, 2*time.Second, mutualInsightSelectors...)
+	if err != nil {
+		return ""
+	}
+
+	text, err := el.Text()
+	if err != nil {
+		return ""
+	}
+
+	name := parseMutualConnectionName(text)
+	if name != "" {
+		m.logger.WithField("mutual_connection", name).Debug("Found mutual connection")
+	}
+	return name
+}
+
+// parseMutualConnectionName extracts the first name listed in a mutual
+// connections insight. Counts without a name ("12 mutual connections") give "".
+func parseMutualConnectionName(text string) string {
+	text = strings.TrimSpace(strings.SplitN(text, "\n", 2)[0])
+	lower := strings.ToLower(text)
+	if !strings.Contains(lower, "mutual") && !strings.Contains(lower, "you both know") {
+		return ""
+	}
+
+	name := strings.TrimSpace(mutualNameEnd.Split(text, 2)[0])
+	if name == "" || unicode.IsDigit([]rune(name)[0]) || strings.Contains(strings.ToLower(name), "mutual") {
+		return ""
+	}
+	return name
+}
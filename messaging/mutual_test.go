@@ -0,0 +1,39 @@
+// Package messaging - Tests for mutual connection names in messages
+package messaging
+
+import "testing"
+
+func TestParseMutualConnectionName(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Jane Doe is a mutual connection", "Jane Doe"},
+		{"Jane Doe and John Roe are mutual connections", "Jane Doe"},
+		{"Jane Doe, John Roe, and 12 other mutual connections", "Jane Doe"},
+		{"Jane Doe and 3 others you both know", "Jane Doe"},
+		{"12 mutual connections", ""},
+		{"Followed by 300 people", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseMutualConnectionName(tt.text); got != tt.want {
+			t.Errorf("parseMutualConnectionName(%q) = %q; want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRenderMutualConnectionName(t *testing.T) {
+	tmpl := "Hi {{.FirstName}}!{{if .MutualConnectionName}} We both know {{.MutualConnectionName}}.{{end}}"
+
+	got, err := renderMessage(tmpl, MessageTemplateData{FirstName: "Jane", MutualConnectionName: "John Roe"})
+	if err != nil || got != "Hi Jane! We both know John Roe." {
+		t.Errorf("Unexpected message with a mutual connection: %q, %v", got, err)
+	}
+
+	got, err = renderMessage(tmpl, MessageTemplateData{FirstName: "Jane"})
+	if err != nil || got != "Hi Jane!" {
+		t.Errorf("Unexpected message without a mutual connection: %q, %v", got, err)
+	}
+}
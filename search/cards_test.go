@@ -0,0 +1,53 @@
+// Package search - Tests for result card hydration retries
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+)
+
+// emptyPager is a page whose result list never hydrates
+type emptyPager struct {
+	elementsCalls int
+}
+
+func (p *emptyPager) Navigate(url string) error { return nil }
+func (p *emptyPager) Element(selector string) (*rod.Element, error) {
+	return nil, &rod.ElementNotFoundError{}
+}
+func (p *emptyPager) Elements(selector string) (rod.Elements, error) {
+	p.elementsCalls++
+	return nil, nil
+}
+func (p *emptyPager) WaitLoad() error { return nil }
+func (p *emptyPager) Eval(js string, args ...interface{}) (*proto.RuntimeRemoteObject, error) {
+	return nil, nil
+}
+func (p *emptyPager) Info() (*proto.TargetTargetInfo, error) { return &proto.TargetTargetInfo{}, nil }
+func (p *emptyPager) KeyActions() *rod.KeyActions            { return nil }
+
+func TestParseSearchResultsRetriesEmptyPage(t *testing.T) {
+	defer func(d time.Duration) { resultCardPollInterval = d }(resultCardPollInterval)
+	resultCardPollInterval = time.Millisecond
+
+	log, _ := logger.New(logger.Config{Level: "error"})
+	s := NewSearcher(config.DefaultConfig(), log, nil, nil, nil)
+	page := &emptyPager{}
+	s.SetPage(page)
+
+	results, err := s.parseSearchResults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+	if page.elementsCalls != resultCardAttempts {
+		t.Errorf("Expected %d card lookups before giving up, got %d", resultCardAttempts, page.elementsCalls)
+	}
+}
@@ -0,0 +1,110 @@
+// Package search - count.go handles reading a query's total result count
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+)
+
+// resultCountSelectors locate the "About N results" header above search results
+var resultCountSelectors = []string{
+	".search-results-container h2",
+	"div.search-results__cluster-title-suffix",
+	".search-results__total",
+	"h2.pb2.t-black--light.t-14",
+}
+
+var (
+	// resultCountPattern matches "About 1,234 results", "12 results" or "1 result"
+	resultCountPattern = regexp.MustCompile(`(?i)([\d][\d,.\s]*)\s*([km])?\+?\s*results?\b`)
+
+	// noResultsPattern matches LinkedIn's empty search page
+	noResultsPattern = regexp.MustCompile(`(?i)no results found`)
+)
+
+// CountResults opens the search for params and returns the total shown in
+// the results header, without collecting any result cards. The page load
+// counts as a search against the hourly limit.
+func (s *Searcher) CountResults(params SearchParams) (count int, err error) {
+	if err := s.blockedError(); err != nil {
+		return 0, err
+	}
+	if !s.rateLimiter.CanPerformAction("search") {
+		return 0, fmt.Errorf("search %w", stealth.ErrRateLimited)
+	}
+
+	defer browser.ScreenshotOnError(s.pager, &s.config.Browser, s.logger, "search", "", &err)
+
+	searchURL := s.buildSearchURL(params)
+	s.logger.WithField("url", searchURL).Info("Counting search results")
+
+	if err := s.openSearchPage(searchURL); err != nil {
+		return 0, err
+	}
+	s.rateLimiter.RecordAction("search")
+
+	header, err := browser.FirstElement(s.pager, 5*time.Second, resultCountSelectors...)
+	if err == nil {
+		text, _ := header.Text()
+		if count, ok := parseResultCount(text); ok {
+			return count, nil
+		}
+	}
+
+	// The header moves around; fall back to the page's visible text
+	body, err := s.pager.Element("main")
+	if err != nil {
+		return 0, fmt.Errorf("result count not found: %w", err)
+	}
+	text, _ := body.Text()
+	if count, ok := parseResultCount(text); ok {
+		return count, nil
+	}
+	return 0, fmt.Errorf("result count not found on search page")
+}
+
+// parseResultCount extracts the total from header text such as
+// "About 12,400 results" or "No results found"
+func parseResultCount(text string) (int, bool) {
+	if noResultsPattern.MatchString(text) {
+		return 0, true
+	}
+
+	m := resultCountPattern.FindStringSubmatch(text)
+	if m == nil {
+		return 0, false
+	}
+
+	multiplier := 1
+	switch strings.ToLower(m[2]) {
+	case "k":
+		multiplier = 1000
+	case "m":
+		multiplier = 1000000
+	}
+
+	digits := m[1]
+	if multiplier > 1 {
+		// "1.2K": keep the decimal point
+		digits = strings.NewReplacer(",", ".", " ", "").Replace(digits)
+		value, err := strconv.ParseFloat(digits, 64)
+		if err != nil {
+			return 0, false
+		}
+		return int(value * float64(multiplier)), true
+	}
+
+	// Thousands separators vary by locale: 1,234 / 1.234 / 1 234
+	digits = strings.NewReplacer(",", "", ".", "", " ", "").Replace(digits)
+	value, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,94 @@
+// Package search - cursor.go handles resuming a search from the page an earlier run stopped at
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Signature identifies a search by its normalized parameters: case,
+// whitespace and the order of keywords and network degrees don't change it,
+// and neither do MaxResults or Resume
+func (p SearchParams) Signature() string {
+	normalize := func(v string) string {
+		return strings.ToLower(strings.Join(strings.Fields(v), " "))
+	}
+	sortedNormalized := func(values []string) string {
+		var out []string
+		for _, v := range values {
+			if v = normalize(v); v != "" {
+				out = append(out, v)
+			}
+		}
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+
+	parts := []string{
+		"job_title=" + normalize(p.JobTitle),
+		"company=" + normalize(p.Company),
+		"location=" + normalize(p.Location),
+		"keywords=" + sortedNormalized(p.Keywords),
+		"network=" + sortedNormalized(p.Network),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// withPage returns searchURL pointed at result page page
+func withPage(searchURL string, page int) string {
+	if page <= 1 {
+		return searchURL
+	}
+	parsed, err := url.Parse(searchURL)
+	if err != nil {
+		return searchURL
+	}
+	query := parsed.Query()
+	query.Set("page", strconv.Itoa(page))
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// resumePage returns the page a search starts from: the one after its stored
+// cursor when params.Resume is set, otherwise page 1
+func (s *Searcher) resumePage(params SearchParams) int {
+	if !params.Resume {
+		return 1
+	}
+	last, err := s.db.GetSearchCursor(params.Signature())
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to load search cursor, starting from page 1")
+		return 1
+	}
+	if last > 0 {
+		s.logger.WithField("page", last+1).Info("Resuming search after the last collected page")
+	}
+	return last + 1
+}
+
+// saveCursor records page as the last fully collected page of a resumable search
+func (s *Searcher) saveCursor(params SearchParams, page int) {
+	if !params.Resume {
+		return
+	}
+	if err := s.db.SaveSearchCursor(params.Signature(), page); err != nil {
+		s.logger.WithError(err).Warn("Failed to save search cursor")
+	}
+}
+
+// clearCursor resets a resumable search that ran out of results, so the next
+// run starts over from page 1
+func (s *Searcher) clearCursor(params SearchParams) {
+	if !params.Resume {
+		return
+	}
+	s.logger.Info("Reached the end of the search results, the next run starts from page 1")
+	if err := s.db.ClearSearchCursor(params.Signature()); err != nil {
+		s.logger.WithError(err).Warn("Failed to clear search cursor")
+	}
+}
@@ -0,0 +1,42 @@
+// Package search - Tests for the dedup cache
+package search
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+	"github.com/nikshitha/linkedin-automation-poc/storage"
+)
+
+func TestWarmCache(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	db, err := storage.NewDatabase(filepath.Join(t.TempDir(), "test.db"), log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	known := "https://www.linkedin.com/in/known/"
+	if _, err := db.SaveProfile(&storage.Profile{ProfileURL: known, Name: "Known"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSearcher(config.DefaultConfig(), log, nil, nil, db)
+	if err := s.WarmCache(); err != nil {
+		t.Fatal(err)
+	}
+	if !s.cacheComplete || !s.seenProfiles[known] {
+		t.Fatalf("Expected a complete cache holding %s", known)
+	}
+
+	// With a complete cache, lookups are answered from memory
+	db.Close()
+	if !s.isDuplicate(known) {
+		t.Error("Known profile should be a duplicate")
+	}
+	if s.isDuplicate("https://www.linkedin.com/in/new/") {
+		t.Error("Unknown profile should not be a duplicate")
+	}
+}
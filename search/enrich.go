@@ -0,0 +1,288 @@
+// Package search - enrich.go handles filling in full profile details for search results from parallel tabs
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+)
+
+// enrichFieldTimeout bounds each field lookup once the top card has loaded;
+// a missing field shouldn't hold the tab for the full ready timeout
+const enrichFieldTimeout = 2 * time.Second
+
+// Selectors for the details read from a profile's top card
+const (
+	profileNameSelector     = ".pv-top-card h1, h1.text-heading-xlarge"
+	profileHeadlineSelector = ".pv-top-card .text-body-medium.break-words, .text-body-medium.break-words"
+	profileLocationSelector = ".pv-top-card .text-body-small.inline.break-words, .pv-text-details__left-panel .text-body-small.inline"
+	profileCompanySelector  = "button[aria-label^='Current company'], .pv-text-details__right-panel-item-text"
+	profileDegreeSelector   = ".pv-top-card .dist-value, .pv-top-card .distance-badge"
+)
+
+var (
+	errNoEnrichTab    = errors.New("no tab available to open the profile")
+	errProfileViewCap = fmt.Errorf("profile view %w", stealth.ErrRateLimited)
+	errEnrichStopped  = errors.New("run stopped before the profile was opened")
+)
+
+// profileDetails are the fields read from an open profile
+type profileDetails struct {
+	name     string
+	headline string
+	company  string
+	location string
+	degree   string
+}
+
+// SetTabOpener sets how EnrichProfiles opens its worker tabs
+func (s *Searcher) SetTabOpener(open func() (*rod.Page, error)) {
+	s.newTab = open
+}
+
+// SetPauseController sets the controller checked before each enrichment visit
+func (s *Searcher) SetPauseController(p *stealth.PauseController) {
+	s.pause = p
+}
+
+// SetContext sets the run context; enrichment stops between visits once it is done
+func (s *Searcher) SetContext(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// enrichTab is a worker tab with its own random stream for the delays it
+// waits out; the stealth manager's isn't safe to share between goroutines
+type enrichTab struct {
+	id   int
+	page *rod.Page
+	rng  *rand.Rand
+	used bool
+}
+
+// EnrichProfiles opens results in up to workers tabs at once and fills in
+// what a search card leaves out: the full headline, current company,
+// location and degree. Results are updated in place; saving them is up to
+// the caller.
+//
+// Every visit is an explicit profile view. A single coordinator waits out a
+// pause, checks the shared view budget and charges the view before handing
+// the profile to a free tab, so the pause's keep-alive only ever runs on the
+// main page from one goroutine and the tabs together never go over the
+// budget or visit closer together than one tab would.
+// Failures are returned per profile URL and never stop the rest of the batch.
+func (s *Searcher) EnrichProfiles(results []*SearchResult, workers int) (enriched int, errs map[string]error) {
+	errs = make(map[string]error)
+	if len(results) == 0 {
+		return 0, errs
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(results) {
+		workers = len(results)
+	}
+
+	s.logger.WithFields(map[string]interface{}{
+		"profiles": len(results),
+		"workers":  workers,
+	}).Info("Enriching search results")
+
+	tabs := s.openEnrichTabs(workers)
+	defer func() {
+		for _, tab := range tabs {
+			tab.page.Close()
+		}
+	}()
+
+	var mu sync.Mutex // guards enriched and errs
+	record := func(result *SearchResult, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs[result.ProfileURL] = err
+			return
+		}
+		enriched++
+	}
+
+	free := make(chan *enrichTab, len(tabs))
+	for _, tab := range tabs {
+		free <- tab
+	}
+
+	var wg sync.WaitGroup
+	for _, result := range results {
+		if len(tabs) == 0 {
+			record(result, errNoEnrichTab)
+			continue
+		}
+		tab := <-free
+
+		// Blocks while paused; a stopped run leaves the rest unvisited
+		if !s.pause.WaitWhilePaused(s.ctx) {
+			free <- tab
+			record(result, errEnrichStopped)
+			continue
+		}
+		if !s.reserveProfileView() {
+			free <- tab
+			record(result, errProfileViewCap)
+			continue
+		}
+
+		wg.Add(1)
+		go func(tab *enrichTab, result *SearchResult) {
+			defer wg.Done()
+			defer func() { free <- tab }()
+			record(result, s.enrichVisit(tab, result))
+		}(tab, result)
+	}
+	wg.Wait()
+
+	for profileURL, err := range errs {
+		s.logger.WithError(err).WithField("profile_url", profileURL).Warn("Failed to enrich profile")
+	}
+	s.tracer.Record("search", "enriched", map[string]interface{}{
+		"profiles": len(results),
+		"enriched": enriched,
+		"failed":   len(errs),
+	})
+	s.logger.Infof("Enriched %d of %d profiles", enriched, len(results))
+
+	return enriched, errs
+}
+
+// openEnrichTabs opens up to n worker tabs one at a time; tabs that fail to
+// open are logged and left out
+func (s *Searcher) openEnrichTabs(n int) []*enrichTab {
+	if s.newTab == nil {
+		return nil
+	}
+	var tabs []*enrichTab
+	for id := 0; id < n; id++ {
+		page, err := s.newTab()
+		if err != nil {
+			s.logger.WithError(err).Warnf("Enrichment worker %d could not open a tab", id)
+			continue
+		}
+		tabs = append(tabs, &enrichTab{
+			id:   id,
+			page: page,
+			rng:  stealth.NewRand(s.config.Stealth.RandomSeed, stealth.StreamEnrich+int64(id)*100),
+		})
+	}
+	return tabs
+}
+
+// enrichVisit enriches result in tab and waits out the reading and spacing
+// delays before the tab takes its next profile
+func (s *Searcher) enrichVisit(tab *enrichTab, result *SearchResult) error {
+	delayMin, delayMax := s.config.Stealth.ActionDelayMin, s.config.Stealth.ActionDelayMax
+
+	// Stagger the first visits so the tabs don't load in lockstep
+	if !tab.used {
+		tab.used = true
+		for i := 0; i < tab.id; i++ {
+			s.enrichDelay(tab.rng, "staggering enrichment tabs", delayMin, delayMax)
+		}
+	}
+
+	err := s.enrichProfile(tab.page, result)
+
+	s.enrichDelay(tab.rng, "reading profile", s.config.Stealth.PageLoadWaitMin, s.config.Stealth.PageLoadWaitMax)
+	s.enrichDelay(tab.rng, "between enrichment visits", delayMin, delayMax)
+	return err
+}
+
+// reserveProfileView takes a visit from the profile view budget. A visit
+// whose page then fails to load stays charged.
+func (s *Searcher) reserveProfileView() bool {
+	if !s.rateLimiter.ReserveProfileView() {
+		return false
+	}
+	s.db.IncrementProfileViews(stealth.ViewExplicit)
+	return true
+}
+
+// enrichProfile opens result's profile in page and merges what it shows
+func (s *Searcher) enrichProfile(page *rod.Page, result *SearchResult) error {
+	profileURL := s.cleanProfileURL(result.ProfileURL)
+
+	err := browser.NavigateAndWaitReady(page, profileURL, s.config.GetReadyTimeout(),
+		".pv-top-card", ".scaffold-layout__main")
+	if err != nil {
+		return fmt.Errorf("profile content not loaded: %w", err)
+	}
+
+	details := readProfileDetails(page)
+	if details.name == "" {
+		return fmt.Errorf("profile details not found")
+	}
+	s.mergeDetails(result, details)
+	return nil
+}
+
+// enrichDelay sleeps between min and max milliseconds using a worker's rng
+func (s *Searcher) enrichDelay(rng *rand.Rand, reason string, minMs, maxMs int) {
+	delay := time.Duration(minMs+rng.Intn(maxMs-minMs+1)) * time.Millisecond
+	s.logger.Delay(reason, delay)
+	time.Sleep(delay)
+}
+
+// readProfileDetails reads the top card of an open profile; fields that
+// aren't shown are left empty
+func readProfileDetails(page *rod.Page) profileDetails {
+	text := func(selector string) string {
+		el, err := page.Timeout(enrichFieldTimeout).Element(selector)
+		if err != nil || el == nil {
+			return ""
+		}
+		value, err := el.Text()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(value)
+	}
+
+	return profileDetails{
+		name:     text(profileNameSelector),
+		headline: text(profileHeadlineSelector),
+		company:  text(profileCompanySelector),
+		location: text(profileLocationSelector),
+		degree:   text(profileDegreeSelector),
+	}
+}
+
+// mergeDetails copies the fields the profile shows over those from the
+// search card. Empty fields keep the card's value, and the company is only
+// guessed from the headline when neither source names one.
+func (s *Searcher) mergeDetails(result *SearchResult, d profileDetails) {
+	if d.name != "" {
+		result.Name = d.name
+		result.FirstName, result.LastName = s.splitName(d.name)
+	}
+	if d.headline != "" {
+		result.Headline = d.headline
+	}
+	if d.location != "" {
+		result.Location = d.location
+	}
+	if degree := NormalizeDegree(d.degree); degree != "" {
+		result.Connection = degree
+	}
+
+	switch {
+	case d.company != "":
+		result.Company = d.company
+	case result.Company == "":
+		result.Company = s.extractCompanyFromHeadline(result.Headline)
+	}
+}
@@ -0,0 +1,64 @@
+// Package search - Tests for profile enrichment
+package search
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-rod/rod"
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+)
+
+func TestMergeDetails(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	s := NewSearcher(config.DefaultConfig(), log, nil, nil, nil)
+
+	result := &SearchResult{Name: "Ann L.", Headline: "Engineer", Location: "Berlin", Connection: "2nd"}
+	s.mergeDetails(result, profileDetails{
+		name:     "Ann Lee",
+		headline: "Staff Engineer at Acme",
+		degree:   "· 3rd+",
+	})
+	if result.Name != "Ann Lee" || result.FirstName != "Ann" || result.LastName != "Lee" {
+		t.Errorf("Expected the profile's full name, got %q (%q %q)", result.Name, result.FirstName, result.LastName)
+	}
+	if result.Headline != "Staff Engineer at Acme" || result.Connection != "3rd+" {
+		t.Errorf("Expected the profile's headline and degree, got %q, %q", result.Headline, result.Connection)
+	}
+	if result.Location != "Berlin" {
+		t.Errorf("A location the profile doesn't show should be kept, got %q", result.Location)
+	}
+	if result.Company != "Acme" {
+		t.Errorf("Expected the company guessed from the headline, got %q", result.Company)
+	}
+
+	s.mergeDetails(result, profileDetails{company: "Acme Corporation"})
+	if result.Company != "Acme Corporation" || result.Name != "Ann Lee" {
+		t.Errorf("Expected only the company to change, got %+v", result)
+	}
+}
+
+func TestEnrichProfilesAggregatesErrors(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	s := NewSearcher(config.DefaultConfig(), log, nil, nil, nil)
+	s.SetTabOpener(func() (*rod.Page, error) { return nil, errors.New("browser closed") })
+
+	results := []*SearchResult{
+		{ProfileURL: "https://www.linkedin.com/in/a"},
+		{ProfileURL: "https://www.linkedin.com/in/b"},
+		{ProfileURL: "https://www.linkedin.com/in/c"},
+	}
+	enriched, errs := s.EnrichProfiles(results, 2)
+	if enriched != 0 {
+		t.Errorf("Expected nothing enriched, got %d", enriched)
+	}
+	if len(errs) != len(results) {
+		t.Fatalf("Expected an error for each of %d profiles, got %v", len(results), errs)
+	}
+	for _, result := range results {
+		if !errors.Is(errs[result.ProfileURL], errNoEnrichTab) {
+			t.Errorf("Expected %s to fail for lack of a tab, got %v", result.ProfileURL, errs[result.ProfileURL])
+		}
+	}
+}
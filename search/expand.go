@@ -0,0 +1,147 @@
+// Package search - expand.go handles growing the target pool from a profile's sidebar
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+)
+
+// sidebarHeadingPattern matches the headings of profile sidebar sections
+// that list similar people, as a JS regex literal for ElementR
+const sidebarHeadingPattern = `/people also viewed|more profiles for you|people you may know/i`
+
+// ExpandFromProfile visits a seed profile and collects the people listed in
+// its "People also viewed" / "More profiles for you" sidebar. New profiles
+// go through the same dedup as search results and are saved.
+func (s *Searcher) ExpandFromProfile(profileURL string) ([]*SearchResult, error) {
+	seedURL := s.cleanProfileURL(profileURL)
+	s.logger.WithField("profile_url", seedURL).Info("Expanding targets from profile sidebar")
+
+	// Check rate limits
+	if !s.rateLimiter.CanPerformAction("profile_view") {
+		return nil, fmt.Errorf("profile view %w", stealth.ErrRateLimited)
+	}
+
+	err := browser.NavigateAndWaitReady(s.pager, seedURL, s.config.GetReadyTimeout(),
+		".pv-top-card", ".scaffold-layout__main")
+	if err != nil {
+		return nil, fmt.Errorf("profile content not loaded: %w", err)
+	}
+
+	s.rateLimiter.RecordProfileView(stealth.ViewExplicit)
+	s.db.IncrementProfileViews(stealth.ViewExplicit)
+
+	s.stealth.PageLoadDelay()
+	s.layout.Check(s.pager, "profile")
+	s.stealth.ApplyFingerprintMasking(s.rodPage())
+
+	// Read down the profile so the lazily rendered sidebar loads
+	s.stealth.HumanScroll(s.rodPage(), "down", 600)
+	s.stealth.ThinkingDelay()
+
+	section, err := s.findSidebarSection()
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := section.Elements("li")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sidebar entries: %w", err)
+	}
+
+	var results []*SearchResult
+	for i, item := range items {
+		result, err := s.parseSidebarItem(item)
+		if err != nil {
+			s.logger.WithError(err).Debugf("Failed to parse sidebar entry %d", i)
+			continue
+		}
+		if result.ProfileURL == seedURL {
+			continue
+		}
+
+		if s.isDuplicate(result.ProfileURL) {
+			s.tracer.Record("search", "dedup_skip", map[string]interface{}{"profile_url": result.ProfileURL})
+			continue
+		}
+		s.markAsSeen(result.ProfileURL)
+
+		if _, err := s.SaveProfile(result); err != nil {
+			s.logger.WithError(err).Warn("Failed to save expanded profile")
+		}
+		results = append(results, result)
+	}
+
+	s.tracer.Record("search", "expanded", map[string]interface{}{
+		"profile_url": seedURL,
+		"found":       len(items),
+		"new":         len(results),
+	})
+	s.logger.Infof("Expansion found %d new profiles", len(results))
+
+	return results, nil
+}
+
+// findSidebarSection locates the similar-people section, preferring the aside
+func (s *Searcher) findSidebarSection() (*rod.Element, error) {
+	timeout := s.config.GetReadyTimeout()
+	for _, selector := range []string{"aside section", "section"} {
+		section, err := browser.ElementRWithin(s.pager, timeout, selector, sidebarHeadingPattern)
+		if err == nil {
+			return section, nil
+		}
+		// The page is loaded by now; the fallback only needs a quick look
+		timeout = 2 * time.Second
+	}
+	return nil, fmt.Errorf("similar profiles sidebar not found")
+}
+
+// parseSidebarItem extracts profile information from a sidebar entry
+func (s *Searcher) parseSidebarItem(item *rod.Element) (*SearchResult, error) {
+	result := &SearchResult{}
+
+	linkEl, err := item.Element("a[href*='/in/']")
+	if err != nil {
+		return nil, fmt.Errorf("profile link not found")
+	}
+
+	href, err := linkEl.Attribute("href")
+	if err != nil || href == nil {
+		return nil, fmt.Errorf("failed to get profile URL")
+	}
+	result.ProfileURL = s.cleanProfileURL(*href)
+
+	// Get name
+	nameEl, err := item.Element(".artdeco-entity-lockup__title span[aria-hidden='true'], .t-bold span[aria-hidden='true']")
+	if err == nil && nameEl != nil {
+		name, _ := nameEl.Text()
+		result.Name = strings.TrimSpace(name)
+		result.FirstName, result.LastName = s.splitName(result.Name)
+	}
+
+	// Get headline
+	headlineEl, err := item.Element(".artdeco-entity-lockup__subtitle span[aria-hidden='true'], .t-14.t-normal span[aria-hidden='true']")
+	if err == nil && headlineEl != nil {
+		headline, _ := headlineEl.Text()
+		result.Headline = strings.TrimSpace(headline)
+		result.Company = s.extractCompanyFromHeadline(result.Headline)
+	}
+
+	// Get connection degree
+	connectionEl, err := item.Element(".distance-badge, .dist-value")
+	if err == nil && connectionEl != nil {
+		connection, _ := connectionEl.Text()
+		result.Connection = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(connection), "·"))
+	}
+
+	if result.Name == "" {
+		return nil, fmt.Errorf("profile name not found")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,23 @@
+// Package search - Tests for expanding targets from a profile sidebar
+package search
+
+import (
+	"testing"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+func TestSidebarHeadingPattern(t *testing.T) {
+	re, err := browser.CompileJSRegex(sidebarHeadingPattern)
+	if err != nil {
+		t.Fatalf("Sidebar heading pattern won't run in the browser: %v", err)
+	}
+	for _, heading := range []string{"People also viewed", "More profiles for you"} {
+		if !re.MatchString(heading) {
+			t.Errorf("Expected %q to mark the similar-people section", heading)
+		}
+	}
+	if re.MatchString("Activity") {
+		t.Error("Other sidebar sections should not match")
+	}
+}
@@ -0,0 +1,76 @@
+// Package search - limits.go handles LinkedIn's monthly commercial search limit wall
+package search
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+)
+
+// ErrSearchLimitReached is returned when LinkedIn shows its monthly search
+// limit wall instead of results
+var ErrSearchLimitReached = errors.New("LinkedIn monthly search limit reached")
+
+// searchLimitPattern matches the commercial use limit wall text, as a JS
+// regex literal for ElementR
+const searchLimitPattern = `/reached the (monthly )?(commercial use )?limit for (profile )?search|commercial use limit/i`
+
+var (
+	// searchResumePattern captures the text following "...searches will reset on"
+	searchResumePattern = regexp.MustCompile(`(?i)(?:reset|resets|available again|search again) (?:on|after) ([^.!\n]+)`)
+)
+
+// checkSearchLimit looks for the monthly search limit wall. When it is shown,
+// searches are blocked until the date it names, or the start of next month
+// (persisted so later runs respect it), and ErrSearchLimitReached is returned.
+func (s *Searcher) checkSearchLimit() error {
+	wall, err := browser.ElementRWithin(s.pager, time.Second, "main, div[role='dialog'], .search-paywall__info", searchLimitPattern)
+	if err != nil {
+		return nil
+	}
+
+	text, _ := wall.Text()
+	until, ok := parseSearchResumeDate(text, time.Now())
+	if !ok {
+		until = nextMonth(time.Now())
+		s.logger.WithField("until", until.Format("2006-01-02")).Warn("Search limit reached but no reset date found, blocking until next month")
+	}
+
+	s.rateLimiter.BlockUntil("search", until)
+	if err := s.db.SaveBlockedUntil("search", until); err != nil {
+		s.logger.WithError(err).Warn("Failed to persist search limit")
+	}
+	s.tracer.Record("search", "limit_wall", map[string]interface{}{"blocked_until": until.Format(time.RFC3339)})
+
+	return fmt.Errorf("%w until %s", ErrSearchLimitReached, until.Format("2006-01-02"))
+}
+
+// blockedError returns ErrSearchLimitReached while an earlier limit wall still
+// applies, so callers stop searching instead of retrying
+func (s *Searcher) blockedError() error {
+	until := s.rateLimiter.BlockedUntil("search")
+	if until.IsZero() {
+		return nil
+	}
+	return fmt.Errorf("%w until %s", ErrSearchLimitReached, until.Format("2006-01-02"))
+}
+
+// parseSearchResumeDate extracts the date from text such as "Your free
+// searches will reset on Nov 1"
+func parseSearchResumeDate(text string, now time.Time) (time.Time, bool) {
+	match := searchResumePattern.FindStringSubmatch(text)
+	if match == nil {
+		return time.Time{}, false
+	}
+	return stealth.ParseLimitDate(match[1], now)
+}
+
+// nextMonth returns local midnight on the first day of the month after now,
+// when LinkedIn's monthly search allowance resets
+func nextMonth(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+}
@@ -0,0 +1,38 @@
+// Package search - Tests for the monthly search limit wall
+package search
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+)
+
+func TestSearchLimitWall(t *testing.T) {
+	now := time.Date(2025, time.October, 15, 10, 0, 0, 0, time.Local)
+
+	searchLimitPattern, err := browser.CompileJSRegex(searchLimitPattern)
+	if err != nil {
+		t.Fatalf("Search limit pattern won't run in the browser: %v", err)
+	}
+
+	wall := "You've reached the monthly limit for profile searches. Your free searches will reset on Nov 1."
+	if !searchLimitPattern.MatchString(wall) {
+		t.Error("Expected the limit wall text to match")
+	}
+	if searchLimitPattern.MatchString("Showing 1,200 results for Software Engineer") {
+		t.Error("Did not expect regular results to match")
+	}
+
+	got, ok := parseSearchResumeDate(wall, now)
+	if want := time.Date(2025, time.November, 1, 0, 0, 0, 0, time.Local); !ok || !got.Equal(want) {
+		t.Errorf("parseSearchResumeDate = %v, %v; want %v", got, ok, want)
+	}
+	if _, ok := parseSearchResumeDate("You've reached the monthly limit for profile searches.", now); ok {
+		t.Error("Expected no date without a reset notice")
+	}
+
+	if got, want := nextMonth(time.Date(2025, time.December, 20, 9, 0, 0, 0, time.Local)), time.Date(2026, time.January, 1, 0, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Errorf("nextMonth = %v; want %v", got, want)
+	}
+}
@@ -0,0 +1,35 @@
+// Package search - quality.go handles leaving incomplete profiles out of targeting
+package search
+
+import "strings"
+
+// FilterIncomplete drops results missing a headline or company when the
+// matching flag is set, returning the kept results and how many were dropped
+func FilterIncomplete(results []*SearchResult, skipMissingHeadline, skipMissingCompany bool) ([]*SearchResult, int) {
+	if !skipMissingHeadline && !skipMissingCompany {
+		return results, 0
+	}
+
+	var kept []*SearchResult
+	for _, result := range results {
+		if skipMissingHeadline && strings.TrimSpace(result.Headline) == "" {
+			continue
+		}
+		if skipMissingCompany && strings.TrimSpace(result.Company) == "" {
+			continue
+		}
+		kept = append(kept, result)
+	}
+	return kept, len(results) - len(kept)
+}
+
+// filterIncomplete applies search.skip_missing_headline and
+// search.skip_missing_company, logging how many results were dropped
+func (s *Searcher) filterIncomplete(results []*SearchResult) []*SearchResult {
+	kept, skipped := FilterIncomplete(results, s.config.Search.SkipMissingHeadline, s.config.Search.SkipMissingCompany)
+	if skipped > 0 {
+		s.logger.WithField("skipped", skipped).Info("Skipped profiles missing a headline or company")
+		s.tracer.Record("search", "quality_skip", map[string]interface{}{"skipped": skipped})
+	}
+	return kept
+}
@@ -0,0 +1,119 @@
+// Package search - score.go handles relevance scoring of search results
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/nikshitha/linkedin-automation-poc/config"
+)
+
+// ScoreResult rates how well a result matches the search intent (0-100)
+// using the default weights
+func ScoreResult(result *SearchResult, params SearchParams) int {
+	return ScoreResultWeighted(result, params, config.DefaultConfig().Search.Scoring)
+}
+
+// ScoreResultWeighted rates a result 0-100 by combining headline keyword
+// matches, mutual connections and connection degree. Signals with nothing to
+// measure (e.g. no search terms for a raw URL search) are left out rather
+// than counted as misses.
+func ScoreResultWeighted(result *SearchResult, params SearchParams, weights config.ScoringConfig) int {
+	var total, earned float64
+
+	if terms := searchTerms(params); weights.KeywordWeight > 0 && len(terms) > 0 {
+		text := strings.ToLower(result.Headline + " " + result.Company)
+		matched := 0
+		for _, term := range terms {
+			if strings.Contains(text, term) {
+				matched++
+			}
+		}
+		total += float64(weights.KeywordWeight)
+		earned += float64(weights.KeywordWeight) * float64(matched) / float64(len(terms))
+	}
+
+	if weights.MutualWeight > 0 {
+		mutualCap := weights.MutualCap
+		if mutualCap <= 0 {
+			mutualCap = 1
+		}
+		mutual := math.Min(float64(result.MutualConns), float64(mutualCap))
+		total += float64(weights.MutualWeight)
+		earned += float64(weights.MutualWeight) * mutual / float64(mutualCap)
+	}
+
+	if degree, ok := degreeScore(result.Connection); weights.DegreeWeight > 0 && ok {
+		total += float64(weights.DegreeWeight)
+		earned += float64(weights.DegreeWeight) * degree
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(100 * earned / total))
+}
+
+// searchTerms returns the lowercased words to look for in a headline
+func searchTerms(params SearchParams) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	add := func(text string) {
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			if len(word) > 1 && !seen[word] {
+				seen[word] = true
+				terms = append(terms, word)
+			}
+		}
+	}
+
+	add(params.JobTitle)
+	add(params.Company)
+	for _, keyword := range params.Keywords {
+		add(keyword)
+	}
+	return terms
+}
+
+// degreeScore favours 2nd-degree contacts, who accept most often; 1st-degree
+// contacts are already connected. Unknown degrees are left out.
+func degreeScore(connection string) (float64, bool) {
+	switch {
+	case strings.HasPrefix(connection, "2"):
+		return 1, true
+	case strings.HasPrefix(connection, "3"):
+		return 0.5, true
+	case strings.HasPrefix(connection, "1"):
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// SortByScore orders results by relevance score, highest first, breaking ties
+// by mutual connection count
+func SortByScore(results []*SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].MutualConns > results[j].MutualConns
+	})
+}
+
+// RankResults scores results against params and orders them as configured:
+// by score when scoring is enabled, otherwise by mutual connections
+func (s *Searcher) RankResults(results []*SearchResult, params SearchParams) {
+	scoring := s.config.Search.Scoring
+	for _, result := range results {
+		result.Score = ScoreResultWeighted(result, params, scoring)
+	}
+
+	switch {
+	case scoring.Enabled:
+		SortByScore(results)
+	case s.config.Search.SortByMutualConnections:
+		SortByMutualConnections(results)
+	}
+}
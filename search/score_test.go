@@ -0,0 +1,56 @@
+// Package search - Tests for result relevance scoring
+package search
+
+import (
+	"testing"
+
+	"github.com/nikshitha/linkedin-automation-poc/config"
+)
+
+func TestScoreResult(t *testing.T) {
+	params := SearchParams{JobTitle: "Software Engineer", Keywords: []string{"golang"}}
+
+	best := &SearchResult{Headline: "Senior Software Engineer | Golang", Connection: "2nd", MutualConns: 25}
+	if score := ScoreResult(best, params); score != 100 {
+		t.Errorf("Full match should score 100, got %d", score)
+	}
+
+	none := &SearchResult{Headline: "Chef", Connection: "1st"}
+	if score := ScoreResult(none, params); score != 0 {
+		t.Errorf("No match should score 0, got %d", score)
+	}
+
+	partial := &SearchResult{Headline: "Software Engineer", Connection: "3rd+", MutualConns: 10}
+	score := ScoreResult(partial, params)
+	if score <= 0 || score >= 100 {
+		t.Errorf("Partial match should score between 0 and 100, got %d", score)
+	}
+
+	// Without search terms only mutual connections and degree count
+	if score := ScoreResult(best, SearchParams{}); score != 100 {
+		t.Errorf("Keyword signal should be ignored without terms, got %d", score)
+	}
+
+	// Zero weights ignore a signal entirely
+	weights := config.ScoringConfig{MutualWeight: 1, MutualCap: 10}
+	if score := ScoreResultWeighted(partial, params, weights); score != 100 {
+		t.Errorf("Only the mutual signal should count, got %d", score)
+	}
+}
+
+func TestSortByScore(t *testing.T) {
+	results := []*SearchResult{
+		{Name: "low", Score: 10},
+		{Name: "tie-few", Score: 50, MutualConns: 1},
+		{Name: "high", Score: 90},
+		{Name: "tie-many", Score: 50, MutualConns: 5},
+	}
+	SortByScore(results)
+
+	want := []string{"high", "tie-many", "tie-few", "low"}
+	for i, name := range want {
+		if results[i].Name != name {
+			t.Fatalf("Position %d: expected %s, got %s", i, name, results[i].Name)
+		}
+	}
+}
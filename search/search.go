@@ -3,13 +3,16 @@
 package search
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/go-rod/rod"
+	"github.com/nikshitha/linkedin-automation-poc/browser"
 	"github.com/nikshitha/linkedin-automation-poc/config"
 	"github.com/nikshitha/linkedin-automation-poc/logger"
 	"github.com/nikshitha/linkedin-automation-poc/stealth"
@@ -21,6 +24,32 @@ const (
 	LinkedInPeopleSearchURL = "https://www.linkedin.com/search/results/people/"
 )
 
+// hardMaxSearchPages caps pagination even if max_pages_per_search is unset,
+// so a bug in next-page detection can't loop forever
+const hardMaxSearchPages = 100
+
+// searchResultSelectors match the hydrated search results list
+var searchResultSelectors = []string{
+	".search-results-container",
+	".reusable-search__entity-result-list",
+	"[data-chameleon-result-urn]",
+}
+
+// resultCardSelector matches individual result cards. The list container can
+// render before its cards hydrate, so readiness alone doesn't mean cards exist.
+const resultCardSelector = ".reusable-search__result-container, [data-chameleon-result-urn], .entity-result"
+
+// resultCardKeyAttrs are what a result card is found again by after a
+// re-render, in order of preference
+var resultCardKeyAttrs = []string{"data-chameleon-result-urn", "href"}
+
+// resultCardAttempts bounds how many times an empty page is re-read before
+// concluding it really has no results
+const resultCardAttempts = 3
+
+// resultCardPollInterval is the delay between card lookups while the list hydrates
+var resultCardPollInterval = 500 * time.Millisecond
+
 // SearchParams holds search parameters
 type SearchParams struct {
 	JobTitle  string   `json:"job_title"`
@@ -29,6 +58,7 @@ type SearchParams struct {
 	Keywords  []string `json:"keywords"`
 	Network   []string `json:"network"` // 1st, 2nd, 3rd+
 	MaxResults int     `json:"max_results"`
+	Resume    bool     `json:"resume"` // continue from the page the last run of this search stopped at
 }
 
 // SearchResult represents a single search result
@@ -42,6 +72,8 @@ type SearchResult struct {
 	Location     string `json:"location"`
 	Connection   string `json:"connection"` // 1st, 2nd, 3rd+
 	MutualConns  int    `json:"mutual_connections"`
+	Score        int    `json:"score"` // relevance 0-100, see ScoreResult
+	SearchID     int64  `json:"search_id,omitempty"` // search_history row that surfaced it (0 = unknown)
 }
 
 // Searcher handles LinkedIn search operations
@@ -51,8 +83,15 @@ type Searcher struct {
 	stealth     *stealth.StealthManager
 	rateLimiter *stealth.RateLimiter
 	db          *storage.Database
-	page        *rod.Page
+	pager       browser.Pager
 	seenProfiles map[string]bool // For duplicate detection
+	cacheComplete bool           // seenProfiles holds every known profile, so misses skip the DB
+	tracer      *logger.Tracer
+	layout      *browser.LayoutMonitor
+
+	newTab func() (*rod.Page, error) // opens the tabs EnrichProfiles works in
+	ctx    context.Context
+	pause  *stealth.PauseController
 }
 
 // NewSearcher creates a new searcher
@@ -64,16 +103,33 @@ func NewSearcher(cfg *config.Config, log *logger.Logger, s *stealth.StealthManag
 		rateLimiter:  rl,
 		db:           db,
 		seenProfiles: make(map[string]bool),
+		ctx:          context.Background(),
 	}
 }
 
 // SetPage sets the page instance
-func (s *Searcher) SetPage(page *rod.Page) {
-	s.page = page
+func (s *Searcher) SetPage(page browser.Pager) {
+	s.pager = page
+}
+
+// rodPage returns the browser page behind the pager for mouse, keyboard and
+// screenshot work, or nil when a fake pager is set
+func (s *Searcher) rodPage() *rod.Page {
+	return browser.RodPage(s.pager)
+}
+
+// SetTracer sets the decision tracer
+func (s *Searcher) SetTracer(t *logger.Tracer) {
+	s.tracer = t
+}
+
+// SetLayoutMonitor sets the monitor that fingerprints the search page layout
+func (s *Searcher) SetLayoutMonitor(m *browser.LayoutMonitor) {
+	s.layout = m
 }
 
 // Search performs a LinkedIn people search with the given parameters
-func (s *Searcher) Search(params SearchParams) ([]*SearchResult, error) {
+func (s *Searcher) Search(params SearchParams) (results []*SearchResult, err error) {
 	s.logger.WithFields(map[string]interface{}{
 		"job_title":   params.JobTitle,
 		"company":     params.Company,
@@ -82,36 +138,30 @@ func (s *Searcher) Search(params SearchParams) ([]*SearchResult, error) {
 		"max_results": params.MaxResults,
 	}).Info("Starting search")
 
+	// Stop for the rest of the period once LinkedIn's search limit wall was hit
+	if err := s.blockedError(); err != nil {
+		return nil, err
+	}
+
 	// Check rate limits
 	if !s.rateLimiter.CanPerformAction("search") {
-		return nil, fmt.Errorf("search rate limit reached")
+		return nil, fmt.Errorf("search %w", stealth.ErrRateLimited)
 	}
 
+	defer browser.ScreenshotOnError(s.pager, &s.config.Browser, s.logger, "search", "", &err)
+
 	// Build search URL
 	searchURL := s.buildSearchURL(params)
 	s.logger.WithField("url", searchURL).Debug("Search URL built")
 
-	// Navigate to search page
-	err := s.page.Navigate(searchURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to navigate to search page: %w", err)
-	}
-
-	s.stealth.PageLoadDelay()
-	err = s.page.WaitLoad()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load search page: %w", err)
+	// Navigate to search page, past the pages earlier runs collected
+	startPage := s.resumePage(params)
+	if err := s.openSearchPage(withPage(searchURL, startPage)); err != nil {
+		return nil, err
 	}
 
-	// Apply fingerprint masking
-	s.stealth.ApplyFingerprintMasking(s.page)
-
-	// Random behavior before parsing results
-	s.stealth.RandomMouseWander(s.page)
-	s.stealth.ThinkingDelay()
-
 	// Collect results with pagination
-	results, err := s.collectResults(params.MaxResults)
+	results, err = s.collectResults(params, startPage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect results: %w", err)
 	}
@@ -120,7 +170,7 @@ func (s *Searcher) Search(params SearchParams) ([]*SearchResult, error) {
 	s.rateLimiter.RecordAction("search")
 
 	// Save search history
-	s.db.SaveSearchHistory(
+	searchID, err := s.db.SaveSearchHistory(
 		searchURL,
 		params.JobTitle,
 		params.Company,
@@ -128,11 +178,110 @@ func (s *Searcher) Search(params SearchParams) ([]*SearchResult, error) {
 		params.Keywords,
 		len(results),
 	)
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to save search history")
+	}
+	tagResults(results, searchID)
 
 	s.logger.Infof("Search completed, found %d unique profiles", len(results))
 	return results, nil
 }
 
+// SearchByURL runs the result collection pipeline against a user-supplied
+// LinkedIn search URL, bypassing buildSearchURL entirely. This lets power users
+// reuse searches crafted in the browser with filters the tool cannot build.
+func (s *Searcher) SearchByURL(rawURL string, maxResults int) (results []*SearchResult, err error) {
+	s.logger.WithFields(map[string]interface{}{
+		"url":         rawURL,
+		"max_results": maxResults,
+	}).Info("Starting search from URL")
+
+	searchURL, err := validateSearchURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stop for the rest of the period once LinkedIn's search limit wall was hit
+	if err := s.blockedError(); err != nil {
+		return nil, err
+	}
+
+	// Check rate limits
+	if !s.rateLimiter.CanPerformAction("search") {
+		return nil, fmt.Errorf("search %w", stealth.ErrRateLimited)
+	}
+
+	defer browser.ScreenshotOnError(s.pager, &s.config.Browser, s.logger, "search", "", &err)
+
+	if err := s.openSearchPage(searchURL); err != nil {
+		return nil, err
+	}
+
+	results, err = s.collectResults(SearchParams{MaxResults: maxResults}, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect results: %w", err)
+	}
+
+	s.rateLimiter.RecordAction("search")
+
+	// Save search history (no structured params for raw URLs)
+	searchID, err := s.db.SaveSearchHistory(searchURL, "", "", "", nil, len(results))
+	if err != nil {
+		s.logger.WithError(err).Warn("Failed to save search history")
+	}
+	tagResults(results, searchID)
+
+	s.logger.Infof("Search completed, found %d unique profiles", len(results))
+	return results, nil
+}
+
+// validateSearchURL checks that a raw URL points at a LinkedIn search results page
+func validateSearchURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid search URL: %w", err)
+	}
+
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return "", fmt.Errorf("invalid search URL %q: must be an http(s) URL", rawURL)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host != "linkedin.com" && !strings.HasSuffix(host, ".linkedin.com") {
+		return "", fmt.Errorf("invalid search URL %q: host must be linkedin.com", rawURL)
+	}
+
+	if !strings.HasPrefix(parsed.Path, "/search/results/") {
+		return "", fmt.Errorf("invalid search URL %q: path must start with /search/results/", rawURL)
+	}
+
+	parsed.Scheme = "https"
+	return parsed.String(), nil
+}
+
+// openSearchPage navigates to a search URL and performs the pre-parse human behavior
+func (s *Searcher) openSearchPage(searchURL string) error {
+	err := browser.NavigateAndWaitReady(s.pager, searchURL, s.config.GetReadyTimeout(), searchResultSelectors...)
+	if err != nil {
+		if limitErr := s.checkSearchLimit(); limitErr != nil {
+			return limitErr
+		}
+		return fmt.Errorf("failed to load search page: %w", err)
+	}
+
+	s.stealth.PageLoadDelay()
+	s.layout.Check(s.pager, "search")
+
+	// Apply fingerprint masking
+	s.stealth.ApplyFingerprintMasking(s.rodPage())
+
+	// Random behavior before parsing results
+	s.stealth.RandomMouseWander(s.rodPage())
+	s.stealth.ThinkingDelay()
+
+	return nil
+}
+
 // buildSearchURL constructs the LinkedIn search URL with parameters
 func (s *Searcher) buildSearchURL(params SearchParams) string {
 	baseURL := LinkedInPeopleSearchURL
@@ -178,13 +327,8 @@ func (s *Searcher) buildSearchURL(params SearchParams) string {
 		// LinkedIn uses network=["F","S","O"] for 1st, 2nd, 3rd+
 		networkFilter := []string{}
 		for _, n := range params.Network {
-			switch n {
-			case "1st", "1":
-				networkFilter = append(networkFilter, "F")
-			case "2nd", "2":
-				networkFilter = append(networkFilter, "S")
-			case "3rd", "3", "3rd+":
-				networkFilter = append(networkFilter, "O")
+			if code, ok := networkCode(n); ok {
+				networkFilter = append(networkFilter, code)
 			}
 		}
 		if len(networkFilter) > 0 {
@@ -201,10 +345,67 @@ func (s *Searcher) buildSearchURL(params SearchParams) string {
 	return baseURL
 }
 
-// collectResults collects search results with pagination
-func (s *Searcher) collectResults(maxResults int) ([]*SearchResult, error) {
+// networkCode maps a connection degree to LinkedIn's network filter code
+func networkCode(degree string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(degree)) {
+	case "1st", "1":
+		return "F", true
+	case "2nd", "2":
+		return "S", true
+	case "3rd", "3", "3rd+":
+		return "O", true
+	}
+	return "", false
+}
+
+// NormalizeDegree maps a scraped or configured connection degree ("2nd",
+// "• 3rd+", "3") to 1st, 2nd or 3rd+, or "" if it is unknown
+func NormalizeDegree(degree string) string {
+	degree = strings.TrimLeft(degree, "•· \t")
+	switch {
+	case strings.HasPrefix(degree, "1"):
+		return "1st"
+	case strings.HasPrefix(degree, "2"):
+		return "2nd"
+	case strings.HasPrefix(degree, "3"):
+		return "3rd+"
+	}
+	return ""
+}
+
+// ParseNetwork parses a comma-separated list of connection degrees such as
+// "2nd,3rd" into normalized SearchParams.Network values
+func ParseNetwork(value string) ([]string, error) {
+	names := map[string]string{"F": "1st", "S": "2nd", "O": "3rd+"}
+
+	var network []string
+	seen := make(map[string]bool)
+	for _, degree := range strings.Split(value, ",") {
+		if strings.TrimSpace(degree) == "" {
+			continue
+		}
+		code, ok := networkCode(degree)
+		if !ok {
+			return nil, fmt.Errorf("unknown connection degree %q (use 1st, 2nd or 3rd)", strings.TrimSpace(degree))
+		}
+		if !seen[code] {
+			seen[code] = true
+			network = append(network, names[code])
+		}
+	}
+	return network, nil
+}
+
+// collectResults collects search results with pagination, starting from the
+// already opened startPage, and ranks them against params
+func (s *Searcher) collectResults(params SearchParams, startPage int) ([]*SearchResult, error) {
+	maxResults := params.MaxResults
+	maxPages := s.config.Search.MaxPagesPerSearch
+	if maxPages <= 0 || maxPages > hardMaxSearchPages {
+		maxPages = hardMaxSearchPages
+	}
 	var allResults []*SearchResult
-	currentPage := 1
+	currentPage := startPage
 	// LinkedIn typically shows 10 results per page
 
 	for len(allResults) < maxResults {
@@ -226,32 +427,63 @@ func (s *Searcher) collectResults(maxResults int) ([]*SearchResult, error) {
 
 		if len(pageResults) == 0 {
 			s.logger.Debug("No more results found")
+			s.clearCursor(params)
 			break
 		}
+		found := len(pageResults)
+		pageResults = s.filterIncomplete(pageResults)
 
 		// Filter duplicates
-		for _, result := range pageResults {
-			if !s.isDuplicate(result.ProfileURL) {
-				allResults = append(allResults, result)
-				s.markAsSeen(result.ProfileURL)
-
-				if len(allResults) >= maxResults {
-					break
-				}
+		pageDone := true
+		for i, result := range pageResults {
+			if s.isDuplicate(result.ProfileURL) {
+				s.tracer.Record("search", "dedup_skip", map[string]interface{}{"profile_url": result.ProfileURL})
+				continue
+			}
+
+			allResults = append(allResults, result)
+			s.markAsSeen(result.ProfileURL)
+
+			if len(allResults) >= maxResults {
+				pageDone = i == len(pageResults)-1
+				break
 			}
 		}
 
+		// A page cut short by max_results is collected again on resume
+		if pageDone {
+			s.saveCursor(params, currentPage)
+		}
+
 		s.logger.Infof("Collected %d profiles so far", len(allResults))
+		s.tracer.Record("search", "page_collected", map[string]interface{}{
+			"page":      currentPage,
+			"found":     found,
+			"collected": len(allResults),
+		})
 
 		// Check if we have enough
 		if len(allResults) >= maxResults {
 			break
 		}
 
+		// Bound the footprint of a single search regardless of result count
+		if pages := currentPage - startPage + 1; pages >= maxPages {
+			s.logger.WithFields(map[string]interface{}{
+				"pages":     pages,
+				"collected": len(allResults),
+			}).Info("Reached max pages per search, stopping pagination")
+			s.tracer.Record("search", "page_cap", map[string]interface{}{"pages": pages})
+			break
+		}
+
 		// Try to go to next page
 		hasNextPage, err := s.goToNextPage()
 		if err != nil || !hasNextPage {
 			s.logger.Debug("No more pages available")
+			if err == nil {
+				s.clearCursor(params)
+			}
 			break
 		}
 
@@ -261,28 +493,81 @@ func (s *Searcher) collectResults(maxResults int) ([]*SearchResult, error) {
 		s.rateLimiter.WaitForNextAction()
 
 		// Natural scrolling and delay
-		s.stealth.HumanScroll(s.page, "up", 200) // Scroll back up
+		s.stealth.HumanScroll(s.rodPage(), "up", 200) // Scroll back up
 		s.stealth.ThinkingDelay()
 	}
 
+	s.RankResults(allResults, params)
+
 	return allResults, nil
 }
 
+// SortByMutualConnections orders results by mutual connection count, highest first
+func SortByMutualConnections(results []*SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].MutualConns > results[j].MutualConns
+	})
+}
+
+// FilterByMinMutualConnections drops results with fewer than min mutual connections
+func FilterByMinMutualConnections(results []*SearchResult, min int) []*SearchResult {
+	if min <= 0 {
+		return results
+	}
+
+	var filtered []*SearchResult
+	for _, result := range results {
+		if result.MutualConns >= min {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
 // waitForResults waits for search results to load
 func (s *Searcher) waitForResults() error {
-	// Wait for search results container
-	_, err := s.page.Timeout(10 * time.Second).Element(".search-results-container, .reusable-search__entity-result-list")
+	err := browser.WaitReady(s.pager, s.config.GetReadyTimeout(), searchResultSelectors...)
 	if err != nil {
-		// Try alternative selectors
-		_, err = s.page.Timeout(5 * time.Second).Element("[data-chameleon-result-urn]")
-		if err != nil {
-			return fmt.Errorf("search results not found: %w", err)
+		if limitErr := s.checkSearchLimit(); limitErr != nil {
+			return limitErr
 		}
+		return fmt.Errorf("search results not found: %w", err)
 	}
 
-	// Small delay for full render
-	time.Sleep(500 * time.Millisecond)
-	return nil
+	// The container can be ready before any card hydrates; give the cards
+	// the rest of the ready timeout to appear. An empty page is left for
+	// parseSearchResults to decide.
+	deadline := time.Now().Add(s.config.GetReadyTimeout())
+	for {
+		cards, err := s.pager.Elements(resultCardSelector)
+		if err == nil && len(cards) > 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			s.logger.Debug("No result cards appeared before the ready timeout")
+			return nil
+		}
+		time.Sleep(resultCardPollInterval)
+	}
+}
+
+// findResultCards returns the result cards on the page, re-reading a few
+// times with short delays when none are found so a list that is still
+// hydrating isn't mistaken for the end of the results
+func (s *Searcher) findResultCards() (rod.Elements, error) {
+	var cards rod.Elements
+	var err error
+	for attempt := 1; attempt <= resultCardAttempts; attempt++ {
+		cards, err = s.pager.Elements(resultCardSelector)
+		if err == nil && len(cards) > 0 {
+			return cards, nil
+		}
+		if attempt < resultCardAttempts {
+			s.logger.WithField("attempt", attempt).Debug("No result cards yet, retrying")
+			time.Sleep(resultCardPollInterval * time.Duration(attempt))
+		}
+	}
+	return cards, err
 }
 
 // parseSearchResults parses the current page of search results
@@ -290,15 +575,27 @@ func (s *Searcher) parseSearchResults() ([]*SearchResult, error) {
 	var results []*SearchResult
 
 	// Find all result cards
-	resultCards, err := s.page.Elements(".reusable-search__result-container, [data-chameleon-result-urn], .entity-result")
+	resultCards, err := s.findResultCards()
 	if err != nil {
 		return nil, fmt.Errorf("failed to find result cards: %w", err)
 	}
 
 	s.logger.Debugf("Found %d result cards on page", len(resultCards))
 
+	// Read what each card can be found by before scrolling re-renders the list
+	keys := browser.CardKeys(s.pager, resultCardSelector, resultCardKeyAttrs...)
+
 	for i, card := range resultCards {
-		result, err := s.parseResultCard(card)
+		// Scrolling below can re-render the list under handles read earlier
+		var result *SearchResult
+		err := browser.RetryStale(card, func() (*rod.Element, error) {
+			s.tracer.Record("search", "stale_refetch", map[string]interface{}{"card": i})
+			return s.refetchResultCard(keys, i)
+		}, func(el *rod.Element) error {
+			var parseErr error
+			result, parseErr = s.parseResultCard(el)
+			return parseErr
+		})
 		if err != nil {
 			s.logger.WithError(err).Debugf("Failed to parse result card %d", i)
 			continue
@@ -309,7 +606,7 @@ func (s *Searcher) parseSearchResults() ([]*SearchResult, error) {
 
 			// Scroll to card to simulate reading
 			if i%3 == 0 {
-				s.stealth.HumanScroll(s.page, "down", 100+i*20)
+				s.stealth.HumanScroll(s.rodPage(), "down", 100+i*20)
 			}
 		}
 	}
@@ -317,6 +614,16 @@ func (s *Searcher) parseSearchResults() ([]*SearchResult, error) {
 	return results, nil
 }
 
+// refetchResultCard finds the i-th result card again after the list
+// re-rendered, by the URN or link read when the cards were first listed. A
+// re-render can reorder the list, so the card's position isn't used.
+func (s *Searcher) refetchResultCard(keys []browser.CardKey, i int) (*rod.Element, error) {
+	if i >= len(keys) {
+		return nil, fmt.Errorf("result card %d was not keyed when listed", i)
+	}
+	return browser.RefetchCard(s.pager, resultCardSelector, keys[i])
+}
+
 // parseResultCard extracts profile information from a result card
 func (s *Searcher) parseResultCard(card *rod.Element) (*SearchResult, error) {
 	result := &SearchResult{}
@@ -327,12 +634,15 @@ func (s *Searcher) parseResultCard(card *rod.Element) (*SearchResult, error) {
 		// Try alternative selectors
 		linkEl, err = card.Element("span.entity-result__title-text a")
 		if err != nil {
-			return nil, fmt.Errorf("profile link not found")
+			return nil, fmt.Errorf("profile link not found: %w", err)
 		}
 	}
 
 	href, err := linkEl.Attribute("href")
-	if err != nil || href == nil {
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile URL: %w", err)
+	}
+	if href == nil {
 		return nil, fmt.Errorf("failed to get profile URL")
 	}
 
@@ -382,6 +692,12 @@ func (s *Searcher) parseResultCard(card *rod.Element) (*SearchResult, error) {
 
 // cleanProfileURL removes tracking parameters from profile URL
 func (s *Searcher) cleanProfileURL(rawURL string) string {
+	return CleanProfileURL(rawURL)
+}
+
+// CleanProfileURL normalizes a profile URL to the form stored in the
+// database: no tracking parameters, absolute, with a trailing slash
+func CleanProfileURL(rawURL string) string {
 	// Parse URL
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
@@ -465,14 +781,14 @@ func (s *Searcher) extractMutualCount(text string) int {
 // goToNextPage attempts to navigate to the next page of results
 func (s *Searcher) goToNextPage() (bool, error) {
 	// Scroll to bottom to ensure pagination is visible
-	s.stealth.HumanScroll(s.page, "down", 500)
+	s.stealth.HumanScroll(s.rodPage(), "down", 500)
 	time.Sleep(500 * time.Millisecond)
 
 	// Look for next page button
-	nextButton, err := s.page.Element("button.artdeco-pagination__button--next:not([disabled])")
+	nextButton, err := s.pager.Element("button.artdeco-pagination__button--next:not([disabled])")
 	if err != nil {
 		// Try alternative selector
-		nextButton, err = s.page.Element("button[aria-label='Next']:not([disabled])")
+		nextButton, err = s.pager.Element("button[aria-label='Next']:not([disabled])")
 		if err != nil {
 			return false, nil
 		}
@@ -485,18 +801,45 @@ func (s *Searcher) goToNextPage() (bool, error) {
 	}
 
 	// Human-like click on next button
-	err = s.stealth.ClickElement(s.page, nextButton)
+	err = s.stealth.ClickElement(s.rodPage(), nextButton)
 	if err != nil {
 		return false, fmt.Errorf("failed to click next button: %w", err)
 	}
 
-	// Wait for page to load
+	// Wait for the next page of results to hydrate
+	if err := browser.WaitReady(s.pager, s.config.GetReadyTimeout(), searchResultSelectors...); err != nil {
+		return false, fmt.Errorf("next page did not load: %w", err)
+	}
 	s.stealth.PageLoadDelay()
-	time.Sleep(time.Second)
 
 	return true, nil
 }
 
+// maxWarmCacheSize caps how many known profile URLs WarmCache loads
+const maxWarmCacheSize = 200000
+
+// WarmCache loads the URLs of all stored profiles and sent requests into the
+// dedup cache, so checking search results doesn't query the database per
+// card. Beyond maxWarmCacheSize only the most recent are loaded and misses
+// still fall back to the database.
+func (s *Searcher) WarmCache() error {
+	urls, err := s.db.KnownProfileURLs(maxWarmCacheSize)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range urls {
+		s.seenProfiles[url] = true
+	}
+	s.cacheComplete = len(urls) < maxWarmCacheSize
+
+	s.logger.WithFields(map[string]interface{}{
+		"profiles": len(urls),
+		"complete": s.cacheComplete,
+	}).Info("Dedup cache warmed")
+	return nil
+}
+
 // isDuplicate checks if a profile URL has already been seen
 func (s *Searcher) isDuplicate(profileURL string) bool {
 	// Check in-memory cache
@@ -504,6 +847,12 @@ func (s *Searcher) isDuplicate(profileURL string) bool {
 		return true
 	}
 
+	// Results are marked as seen once saved, and connecting re-checks the
+	// database before sending
+	if s.cacheComplete {
+		return false
+	}
+
 	// Check database
 	exists, err := s.db.ProfileExists(profileURL)
 	if err == nil && exists {
@@ -511,7 +860,8 @@ func (s *Searcher) isDuplicate(profileURL string) bool {
 		return true
 	}
 
-	// Check if connection request was already sent
+	// Check if connection request was already sent (in any status, so
+	// declined and withdrawn profiles are never targeted again)
 	hasSent, err := s.db.HasSentConnectionRequest(profileURL)
 	if err == nil && hasSent {
 		s.seenProfiles[profileURL] = true
@@ -526,23 +876,60 @@ func (s *Searcher) markAsSeen(profileURL string) {
 	s.seenProfiles[profileURL] = true
 }
 
-// SaveProfile saves a search result as a profile in the database
+// tagResults records which search surfaced each result
+func tagResults(results []*SearchResult, searchID int64) {
+	for _, result := range results {
+		result.SearchID = searchID
+	}
+}
+
+// SaveProfile saves a search result as a profile in the database, linking it
+// to the search that surfaced it
 func (s *Searcher) SaveProfile(result *SearchResult) (int64, error) {
-	profile := &storage.Profile{
-		ProfileURL:       result.ProfileURL,
-		Name:             result.Name,
-		FirstName:        result.FirstName,
-		LastName:         result.LastName,
-		Headline:         result.Headline,
-		Company:          result.Company,
-		Location:         result.Location,
-		ConnectionDegree: result.Connection,
+	id, err := s.db.SaveProfile(result.ToProfile())
+	if err != nil || result.SearchID == 0 {
+		return id, err
 	}
 
-	return s.db.SaveProfile(profile)
+	if err := s.db.LinkSearchResult(result.SearchID, id); err != nil {
+		s.logger.WithError(err).Warn("Failed to record search provenance")
+	}
+	return id, nil
+}
+
+// ToProfile converts a search result into its storage representation.
+// Every scraped field must be mapped here so nothing is lost on save.
+func (r *SearchResult) ToProfile() *storage.Profile {
+	return &storage.Profile{
+		ProfileURL:        r.ProfileURL,
+		Name:              r.Name,
+		FirstName:         r.FirstName,
+		LastName:          r.LastName,
+		Headline:          r.Headline,
+		Company:           r.Company,
+		Location:          r.Location,
+		ConnectionDegree:  r.Connection,
+		MutualConnections: r.MutualConns,
+	}
+}
+
+// ResultFromProfile rebuilds a search result from a stored profile
+func ResultFromProfile(p *storage.Profile) *SearchResult {
+	return &SearchResult{
+		ProfileURL:  p.ProfileURL,
+		Name:        p.Name,
+		FirstName:   p.FirstName,
+		LastName:    p.LastName,
+		Headline:    p.Headline,
+		Company:     p.Company,
+		Location:    p.Location,
+		Connection:  p.ConnectionDegree,
+		MutualConns: p.MutualConnections,
+	}
 }
 
 // ClearSeenProfiles clears the in-memory seen profiles cache
 func (s *Searcher) ClearSeenProfiles() {
 	s.seenProfiles = make(map[string]bool)
+	s.cacheComplete = false
 }
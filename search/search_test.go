@@ -0,0 +1,134 @@
+// Package search - Tests for search parameter handling
+package search
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseNetwork(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"2nd", []string{"2nd"}, false},
+		{"2nd,3rd", []string{"2nd", "3rd+"}, false},
+		{" 1 , 2nd , 3rd+ ", []string{"1st", "2nd", "3rd+"}, false},
+		{"2nd,2", []string{"2nd"}, false},
+		{"2ND", []string{"2nd"}, false},
+		{"2nd,4th", nil, true},
+		{"friends", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseNetwork(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseNetwork(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseNetwork(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBuildSearchURLNetwork(t *testing.T) {
+	s := &Searcher{}
+	network, _ := ParseNetwork("2nd,3rd")
+
+	raw := s.buildSearchURL(SearchParams{JobTitle: "engineer", Network: network})
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Query().Get("network"); got != `["S","O"]` {
+		t.Errorf("Expected network filter [\"S\",\"O\"], got %q", got)
+	}
+}
+
+func TestParseResultCount(t *testing.T) {
+	tests := []struct {
+		text  string
+		count int
+		ok    bool
+	}{
+		{"About 12,400 results", 12400, true},
+		{"About 1.234 results", 1234, true},
+		{"3 results", 3, true},
+		{"1 result", 1, true},
+		{"About 1.2K results", 1200, true},
+		{"No results found", 0, true},
+		{"People you may know", 0, false},
+	}
+
+	for _, tt := range tests {
+		count, ok := parseResultCount(tt.text)
+		if count != tt.count || ok != tt.ok {
+			t.Errorf("parseResultCount(%q) = %d, %v; want %d, %v", tt.text, count, ok, tt.count, tt.ok)
+		}
+	}
+}
+
+func TestFilterIncomplete(t *testing.T) {
+	results := []*SearchResult{
+		{ProfileURL: "complete", Headline: "Engineer", Company: "Acme"},
+		{ProfileURL: "no-headline", Headline: "  ", Company: "Acme"},
+		{ProfileURL: "no-company", Headline: "Engineer"},
+	}
+
+	tests := []struct {
+		skipHeadline, skipCompany bool
+		want                      []string
+	}{
+		{false, false, []string{"complete", "no-headline", "no-company"}},
+		{true, false, []string{"complete", "no-company"}},
+		{false, true, []string{"complete", "no-headline"}},
+		{true, true, []string{"complete"}},
+	}
+
+	for _, tt := range tests {
+		kept, skipped := FilterIncomplete(results, tt.skipHeadline, tt.skipCompany)
+		var urls []string
+		for _, r := range kept {
+			urls = append(urls, r.ProfileURL)
+		}
+		if !reflect.DeepEqual(urls, tt.want) {
+			t.Errorf("FilterIncomplete(headline=%v, company=%v) kept %v, want %v", tt.skipHeadline, tt.skipCompany, urls, tt.want)
+		}
+		if skipped != len(results)-len(tt.want) {
+			t.Errorf("FilterIncomplete(headline=%v, company=%v) skipped %d, want %d", tt.skipHeadline, tt.skipCompany, skipped, len(results)-len(tt.want))
+		}
+	}
+}
+
+func TestSearchParamsSignature(t *testing.T) {
+	base := SearchParams{JobTitle: "Software Engineer", Company: "Acme", Keywords: []string{"go", "cloud"}, Network: []string{"2nd", "3rd+"}}
+	same := SearchParams{JobTitle: "  software   engineer ", Company: "ACME", Keywords: []string{"cloud", "go"}, Network: []string{"3rd+", "2nd"}, MaxResults: 50, Resume: true}
+	if base.Signature() != same.Signature() {
+		t.Error("Signature should ignore case, spacing, ordering, max results and resume")
+	}
+
+	other := base
+	other.Location = "Berlin"
+	if base.Signature() == other.Signature() {
+		t.Error("Different filters should give different signatures")
+	}
+}
+
+func TestWithPage(t *testing.T) {
+	searchURL := "https://www.linkedin.com/search/results/people/?keywords=go"
+	if got := withPage(searchURL, 1); got != searchURL {
+		t.Errorf("Page 1 should leave the URL unchanged, got %s", got)
+	}
+
+	parsed, err := url.Parse(withPage(searchURL, 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Query().Get("page") != "4" || parsed.Query().Get("keywords") != "go" {
+		t.Errorf("Expected page=4 alongside the original query, got %s", parsed.RawQuery)
+	}
+}
@@ -0,0 +1,41 @@
+// Package search - view.go handles visiting a profile without further action
+package search
+
+import (
+	"fmt"
+
+	"github.com/nikshitha/linkedin-automation-poc/browser"
+	"github.com/nikshitha/linkedin-automation-poc/stealth"
+)
+
+// ViewProfile opens a profile and reads it like a person would. The visit
+// counts toward the daily profile view cap.
+func (s *Searcher) ViewProfile(profileURL string) error {
+	profileURL = s.cleanProfileURL(profileURL)
+	s.logger.WithField("profile_url", profileURL).Info("Viewing profile")
+
+	if !s.rateLimiter.CanPerformAction("profile_view") {
+		return fmt.Errorf("profile view %w", stealth.ErrRateLimited)
+	}
+
+	err := browser.NavigateAndWaitReady(s.pager, profileURL, s.config.GetReadyTimeout(),
+		".pv-top-card", ".scaffold-layout__main")
+	if err != nil {
+		return fmt.Errorf("profile content not loaded: %w", err)
+	}
+
+	s.rateLimiter.RecordProfileView(stealth.ViewExplicit)
+	s.db.IncrementProfileViews(stealth.ViewExplicit)
+
+	s.stealth.PageLoadDelay()
+	s.layout.Check(s.pager, "profile")
+	s.stealth.ApplyFingerprintMasking(s.rodPage())
+
+	s.stealth.RandomMouseWander(s.rodPage())
+	s.stealth.ReadingDelay(s.rodPage())
+	s.stealth.HumanScroll(s.rodPage(), "down", 400)
+	s.stealth.ActionDelay()
+
+	s.tracer.Record("search", "profile_view", map[string]interface{}{"profile_url": profileURL})
+	return nil
+}
@@ -0,0 +1,53 @@
+// Package stealth - blur.go handles simulated switching away from the browser tab
+package stealth
+
+import (
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Time spent "in another tab", in milliseconds
+const (
+	tabBlurMinMs = 5000
+	tabBlurMaxMs = 45000
+)
+
+// SimulateTabBlur switches to a blank tab for a human interval, as when the
+// user looks at something else, then activates the page again. The switch is
+// a real target activation, so the browser itself updates the page's
+// visibility and focus and fires trusted blur and focus events.
+func (s *StealthManager) SimulateTabBlur(page *rod.Page) error {
+	if page == nil {
+		return errNoPage
+	}
+	other, err := page.Browser().Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		return err
+	}
+	defer other.Close()
+	if _, err := other.Activate(); err != nil {
+		return err
+	}
+
+	away := tabBlurMinMs + s.rand.Intn(tabBlurMaxMs-tabBlurMinMs+1)
+	s.tracer.Record("stealth", "tab_blur", map[string]interface{}{"duration_ms": away})
+	s.logger.Delay("switched to another tab", time.Duration(away)*time.Millisecond)
+	time.Sleep(time.Duration(away) * time.Millisecond)
+
+	_, err = page.Activate()
+	s.logger.StealthAction("tab_blur", map[string]interface{}{"duration_ms": away})
+	return err
+}
+
+// MaybeSimulateTabBlur switches away from the tab with probability
+// TabBlurChance; called between actions
+func (s *StealthManager) MaybeSimulateTabBlur(page *rod.Page) {
+	if page == nil || s.config.TabBlurChance <= 0 || s.rand.Float64() >= s.config.TabBlurChance {
+		return
+	}
+	if err := s.SimulateTabBlur(page); err != nil {
+		s.logger.WithError(err).Debug("Failed to simulate tab switch")
+	}
+}
@@ -0,0 +1,42 @@
+// Package stealth - burst.go handles letting a few actions cluster before normal pacing applies
+package stealth
+
+import "time"
+
+// Spacing between actions taken from the burst allowance
+const (
+	burstDelayMin = 500 * time.Millisecond
+	burstDelayMax = 1500 * time.Millisecond
+)
+
+// takeBurstToken refills the burst bucket for the time since the last refill
+// and spends one token if available. The bucket starts full, holds at most
+// burst_size tokens and regains one every burst_refill_seconds.
+func (r *RateLimiter) takeBurstToken(now time.Time) bool {
+	size := r.config.BurstSize
+	refill := time.Duration(r.config.BurstRefillSeconds) * time.Second
+	if size <= 0 || refill <= 0 {
+		return false
+	}
+
+	if r.burstRefilled.IsZero() {
+		r.burstTokens = float64(size)
+	} else {
+		r.burstTokens += float64(now.Sub(r.burstRefilled)) / float64(refill)
+		if r.burstTokens > float64(size) {
+			r.burstTokens = float64(size)
+		}
+	}
+	r.burstRefilled = now
+
+	if r.burstTokens < 1 {
+		return false
+	}
+	r.burstTokens--
+	return true
+}
+
+// burstDelay returns a short random gap for an action taken from the burst allowance
+func (r *RateLimiter) burstDelay() time.Duration {
+	return burstDelayMin + time.Duration(r.rand.Int63n(int64(burstDelayMax-burstDelayMin)))
+}
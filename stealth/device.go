@@ -0,0 +1,149 @@
+// Package stealth - device.go handles user agents and viewports for emulated devices
+package stealth
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Device profiles selectable with browser.device_profile
+const (
+	DeviceDesktop = "desktop"
+	DeviceTablet  = "tablet"
+	DeviceMobile  = "mobile"
+)
+
+// DeviceMetrics describes the screen of an emulated device
+type DeviceMetrics struct {
+	Width       int
+	Height      int
+	ScaleFactor float64
+	Mobile      bool // mobile viewport meta handling and scrollbars
+	Touch       bool
+}
+
+// deviceUserAgents lists realistic user agents for the non-desktop profiles.
+// They are all Chrome on Android: the browser is Chromium, so an iOS Safari
+// user agent would contradict its Client Hints and JavaScript engine.
+var deviceUserAgents = map[string][]string{
+	DeviceMobile: {
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 7a) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36",
+		"Mozilla/5.0 (Linux; Android 13; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.6045.163 Mobile Safari/537.36",
+		"Mozilla/5.0 (Linux; Android 13; SM-A546B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.6045.163 Mobile Safari/537.36",
+	},
+	DeviceTablet: {
+		"Mozilla/5.0 (Linux; Android 14; Pixel Tablet) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Safari/537.36",
+		"Mozilla/5.0 (Linux; Android 13; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Safari/537.36",
+	},
+}
+
+// Parts of a Chromium user agent that its Client Hints repeat
+var (
+	chromeVersionPattern = regexp.MustCompile(`Chrome/((\d+)[\d.]*)`)
+	androidPattern       = regexp.MustCompile(`Android (\d+)(?:; ([^)]+))?\)`)
+)
+
+// UserAgentMetadata returns the Client Hints (Sec-CH-UA headers and
+// navigator.userAgentData) that match a Chrome or Edge user agent, so an
+// override doesn't leave the browser's real platform in the hints. Other
+// browsers don't send Client Hints; for them it returns nil.
+func UserAgentMetadata(userAgent string) *proto.EmulationUserAgentMetadata {
+	version := chromeVersionPattern.FindStringSubmatch(userAgent)
+	if version == nil {
+		return nil
+	}
+	full, major := version[1], version[2]
+
+	brand := "Google Chrome"
+	if strings.Contains(userAgent, " Edg/") {
+		brand = "Microsoft Edge"
+	}
+	brands := func(v string) []*proto.EmulationUserAgentBrandVersion {
+		return []*proto.EmulationUserAgentBrandVersion{
+			{Brand: "Not_A Brand", Version: "8"},
+			{Brand: "Chromium", Version: v},
+			{Brand: brand, Version: v},
+		}
+	}
+
+	metadata := &proto.EmulationUserAgentMetadata{
+		Brands:          brands(major),
+		FullVersionList: brands(full),
+		FullVersion:     full,
+		Mobile:          strings.Contains(userAgent, " Mobile "),
+	}
+	switch {
+	case androidPattern.MatchString(userAgent):
+		android := androidPattern.FindStringSubmatch(userAgent)
+		metadata.Platform = "Android"
+		metadata.PlatformVersion = android[1] + ".0.0"
+		metadata.Model = android[2]
+	case strings.Contains(userAgent, "Windows"):
+		metadata.Platform = "Windows"
+		metadata.PlatformVersion = "10.0.0"
+		metadata.Architecture = "x86"
+		metadata.Bitness = "64"
+	case strings.Contains(userAgent, "Macintosh"):
+		metadata.Platform = "macOS"
+		metadata.PlatformVersion = "10.15.7"
+		metadata.Architecture = "x86"
+		metadata.Bitness = "64"
+	default:
+		metadata.Platform = "Linux"
+		metadata.Architecture = "x86"
+		metadata.Bitness = "64"
+	}
+	return metadata
+}
+
+// NavigatorPlatform returns the navigator.platform a browser with the user
+// agent reports, or "" to keep the real one
+func NavigatorPlatform(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "Android"):
+		return "Linux armv8l"
+	case strings.Contains(userAgent, "Windows"):
+		return "Win32"
+	case strings.Contains(userAgent, "Macintosh"):
+		return "MacIntel"
+	}
+	return ""
+}
+
+// deviceScreens lists common screens for the non-desktop profiles
+var deviceScreens = map[string][]DeviceMetrics{
+	DeviceMobile: {
+		{Width: 390, Height: 844, ScaleFactor: 3, Mobile: true, Touch: true},
+		{Width: 393, Height: 852, ScaleFactor: 3, Mobile: true, Touch: true},
+		{Width: 412, Height: 915, ScaleFactor: 2.625, Mobile: true, Touch: true},
+		{Width: 360, Height: 780, ScaleFactor: 3, Mobile: true, Touch: true},
+	},
+	DeviceTablet: {
+		{Width: 820, Height: 1180, ScaleFactor: 2, Touch: true},
+		{Width: 768, Height: 1024, ScaleFactor: 2, Touch: true},
+		{Width: 800, Height: 1280, ScaleFactor: 2, Touch: true},
+	},
+}
+
+// GetRandomUserAgentForDevice returns a user agent matching the device
+// profile, falling back to the desktop list
+func (s *StealthManager) GetRandomUserAgentForDevice(device string) string {
+	agents, ok := deviceUserAgents[device]
+	if !ok {
+		return s.GetRandomUserAgent()
+	}
+	return agents[s.rand.Intn(len(agents))]
+}
+
+// GetDeviceMetrics returns the screen to emulate for a device profile. For
+// desktop it is the given viewport at scale 1 without touch.
+func (s *StealthManager) GetDeviceMetrics(device string, width, height int) DeviceMetrics {
+	screens, ok := deviceScreens[device]
+	if !ok {
+		return DeviceMetrics{Width: width, Height: height, ScaleFactor: 1}
+	}
+	return screens[s.rand.Intn(len(screens))]
+}
@@ -0,0 +1,132 @@
+// Package stealth - graphemes.go handles splitting typed text into user-perceived characters
+package stealth
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner = '\u200d'
+	keycapCombining = '\u20e3'
+)
+
+// splitGraphemes splits text into grapheme clusters so that emoji with skin-tone
+// modifiers, ZWJ sequences, flags and combining marks are typed as one keystroke.
+// It covers the cases that show up in messages rather than the full UAX #29 rules.
+func splitGraphemes(text string) []string {
+	runes := []rune(text)
+	var clusters []string
+
+	for i := 0; i < len(runes); {
+		start := i
+		r := runes[i]
+		i++
+
+		switch {
+		case r == '\r' && i < len(runes) && runes[i] == '\n':
+			// CRLF is a single line break
+			i++
+		case isRegionalIndicator(r):
+			// Flags are pairs of regional indicators
+			if i < len(runes) && isRegionalIndicator(runes[i]) {
+				i++
+			}
+		}
+
+		// Absorb extenders and anything joined with a ZWJ
+		for i < len(runes) && r != '\n' && r != '\r' {
+			next := runes[i]
+			if isGraphemeExtender(next) {
+				i++
+				continue
+			}
+			if next == zeroWidthJoiner && i+1 < len(runes) {
+				i += 2
+				continue
+			}
+			break
+		}
+
+		clusters = append(clusters, string(runes[start:i]))
+	}
+
+	return clusters
+}
+
+// TruncateText shortens text to at most limit characters (runes), appending
+// "..." and cutting at the last word boundary rather than splitting a word.
+// It never splits a grapheme cluster, so emoji with modifiers, flags and ZWJ
+// sequences are kept whole or dropped whole.
+func TruncateText(text string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	const ellipsis = "..."
+	budget := limit - len(ellipsis)
+	if limit <= len(ellipsis) {
+		budget = limit
+	}
+
+	clusters := splitGraphemes(text)
+	n, used := 0, 0
+	for n < len(clusters) {
+		size := utf8.RuneCountInString(clusters[n])
+		if used+size > budget {
+			break
+		}
+		used += size
+		n++
+	}
+	if limit <= len(ellipsis) {
+		return strings.Join(clusters[:n], "")
+	}
+
+	// Only back up to a space if the cut landed inside a word
+	cut := clusters[:n]
+	if n < len(clusters) && !isSpaceCluster(clusters[n]) {
+		for i := len(cut) - 1; i > 0; i-- {
+			if isSpaceCluster(cut[i]) {
+				cut = cut[:i]
+				break
+			}
+		}
+	}
+
+	return strings.TrimRightFunc(strings.Join(cut, ""), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + ellipsis
+}
+
+// isSpaceCluster reports whether a cluster is whitespace
+func isSpaceCluster(cluster string) bool {
+	r, _ := utf8.DecodeRuneInString(cluster)
+	return unicode.IsSpace(r)
+}
+
+// isLineBreak reports whether a cluster is a newline that needs Shift+Enter in the composer
+func isLineBreak(cluster string) bool {
+	return cluster == "\n" || cluster == "\r\n" || cluster == "\r"
+}
+
+// isRegionalIndicator reports whether r is one half of a flag emoji
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// isGraphemeExtender reports whether r attaches to the preceding character
+func isGraphemeExtender(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true // combining marks (accents, variation selectors)
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		return true // emoji skin-tone modifiers
+	case r >= 0xE0020 && r <= 0xE007F:
+		return true // tag sequences (subdivision flags)
+	case r == keycapCombining:
+		return true
+	}
+	return false
+}
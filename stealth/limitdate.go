@@ -0,0 +1,53 @@
+// Package stealth - limitdate.go handles parsing the resume dates in LinkedIn's limit notices
+package stealth
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var weekdayPrefix = regexp.MustCompile(`(?i)^(mon|tue|wed|thu|fri|sat|sun)[a-z]*,?\s+`)
+
+// limitDateLayouts are the date formats LinkedIn uses across locales
+var limitDateLayouts = []string{
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"1/2/2006",
+	"2006-01-02",
+	"January 2",
+	"Jan 2",
+	"2 January",
+	"2 Jan",
+	"1/2",
+}
+
+// ParseLimitDate parses the date at the start of text, e.g. "Monday, Oct 20."
+// as captured from a LinkedIn limit notice. Dates without a year are taken to
+// be the next such date from now. The block lifts at local midnight of that day.
+func ParseLimitDate(text string, now time.Time) (time.Time, bool) {
+	candidate := weekdayPrefix.ReplaceAllString(strings.TrimSpace(text), "")
+	words := strings.Fields(candidate)
+
+	// The date may be followed by more text; try the longest prefix first
+	for n := len(words); n > 0; n-- {
+		value := strings.TrimRight(strings.Join(words[:n], " "), ",;:.")
+		for _, layout := range limitDateLayouts {
+			date, err := time.ParseInLocation(layout, value, now.Location())
+			if err != nil {
+				continue
+			}
+			if date.Year() == 0 {
+				date = date.AddDate(now.Year(), 0, 0)
+				if date.Before(now.AddDate(0, 0, -1)) {
+					date = date.AddDate(1, 0, 0)
+				}
+			}
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
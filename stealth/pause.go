@@ -0,0 +1,119 @@
+// Package stealth - pause.go handles operator pause/resume between actions
+package stealth
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/config"
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+)
+
+// pausePollInterval is how often a paused run checks whether to resume
+const pausePollInterval = 2 * time.Second
+
+// PauseController lets an operator pause a run between actions, by signal or
+// by creating the control file, without closing the browser. A nil
+// controller never pauses.
+type PauseController struct {
+	logger            *logger.Logger
+	tracer            *logger.Tracer
+	toggled           atomic.Bool
+	controlFile       string
+	keepAlive         func()
+	keepAliveInterval time.Duration
+	pollInterval      time.Duration
+	watchdog          *Watchdog
+}
+
+// NewPauseController creates a pause controller watching cfg.PauseFile
+func NewPauseController(cfg *config.ScheduleConfig, log *logger.Logger) *PauseController {
+	return &PauseController{
+		logger:            log.WithModule("pause"),
+		controlFile:       cfg.PauseFile,
+		keepAliveInterval: time.Duration(cfg.PauseKeepAliveMinutes) * time.Minute,
+		pollInterval:      pausePollInterval,
+	}
+}
+
+// SetTracer sets the decision tracer
+func (p *PauseController) SetTracer(t *logger.Tracer) {
+	p.tracer = t
+}
+
+// SetKeepAlive sets the light browsing run periodically while paused
+func (p *PauseController) SetKeepAlive(fn func()) {
+	p.keepAlive = fn
+}
+
+// SetWatchdog sets the watchdog suspended while the run is paused
+func (p *PauseController) SetWatchdog(w *Watchdog) {
+	p.watchdog = w
+}
+
+// Toggle flips the paused state and returns the new state
+func (p *PauseController) Toggle() bool {
+	paused := !p.toggled.Load()
+	p.toggled.Store(paused)
+	if paused {
+		p.logger.Info("Pause requested, stopping before the next action")
+	} else {
+		p.logger.Info("Resume requested")
+	}
+	return paused
+}
+
+// Paused reports whether the run is paused by toggle or control file
+func (p *PauseController) Paused() bool {
+	if p == nil {
+		return false
+	}
+	if p.toggled.Load() {
+		return true
+	}
+	if p.controlFile == "" {
+		return false
+	}
+	_, err := os.Stat(p.controlFile)
+	return err == nil
+}
+
+// WaitWhilePaused blocks while the run is paused, keeping the session warm at
+// the configured interval. Returns false if ctx ends while paused.
+func (p *PauseController) WaitWhilePaused(ctx context.Context) bool {
+	if !p.Paused() {
+		return ctx.Err() == nil
+	}
+
+	defer p.watchdog.Suspend()()
+
+	pausedAt := time.Now()
+	p.logger.WithField("control_file", p.controlFile).Info("Run paused (send the signal again or remove the control file to resume)")
+	p.tracer.Record("pause", "paused", nil)
+
+	poll := time.NewTicker(p.pollInterval)
+	defer poll.Stop()
+
+	lastKeepAlive := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-poll.C:
+		}
+
+		if !p.Paused() {
+			p.logger.WithField("paused_for", time.Since(pausedAt).Round(time.Second).String()).Info("Run resumed")
+			p.tracer.Record("pause", "resumed", map[string]interface{}{"paused_ms": time.Since(pausedAt).Milliseconds()})
+			return true
+		}
+
+		if p.keepAlive != nil && p.keepAliveInterval > 0 && time.Since(lastKeepAlive) >= p.keepAliveInterval {
+			p.logger.Debug("Keeping session warm while paused")
+			p.keepAlive()
+			lastKeepAlive = time.Now()
+		}
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+// Package stealth - pause_unix.go handles toggling pause on SIGUSR1
+package stealth
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ToggleOnSignal toggles the paused state every time the process receives SIGUSR1
+func (p *PauseController) ToggleOnSignal() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGUSR1)
+
+	go func() {
+		for range sigChan {
+			p.Toggle()
+		}
+	}()
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+// Package stealth - pause_windows.go stubs signal toggling, which needs SIGUSR1
+package stealth
+
+// ToggleOnSignal is unavailable on Windows; use the pause control file instead
+func (p *PauseController) ToggleOnSignal() {
+	p.logger.Debug("Pause on signal is not supported on Windows, use the control file")
+}
@@ -0,0 +1,126 @@
+// Package stealth - profile.go handles the persisted per-profile browser fingerprint
+package stealth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FingerprintProfileFile is the file in the browser user data dir that keeps
+// the fingerprint the profile was created with
+const FingerprintProfileFile = "fingerprint.json"
+
+// FingerprintProfile fixes the device identity a browser profile presents, so
+// the same "person" doesn't look like a new device every session
+type FingerprintProfile struct {
+	Device              string        `json:"device"`
+	UserAgent           string        `json:"user_agent"`
+	Screen              DeviceMetrics `json:"screen"`
+	HardwareConcurrency int           `json:"hardware_concurrency"`
+	Languages           []string      `json:"languages"`
+	CreatedAt           time.Time     `json:"created_at"`
+}
+
+// defaultLanguages is the navigator.languages every new profile starts with
+var defaultLanguages = []string{"en-US", "en"}
+
+// NewFingerprintProfile picks a fresh identity for device. width and height
+// are the desktop window size (random or configured); tablets and phones
+// bring their own screen.
+func (s *StealthManager) NewFingerprintProfile(device string, width, height int) *FingerprintProfile {
+	return &FingerprintProfile{
+		Device:              device,
+		UserAgent:           s.GetRandomUserAgentForDevice(device),
+		Screen:              s.GetDeviceMetrics(device, width, height),
+		HardwareConcurrency: s.randomHardwareConcurrency(),
+		Languages:           append([]string(nil), defaultLanguages...),
+		CreatedAt:           time.Now(),
+	}
+}
+
+// LoadOrCreateFingerprintProfile returns the profile stored in dir, or saves
+// and returns generated if none exists yet. A stored profile for a different
+// device is replaced, since its screen and user agent no longer fit.
+func (s *StealthManager) LoadOrCreateFingerprintProfile(dir string, generated *FingerprintProfile) (*FingerprintProfile, error) {
+	path := filepath.Join(dir, FingerprintProfileFile)
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		stored := &FingerprintProfile{}
+		if err := json.Unmarshal(data, stored); err != nil {
+			s.logger.WithError(err).Warn("Stored fingerprint profile is unreadable, creating a new one")
+		} else if stored.Device != generated.Device {
+			s.logger.WithFields(map[string]interface{}{
+				"stored_device": stored.Device,
+				"device":        generated.Device,
+			}).Warn("Device profile changed, creating a new fingerprint profile")
+		} else if stored.valid() {
+			s.logger.WithField("created_at", stored.CreatedAt.Format("2006-01-02")).Debug("Using stored fingerprint profile")
+			return stored, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read fingerprint profile: %w", err)
+	}
+
+	data, err = json.MarshalIndent(generated, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode fingerprint profile: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fingerprint profile directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save fingerprint profile: %w", err)
+	}
+
+	s.logger.WithField("path", path).Info("Created fingerprint profile")
+	return generated, nil
+}
+
+// valid reports whether a stored profile has everything the browser needs.
+// Phones and tablets need a Chromium user agent whose Client Hints can match;
+// profiles saved with an iOS one are replaced.
+func (p *FingerprintProfile) valid() bool {
+	if p.Device != "" && p.Device != DeviceDesktop && UserAgentMetadata(p.UserAgent) == nil {
+		return false
+	}
+	return p.UserAgent != "" && p.Screen.Width > 0 && p.Screen.Height > 0 &&
+		p.Screen.ScaleFactor > 0 && p.HardwareConcurrency > 0 && len(p.Languages) > 0
+}
+
+// SetFingerprintProfile makes the masking scripts present p's identity
+func (s *StealthManager) SetFingerprintProfile(p *FingerprintProfile) {
+	s.profile = p
+}
+
+// FingerprintProfile returns the identity in use, or nil before launch
+func (s *StealthManager) FingerprintProfile() *FingerprintProfile {
+	return s.profile
+}
+
+// hardwareConcurrency returns the core count to report, fixed by the profile
+func (s *StealthManager) hardwareConcurrency() int {
+	if s.profile != nil {
+		return s.profile.HardwareConcurrency
+	}
+	return s.randomHardwareConcurrency()
+}
+
+// LanguagesJS returns navigator.languages as a JavaScript array literal
+func (s *StealthManager) LanguagesJS() string {
+	languages := defaultLanguages
+	if s.profile != nil {
+		languages = s.profile.Languages
+	}
+
+	quoted := make([]string, len(languages))
+	for i, lang := range languages {
+		quoted[i] = strconv.Quote(lang)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
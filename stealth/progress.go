@@ -0,0 +1,39 @@
+// Package stealth - progress.go handles the periodic one-line session progress summary
+package stealth
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetProgressInterval sets how often, at most, RecordAction and
+// RecordFailure log the session progress line (0 disables it)
+func (r *RateLimiter) SetProgressInterval(interval time.Duration) {
+	r.progressInterval = interval
+}
+
+// RecordFailure counts a failed connect or message for the progress line
+func (r *RateLimiter) RecordFailure(actionType string) {
+	r.sessionFailures++
+	r.logger.WithField("action_type", actionType).Debug("Failure recorded")
+	r.maybeLogProgress()
+}
+
+// SessionProgress summarizes this run's activity against today's caps, e.g.
+// "Session: 12 connects (13 remaining today), 4 messages, 2 failures"
+func (r *RateLimiter) SessionProgress() string {
+	return fmt.Sprintf("Session: %d connects (%d remaining today), %d messages, %d failures",
+		r.sessionCounts["connection"], r.GetRemainingActions("connection"),
+		r.sessionCounts["message"], r.sessionFailures)
+}
+
+// maybeLogProgress logs SessionProgress when the interval has passed since
+// the last time. Hooked into recording rather than a timer so it only
+// repeats when something changed and never races the run loop.
+func (r *RateLimiter) maybeLogProgress() {
+	if r.progressInterval <= 0 || time.Since(r.lastProgress) < r.progressInterval {
+		return
+	}
+	r.lastProgress = time.Now()
+	r.logger.Info(r.SessionProgress())
+}
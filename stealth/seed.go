@@ -0,0 +1,36 @@
+// Package stealth - seed.go handles seeding randomness for reproducible runs
+package stealth
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Random streams derived from one seed, so components seeded alike don't
+// replay each other's sequences
+const (
+	StreamStealth int64 = iota + 1
+	StreamScheduler
+	StreamRateLimiter
+	StreamConnection
+	StreamEnrich // offset by the worker index, see search.EnrichProfiles
+)
+
+// NewRand returns a random source for a component. A zero seed is time-based;
+// otherwise the same seed and stream always give the same sequence.
+func NewRand(seed, stream int64) *rand.Rand {
+	if seed == 0 {
+		return rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return rand.New(rand.NewSource(seed*1000003 + stream))
+}
+
+// SetSeed reseeds the scheduler's randomness (0 = time-based)
+func (s *Scheduler) SetSeed(seed int64) {
+	s.rand = NewRand(seed, StreamScheduler)
+}
+
+// SetSeed reseeds the rate limiter's randomness (0 = time-based)
+func (r *RateLimiter) SetSeed(seed int64) {
+	r.rand = NewRand(seed, StreamRateLimiter)
+}
@@ -0,0 +1,189 @@
+// Package stealth - selftest.go handles checking the anti-detection layer against a test page
+package stealth
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-rod/rod"
+)
+
+// SelfTestCheck is the outcome of one self-test check
+type SelfTestCheck struct {
+	Name   string
+	Passed bool
+	Detail string
+}
+
+// SelfTestPage is the local page -selftest loads when no URL is given
+const SelfTestPage = `<!DOCTYPE html>
+<html><head><title>Stealth self-test</title></head>
+<body style="width:1200px;height:700px"><h1>Stealth self-test</h1></body></html>`
+
+// selfTestRecorder records mouse moves and key timings and adds a text
+// field, so the checks work on any page, including third-party bot tests
+const selfTestRecorder = `() => {
+	window.__selftest = {moves: [], keys: []};
+	document.addEventListener('mousemove', e => window.__selftest.moves.push([e.clientX, e.clientY]), true);
+	document.addEventListener('keydown', () => window.__selftest.keys.push(performance.now()), true);
+	const input = document.createElement('input');
+	input.id = '__selftest_input';
+	input.style.cssText = 'position:fixed;top:20px;left:20px;width:300px;z-index:2147483647';
+	document.body.appendChild(input);
+}`
+
+// selfTestPhrase is typed to measure inter-key timing
+const selfTestPhrase = "the quick brown fox jumps over the lazy dog"
+
+// Self-test thresholds
+const (
+	minPathDeviation = 3.0  // px a mouse path must bend away from a straight line
+	minKeyInterval   = 30.0 // ms; faster is machine-like
+	maxKeyInterval   = 1000.0
+	minKeyVariation  = 0.15 // coefficient of variation of key intervals
+)
+
+// RunSelfTest checks the current page: navigator.webdriver is hidden,
+// plugins are present, mouse paths curve and typing has human timing
+func (s *StealthManager) RunSelfTest(page *rod.Page) []SelfTestCheck {
+	checks := []SelfTestCheck{s.checkWebdriver(page), s.checkPlugins(page)}
+
+	if _, err := page.Eval(selfTestRecorder); err != nil {
+		return append(checks, SelfTestCheck{Name: "event recorder", Detail: err.Error()})
+	}
+	return append(checks, s.checkMousePath(page), s.checkTypingRhythm(page))
+}
+
+// checkWebdriver passes when navigator.webdriver isn't true
+func (s *StealthManager) checkWebdriver(page *rod.Page) SelfTestCheck {
+	check := SelfTestCheck{Name: "navigator.webdriver hidden"}
+	res, err := page.Eval(`() => navigator.webdriver`)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.Passed = res.Value.Nil() || !res.Value.Bool()
+	check.Detail = fmt.Sprintf("navigator.webdriver = %s", res.Value.String())
+	return check
+}
+
+// checkPlugins passes when navigator.plugins isn't empty, as in headless Chrome
+func (s *StealthManager) checkPlugins(page *rod.Page) SelfTestCheck {
+	check := SelfTestCheck{Name: "plugins spoofed"}
+	res, err := page.Eval(`() => navigator.plugins.length`)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	count := res.Value.Int()
+	check.Passed = count > 0
+	check.Detail = fmt.Sprintf("%d plugins", count)
+	return check
+}
+
+// checkMousePath moves the mouse across the page and passes when the
+// recorded path bends away from a straight line
+func (s *StealthManager) checkMousePath(page *rod.Page) SelfTestCheck {
+	check := SelfTestCheck{Name: "mouse path non-linear"}
+
+	start := Point{X: 150 + s.rand.Float64()*100, Y: 150 + s.rand.Float64()*100}
+	s.mouse = start
+	if err := s.MoveMouse(page, start.X+500, start.Y+250); err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	res, err := page.Eval(`() => window.__selftest.moves`)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	var points []Point
+	for _, p := range res.Value.Arr() {
+		xy := p.Arr()
+		if len(xy) == 2 {
+			points = append(points, Point{X: xy[0].Num(), Y: xy[1].Num()})
+		}
+	}
+
+	deviation := maxPathDeviation(points)
+	check.Passed = len(points) >= 5 && deviation >= minPathDeviation
+	check.Detail = fmt.Sprintf("%d events, max %.1fpx from a straight line", len(points), deviation)
+	return check
+}
+
+// checkTypingRhythm types a phrase and passes when the gaps between keys
+// are human-paced and uneven
+func (s *StealthManager) checkTypingRhythm(page *rod.Page) SelfTestCheck {
+	check := SelfTestCheck{Name: "typing rhythm realistic"}
+
+	input, err := page.Element("#__selftest_input")
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	if err := s.HumanType(page, input, selfTestPhrase); err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+
+	res, err := page.Eval(`() => window.__selftest.keys`)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	var times []float64
+	for _, t := range res.Value.Arr() {
+		times = append(times, t.Num())
+	}
+
+	mean, variation := keyIntervalStats(times)
+	check.Passed = len(times) > 1 && mean >= minKeyInterval && mean <= maxKeyInterval && variation >= minKeyVariation
+	check.Detail = fmt.Sprintf("%d keys, mean gap %.0fms, variation %.2f", len(times), mean, variation)
+	return check
+}
+
+// maxPathDeviation returns how far, at most, a path strays from the straight
+// line between its first and last points
+func maxPathDeviation(points []Point) float64 {
+	if len(points) < 3 {
+		return 0
+	}
+	a, b := points[0], points[len(points)-1]
+	length := math.Hypot(b.X-a.X, b.Y-a.Y)
+	if length == 0 {
+		return 0
+	}
+
+	max := 0.0
+	for _, p := range points[1 : len(points)-1] {
+		d := math.Abs((b.X-a.X)*(a.Y-p.Y)-(a.X-p.X)*(b.Y-a.Y)) / length
+		if d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// keyIntervalStats returns the mean gap between key timestamps (ms) and its
+// coefficient of variation
+func keyIntervalStats(times []float64) (mean, variation float64) {
+	if len(times) < 2 {
+		return 0, 0
+	}
+	gaps := make([]float64, len(times)-1)
+	for i := range gaps {
+		gaps[i] = times[i+1] - times[i]
+		mean += gaps[i]
+	}
+	mean /= float64(len(gaps))
+	if mean == 0 {
+		return 0, 0
+	}
+
+	var sq float64
+	for _, g := range gaps {
+		sq += (g - mean) * (g - mean)
+	}
+	return mean, math.Sqrt(sq/float64(len(gaps))) / mean
+}
@@ -3,8 +3,12 @@
 package stealth
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
@@ -12,22 +16,46 @@ import (
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/nikshitha/linkedin-automation-poc/config"
 	"github.com/nikshitha/linkedin-automation-poc/logger"
+	"github.com/nikshitha/linkedin-automation-poc/storage"
 )
 
+// errNoPage is returned by interactions called without a browser page, as
+// when a manager runs against a fake browser.Pager. Idle behaviours (scrolling,
+// wandering, masking) are skipped instead.
+var errNoPage = errors.New("no browser page")
+
+// ErrRateLimited is wrapped by errors for actions refused because a
+// configured rate limit has no room left
+var ErrRateLimited = errors.New("rate limit reached")
+
 // StealthManager handles all anti-detection operations
 type StealthManager struct {
 	config *config.StealthConfig
 	logger *logger.Logger
 	rand   *rand.Rand
+	tracer *logger.Tracer
+
+	// Identity presented by the browser; set once it is launched
+	profile *FingerprintProfile
+
+	// Last known cursor position; movements start from here
+	mouse Point
 }
 
 // NewStealthManager creates a new stealth manager
 func NewStealthManager(cfg *config.StealthConfig, log *logger.Logger) *StealthManager {
-	return &StealthManager{
+	s := &StealthManager{
 		config: cfg,
 		logger: log.WithModule("stealth"),
-		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		rand:   NewRand(cfg.RandomSeed, StreamStealth),
 	}
+	s.mouse = s.initialMousePosition()
+	return s
+}
+
+// SetTracer sets the decision tracer that records every delay slept
+func (s *StealthManager) SetTracer(t *logger.Tracer) {
+	s.tracer = t
 }
 
 // Point represents a 2D coordinate
@@ -41,8 +69,11 @@ type Point struct {
 
 // MoveMouse moves the mouse from current position to target with human-like motion
 func (s *StealthManager) MoveMouse(page *rod.Page, targetX, targetY float64) error {
-	// Get current mouse position (approximate from page center if unknown)
-	currentX, currentY := s.getApproximateMousePosition(page)
+	if page == nil {
+		return errNoPage
+	}
+	// Start from wherever the last movement left the cursor
+	currentX, currentY := s.mouse.X, s.mouse.Y
 
 	// Generate Bézier curve path
 	points := s.generateBezierPath(
@@ -65,6 +96,7 @@ func (s *StealthManager) MoveMouse(page *rod.Page, targetX, targetY float64) err
 		if err != nil {
 			return err
 		}
+		s.mouse = point
 
 		// Micro-corrections at the end
 		if s.config.MouseMicroCorrect && i > len(points)-5 {
@@ -149,7 +181,9 @@ func (s *StealthManager) addMicroCorrection(page *rod.Page, x, y float64) {
 	microX := x + (s.rand.Float64()-0.5)*2
 	microY := y + (s.rand.Float64()-0.5)*2
 	time.Sleep(time.Duration(5+s.rand.Intn(10)) * time.Millisecond)
-	page.Mouse.MoveLinear(proto.NewPoint(microX, microY), 1)
+	if page.Mouse.MoveLinear(proto.NewPoint(microX, microY), 1) == nil {
+		s.mouse = Point{microX, microY}
+	}
 }
 
 // calculateMovementDelay returns variable delay (ease-in-out effect)
@@ -166,10 +200,19 @@ func (s *StealthManager) calculateMovementDelay(step, totalSteps int) int {
 	return delay + s.rand.Intn(3)
 }
 
-// getApproximateMousePosition gets current mouse position
-func (s *StealthManager) getApproximateMousePosition(page *rod.Page) (float64, float64) {
-	// Default to page center if position unknown
-	return 683, 384 // Common half-HD viewport center
+// initialMousePosition picks a plausible resting place for the cursor before
+// the first movement: somewhere in the middle of a half-HD viewport, away
+// from the edges
+func (s *StealthManager) initialMousePosition() Point {
+	return Point{
+		X: 200 + s.rand.Float64()*966, // 1366 wide
+		Y: 150 + s.rand.Float64()*468, // 768 tall
+	}
+}
+
+// MousePosition returns the last known cursor position
+func (s *StealthManager) MousePosition() Point {
+	return s.mouse
 }
 
 // ==============================================================================
@@ -178,13 +221,20 @@ func (s *StealthManager) getApproximateMousePosition(page *rod.Page) (float64, f
 
 // RandomDelay adds a randomized delay between min and max milliseconds
 func (s *StealthManager) RandomDelay(minMs, maxMs int) {
+	s.randomDelay("random delay", minMs, maxMs)
+}
+
+// randomDelay sleeps between min and max milliseconds, logging the reason
+func (s *StealthManager) randomDelay(reason string, minMs, maxMs int) {
 	delay := minMs + s.rand.Intn(maxMs-minMs+1)
+	s.tracer.Record("stealth", "delay", map[string]interface{}{"duration_ms": delay})
+	s.logger.Delay(reason, time.Duration(delay)*time.Millisecond)
 	time.Sleep(time.Duration(delay) * time.Millisecond)
 }
 
 // ActionDelay adds human-like delay between actions
 func (s *StealthManager) ActionDelay() {
-	s.RandomDelay(s.config.ActionDelayMin, s.config.ActionDelayMax)
+	s.randomDelay("between actions", s.config.ActionDelayMin, s.config.ActionDelayMax)
 }
 
 // ThinkingDelay simulates human cognitive processing time
@@ -195,13 +245,61 @@ func (s *StealthManager) ThinkingDelay() {
 	if s.rand.Float64() < 0.2 {
 		baseDelay += 2000 + s.rand.Intn(3000)
 	}
+	s.tracer.Record("stealth", "thinking_delay", map[string]interface{}{"duration_ms": baseDelay})
+	s.logger.Delay("thinking", time.Duration(baseDelay)*time.Millisecond)
 	time.Sleep(time.Duration(baseDelay) * time.Millisecond)
-	s.logger.StealthAction("thinking_delay", map[string]interface{}{"duration_ms": baseDelay})
+}
+
+// readingTextScript returns the length of the page's visible text
+const readingTextScript = `() => document.body ? document.body.innerText.length : 0`
+
+// ReadingDelay dwells on the page for as long as a person would take to skim
+// its visible text, bounded by the configured reading delay range. Falls back
+// to ThinkingDelay when the text length can't be read.
+func (s *StealthManager) ReadingDelay(page *rod.Page) {
+	if page == nil {
+		return
+	}
+	res, err := page.Eval(readingTextScript)
+	if err != nil {
+		s.ThinkingDelay()
+		return
+	}
+
+	chars := res.Value.Int()
+	// Reading speed varies by +/-25% between pages
+	delay := s.readingDuration(chars, 0.75+s.rand.Float64()*0.5)
+
+	s.tracer.Record("stealth", "reading_delay", map[string]interface{}{
+		"duration_ms": delay.Milliseconds(),
+		"chars":       chars,
+	})
+	s.logger.WithField("chars", chars).Delay("reading the page", delay)
+	time.Sleep(delay)
+}
+
+// readingDuration converts a text length into a reading time: it scales
+// from the minimum reading delay for an empty page to the maximum at
+// reading_max_chars, times factor, clamped to the reading delay bounds
+func (s *StealthManager) readingDuration(chars int, factor float64) time.Duration {
+	share := 1.0
+	if s.config.ReadingMaxChars > 0 && chars < s.config.ReadingMaxChars {
+		share = float64(chars) / float64(s.config.ReadingMaxChars)
+	}
+	span := float64(s.config.ReadingDelayMax - s.config.ReadingDelayMin)
+	ms := int((float64(s.config.ReadingDelayMin) + span*share) * factor)
+	if ms < s.config.ReadingDelayMin {
+		ms = s.config.ReadingDelayMin
+	}
+	if ms > s.config.ReadingDelayMax {
+		ms = s.config.ReadingDelayMax
+	}
+	return time.Duration(ms) * time.Millisecond
 }
 
 // PageLoadDelay waits for page to fully load with natural variation
 func (s *StealthManager) PageLoadDelay() {
-	s.RandomDelay(s.config.PageLoadWaitMin, s.config.PageLoadWaitMax)
+	s.randomDelay("page load", s.config.PageLoadWaitMin, s.config.PageLoadWaitMax)
 }
 
 // ==============================================================================
@@ -210,6 +308,9 @@ func (s *StealthManager) PageLoadDelay() {
 
 // ApplyFingerprintMasking applies various browser fingerprint modifications
 func (s *StealthManager) ApplyFingerprintMasking(page *rod.Page) error {
+	if page == nil {
+		return nil
+	}
 	scripts := []string{}
 
 	// Disable webdriver flag
@@ -239,7 +340,7 @@ func (s *StealthManager) ApplyFingerprintMasking(page *rod.Page) error {
 
 		// Override languages
 		Object.defineProperty(navigator, 'languages', {
-			get: () => ['en-US', 'en']
+			get: () => `+s.LanguagesJS()+`
 		});
 
 		// Override permissions
@@ -258,7 +359,7 @@ func (s *StealthManager) ApplyFingerprintMasking(page *rod.Page) error {
 	// Mask hardware concurrency
 	scripts = append(scripts, `
 		Object.defineProperty(navigator, 'hardwareConcurrency', {
-			get: () => `+s.randomHardwareConcurrency()+`
+			get: () => `+strconv.Itoa(s.hardwareConcurrency())+`
 		});
 	`)
 
@@ -303,9 +404,10 @@ func (s *StealthManager) GetRandomViewport() (int, int) {
 	return vp.width + s.rand.Intn(20) - 10, vp.height + s.rand.Intn(20) - 10
 }
 
-func (s *StealthManager) randomHardwareConcurrency() string {
+// randomHardwareConcurrency picks a common CPU core count
+func (s *StealthManager) randomHardwareConcurrency() int {
 	cores := []int{4, 8, 12, 16}
-	return string(rune('0' + cores[s.rand.Intn(len(cores))]))
+	return cores[s.rand.Intn(len(cores))]
 }
 
 // ==============================================================================
@@ -314,6 +416,9 @@ func (s *StealthManager) randomHardwareConcurrency() string {
 
 // HumanScroll performs natural scrolling behavior on the page
 func (s *StealthManager) HumanScroll(page *rod.Page, direction string, amount int) error {
+	if page == nil {
+		return nil
+	}
 	// Vary the scroll amount slightly
 	actualAmount := amount + s.rand.Intn(100) - 50
 
@@ -373,6 +478,9 @@ func (s *StealthManager) scrollBack(page *rod.Page, originalDirection string, am
 
 // ScrollToElement scrolls to bring an element into view with natural motion
 func (s *StealthManager) ScrollToElement(page *rod.Page, selector string) error {
+	if page == nil {
+		return errNoPage
+	}
 	el, err := page.Element(selector)
 	if err != nil {
 		return err
@@ -408,38 +516,63 @@ func (s *StealthManager) ScrollToElement(page *rod.Page, selector string) error
 // TECHNIQUE 5: Realistic Typing Simulation
 // ==============================================================================
 
-// HumanType types text with human-like characteristics
+// HumanType types text with human-like characteristics. Text is typed one
+// grapheme cluster at a time so emoji sequences stay intact, and line breaks
+// are sent as Shift+Enter so they create new lines in message composers.
 func (s *StealthManager) HumanType(page *rod.Page, element *rod.Element, text string) error {
-	runes := []rune(text)
+	if page == nil {
+		return errNoPage
+	}
+	clusters := splitGraphemes(text)
+	mistakes, uncorrected := 0, 0
 
-	for i := 0; i < len(runes); i++ {
-		char := runes[i]
+	for i := 0; i < len(clusters); i++ {
+		cluster := clusters[i]
 
 		// Random delay between keystrokes
-		delay := s.config.TypingDelayMin + s.rand.Intn(s.config.TypingDelayMax-s.config.TypingDelayMin)
+		delay := s.keystrokeDelay()
 
 		// Occasionally add extra delay (thinking)
-		if s.rand.Float64() < 0.05 {
-			delay += 200 + s.rand.Intn(400)
-		}
+		delay += s.thinkingPause()
 
-		// Simulate typing mistakes
-		if s.config.TypingMistakeRate > 0 && s.rand.Float64() < s.config.TypingMistakeRate {
+		// Simulate typing mistakes (only on plain single-rune characters)
+		char, plain := plainRune(cluster)
+		if mistakeRate := s.typingMistakeRate(); plain && mistakeRate > 0 && s.rand.Float64() < mistakeRate {
 			// Type wrong character
 			wrongChar := s.getAdjacentKey(char)
 			err := element.Input(string(wrongChar))
 			if err != nil {
 				return err
 			}
+			mistakes++
+
+			plan := s.planTypoCorrection(typeAheadLimit(clusters[i+1:]))
+
+			// Very rarely a minor typo slips through unnoticed
+			if !plan.correct && wrongChar != char {
+				uncorrected++
+				time.Sleep(time.Duration(delay) * time.Millisecond)
+				continue
+			}
+
+			// Sometimes keep typing a few characters before noticing
+			for j := 1; j <= plan.lag; j++ {
+				time.Sleep(time.Duration(s.keystrokeDelay()) * time.Millisecond)
+				if err := element.Input(clusters[i+j]); err != nil {
+					return err
+				}
+			}
 			time.Sleep(time.Duration(100+s.rand.Intn(200)) * time.Millisecond)
 
-			// Delete it using Backspace key
-			page.Keyboard.Press(input.Backspace)
-			time.Sleep(time.Duration(50+s.rand.Intn(100)) * time.Millisecond)
+			// Delete back to the mistake using Backspace key
+			for j := 0; j <= plan.lag; j++ {
+				page.Keyboard.Press(input.Backspace)
+				time.Sleep(time.Duration(50+s.rand.Intn(100)) * time.Millisecond)
+			}
 		}
 
 		// Type the correct character
-		err := element.Input(string(char))
+		err := s.typeCluster(page, element, cluster)
 		if err != nil {
 			return err
 		}
@@ -448,13 +581,77 @@ func (s *StealthManager) HumanType(page *rod.Page, element *rod.Element, text st
 	}
 
 	s.logger.StealthAction("typing", map[string]interface{}{
-		"length":   len(text),
-		"mistakes": int(float64(len(text)) * s.config.TypingMistakeRate),
+		"length":      len(text),
+		"mistakes":    mistakes,
+		"uncorrected": uncorrected,
 	})
 
 	return nil
 }
 
+// typeCluster types one grapheme cluster, turning line breaks into Shift+Enter
+func (s *StealthManager) typeCluster(page *rod.Page, element *rod.Element, cluster string) error {
+	if isLineBreak(cluster) {
+		return page.KeyActions().Press(input.ShiftLeft).Type(input.Enter).Do()
+	}
+	return element.Input(cluster)
+}
+
+// plainRune returns the rune of a single-rune, non-newline cluster
+func plainRune(cluster string) (rune, bool) {
+	runes := []rune(cluster)
+	if len(runes) != 1 || isLineBreak(cluster) {
+		return 0, false
+	}
+	return runes[0], true
+}
+
+// typeAheadLimit counts the clusters that can be typed past a typo before it is
+// corrected; typing ahead stops at a line break so a correction never spans lines
+func typeAheadLimit(rest []string) int {
+	for i, cluster := range rest {
+		if isLineBreak(cluster) {
+			return i
+		}
+	}
+	return len(rest)
+}
+
+// Typo correction tuning
+const (
+	maxTypoCorrectionLag  = 4    // most characters typed before noticing a typo
+	uncorrectedTypoFactor = 0.05 // share of variance applied to leaving a typo in place
+)
+
+// typoCorrection describes how a simulated typo gets handled
+type typoCorrection struct {
+	lag     int  // correct characters typed after the typo before noticing it
+	correct bool // false leaves the typo in the text
+}
+
+// planTypoCorrection decides how a typo is corrected. With no correction variance
+// every typo is fixed immediately; otherwise some are noticed a few characters
+// late and, very rarely, one is left uncorrected.
+func (s *StealthManager) planTypoCorrection(remaining int) typoCorrection {
+	variance := s.config.TypingCorrectionVariance
+	if variance <= 0 {
+		return typoCorrection{correct: true}
+	}
+
+	if s.rand.Float64() < variance*uncorrectedTypoFactor {
+		return typoCorrection{correct: false}
+	}
+
+	plan := typoCorrection{correct: true}
+	if s.rand.Float64() < variance {
+		plan.lag = 1 + s.rand.Intn(maxTypoCorrectionLag)
+	}
+	if plan.lag > remaining {
+		plan.lag = remaining
+	}
+	return plan
+}
+
 // getAdjacentKey returns a key adjacent to the given key on a QWERTY keyboard
 func (s *StealthManager) getAdjacentKey(char rune) rune {
 	adjacentKeys := map[rune][]rune{
@@ -507,30 +704,107 @@ func (s *StealthManager) getAdjacentKey(char rune) rune {
 
 // HoverElement hovers over an element naturally
 func (s *StealthManager) HoverElement(page *rod.Page, element *rod.Element) error {
+	_, err := s.hover(page, element)
+	return err
+}
+
+// hover scrolls the element into view, moves the mouse to a random point on
+// it, lingers, and returns the element's quad at the time of the move
+func (s *StealthManager) hover(page *rod.Page, element *rod.Element) ([]float64, error) {
+	if page == nil {
+		return nil, errNoPage
+	}
+	if err := element.ScrollIntoView(); err != nil {
+		return nil, err
+	}
 	box, err := element.Shape()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(box.Quads) == 0 {
+		return nil, fmt.Errorf("element is not visible")
 	}
 
-	quad := box.Quads[0]
-	// Random position within element bounds
-	x := quad[0] + (quad[2]-quad[0])*s.rand.Float64()*0.6 + (quad[2]-quad[0])*0.2
-	y := quad[1] + (quad[5]-quad[1])*s.rand.Float64()*0.6 + (quad[5]-quad[1])*0.2
-
-	err = s.MoveMouse(page, x, y)
-	if err != nil {
-		return err
+	quad := []float64(box.Quads[0])
+	x, y := s.hoverPoint(quad)
+	if err := s.MoveMouse(page, x, y); err != nil {
+		return nil, err
 	}
 
 	// Hover for a natural duration
 	hoverTime := 200 + s.rand.Intn(500)
 	time.Sleep(time.Duration(hoverTime) * time.Millisecond)
 
-	return nil
+	return quad, nil
+}
+
+// hoverPoint picks a random point in the central ClickAreaPercent of an
+// element's quad, so hovers and clicks don't always hit dead center
+func (s *StealthManager) hoverPoint(quad []float64) (float64, float64) {
+	area := float64(s.config.ClickAreaPercent) / 100
+	margin := (1 - area) / 2
+
+	width := quad[2] - quad[0]
+	height := quad[5] - quad[1]
+	x := quad[0] + width*(margin+s.rand.Float64()*area)
+	y := quad[1] + height*(margin+s.rand.Float64()*area)
+	return x, y
+}
+
+// WaitForElementStable polls an element's bounding box every interval until
+// it reads the same for checks consecutive polls. It gives up with an error
+// after a few times that many polls if the element keeps moving.
+func (s *StealthManager) WaitForElementStable(el *rod.Element, checks int, interval time.Duration) error {
+	if checks < 1 {
+		checks = 1
+	}
+
+	var last []float64
+	stable := 0
+	for attempt := 0; attempt < checks*5; attempt++ {
+		box, err := el.Shape()
+		if err != nil {
+			return fmt.Errorf("failed to read element position: %w", err)
+		}
+		if len(box.Quads) == 0 {
+			return fmt.Errorf("element is not visible")
+		}
+
+		quad := []float64(box.Quads[0])
+		if last != nil && sameQuad(last, quad) {
+			stable++
+			if stable >= checks {
+				return nil
+			}
+		} else {
+			stable = 0
+		}
+		last = quad
+
+		time.Sleep(interval)
+	}
+
+	return fmt.Errorf("element still moving after %d checks", checks*5)
+}
+
+// sameQuad reports whether two element quads are within half a pixel
+func sameQuad(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 0.5 {
+			return false
+		}
+	}
+	return true
 }
 
 // RandomMouseWander performs random mouse movements to simulate idle behavior
 func (s *StealthManager) RandomMouseWander(page *rod.Page) error {
+	if page == nil {
+		return nil
+	}
 	numMoves := 2 + s.rand.Intn(4)
 
 	for i := 0; i < numMoves; i++ {
@@ -560,6 +834,7 @@ type Scheduler struct {
 	config *config.ScheduleConfig
 	logger *logger.Logger
 	rand   *rand.Rand
+	tracer *logger.Tracer
 }
 
 // NewScheduler creates a new activity scheduler
@@ -571,6 +846,11 @@ func NewScheduler(cfg *config.ScheduleConfig, log *logger.Logger) *Scheduler {
 	}
 }
 
+// SetTracer sets the decision tracer
+func (s *Scheduler) SetTracer(t *logger.Tracer) {
+	s.tracer = t
+}
+
 // IsWithinOperatingHours checks if current time is within allowed hours
 func (s *Scheduler) IsWithinOperatingHours() bool {
 	if !s.config.Enabled {
@@ -605,6 +885,28 @@ func (s *Scheduler) WaitForOperatingHours() {
 		s.logger.Info("Waiting for operating hours...")
 		time.Sleep(5 * time.Minute)
 	}
+	s.WaitStartJitter()
+}
+
+// startJitter picks a random delay of 0..StartJitterMinutes so that runs
+// launched by a fixed cron don't begin at the same minute every day
+func (s *Scheduler) startJitter() time.Duration {
+	if !s.config.Enabled || s.config.StartJitterMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(s.rand.Int63n(int64(s.config.StartJitterMinutes)*int64(time.Minute) + 1))
+}
+
+// WaitStartJitter waits a random delay before activity begins
+func (s *Scheduler) WaitStartJitter() {
+	jitter := s.startJitter()
+	if jitter <= 0 {
+		return
+	}
+	s.logger.Infof("Delaying start by %s (start jitter up to %d minutes)",
+		jitter.Round(time.Second), s.config.StartJitterMinutes)
+	s.tracer.Record("scheduler", "start_jitter", map[string]interface{}{"duration": jitter.Round(time.Second).String()})
+	time.Sleep(jitter)
 }
 
 // ShouldTakeBreak determines if it's time for a break
@@ -625,6 +927,7 @@ func (s *Scheduler) ShouldTakeBreak(sessionStart time.Time) bool {
 func (s *Scheduler) TakeBreak() {
 	breakDuration := s.config.BreakMinMin + s.rand.Intn(s.config.BreakMinMax-s.config.BreakMinMin+1)
 	s.logger.Infof("Taking a break for %d minutes", breakDuration)
+	s.tracer.Record("scheduler", "break", map[string]interface{}{"duration_min": breakDuration})
 	time.Sleep(time.Duration(breakDuration) * time.Minute)
 }
 
@@ -640,6 +943,25 @@ type RateLimiter struct {
 	lastReset   time.Time
 	lastAction  time.Time
 	rand        *rand.Rand
+	tracer      *logger.Tracer
+	accountAge  int // days; -1 while unknown (no warmup ramp applied)
+	blockedUntil map[string]time.Time // LinkedIn-imposed blocks, independent of local counts
+	weeklyConnections int // sent in the trailing 7 days; seeded from the database
+	weeklySource      func() (int, error) // reloads weeklyConnections at the daily reset
+
+	// This run's actions and failures, for the periodic progress line
+	sessionCounts    map[string]int
+	sessionFailures  int
+	progressInterval time.Duration // 0 disables
+	lastProgress     time.Time
+
+	watchdog *Watchdog // reset on every recorded action
+
+	// Burst allowance token bucket; see takeBurstToken
+	burstTokens   float64
+	burstRefilled time.Time
+
+	reserveMu sync.Mutex // serializes ReserveProfileView across goroutines
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -651,13 +973,116 @@ func NewRateLimiter(cfg *config.RateLimitConfig, log *logger.Logger) *RateLimite
 		lastReset:    time.Now(),
 		lastAction:   time.Now(),
 		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		accountAge:   -1,
+		blockedUntil: make(map[string]time.Time),
+		sessionCounts: make(map[string]int),
+		lastProgress: time.Now(),
 	}
 }
 
-// CanPerformAction checks if an action can be performed within rate limits
-func (r *RateLimiter) CanPerformAction(actionType string) bool {
-	r.checkReset()
+// SetTracer sets the decision tracer that records every rate-limit check
+func (r *RateLimiter) SetTracer(t *logger.Tracer) {
+	r.tracer = t
+}
 
+// SetWatchdog sets the watchdog reset by every recorded action
+func (r *RateLimiter) SetWatchdog(w *Watchdog) {
+	r.watchdog = w
+}
+
+// SetAccountAge applies the account maturity warmup ramp for an account ageDays old
+func (r *RateLimiter) SetAccountAge(ageDays int) {
+	r.accountAge = ageDays
+
+	maturity := r.config.AccountMaturity
+	if !maturity.Enabled {
+		return
+	}
+
+	r.logger.WithFields(map[string]interface{}{
+		"account_age_days": ageDays,
+		"ramp_percent":     WarmupPercent(maturity, ageDays),
+		"connections":      r.limitFor("connection"),
+		"messages":         r.limitFor("message"),
+		"profile_views":    r.limitFor("profile_view"),
+		"searches":         r.limitFor("search"),
+	}).Info("Account warmup ramp applied")
+}
+
+// SetWeeklyConnectionCount seeds the connections already sent in the trailing
+// 7 days, which max_connections_per_week is checked against
+func (r *RateLimiter) SetWeeklyConnectionCount(count int) {
+	r.weeklyConnections = count
+}
+
+// SetWeeklyConnectionSource seeds the trailing 7-day connection count from
+// load and reloads it at every daily reset, so connections that drop out of
+// the window stop counting in runs spanning several days
+func (r *RateLimiter) SetWeeklyConnectionSource(load func() (int, error)) {
+	r.weeklySource = load
+	r.reloadWeeklyConnections()
+}
+
+// reloadWeeklyConnections refreshes the weekly count from its source, keeping
+// the running count if it can't be read
+func (r *RateLimiter) reloadWeeklyConnections() {
+	if r.weeklySource == nil {
+		return
+	}
+	count, err := r.weeklySource()
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to reload weekly connection count")
+		return
+	}
+	r.weeklyConnections = count
+}
+
+// weeklyConnectionLimit returns the effective weekly connection cap, or -1 if
+// there is none
+func (r *RateLimiter) weeklyConnectionLimit() int {
+	if r.config.MaxConnectionsPerWeek <= 0 {
+		return -1
+	}
+	return WarmupLimit(r.config.AccountMaturity, "connection", r.config.MaxConnectionsPerWeek, r.accountAge)
+}
+
+// WarmupPercent returns the share (0-100) of the configured limits an account
+// ageDays old may use: start_percent at min_age_days rising linearly to 100 at warmup_days
+func WarmupPercent(maturity config.AccountMaturityConfig, ageDays int) int {
+	if !maturity.Enabled || ageDays >= maturity.WarmupDays {
+		return 100
+	}
+	if ageDays < maturity.MinAgeDays {
+		return maturity.StartPercent
+	}
+
+	span := maturity.WarmupDays - maturity.MinAgeDays
+	progress := ageDays - maturity.MinAgeDays
+	return maturity.StartPercent + (100-maturity.StartPercent)*progress/span
+}
+
+// WarmupLimit returns min(configured, rampLimit(ageDays)) for an action. Connection
+// requests are refused entirely until the account reaches min_age_days.
+func WarmupLimit(maturity config.AccountMaturityConfig, actionType string, configured, ageDays int) int {
+	if !maturity.Enabled || ageDays < 0 {
+		return configured
+	}
+	if actionType == "connection" && ageDays < maturity.MinAgeDays {
+		return 0
+	}
+
+	ramp := configured * WarmupPercent(maturity, ageDays) / 100
+	if ramp < 1 && configured > 0 {
+		ramp = 1
+	}
+	if ramp < configured {
+		return ramp
+	}
+	return configured
+}
+
+// limitFor returns the effective limit for an action type, or -1 if it is unlimited
+func (r *RateLimiter) limitFor(actionType string) int {
 	var limit int
 	switch actionType {
 	case "connection":
@@ -669,11 +1094,80 @@ func (r *RateLimiter) CanPerformAction(actionType string) bool {
 	case "search":
 		limit = r.config.MaxSearchesPerHour
 	default:
+		return -1
+	}
+
+	return WarmupLimit(r.config.AccountMaturity, actionType, limit, r.accountAge)
+}
+
+// BlockUntil refuses an action type until the given time, e.g. when LinkedIn
+// reports its own limit was reached. Local counts and resets don't lift it.
+func (r *RateLimiter) BlockUntil(actionType string, until time.Time) {
+	if !until.After(time.Now()) {
+		return
+	}
+
+	r.blockedUntil[actionType] = until
+	r.logger.WithFields(map[string]interface{}{
+		"action_type":   actionType,
+		"blocked_until": until.Format(time.RFC3339),
+	}).Warn("Action blocked by LinkedIn limit")
+	r.tracer.Record("rate_limiter", "blocked", map[string]interface{}{
+		"action_type":   actionType,
+		"blocked_until": until.Format(time.RFC3339),
+	})
+}
+
+// BlockedUntil returns when a LinkedIn-imposed block on an action type ends,
+// or the zero time if it isn't blocked
+func (r *RateLimiter) BlockedUntil(actionType string) time.Time {
+	until, ok := r.blockedUntil[actionType]
+	if !ok || !time.Now().Before(until) {
+		return time.Time{}
+	}
+	return until
+}
+
+// CanPerformAction checks if an action can be performed within rate limits
+func (r *RateLimiter) CanPerformAction(actionType string) bool {
+	r.checkReset()
+
+	if until := r.BlockedUntil(actionType); !until.IsZero() {
+		r.tracer.Record("rate_limiter", "check", map[string]interface{}{
+			"action_type":   actionType,
+			"allowed":       false,
+			"blocked_until": until.Format(time.RFC3339),
+		})
+		return false
+	}
+
+	if actionType == "connection" {
+		if weekly := r.weeklyConnectionLimit(); weekly >= 0 && r.weeklyConnections >= weekly {
+			r.tracer.Record("rate_limiter", "check", map[string]interface{}{
+				"action_type": "connection_weekly",
+				"current":     r.weeklyConnections,
+				"limit":       weekly,
+				"allowed":     false,
+			})
+			r.logger.RateLimit("connection_weekly", r.weeklyConnections, weekly)
+			return false
+		}
+	}
+
+	limit := r.limitFor(actionType)
+	if limit < 0 {
 		return true
 	}
 
 	current := r.actionCounts[actionType]
-	if current >= limit {
+	allowed := current < limit
+	r.tracer.Record("rate_limiter", "check", map[string]interface{}{
+		"action_type": actionType,
+		"current":     current,
+		"limit":       limit,
+		"allowed":     allowed,
+	})
+	if !allowed {
 		r.logger.RateLimit(actionType, current, limit)
 		return false
 	}
@@ -681,58 +1175,161 @@ func (r *RateLimiter) CanPerformAction(actionType string) bool {
 	return true
 }
 
+// Profile view types passed to RecordProfileView; the same values the
+// database's daily stats are kept by
+const (
+	ViewExplicit   = storage.ViewExplicit
+	ViewIncidental = storage.ViewIncidental
+)
+
+// ProfileViewAction returns the action counter a profile view is charged to.
+// Only explicit views count against max_profile_views_per_day; incidental
+// ones are already bounded by the connection and message caps.
+func ProfileViewAction(viewType string) string {
+	if viewType == ViewIncidental {
+		return "profile_view_incidental"
+	}
+	return "profile_view"
+}
+
+// RecordProfileView records a profile visit under the counter for its view type
+func (r *RateLimiter) RecordProfileView(viewType string) {
+	r.RecordAction(ProfileViewAction(viewType))
+}
+
+// ReserveProfileView checks the profile view budget, waits out the spacing
+// since the last action and charges an explicit view, all under one lock.
+// Goroutines sharing the limiter, like parallel enrichment tabs, can't both
+// take the last view or skip the spacing between their visits.
+func (r *RateLimiter) ReserveProfileView() bool {
+	r.reserveMu.Lock()
+	defer r.reserveMu.Unlock()
+
+	if !r.CanPerformAction("profile_view") {
+		return false
+	}
+	r.WaitForNextAction()
+	r.RecordProfileView(ViewExplicit)
+	return true
+}
+
 // RecordAction records that an action was performed
 func (r *RateLimiter) RecordAction(actionType string) {
 	r.actionCounts[actionType]++
+	r.sessionCounts[actionType]++
 	r.lastAction = time.Now()
+	r.watchdog.Reset()
+	if actionType == "connection" {
+		r.weeklyConnections++
+	}
 
 	r.logger.WithFields(map[string]interface{}{
 		"action_type": actionType,
 		"count":       r.actionCounts[actionType],
 	}).Debug("Action recorded")
+	r.maybeLogProgress()
 }
 
-// WaitForNextAction enforces minimum delay between actions
+// WaitForNextAction enforces minimum delay between actions. While the burst
+// allowance has tokens, actions follow each other after only a short gap.
+// A token is only spent when the normal spacing would actually make the
+// action wait, so slow stretches don't drain the allowance.
 func (r *RateLimiter) WaitForNextAction() {
 	elapsed := time.Since(r.lastAction)
+
 	minDelay := time.Duration(r.config.MinDelayBetweenActions) * time.Millisecond
 	maxDelay := time.Duration(r.config.MaxDelayBetweenActions) * time.Millisecond
 
 	// Random delay within range
 	targetDelay := minDelay + time.Duration(r.rand.Int63n(int64(maxDelay-minDelay)))
+	reason := "spacing between actions"
+	if elapsed < targetDelay && r.takeBurstToken(time.Now()) {
+		targetDelay = r.burstDelay()
+		reason = "spacing within a burst"
+	}
 
 	if elapsed < targetDelay {
 		sleepTime := targetDelay - elapsed
+		r.tracer.Record("rate_limiter", "wait", map[string]interface{}{"duration_ms": sleepTime.Milliseconds(), "reason": reason})
+		r.logger.Delay(reason, sleepTime)
 		time.Sleep(sleepTime)
 	}
 }
 
-// EnforceCooldown enforces a cooldown period
+// EnforceCooldown waits out the cooldown between workflow cycles for the current hour
 func (r *RateLimiter) EnforceCooldown() {
-	cooldownDuration := time.Duration(r.config.CooldownMinutes) * time.Minute
-	r.logger.Infof("Enforcing cooldown for %d minutes", r.config.CooldownMinutes)
-	time.Sleep(cooldownDuration)
+	hour := time.Now().Hour()
+	minutes := r.cooldownMinutes(hour)
+	r.logger.Infof("Enforcing cooldown for %d minutes", minutes)
+	r.tracer.Record("rate_limiter", "cooldown", map[string]interface{}{"duration_min": minutes, "hour": hour})
+	time.Sleep(time.Duration(minutes) * time.Minute)
+}
+
+// cooldownMinutes returns the cooldown between workflow cycles for an hour
+// of the day: its hourly_cooldown_minutes entry, or cooldown_minutes
+func (r *RateLimiter) cooldownMinutes(hour int) int {
+	if minutes, ok := r.config.HourlyCooldownMinutes[hour]; ok {
+		return minutes
+	}
+	return r.config.CooldownMinutes
+}
+
+// FailureBackoff decides what follows the given number of consecutive
+// failures: a cooldown that starts at failure_cooldown_minutes once
+// failure_cooldown_after is reached and doubles with each further failure
+// (capped at 8x), or aborting the batch at failure_abort_after.
+func FailureBackoff(cfg config.RateLimitConfig, consecutive int) (time.Duration, bool) {
+	if cfg.FailureAbortAfter > 0 && consecutive >= cfg.FailureAbortAfter {
+		return 0, true
+	}
+	if cfg.FailureCooldownAfter <= 0 || consecutive < cfg.FailureCooldownAfter {
+		return 0, false
+	}
+
+	steps := consecutive - cfg.FailureCooldownAfter
+	if steps > 3 {
+		steps = 3
+	}
+	return time.Duration(cfg.FailureCooldownMinutes) * time.Minute << uint(steps), false
 }
 
 // GetRemainingActions returns how many more actions of a type can be performed
 func (r *RateLimiter) GetRemainingActions(actionType string) int {
 	r.checkReset()
 
-	var limit int
-	switch actionType {
-	case "connection":
-		limit = r.config.MaxConnectionsPerDay
-	case "message":
-		limit = r.config.MaxMessagesPerDay
-	case "profile_view":
-		limit = r.config.MaxProfileViewsPerDay
-	case "search":
-		limit = r.config.MaxSearchesPerHour
-	default:
+	if !r.BlockedUntil(actionType).IsZero() {
+		return 0
+	}
+
+	limit := r.limitFor(actionType)
+	if limit < 0 {
 		return 999
 	}
 
-	return limit - r.actionCounts[actionType]
+	remaining := limit - r.actionCounts[actionType]
+	if actionType == "connection" {
+		if weekly := r.weeklyConnectionLimit(); weekly >= 0 && weekly-r.weeklyConnections < remaining {
+			remaining = weekly - r.weeklyConnections
+		}
+	}
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// LowBudgetPriority returns the configured low_budget_priority when both the
+// remaining connection and message budgets are at or below
+// low_budget_threshold, or "" while either still has room
+func (r *RateLimiter) LowBudgetPriority() string {
+	threshold := r.config.LowBudgetThreshold
+	if threshold <= 0 || r.config.LowBudgetPriority == "" {
+		return ""
+	}
+	if r.GetRemainingActions("connection") > threshold || r.GetRemainingActions("message") > threshold {
+		return ""
+	}
+	return r.config.LowBudgetPriority
 }
 
 // checkReset resets counts if a new day/hour has started
@@ -744,7 +1341,9 @@ func (r *RateLimiter) checkReset() {
 		r.actionCounts["connection"] = 0
 		r.actionCounts["message"] = 0
 		r.actionCounts["profile_view"] = 0
+		r.actionCounts["profile_view_incidental"] = 0
 		r.lastReset = now
+		r.reloadWeeklyConnections()
 		r.logger.Info("Daily rate limits reset")
 	}
 
@@ -768,16 +1367,35 @@ func (s *StealthManager) randomSign() float64 {
 // ClickElement performs a human-like click on an element
 func (s *StealthManager) ClickElement(page *rod.Page, element *rod.Element) error {
 	// First hover over the element
-	err := s.HoverElement(page, element)
+	hovered, err := s.hover(page, element)
 	if err != nil {
 		return err
 	}
 
+	// LinkedIn shifts content while it hydrates; don't click a moving target
+	if err := s.WaitForElementStable(element, 3, 100*time.Millisecond); err != nil {
+		s.logger.WithError(err).Debug("Clicking element that has not settled")
+	}
+
+	// If it moved from under the cursor, follow it before clicking
+	if box, err := element.Shape(); err == nil && len(box.Quads) > 0 && !sameQuad(hovered, box.Quads[0]) {
+		x, y := s.hoverPoint(box.Quads[0])
+		if err := s.MoveMouse(page, x, y); err != nil {
+			return err
+		}
+	}
+
 	// Small delay before clicking
 	time.Sleep(time.Duration(50+s.rand.Intn(150)) * time.Millisecond)
 
-	// Click
-	err = element.Click(proto.InputMouseButtonLeft, 1)
+	// A raw mouse click lands on whatever is on top, so make sure that's the
+	// element and not an overlay or sticky header
+	if _, err := element.Interactable(); err != nil {
+		return fmt.Errorf("element can't be clicked: %w", err)
+	}
+
+	// Click where the cursor rests rather than at the element's center
+	err = page.Mouse.Click(proto.InputMouseButtonLeft, 1)
 	if err != nil {
 		return err
 	}
@@ -2,8 +2,14 @@
 package stealth
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/nikshitha/linkedin-automation-poc/config"
 	"github.com/nikshitha/linkedin-automation-poc/logger"
@@ -157,6 +163,31 @@ func TestScheduler(t *testing.T) {
 	}
 }
 
+func TestSchedulerStartJitter(t *testing.T) {
+	cfg := &config.ScheduleConfig{Enabled: true, StartJitterMinutes: 10}
+
+	log, _ := logger.New(logger.Config{Level: "error"})
+	scheduler := NewScheduler(cfg, log)
+
+	for i := 0; i < 50; i++ {
+		jitter := scheduler.startJitter()
+		if jitter < 0 || jitter > 10*time.Minute {
+			t.Fatalf("Start jitter %s outside 0..10m", jitter)
+		}
+	}
+
+	cfg.StartJitterMinutes = 0
+	if jitter := scheduler.startJitter(); jitter != 0 {
+		t.Errorf("Expected no jitter when disabled, got %s", jitter)
+	}
+
+	cfg.StartJitterMinutes = 10
+	cfg.Enabled = false
+	if jitter := scheduler.startJitter(); jitter != 0 {
+		t.Errorf("Expected no jitter when scheduling is disabled, got %s", jitter)
+	}
+}
+
 func TestRateLimiter(t *testing.T) {
 	cfg := &config.RateLimitConfig{
 		MaxConnectionsPerDay:   5,
@@ -259,3 +290,892 @@ func TestCalculateMovementDelay(t *testing.T) {
 		t.Error("Delay in middle should be less than at end (ease-out)")
 	}
 }
+
+func TestPlanTypoCorrection(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	// Without variance every typo is corrected immediately
+	sm := NewStealthManager(&config.StealthConfig{TypingCorrectionVariance: 0}, log)
+	for i := 0; i < 100; i++ {
+		plan := sm.planTypoCorrection(10)
+		if !plan.correct || plan.lag != 0 {
+			t.Fatalf("Expected immediate correction without variance, got %+v", plan)
+		}
+	}
+
+	// With variance the correction length should vary
+	sm = NewStealthManager(&config.StealthConfig{TypingCorrectionVariance: 0.5}, log)
+	lengths := make(map[int]int)
+	for i := 0; i < 2000; i++ {
+		plan := sm.planTypoCorrection(10)
+		if plan.lag > maxTypoCorrectionLag {
+			t.Fatalf("Correction lag %d exceeds maximum %d", plan.lag, maxTypoCorrectionLag)
+		}
+		if plan.correct {
+			lengths[plan.lag]++
+		}
+	}
+
+	if len(lengths) < 3 {
+		t.Errorf("Expected varied correction lengths, got %v", lengths)
+	}
+	if lengths[0] == 0 {
+		t.Error("Some typos should still be corrected immediately")
+	}
+
+	// Lag never runs past the end of the text
+	for i := 0; i < 200; i++ {
+		if plan := sm.planTypoCorrection(1); plan.lag > 1 {
+			t.Fatalf("Lag %d exceeds remaining characters", plan.lag)
+		}
+	}
+}
+
+func TestSplitGraphemes(t *testing.T) {
+	// Newlines, a flag, a skin-toned wave and a ZWJ family emoji
+	message := "Hi 👋🏽\nGreetings from 🇮🇳!\r\nBest,\n👨\u200d👩\u200d👧"
+	clusters := splitGraphemes(message)
+
+	if joined := strings.Join(clusters, ""); joined != message {
+		t.Fatalf("Clusters should recompose the message, got %q", joined)
+	}
+
+	expected := map[string]bool{
+		"👋🏽":              false,
+		"🇮🇳":              false,
+		"👨\u200d👩\u200d👧": false,
+	}
+	lineBreaks := 0
+	for _, cluster := range clusters {
+		if _, ok := expected[cluster]; ok {
+			expected[cluster] = true
+		}
+		if isLineBreak(cluster) {
+			lineBreaks++
+		}
+		if _, plain := plainRune(cluster); !plain && !isLineBreak(cluster) {
+			if _, ok := expected[cluster]; !ok {
+				t.Errorf("Unexpected multi-rune cluster %q", cluster)
+			}
+		}
+	}
+
+	for emoji, found := range expected {
+		if !found {
+			t.Errorf("Emoji %q should be typed as a single cluster", emoji)
+		}
+	}
+	if lineBreaks != 3 {
+		t.Errorf("Expected 3 line breaks (CRLF counted once), got %d", lineBreaks)
+	}
+
+	// Typing ahead after a typo never crosses a line break
+	if limit := typeAheadLimit(splitGraphemes("ab\ncd")); limit != 2 {
+		t.Errorf("Expected type-ahead to stop before the newline, got %d", limit)
+	}
+}
+
+func TestWarmupLimit(t *testing.T) {
+	maturity := config.AccountMaturityConfig{
+		Enabled:      true,
+		MinAgeDays:   7,
+		WarmupDays:   30,
+		StartPercent: 20,
+	}
+
+	// Connections are refused before the minimum age, other actions are ramped
+	if got := WarmupLimit(maturity, "connection", 25, 3); got != 0 {
+		t.Errorf("Expected no connections before min age, got %d", got)
+	}
+	if got := WarmupLimit(maturity, "message", 50, 3); got != 10 {
+		t.Errorf("Expected messages at start percent (10), got %d", got)
+	}
+
+	// Linear ramp between min age and warmup days
+	if got := WarmupLimit(maturity, "connection", 25, 7); got != 5 {
+		t.Errorf("Expected 20%% of 25 at min age, got %d", got)
+	}
+	previous := 0
+	for age := 7; age <= 30; age++ {
+		got := WarmupLimit(maturity, "connection", 25, age)
+		if got < previous {
+			t.Fatalf("Ramp should never decrease: day %d gave %d after %d", age, got, previous)
+		}
+		previous = got
+	}
+	if previous != 25 {
+		t.Errorf("Expected full limit at warmup days, got %d", previous)
+	}
+
+	// Never exceeds the configured limit, unknown age and disabled policy are untouched
+	if got := WarmupLimit(maturity, "connection", 25, 365); got != 25 {
+		t.Errorf("Mature account should get the configured limit, got %d", got)
+	}
+	if got := WarmupLimit(maturity, "connection", 25, -1); got != 25 {
+		t.Errorf("Unknown age should not be ramped, got %d", got)
+	}
+	maturity.Enabled = false
+	if got := WarmupLimit(maturity, "connection", 25, 0); got != 25 {
+		t.Errorf("Disabled policy should not ramp, got %d", got)
+	}
+}
+
+func TestHourlyCooldown(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	cfg := &config.RateLimitConfig{CooldownMinutes: 5}
+	rl := NewRateLimiter(cfg, log)
+	if got := rl.cooldownMinutes(12); got != 5 {
+		t.Errorf("Expected flat cooldown without a schedule, got %d", got)
+	}
+
+	cfg.HourlyCooldownMinutes = map[int]int{10: 2, 12: 45}
+	for hour, want := range map[int]int{10: 2, 12: 45, 15: 5} {
+		if got := rl.cooldownMinutes(hour); got != want {
+			t.Errorf("Hour %d: expected %d minutes, got %d", hour, want, got)
+		}
+	}
+}
+
+func TestFailureBackoff(t *testing.T) {
+	cfg := config.RateLimitConfig{FailureCooldownAfter: 3, FailureCooldownMinutes: 5, FailureAbortAfter: 8}
+
+	tests := []struct {
+		consecutive int
+		cooldown    time.Duration
+		abort       bool
+	}{
+		{1, 0, false},
+		{2, 0, false},
+		{3, 5 * time.Minute, false},
+		{4, 10 * time.Minute, false},
+		{5, 20 * time.Minute, false},
+		{6, 40 * time.Minute, false},
+		{7, 40 * time.Minute, false}, // capped at 8x
+		{8, 0, true},
+	}
+
+	for _, tt := range tests {
+		cooldown, abort := FailureBackoff(cfg, tt.consecutive)
+		if cooldown != tt.cooldown || abort != tt.abort {
+			t.Errorf("%d failures: got (%v, %v), want (%v, %v)", tt.consecutive, cooldown, abort, tt.cooldown, tt.abort)
+		}
+	}
+
+	// Zero thresholds disable both behaviours
+	if cooldown, abort := FailureBackoff(config.RateLimitConfig{}, 100); cooldown != 0 || abort {
+		t.Errorf("Disabled backoff should never cool down or abort, got (%v, %v)", cooldown, abort)
+	}
+}
+
+func TestBlockUntil(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cfg := config.DefaultConfig()
+	rl := NewRateLimiter(&cfg.RateLimits, log)
+
+	if !rl.CanPerformAction("connection") {
+		t.Fatal("Connections should be allowed before any block")
+	}
+
+	// Past times are ignored
+	rl.BlockUntil("connection", time.Now().Add(-time.Hour))
+	if !rl.CanPerformAction("connection") {
+		t.Error("A block in the past should not apply")
+	}
+
+	rl.BlockUntil("connection", time.Now().Add(time.Hour))
+	if rl.CanPerformAction("connection") {
+		t.Error("Connections should be refused while blocked")
+	}
+	if remaining := rl.GetRemainingActions("connection"); remaining != 0 {
+		t.Errorf("Expected 0 remaining while blocked, got %d", remaining)
+	}
+	if !rl.CanPerformAction("message") {
+		t.Error("A connection block should not affect messages")
+	}
+}
+
+func TestPauseController(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	controlFile := filepath.Join(t.TempDir(), "paused")
+	p := NewPauseController(&config.ScheduleConfig{PauseFile: controlFile}, log)
+	p.pollInterval = 10 * time.Millisecond
+
+	if p.Paused() {
+		t.Fatal("Should not start paused")
+	}
+	if !p.Toggle() || !p.Paused() {
+		t.Error("Toggle should pause")
+	}
+	if p.Toggle() || p.Paused() {
+		t.Error("Second toggle should resume")
+	}
+
+	// The control file pauses until it is removed
+	if err := os.WriteFile(controlFile, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Paused() {
+		t.Error("Control file should pause the run")
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		os.Remove(controlFile)
+	}()
+	if !p.WaitWhilePaused(context.Background()) {
+		t.Error("WaitWhilePaused should return true on resume")
+	}
+
+	// Cancelling the run ends the wait
+	p.Toggle()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if p.WaitWhilePaused(ctx) {
+		t.Error("WaitWhilePaused should return false when the run ends")
+	}
+
+	// A nil controller never pauses
+	var none *PauseController
+	if none.Paused() || !none.WaitWhilePaused(context.Background()) {
+		t.Error("Nil controller should never pause")
+	}
+}
+
+func TestOrderSteps(t *testing.T) {
+	cfg := &config.StealthConfig{ShuffleWorkflowSteps: true}
+	log, _ := logger.New(logger.Config{Level: "error"})
+	sm := NewStealthManager(cfg, log)
+
+	steps := []WorkflowStep{
+		{Name: "messages"},
+		{Name: "search"},
+		{Name: "connect", After: []string{"search"}},
+		{Name: "withdraw", After: []string{"unknown"}},
+	}
+
+	orders := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		ordered := sm.OrderSteps(steps)
+		if len(ordered) != len(steps) {
+			t.Fatalf("Expected %d steps, got %d", len(steps), len(ordered))
+		}
+
+		position := make(map[string]int)
+		key := ""
+		for j, step := range ordered {
+			position[step.Name] = j
+			key += step.Name + ","
+		}
+		if len(position) != len(steps) {
+			t.Fatalf("Steps should appear once each: %s", key)
+		}
+		if position["search"] > position["connect"] {
+			t.Fatalf("Search should run before connect: %s", key)
+		}
+		orders[key] = true
+	}
+	if len(orders) < 2 {
+		t.Error("Shuffling should vary the step order")
+	}
+
+	// Without shuffling the order is unchanged
+	cfg.ShuffleWorkflowSteps = false
+	for i, step := range sm.OrderSteps(steps) {
+		if step.Name != steps[i].Name {
+			t.Fatalf("Position %d: expected %s, got %s", i, steps[i].Name, step.Name)
+		}
+	}
+}
+
+func TestReadingDuration(t *testing.T) {
+	cfg := &config.StealthConfig{ReadingDelayMin: 1000, ReadingDelayMax: 10000, ReadingMaxChars: 9000}
+	log, _ := logger.New(logger.Config{Level: "error"})
+	sm := NewStealthManager(cfg, log)
+
+	if d := sm.readingDuration(4500, 1); d != 5500*time.Millisecond {
+		t.Errorf("Half of reading_max_chars should take halfway between the bounds, got %v", d)
+	}
+	if d := sm.readingDuration(0, 1); d != time.Second {
+		t.Errorf("Empty pages should get the minimum, got %v", d)
+	}
+	if d := sm.readingDuration(100000, 1); d != 10*time.Second {
+		t.Errorf("Dense pages should be capped at the maximum, got %v", d)
+	}
+	if sm.readingDuration(4000, 1) <= sm.readingDuration(2000, 1) {
+		t.Error("More text should take longer to read, well past a few hundred characters")
+	}
+}
+
+func TestDeviceProfiles(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	sm := NewStealthManager(&config.StealthConfig{}, log)
+
+	desktop := sm.GetDeviceMetrics(DeviceDesktop, 1366, 768)
+	if desktop.Width != 1366 || desktop.Height != 768 || desktop.ScaleFactor != 1 || desktop.Mobile || desktop.Touch {
+		t.Errorf("Desktop should keep the given viewport without touch, got %+v", desktop)
+	}
+
+	mobile := sm.GetDeviceMetrics(DeviceMobile, 1366, 768)
+	if !mobile.Mobile || !mobile.Touch || mobile.Width >= mobile.Height {
+		t.Errorf("Mobile should be a portrait touch screen, got %+v", mobile)
+	}
+
+	tablet := sm.GetDeviceMetrics(DeviceTablet, 1366, 768)
+	if tablet.Mobile || !tablet.Touch {
+		t.Errorf("Tablet should have touch without a mobile viewport, got %+v", tablet)
+	}
+
+	if ua := sm.GetRandomUserAgentForDevice(DeviceMobile); !strings.Contains(ua, "Mobile") {
+		t.Errorf("Expected a mobile user agent, got %s", ua)
+	}
+	if ua := sm.GetRandomUserAgentForDevice(DeviceDesktop); strings.Contains(ua, "Mobile") {
+		t.Errorf("Expected a desktop user agent, got %s", ua)
+	}
+}
+
+func TestUserAgentMetadata(t *testing.T) {
+	for device, agents := range deviceUserAgents {
+		for _, ua := range agents {
+			metadata := UserAgentMetadata(ua)
+			if metadata == nil || metadata.Platform != "Android" || metadata.Model == "" {
+				t.Errorf("%s user agent %q should get Android Client Hints, got %+v", device, ua, metadata)
+				continue
+			}
+			if metadata.Mobile != (device == DeviceMobile) {
+				t.Errorf("%s user agent %q: mobile hint = %v", device, ua, metadata.Mobile)
+			}
+		}
+	}
+
+	metadata := UserAgentMetadata("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0")
+	if metadata == nil || metadata.Platform != "Windows" || metadata.Brands[2].Brand != "Microsoft Edge" || metadata.Brands[2].Version != "120" {
+		t.Errorf("Expected Windows Edge 120 hints, got %+v", metadata)
+	}
+	if UserAgentMetadata("Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1") != nil {
+		t.Error("Safari sends no Client Hints")
+	}
+
+	stale := &FingerprintProfile{
+		Device:              DeviceMobile,
+		UserAgent:           "Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+		Screen:              DeviceMetrics{Width: 390, Height: 844, ScaleFactor: 3},
+		HardwareConcurrency: 6,
+		Languages:           []string{"en-US"},
+	}
+	if stale.valid() {
+		t.Error("A stored mobile profile with an iOS user agent should be replaced")
+	}
+}
+
+func TestSeededBezierPath(t *testing.T) {
+	cfg := &config.StealthConfig{
+		MouseSpeedMin:     0.5,
+		MouseSpeedMax:     2.0,
+		MouseOvershoot:    true,
+		MouseMicroCorrect: true,
+		RandomSeed:        42,
+	}
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	start, end := Point{X: 10, Y: 20}, Point{X: 640, Y: 480}
+	first := NewStealthManager(cfg, log).generateBezierPath(start, end)
+	second := NewStealthManager(cfg, log).generateBezierPath(start, end)
+
+	if len(first) != len(second) {
+		t.Fatalf("Same seed should give the same path length: %d vs %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Paths differ at point %d: %+v vs %+v", i, first[i], second[i])
+		}
+	}
+
+	other := *cfg
+	other.RandomSeed = 7
+	different := NewStealthManager(&other, log).generateBezierPath(start, end)
+	same := len(different) == len(first)
+	for i := 0; same && i < len(first); i++ {
+		same = first[i] == different[i]
+	}
+	if same {
+		t.Error("A different seed should give a different path")
+	}
+}
+
+func TestFingerprintProfilePersisted(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	sm := NewStealthManager(&config.StealthConfig{}, log)
+	dir := t.TempDir()
+
+	first, err := sm.LoadOrCreateFingerprintProfile(dir, sm.NewFingerprintProfile(DeviceDesktop, 1366, 768))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Later runs generate a different candidate but keep the stored identity
+	candidate := sm.NewFingerprintProfile(DeviceDesktop, 1920, 1080)
+	again, err := sm.LoadOrCreateFingerprintProfile(dir, candidate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.UserAgent != first.UserAgent || again.Screen != first.Screen || again.HardwareConcurrency != first.HardwareConcurrency {
+		t.Errorf("Expected the stored profile %+v, got %+v", first, again)
+	}
+
+	// Switching device replaces it
+	mobile, err := sm.LoadOrCreateFingerprintProfile(dir, sm.NewFingerprintProfile(DeviceMobile, 1366, 768))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mobile.Device != DeviceMobile || !mobile.Screen.Mobile {
+		t.Errorf("Expected a new mobile profile, got %+v", mobile)
+	}
+
+	sm.SetFingerprintProfile(mobile)
+	if sm.hardwareConcurrency() != mobile.HardwareConcurrency {
+		t.Error("Hardware concurrency should come from the profile")
+	}
+	if got := sm.LanguagesJS(); got != `["en-US", "en"]` {
+		t.Errorf("Unexpected languages %s", got)
+	}
+}
+
+func TestSameQuad(t *testing.T) {
+	box := []float64{10, 20, 110, 20, 110, 60, 10, 60}
+
+	if !sameQuad(box, []float64{10.2, 20, 110.3, 20, 110, 60, 10, 59.8}) {
+		t.Error("Sub-pixel jitter should count as stable")
+	}
+	if sameQuad(box, []float64{10, 52, 110, 52, 110, 92, 10, 92}) {
+		t.Error("An element pushed down by new content should count as moved")
+	}
+	if sameQuad(box, box[:4]) {
+		t.Error("Quads of different lengths should not match")
+	}
+}
+
+func TestWeeklyConnectionLimit(t *testing.T) {
+	cfg := &config.RateLimitConfig{
+		MaxConnectionsPerDay:  20,
+		MaxConnectionsPerWeek: 50,
+	}
+
+	log, _ := logger.New(logger.Config{Level: "error"})
+	rl := NewRateLimiter(cfg, log)
+	rl.SetWeeklyConnectionCount(47)
+
+	if remaining := rl.GetRemainingActions("connection"); remaining != 3 {
+		t.Errorf("Expected the weekly cap to leave 3 connections, got %d", remaining)
+	}
+
+	for i := 0; i < 3; i++ {
+		if !rl.CanPerformAction("connection") {
+			t.Fatalf("Connection %d should fit under the weekly cap", i+1)
+		}
+		rl.RecordAction("connection")
+	}
+
+	if rl.CanPerformAction("connection") {
+		t.Error("Should not be able to connect past the weekly cap")
+	}
+	if remaining := rl.GetRemainingActions("connection"); remaining != 0 {
+		t.Errorf("Expected 0 remaining connections, got %d", remaining)
+	}
+
+	cfg.MaxConnectionsPerWeek = 0
+	if !rl.CanPerformAction("connection") {
+		t.Error("A weekly cap of 0 should disable the check")
+	}
+}
+
+func TestWeeklyConnectionsReloadDaily(t *testing.T) {
+	cfg := &config.RateLimitConfig{
+		MaxConnectionsPerDay:  20,
+		MaxConnectionsPerWeek: 50,
+	}
+
+	log, _ := logger.New(logger.Config{Level: "error"})
+	rl := NewRateLimiter(cfg, log)
+	weekly := 47
+	rl.SetWeeklyConnectionSource(func() (int, error) { return weekly, nil })
+
+	if remaining := rl.GetRemainingActions("connection"); remaining != 3 {
+		t.Errorf("Expected the seeded weekly count to leave 3 connections, got %d", remaining)
+	}
+
+	// A day later most of last week's connections have left the window
+	weekly = 10
+	rl.lastReset = rl.lastReset.AddDate(0, 0, -1)
+	if remaining := rl.GetRemainingActions("connection"); remaining != 20 {
+		t.Errorf("Expected the daily reset to reload the weekly count, got %d remaining", remaining)
+	}
+}
+
+func TestIncidentalProfileViews(t *testing.T) {
+	cfg := &config.RateLimitConfig{
+		MaxConnectionsPerDay:  5,
+		MaxProfileViewsPerDay: 5,
+	}
+
+	log, _ := logger.New(logger.Config{Level: "error"})
+	rl := NewRateLimiter(cfg, log)
+
+	// A full day of connects opens as many profiles as it sends requests
+	for i := 0; i < 5; i++ {
+		rl.RecordAction("connection")
+		rl.RecordProfileView(ViewIncidental)
+	}
+
+	if !rl.CanPerformAction("profile_view") {
+		t.Error("Views made while connecting should not use up the profile view cap")
+	}
+	if remaining := rl.GetRemainingActions("profile_view"); remaining != 5 {
+		t.Errorf("Expected 5 explicit profile views left, got %d", remaining)
+	}
+
+	for i := 0; i < 5; i++ {
+		rl.RecordProfileView(ViewExplicit)
+	}
+	if rl.CanPerformAction("profile_view") {
+		t.Error("Explicit views should count against the profile view cap")
+	}
+}
+
+func TestReserveProfileViewConcurrent(t *testing.T) {
+	cfg := &config.RateLimitConfig{
+		MaxProfileViewsPerDay:  5,
+		MinDelayBetweenActions: 5,
+		MaxDelayBetweenActions: 10,
+	}
+
+	log, _ := logger.New(logger.Config{Level: "error"})
+	rl := NewRateLimiter(cfg, log)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved int
+	)
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rl.ReserveProfileView() {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != 5 {
+		t.Fatalf("Expected exactly 5 reservations against a cap of 5, got %d", reserved)
+	}
+
+	// Each reservation waited out the spacing after the one before it
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("5 reservations at 5ms minimum spacing took only %s", elapsed)
+	}
+}
+
+func TestTypingProfiles(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	average := func(sm *StealthManager) float64 {
+		total := 0
+		for i := 0; i < 2000; i++ {
+			total += sm.keystrokeDelay()
+		}
+		return float64(total) / 2000
+	}
+
+	for name, p := range typingProfiles {
+		sm := NewStealthManager(&config.StealthConfig{TypingProfile: name}, log)
+		atBounds := 0
+		for i := 0; i < 5000; i++ {
+			d := sm.keystrokeDelay()
+			if d < p.MinDelay || d > p.MaxDelay {
+				t.Fatalf("%s: delay %d outside %d-%d", name, d, p.MinDelay, p.MaxDelay)
+			}
+			if d == p.MinDelay || d == p.MaxDelay {
+				atBounds++
+			}
+		}
+		// Clamping would pile about 2% of delays onto the bounds
+		if atBounds > 25 {
+			t.Errorf("%s: %d of 5000 delays landed exactly on the bounds", name, atBounds)
+		}
+		if rate := sm.typingMistakeRate(); rate != p.MistakeRate {
+			t.Errorf("%s: mistake rate %v, want %v", name, rate, p.MistakeRate)
+		}
+	}
+
+	fast := NewStealthManager(&config.StealthConfig{TypingProfile: TypingFast}, log)
+	slow := NewStealthManager(&config.StealthConfig{TypingProfile: TypingHuntAndPeck}, log)
+	if average(fast) >= average(slow) {
+		t.Error("Hunt-and-peck typing should be slower than fast typing")
+	}
+
+	// Without a profile the configured range and mistake rate apply
+	sm := NewStealthManager(&config.StealthConfig{TypingDelayMin: 50, TypingDelayMax: 60, TypingMistakeRate: 0.1}, log)
+	for i := 0; i < 100; i++ {
+		if d := sm.keystrokeDelay(); d < 50 || d >= 60 {
+			t.Fatalf("Delay %d outside configured 50-60 range", d)
+		}
+	}
+	if rate := sm.typingMistakeRate(); rate != 0.1 {
+		t.Errorf("Expected configured mistake rate 0.1, got %v", rate)
+	}
+}
+
+func TestHoverPointWithinBounds(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	box := []float64{100, 200, 220, 200, 220, 240, 100, 240}
+
+	sm := NewStealthManager(&config.StealthConfig{ClickAreaPercent: 60}, log)
+	seen := make(map[[2]float64]bool)
+	for i := 0; i < 200; i++ {
+		x, y := sm.hoverPoint(box)
+		// Central 60%: 20% margin on each side
+		if x < 124 || x > 196 || y < 208 || y > 232 {
+			t.Fatalf("Point (%.1f, %.1f) outside the central click area", x, y)
+		}
+		seen[[2]float64{x, y}] = true
+	}
+	if len(seen) < 100 {
+		t.Errorf("Expected varied click points, got %d distinct", len(seen))
+	}
+
+	center := NewStealthManager(&config.StealthConfig{ClickAreaPercent: 0}, log)
+	if x, y := center.hoverPoint(box); x != 160 || y != 220 {
+		t.Errorf("Expected dead center at 0%%, got (%.1f, %.1f)", x, y)
+	}
+}
+
+func TestInitialMousePosition(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+
+	seen := make(map[Point]bool)
+	for seed := int64(1); seed <= 20; seed++ {
+		p := NewStealthManager(&config.StealthConfig{RandomSeed: seed}, log).MousePosition()
+		if p.X < 200 || p.X > 1166 || p.Y < 150 || p.Y > 618 {
+			t.Fatalf("Initial position %+v is off the plausible viewport area", p)
+		}
+		seen[p] = true
+	}
+	if len(seen) < 20 {
+		t.Errorf("Expected the cursor to start somewhere different each run, got %d distinct", len(seen))
+	}
+	if seen[Point{683, 384}] {
+		t.Error("Cursor should not start at the fixed viewport center")
+	}
+}
+
+func TestSessionProgress(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	rl := NewRateLimiter(&config.RateLimitConfig{MaxConnectionsPerDay: 25, MaxMessagesPerDay: 50}, log)
+
+	for i := 0; i < 12; i++ {
+		rl.RecordAction("connection")
+	}
+	for i := 0; i < 4; i++ {
+		rl.RecordAction("message")
+	}
+	rl.RecordFailure("connection")
+	rl.RecordFailure("message")
+
+	want := "Session: 12 connects (13 remaining today), 4 messages, 2 failures"
+	if got := rl.SessionProgress(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// Logged at most once per interval
+	rl.SetProgressInterval(time.Hour)
+	before := rl.lastProgress
+	rl.RecordAction("connection")
+	if rl.lastProgress != before {
+		t.Error("Progress should not be logged before the interval has passed")
+	}
+	rl.lastProgress = time.Now().Add(-2 * time.Hour)
+	rl.RecordAction("connection")
+	if time.Since(rl.lastProgress) > time.Minute {
+		t.Error("Progress should be logged once the interval has passed")
+	}
+}
+
+func TestSelfTestAnalysis(t *testing.T) {
+	straight := []Point{{0, 0}, {50, 25}, {100, 50}, {200, 100}}
+	if d := maxPathDeviation(straight); d > 0.001 {
+		t.Errorf("A straight path should not deviate, got %.3f", d)
+	}
+	curved := []Point{{0, 0}, {50, 40}, {100, 0}}
+	if d := maxPathDeviation(curved); d != 40 {
+		t.Errorf("Expected 40px deviation, got %.3f", d)
+	}
+
+	mean, variation := keyIntervalStats([]float64{0, 100, 200, 300})
+	if mean != 100 || variation != 0 {
+		t.Errorf("Evenly spaced keys: expected mean 100 and no variation, got %.1f, %.2f", mean, variation)
+	}
+	mean, variation = keyIntervalStats([]float64{0, 50, 200, 260, 500})
+	if mean != 125 || variation < minKeyVariation {
+		t.Errorf("Uneven keys: expected mean 125 with variation, got %.1f, %.2f", mean, variation)
+	}
+}
+
+func TestLowBudgetPriority(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cfg := &config.RateLimitConfig{
+		MaxConnectionsPerDay: 10,
+		MaxMessagesPerDay:    10,
+		LowBudgetThreshold:   3,
+		LowBudgetPriority:    "messages",
+	}
+	rl := NewRateLimiter(cfg, log)
+
+	if got := rl.LowBudgetPriority(); got != "" {
+		t.Errorf("Expected no priority with full budgets, got %q", got)
+	}
+
+	for i := 0; i < 7; i++ {
+		rl.RecordAction("connection")
+	}
+	if got := rl.LowBudgetPriority(); got != "" {
+		t.Errorf("Expected no priority while messages still have room, got %q", got)
+	}
+
+	for i := 0; i < 7; i++ {
+		rl.RecordAction("message")
+	}
+	if got := rl.LowBudgetPriority(); got != "messages" {
+		t.Errorf("Expected messages priority with both budgets low, got %q", got)
+	}
+
+	cfg.LowBudgetThreshold = 0
+	if got := rl.LowBudgetPriority(); got != "" {
+		t.Errorf("A zero threshold should disable the policy, got %q", got)
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	w := NewWatchdog(time.Minute)
+	start := w.last
+
+	if _, stalled := w.stalled(start.Add(30 * time.Second)); stalled {
+		t.Error("Should not stall within the window")
+	}
+	if idle, stalled := w.stalled(start.Add(2 * time.Minute)); !stalled || idle < 2*time.Minute {
+		t.Errorf("Expected a stall after the window, got stalled=%v idle=%v", stalled, idle)
+	}
+
+	resume := w.Suspend()
+	if _, stalled := w.stalled(start.Add(2 * time.Minute)); stalled {
+		t.Error("A suspended watchdog should not stall")
+	}
+	resume()
+	resume()
+	if w.suspended != 0 {
+		t.Errorf("Resuming twice should only lift one suspension, got %d", w.suspended)
+	}
+	if _, stalled := w.stalled(time.Now().Add(30 * time.Second)); stalled {
+		t.Error("Resuming should restart the window")
+	}
+
+	// Recorded actions count as progress
+	log, _ := logger.New(logger.Config{Level: "error"})
+	rl := NewRateLimiter(&config.RateLimitConfig{}, log)
+	rl.SetWatchdog(w)
+	w.last = start.Add(-time.Hour)
+	rl.RecordAction("search")
+	if _, stalled := w.stalled(time.Now()); stalled {
+		t.Error("RecordAction should reset the watchdog")
+	}
+
+	// A nil watchdog is a no-op
+	var none *Watchdog
+	none.Reset()
+	none.Suspend()()
+}
+
+func TestTruncateText(t *testing.T) {
+	if got := TruncateText("Hi Jane 👋", 300); got != "Hi Jane 👋" {
+		t.Errorf("Short text should be unchanged, got %q", got)
+	}
+
+	// Byte slicing at these limits would cut an emoji in half
+	text := "Loved your talk 🎉🎉 on distributed systems"
+	for limit, want := range map[int]string{21: "Loved your talk 🎉🎉...", 20: "Loved your talk..."} {
+		got := TruncateText(text, limit)
+		if !utf8.ValidString(got) {
+			t.Fatalf("Truncated text is not valid UTF-8: %q", got)
+		}
+		if n := utf8.RuneCountInString(got); n > limit {
+			t.Errorf("Truncated text exceeds limit %d: %q (%d)", limit, got, n)
+		}
+		if got != want {
+			t.Errorf("TruncateText(%d) = %q, want %q", limit, got, want)
+		}
+	}
+
+	// A ZWJ family emoji straddling the limit is dropped whole
+	family := "👨‍👩‍👧"
+	got := TruncateText("Congrats "+family+" and welcome", 13)
+	if strings.Contains(got, "‍") || !strings.HasPrefix(got, "Congrats") {
+		t.Errorf("Emoji sequence should not be split, got %q", got)
+	}
+}
+
+func TestBurstTokens(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cfg := &config.RateLimitConfig{BurstSize: 3, BurstRefillSeconds: 60}
+	rl := NewRateLimiter(cfg, log)
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if !rl.takeBurstToken(now) {
+			t.Fatalf("Expected burst token %d from a full bucket", i+1)
+		}
+	}
+	if rl.takeBurstToken(now) {
+		t.Error("An empty bucket should fall back to normal spacing")
+	}
+
+	// Half a refill period isn't enough for another token
+	if rl.takeBurstToken(now.Add(30 * time.Second)) {
+		t.Error("Expected no token before a full refill period")
+	}
+	if !rl.takeBurstToken(now.Add(61 * time.Second)) {
+		t.Error("Expected a token after one refill period")
+	}
+
+	// A long idle period refills only up to the burst size
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !rl.takeBurstToken(later) {
+			t.Fatalf("Expected burst token %d after a long idle period", i+1)
+		}
+	}
+	if rl.takeBurstToken(later) {
+		t.Error("The bucket should hold at most burst_size tokens")
+	}
+
+	cfg.BurstSize = 0
+	if rl.takeBurstToken(later.Add(time.Hour)) {
+		t.Error("A zero burst size should disable bursts")
+	}
+}
+
+func TestBurstTokenOnlySpentWhenWaiting(t *testing.T) {
+	log, _ := logger.New(logger.Config{Level: "error"})
+	cfg := &config.RateLimitConfig{BurstSize: 1, BurstRefillSeconds: 3600, MinDelayBetweenActions: 10, MaxDelayBetweenActions: 20}
+	rl := NewRateLimiter(cfg, log)
+
+	// Long after the last action the normal spacing doesn't wait at all
+	rl.lastAction = time.Now().Add(-time.Minute)
+	rl.WaitForNextAction()
+	if !rl.takeBurstToken(time.Now()) {
+		t.Fatal("An action that didn't have to wait should not spend a burst token")
+	}
+}
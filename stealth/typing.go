@@ -0,0 +1,94 @@
+// Package stealth - typing.go handles named typing speed profiles
+package stealth
+
+import "math"
+
+// Typing profiles selectable with stealth.typing_profile
+const (
+	TypingFast        = "fast"
+	TypingAverage     = "average"
+	TypingHuntAndPeck = "hunt-and-peck"
+)
+
+// TypingProfile describes one typist's cadence. Inter-key delays are drawn
+// from a normal distribution clamped to [MinDelay, MaxDelay].
+type TypingProfile struct {
+	MeanDelay   int // milliseconds
+	DelayStdDev int
+	MinDelay    int
+	MaxDelay    int
+
+	ThinkChance float64 // chance of a longer pause before a keystroke
+	ThinkMin    int     // milliseconds
+	ThinkMax    int
+
+	MistakeRate float64
+}
+
+// typingProfiles holds the built-in profiles
+var typingProfiles = map[string]TypingProfile{
+	TypingFast: {
+		MeanDelay: 70, DelayStdDev: 20, MinDelay: 30, MaxDelay: 150,
+		ThinkChance: 0.03, ThinkMin: 150, ThinkMax: 400,
+		MistakeRate: 0.03,
+	},
+	TypingAverage: {
+		MeanDelay: 130, DelayStdDev: 45, MinDelay: 50, MaxDelay: 300,
+		ThinkChance: 0.05, ThinkMin: 200, ThinkMax: 600,
+		MistakeRate: 0.02,
+	},
+	TypingHuntAndPeck: {
+		MeanDelay: 320, DelayStdDev: 140, MinDelay: 120, MaxDelay: 900,
+		ThinkChance: 0.12, ThinkMin: 400, ThinkMax: 1500,
+		MistakeRate: 0.05,
+	},
+}
+
+// typingProfile returns the configured profile, or false when typing uses the
+// plain typing_delay_min_ms/max_ms range
+func (s *StealthManager) typingProfile() (TypingProfile, bool) {
+	p, ok := typingProfiles[s.config.TypingProfile]
+	return p, ok
+}
+
+// maxDelayDraws bounds the resampling in keystrokeDelay; every profile's range
+// covers well over 90% of its distribution, so it is never reached in practice
+const maxDelayDraws = 100
+
+// keystrokeDelay returns a random base delay in milliseconds between keystrokes.
+// A profile's delay follows a normal distribution truncated to its min-max
+// range: out-of-range draws are redrawn rather than clamped, so no delay
+// value turns up more often than the curve says it should.
+func (s *StealthManager) keystrokeDelay() int {
+	p, ok := s.typingProfile()
+	if !ok {
+		return s.config.TypingDelayMin + s.rand.Intn(s.config.TypingDelayMax-s.config.TypingDelayMin)
+	}
+	for i := 0; i < maxDelayDraws; i++ {
+		delay := int(math.Round(float64(p.MeanDelay) + s.rand.NormFloat64()*float64(p.DelayStdDev)))
+		if delay >= p.MinDelay && delay <= p.MaxDelay {
+			return delay
+		}
+	}
+	return p.MinDelay + s.rand.Intn(p.MaxDelay-p.MinDelay+1)
+}
+
+// thinkingPause returns an occasional extra pause in milliseconds, or 0
+func (s *StealthManager) thinkingPause() int {
+	p, ok := s.typingProfile()
+	if !ok {
+		p = TypingProfile{ThinkChance: 0.05, ThinkMin: 200, ThinkMax: 600}
+	}
+	if s.rand.Float64() >= p.ThinkChance {
+		return 0
+	}
+	return p.ThinkMin + s.rand.Intn(p.ThinkMax-p.ThinkMin)
+}
+
+// typingMistakeRate returns the chance of a typo per character
+func (s *StealthManager) typingMistakeRate() float64 {
+	if p, ok := s.typingProfile(); ok {
+		return p.MistakeRate
+	}
+	return s.config.TypingMistakeRate
+}
@@ -0,0 +1,93 @@
+// Package stealth - watchdog.go handles detecting runs that stopped making progress
+package stealth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// minWatchdogCheck bounds how often the watchdog checks for a stall
+const minWatchdogCheck = time.Second
+
+// Watchdog tracks the time of the last successful action and reports a stall
+// when none happens within its window. Intentional waits (cooldowns, breaks,
+// pauses) suspend it. A nil watchdog never fires.
+type Watchdog struct {
+	window    time.Duration
+	mu        sync.Mutex
+	last      time.Time
+	suspended int
+}
+
+// NewWatchdog creates a watchdog that stalls after window without progress
+func NewWatchdog(window time.Duration) *Watchdog {
+	return &Watchdog{window: window, last: time.Now()}
+}
+
+// Reset records progress, restarting the window
+func (w *Watchdog) Reset() {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	w.last = time.Now()
+	w.mu.Unlock()
+}
+
+// Suspend stops the watchdog from firing until the returned function is
+// called, which also restarts the window. Suspensions nest.
+func (w *Watchdog) Suspend() (resume func()) {
+	if w == nil {
+		return func() {}
+	}
+	w.mu.Lock()
+	w.suspended++
+	w.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			w.mu.Lock()
+			w.suspended--
+			w.last = time.Now()
+			w.mu.Unlock()
+		})
+	}
+}
+
+// stalled reports how long the watchdog has gone without progress at now,
+// and whether that exceeds the window while not suspended
+func (w *Watchdog) stalled(now time.Time) (time.Duration, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	idle := now.Sub(w.last)
+	return idle, w.suspended == 0 && idle >= w.window
+}
+
+// Run checks for a stall until ctx ends, calling onStall once with the idle
+// time when one is detected
+func (w *Watchdog) Run(ctx context.Context, onStall func(idle time.Duration)) {
+	if w == nil || w.window <= 0 {
+		return
+	}
+
+	interval := w.window / 10
+	if interval < minWatchdogCheck {
+		interval = minWatchdogCheck
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if idle, stalled := w.stalled(now); stalled {
+				onStall(idle)
+				return
+			}
+		}
+	}
+}
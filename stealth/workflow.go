@@ -0,0 +1,64 @@
+// Package stealth - workflow.go handles varying the order of workflow steps
+package stealth
+
+// WorkflowStep is a named unit of work in a workflow cycle. After names the
+// steps that must run before it; all other steps may run in any order.
+type WorkflowStep struct {
+	Name  string
+	After []string
+	Run   func() error
+}
+
+// OrderSteps returns the steps for one cycle. With shuffling enabled the
+// order is randomized each call while keeping every step after its
+// dependencies; otherwise the steps are returned as given.
+func (s *StealthManager) OrderSteps(steps []WorkflowStep) []WorkflowStep {
+	if !s.config.ShuffleWorkflowSteps {
+		return steps
+	}
+
+	known := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		known[step.Name] = true
+	}
+
+	done := make(map[string]bool, len(steps))
+	remaining := append([]WorkflowStep(nil), steps...)
+	ordered := make([]WorkflowStep, 0, len(steps))
+
+	for len(remaining) > 0 {
+		// Steps whose dependencies have all run (unknown names don't block)
+		var ready []int
+		for i, step := range remaining {
+			blocked := false
+			for _, dep := range step.After {
+				if known[dep] && !done[dep] {
+					blocked = true
+					break
+				}
+			}
+			if !blocked {
+				ready = append(ready, i)
+			}
+		}
+
+		// A dependency cycle can't be shuffled; keep the given order
+		if len(ready) == 0 {
+			ordered = append(ordered, remaining...)
+			break
+		}
+
+		pick := ready[s.rand.Intn(len(ready))]
+		ordered = append(ordered, remaining[pick])
+		done[remaining[pick].Name] = true
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+
+	names := make([]string, len(ordered))
+	for i, step := range ordered {
+		names[i] = step.Name
+	}
+	s.tracer.Record("stealth", "step_order", map[string]interface{}{"steps": names})
+
+	return ordered
+}
@@ -31,6 +31,8 @@ type Profile struct {
 	Company     string    `json:"company"`
 	Location    string    `json:"location"`
 	ConnectionDegree string `json:"connection_degree"`
+	MutualConnections int   `json:"mutual_connections"`
+	Notes       string    `json:"notes,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -41,6 +43,7 @@ type ConnectionRequest struct {
 	ProfileID   int64     `json:"profile_id"`
 	ProfileURL  string    `json:"profile_url"`
 	Note        string    `json:"note"`
+	HasNote     bool      `json:"has_note"`
 	Status      string    `json:"status"` // pending, accepted, declined
 	SentAt      time.Time `json:"sent_at"`
 	AcceptedAt  *time.Time `json:"accepted_at,omitempty"`
@@ -53,7 +56,8 @@ type Message struct {
 	ProfileURL  string    `json:"profile_url"`
 	Content     string    `json:"content"`
 	Template    string    `json:"template"`
-	MessageType string    `json:"message_type"` // connection_note, follow_up, direct
+	MessageType string    `json:"message_type"` // connection_note, follow_up, direct, message_request
+	Status      string    `json:"status"`       // sent, pending_approval, unknown
 	SentAt      time.Time `json:"sent_at"`
 }
 
@@ -64,6 +68,7 @@ type DailyStats struct {
 	ConnectionsAccepted int  `json:"connections_accepted"`
 	MessagesSent      int    `json:"messages_sent"`
 	ProfilesViewed    int    `json:"profiles_viewed"`
+	IncidentalProfileViews int `json:"incidental_profile_views"` // visited during a connect or message
 	SearchesPerformed int    `json:"searches_performed"`
 }
 
@@ -91,108 +96,24 @@ func NewDatabase(dbPath string, log *logger.Logger) (*Database, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	// SQLite allows one writer at a time; a single connection serializes
+	// managers sharing the database instead of failing with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+
 	database := &Database{
 		db:     db,
 		logger: log.WithModule("storage"),
 	}
 
-	// Initialize schema
-	if err := database.initSchema(); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	// Bring the schema up to date
+	if err := database.Migrate(); err != nil {
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
 	}
 
 	database.logger.Info("Database initialized successfully")
 	return database, nil
 }
 
-// initSchema creates the database tables if they don't exist
-func (d *Database) initSchema() error {
-	schema := `
-	-- Profiles table
-	CREATE TABLE IF NOT EXISTS profiles (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		profile_url TEXT UNIQUE NOT NULL,
-		name TEXT,
-		first_name TEXT,
-		last_name TEXT,
-		headline TEXT,
-		company TEXT,
-		location TEXT,
-		connection_degree TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	-- Connection requests table
-	CREATE TABLE IF NOT EXISTS connection_requests (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		profile_id INTEGER,
-		profile_url TEXT NOT NULL,
-		note TEXT,
-		status TEXT DEFAULT 'pending',
-		sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		accepted_at DATETIME,
-		FOREIGN KEY (profile_id) REFERENCES profiles(id)
-	);
-
-	-- Messages table
-	CREATE TABLE IF NOT EXISTS messages (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		profile_id INTEGER,
-		profile_url TEXT NOT NULL,
-		content TEXT NOT NULL,
-		template TEXT,
-		message_type TEXT DEFAULT 'direct',
-		sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (profile_id) REFERENCES profiles(id)
-	);
-
-	-- Daily stats table
-	CREATE TABLE IF NOT EXISTS daily_stats (
-		date TEXT PRIMARY KEY,
-		connections_sent INTEGER DEFAULT 0,
-		connections_accepted INTEGER DEFAULT 0,
-		messages_sent INTEGER DEFAULT 0,
-		profiles_viewed INTEGER DEFAULT 0,
-		searches_performed INTEGER DEFAULT 0
-	);
-
-	-- Session cookies table
-	CREATE TABLE IF NOT EXISTS session_cookies (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		value TEXT NOT NULL,
-		domain TEXT,
-		path TEXT,
-		expires INTEGER,
-		http_only BOOLEAN,
-		secure BOOLEAN,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	-- Search history table
-	CREATE TABLE IF NOT EXISTS search_history (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		query TEXT NOT NULL,
-		job_title TEXT,
-		company TEXT,
-		location TEXT,
-		keywords TEXT,
-		results_count INTEGER,
-		searched_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	-- Create indexes
-	CREATE INDEX IF NOT EXISTS idx_profiles_url ON profiles(profile_url);
-	CREATE INDEX IF NOT EXISTS idx_connection_requests_status ON connection_requests(status);
-	CREATE INDEX IF NOT EXISTS idx_connection_requests_sent_at ON connection_requests(sent_at);
-	CREATE INDEX IF NOT EXISTS idx_messages_sent_at ON messages(sent_at);
-	`
-
-	_, err := d.db.Exec(schema)
-	return err
-}
-
 // Close closes the database connection
 func (d *Database) Close() error {
 	return d.db.Close()
@@ -202,11 +123,12 @@ func (d *Database) Close() error {
 // Profile Operations
 // ==============================================================================
 
-// SaveProfile saves or updates a profile
+// SaveProfile saves or updates a profile.
+// A zero mutual connection count never overwrites a previously scraped one.
 func (d *Database) SaveProfile(profile *Profile) (int64, error) {
 	query := `
-		INSERT INTO profiles (profile_url, name, first_name, last_name, headline, company, location, connection_degree)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO profiles (profile_url, name, first_name, last_name, headline, company, location, connection_degree, mutual_connections)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(profile_url) DO UPDATE SET
 			name = excluded.name,
 			first_name = excluded.first_name,
@@ -215,6 +137,7 @@ func (d *Database) SaveProfile(profile *Profile) (int64, error) {
 			company = excluded.company,
 			location = excluded.location,
 			connection_degree = excluded.connection_degree,
+			mutual_connections = CASE WHEN excluded.mutual_connections > 0 THEN excluded.mutual_connections ELSE profiles.mutual_connections END,
 			updated_at = CURRENT_TIMESTAMP
 		RETURNING id
 	`
@@ -223,6 +146,7 @@ func (d *Database) SaveProfile(profile *Profile) (int64, error) {
 	err := d.db.QueryRow(query,
 		profile.ProfileURL, profile.Name, profile.FirstName, profile.LastName,
 		profile.Headline, profile.Company, profile.Location, profile.ConnectionDegree,
+		profile.MutualConnections,
 	).Scan(&id)
 
 	if err != nil {
@@ -233,16 +157,33 @@ func (d *Database) SaveProfile(profile *Profile) (int64, error) {
 	return id, nil
 }
 
-// GetProfile retrieves a profile by URL
-func (d *Database) GetProfile(profileURL string) (*Profile, error) {
-	query := `SELECT id, profile_url, name, first_name, last_name, headline, company, location, connection_degree, created_at, updated_at FROM profiles WHERE profile_url = ?`
+// profileColumns is the column list scanned by scanProfile
+const profileColumns = `id, profile_url, name, first_name, last_name, headline, company, location, connection_degree, mutual_connections, COALESCE(notes, ''), created_at, updated_at`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanProfile reads a profile selected with profileColumns
+func scanProfile(row rowScanner) (*Profile, error) {
 	profile := &Profile{}
-	err := d.db.QueryRow(query, profileURL).Scan(
+	err := row.Scan(
 		&profile.ID, &profile.ProfileURL, &profile.Name, &profile.FirstName, &profile.LastName,
 		&profile.Headline, &profile.Company, &profile.Location, &profile.ConnectionDegree,
-		&profile.CreatedAt, &profile.UpdatedAt,
+		&profile.MutualConnections, &profile.Notes, &profile.CreatedAt, &profile.UpdatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// GetProfile retrieves a profile by URL
+func (d *Database) GetProfile(profileURL string) (*Profile, error) {
+	query := `SELECT ` + profileColumns + ` FROM profiles WHERE profile_url = ?`
+
+	profile, err := scanProfile(d.db.QueryRow(query, profileURL))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -265,9 +206,39 @@ func (d *Database) ProfileExists(profileURL string) (bool, error) {
 	return count > 0, nil
 }
 
+// KnownProfileURLs returns up to limit URLs of stored profiles and profiles
+// that were sent a connection request, most recently touched first
+func (d *Database) KnownProfileURLs(limit int) ([]string, error) {
+	query := `
+		SELECT profile_url FROM (
+			SELECT profile_url, updated_at AS touched_at FROM profiles
+			UNION ALL
+			SELECT profile_url, sent_at AS touched_at FROM connection_requests
+		)
+		GROUP BY profile_url
+		ORDER BY MAX(touched_at) DESC
+		LIMIT ?`
+
+	rows, err := d.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get known profile URLs: %w", err)
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
+
 // GetAllProfiles retrieves all profiles
 func (d *Database) GetAllProfiles() ([]*Profile, error) {
-	query := `SELECT id, profile_url, name, first_name, last_name, headline, company, location, connection_degree, created_at, updated_at FROM profiles ORDER BY created_at DESC`
+	query := `SELECT ` + profileColumns + ` FROM profiles ORDER BY created_at DESC`
 
 	rows, err := d.db.Query(query)
 	if err != nil {
@@ -277,12 +248,7 @@ func (d *Database) GetAllProfiles() ([]*Profile, error) {
 
 	var profiles []*Profile
 	for rows.Next() {
-		profile := &Profile{}
-		err := rows.Scan(
-			&profile.ID, &profile.ProfileURL, &profile.Name, &profile.FirstName, &profile.LastName,
-			&profile.Headline, &profile.Company, &profile.Location, &profile.ConnectionDegree,
-			&profile.CreatedAt, &profile.UpdatedAt,
-		)
+		profile, err := scanProfile(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -299,23 +265,27 @@ func (d *Database) GetAllProfiles() ([]*Profile, error) {
 // SaveConnectionRequest saves a connection request
 func (d *Database) SaveConnectionRequest(request *ConnectionRequest) (int64, error) {
 	query := `
-		INSERT INTO connection_requests (profile_id, profile_url, note, status, sent_at)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO connection_requests (profile_id, profile_url, note, has_note, status, sent_at)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := d.db.Exec(query,
-		request.ProfileID, request.ProfileURL, request.Note, request.Status, time.Now(),
-	)
+	// Save the request and update daily stats together
+	var id int64
+	err := d.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(query,
+			request.ProfileID, request.ProfileURL, request.Note, request.HasNote, request.Status, time.Now(),
+		)
+		if err != nil {
+			return err
+		}
+		id, _ = result.LastInsertId()
+		return incrementDailyStat(tx, "connections_sent")
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to save connection request: %w", err)
 	}
 
-	id, _ := result.LastInsertId()
 	d.logger.WithField("profile_url", request.ProfileURL).Info("Connection request saved")
-
-	// Update daily stats
-	d.incrementDailyStat("connections_sent")
-
 	return id, nil
 }
 
@@ -333,7 +303,7 @@ func (d *Database) HasSentConnectionRequest(profileURL string) (bool, error) {
 // GetPendingConnectionRequests gets all pending connection requests
 func (d *Database) GetPendingConnectionRequests() ([]*ConnectionRequest, error) {
 	query := `
-		SELECT id, profile_id, profile_url, note, status, sent_at, accepted_at
+		SELECT id, profile_id, profile_url, note, has_note, status, sent_at, accepted_at
 		FROM connection_requests WHERE status = 'pending'
 		ORDER BY sent_at DESC
 	`
@@ -347,7 +317,37 @@ func (d *Database) GetPendingConnectionRequests() ([]*ConnectionRequest, error)
 	var requests []*ConnectionRequest
 	for rows.Next() {
 		req := &ConnectionRequest{}
-		err := rows.Scan(&req.ID, &req.ProfileID, &req.ProfileURL, &req.Note, &req.Status, &req.SentAt, &req.AcceptedAt)
+		err := rows.Scan(&req.ID, &req.ProfileID, &req.ProfileURL, &req.Note, &req.HasNote, &req.Status, &req.SentAt, &req.AcceptedAt)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, nil
+}
+
+// GetPendingConnectionRequestsByCompany gets pending connection requests to
+// profiles whose stored company matches company (case-insensitive)
+func (d *Database) GetPendingConnectionRequestsByCompany(company string) ([]*ConnectionRequest, error) {
+	query := `
+		SELECT cr.id, cr.profile_id, cr.profile_url, cr.note, cr.has_note, cr.status, cr.sent_at, cr.accepted_at
+		FROM connection_requests cr
+		JOIN profiles p ON p.profile_url = cr.profile_url
+		WHERE cr.status = 'pending' AND LOWER(TRIM(p.company)) = LOWER(TRIM(?))
+		ORDER BY cr.sent_at DESC
+	`
+
+	rows, err := d.db.Query(query, company)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*ConnectionRequest
+	for rows.Next() {
+		req := &ConnectionRequest{}
+		err := rows.Scan(&req.ID, &req.ProfileID, &req.ProfileURL, &req.Note, &req.HasNote, &req.Status, &req.SentAt, &req.AcceptedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -365,10 +365,18 @@ func (d *Database) UpdateConnectionStatus(profileURL string, status string) erro
 	if status == "accepted" {
 		now := time.Now()
 		acceptedAt = &now
-		d.incrementDailyStat("connections_accepted")
 	}
 
-	_, err := d.db.Exec(query, status, acceptedAt, profileURL)
+	// Update the status and daily stats together
+	err := d.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(query, status, acceptedAt, profileURL); err != nil {
+			return err
+		}
+		if status != "accepted" {
+			return nil
+		}
+		return incrementDailyStat(tx, "connections_accepted")
+	})
 	if err != nil {
 		return fmt.Errorf("failed to update connection status: %w", err)
 	}
@@ -381,6 +389,28 @@ func (d *Database) UpdateConnectionStatus(profileURL string, status string) erro
 	return nil
 }
 
+// MarkConnectionsDeclined sets still-pending requests to the given profiles to
+// "declined" and returns how many were updated. Declined profiles are never
+// invited again, since HasSentConnectionRequest counts every status.
+func (d *Database) MarkConnectionsDeclined(profileURLs []string) (int, error) {
+	var marked int64
+	err := d.withTx(func(tx *sql.Tx) error {
+		for _, url := range profileURLs {
+			result, err := tx.Exec(`UPDATE connection_requests SET status = 'declined' WHERE profile_url = ? AND status = 'pending'`, url)
+			if err != nil {
+				return err
+			}
+			n, _ := result.RowsAffected()
+			marked += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark declined connections: %w", err)
+	}
+	return int(marked), nil
+}
+
 // GetTodayConnectionCount returns the number of connections sent today
 func (d *Database) GetTodayConnectionCount() (int, error) {
 	query := `SELECT COUNT(*) FROM connection_requests WHERE DATE(sent_at) = DATE('now')`
@@ -389,10 +419,35 @@ func (d *Database) GetTodayConnectionCount() (int, error) {
 	return count, err
 }
 
+// GetTodayConnectionCountByCompany returns the number of connections sent
+// today to profiles whose stored company matches company (case-insensitive)
+func (d *Database) GetTodayConnectionCountByCompany(company string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM connection_requests cr
+		JOIN profiles p ON p.profile_url = cr.profile_url
+		WHERE cr.sent_at >= ? AND LOWER(TRIM(p.company)) = LOWER(TRIM(?))
+	`
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var count int
+	err := d.db.QueryRow(query, midnight, company).Scan(&count)
+	return count, err
+}
+
+// GetWeeklyConnectionCount returns the number of connections sent in the trailing 7 days
+func (d *Database) GetWeeklyConnectionCount() (int, error) {
+	query := `SELECT COUNT(*) FROM connection_requests WHERE sent_at >= ?`
+	var count int
+	err := d.db.QueryRow(query, time.Now().AddDate(0, 0, -7)).Scan(&count)
+	return count, err
+}
+
 // GetRecentlyAcceptedConnections gets connections accepted in the last N days
 func (d *Database) GetRecentlyAcceptedConnections(days int) ([]*ConnectionRequest, error) {
 	query := `
-		SELECT id, profile_id, profile_url, note, status, sent_at, accepted_at
+		SELECT id, profile_id, profile_url, note, has_note, status, sent_at, accepted_at
 		FROM connection_requests 
 		WHERE status = 'accepted' AND accepted_at > datetime('now', ?)
 		ORDER BY accepted_at DESC
@@ -407,7 +462,7 @@ func (d *Database) GetRecentlyAcceptedConnections(days int) ([]*ConnectionReques
 	var requests []*ConnectionRequest
 	for rows.Next() {
 		req := &ConnectionRequest{}
-		err := rows.Scan(&req.ID, &req.ProfileID, &req.ProfileURL, &req.Note, &req.Status, &req.SentAt, &req.AcceptedAt)
+		err := rows.Scan(&req.ID, &req.ProfileID, &req.ProfileURL, &req.Note, &req.HasNote, &req.Status, &req.SentAt, &req.AcceptedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -424,29 +479,42 @@ func (d *Database) GetRecentlyAcceptedConnections(days int) ([]*ConnectionReques
 // SaveMessage saves a sent message
 func (d *Database) SaveMessage(message *Message) (int64, error) {
 	query := `
-		INSERT INTO messages (profile_id, profile_url, content, template, message_type, sent_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO messages (profile_id, profile_url, content, template, message_type, status, sent_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := d.db.Exec(query,
-		message.ProfileID, message.ProfileURL, message.Content, message.Template, message.MessageType, time.Now(),
-	)
+	if message.Status == "" {
+		message.Status = "sent"
+	}
+
+	// Save the message and update daily stats together; queued message
+	// requests haven't been delivered so they aren't counted
+	var id int64
+	err := d.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(query,
+			message.ProfileID, message.ProfileURL, message.Content, message.Template, message.MessageType, message.Status, time.Now(),
+		)
+		if err != nil {
+			return err
+		}
+		id, _ = result.LastInsertId()
+		if message.Status != "sent" {
+			return nil
+		}
+		return incrementDailyStat(tx, "messages_sent")
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to save message: %w", err)
 	}
 
-	id, _ := result.LastInsertId()
 	d.logger.WithField("profile_url", message.ProfileURL).Info("Message saved")
-
-	// Update daily stats
-	d.incrementDailyStat("messages_sent")
-
 	return id, nil
 }
 
 // HasSentFollowUpMessage checks if a follow-up message was already sent
 func (d *Database) HasSentFollowUpMessage(profileURL string) (bool, error) {
-	query := `SELECT COUNT(*) FROM messages WHERE profile_url = ? AND message_type = 'follow_up'`
+	// A follow-up that LinkedIn queued as a message request still counts
+	query := `SELECT COUNT(*) FROM messages WHERE profile_url = ? AND message_type IN ('follow_up', 'message_request')`
 	var count int
 	err := d.db.QueryRow(query, profileURL).Scan(&count)
 	if err != nil {
@@ -455,6 +523,17 @@ func (d *Database) HasSentFollowUpMessage(profileURL string) (bool, error) {
 	return count > 0, nil
 }
 
+// HasMessagedSince checks if any message went to a profile at or after since
+func (d *Database) HasMessagedSince(profileURL string, since time.Time) (bool, error) {
+	query := `SELECT COUNT(*) FROM messages WHERE profile_url = ? AND sent_at >= ?`
+	var count int
+	err := d.db.QueryRow(query, profileURL, since).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 // GetTodayMessageCount returns the number of messages sent today
 func (d *Database) GetTodayMessageCount() (int, error) {
 	query := `SELECT COUNT(*) FROM messages WHERE DATE(sent_at) = DATE('now')`
@@ -466,7 +545,7 @@ func (d *Database) GetTodayMessageCount() (int, error) {
 // GetMessageHistory gets message history for a profile
 func (d *Database) GetMessageHistory(profileURL string) ([]*Message, error) {
 	query := `
-		SELECT id, profile_id, profile_url, content, template, message_type, sent_at
+		SELECT id, profile_id, profile_url, content, template, message_type, status, sent_at
 		FROM messages WHERE profile_url = ?
 		ORDER BY sent_at DESC
 	`
@@ -480,7 +559,7 @@ func (d *Database) GetMessageHistory(profileURL string) ([]*Message, error) {
 	var messages []*Message
 	for rows.Next() {
 		msg := &Message{}
-		err := rows.Scan(&msg.ID, &msg.ProfileID, &msg.ProfileURL, &msg.Content, &msg.Template, &msg.MessageType, &msg.SentAt)
+		err := rows.Scan(&msg.ID, &msg.ProfileID, &msg.ProfileURL, &msg.Content, &msg.Template, &msg.MessageType, &msg.Status, &msg.SentAt)
 		if err != nil {
 			return nil, err
 		}
@@ -497,12 +576,12 @@ func (d *Database) GetMessageHistory(profileURL string) ([]*Message, error) {
 // GetTodayStats returns today's activity statistics
 func (d *Database) GetTodayStats() (*DailyStats, error) {
 	today := time.Now().Format("2006-01-02")
-	query := `SELECT date, connections_sent, connections_accepted, messages_sent, profiles_viewed, searches_performed FROM daily_stats WHERE date = ?`
+	query := `SELECT date, connections_sent, connections_accepted, messages_sent, profiles_viewed, incidental_profile_views, searches_performed FROM daily_stats WHERE date = ?`
 
 	stats := &DailyStats{Date: today}
 	err := d.db.QueryRow(query, today).Scan(
 		&stats.Date, &stats.ConnectionsSent, &stats.ConnectionsAccepted,
-		&stats.MessagesSent, &stats.ProfilesViewed, &stats.SearchesPerformed,
+		&stats.MessagesSent, &stats.ProfilesViewed, &stats.IncidentalProfileViews, &stats.SearchesPerformed,
 	)
 
 	if err == sql.ErrNoRows {
@@ -515,28 +594,98 @@ func (d *Database) GetTodayStats() (*DailyStats, error) {
 	return stats, nil
 }
 
-// incrementDailyStat increments a daily stat counter
-func (d *Database) incrementDailyStat(statName string) error {
-	today := time.Now().Format("2006-01-02")
+// GetDailyStatsRange returns one entry per day from from to to (inclusive),
+// oldest first. Days without activity are included with zero counts.
+func (d *Database) GetDailyStatsRange(from, to time.Time) ([]*DailyStats, error) {
+	first := from.Format("2006-01-02")
+	last := to.Format("2006-01-02")
+	query := `
+		SELECT date, connections_sent, connections_accepted, messages_sent, profiles_viewed, incidental_profile_views, searches_performed
+		FROM daily_stats WHERE date >= ? AND date <= ?
+	`
 
-	// Ensure row exists
-	insertQuery := `INSERT OR IGNORE INTO daily_stats (date) VALUES (?)`
-	d.db.Exec(insertQuery, today)
+	rows, err := d.db.Query(query, first, last)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily stats: %w", err)
+	}
+	defer rows.Close()
 
-	// Update the stat
-	updateQuery := fmt.Sprintf(`UPDATE daily_stats SET %s = %s + 1 WHERE date = ?`, statName, statName)
-	_, err := d.db.Exec(updateQuery, today)
+	byDate := make(map[string]*DailyStats)
+	for rows.Next() {
+		stats := &DailyStats{}
+		err := rows.Scan(&stats.Date, &stats.ConnectionsSent, &stats.ConnectionsAccepted,
+			&stats.MessagesSent, &stats.ProfilesViewed, &stats.IncidentalProfileViews, &stats.SearchesPerformed)
+		if err != nil {
+			return nil, err
+		}
+		byDate[stats.Date] = stats
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var days []*DailyStats
+	for day := from; day.Format("2006-01-02") <= last; day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if stats, ok := byDate[date]; ok {
+			days = append(days, stats)
+		} else {
+			days = append(days, &DailyStats{Date: date})
+		}
+	}
+	return days, nil
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// withTx runs fn in a transaction, committing only if it succeeds
+func (d *Database) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// incrementDailyStat increments a daily stat counter in a single atomic
+// upsert, so concurrent increments are never lost
+func incrementDailyStat(ex execer, statName string) error {
+	today := time.Now().Format("2006-01-02")
+
+	query := fmt.Sprintf(`
+		INSERT INTO daily_stats (date, %s) VALUES (?, 1)
+		ON CONFLICT(date) DO UPDATE SET %s = %s + 1
+	`, statName, statName, statName)
+	_, err := ex.Exec(query, today)
 	return err
 }
 
-// IncrementProfileViews increments the profile views counter
-func (d *Database) IncrementProfileViews() error {
-	return d.incrementDailyStat("profiles_viewed")
+// Profile view types, shared with the rate limiter's counters
+const (
+	ViewExplicit   = "explicit"   // opening the profile is the action itself
+	ViewIncidental = "incidental" // opened on the way to a connect or message
+)
+
+// IncrementProfileViews increments the profile views counter for a view
+// type: incidental views (during a connect or message) are kept apart
+// from explicit ones
+func (d *Database) IncrementProfileViews(viewType string) error {
+	if viewType == ViewIncidental {
+		return incrementDailyStat(d.db, "incidental_profile_views")
+	}
+	return incrementDailyStat(d.db, "profiles_viewed")
 }
 
 // IncrementSearches increments the searches counter
 func (d *Database) IncrementSearches() error {
-	return d.incrementDailyStat("searches_performed")
+	return incrementDailyStat(d.db, "searches_performed")
 }
 
 // ==============================================================================
@@ -618,12 +767,29 @@ func (d *Database) LoadCookiesFromFile(filePath string) ([]*SessionCookie, error
 	return cookies, nil
 }
 
+// ClearCookies deletes the stored session cookies, returning how many there were
+func (d *Database) ClearCookies() (int64, error) {
+	result, err := d.db.Exec("DELETE FROM session_cookies")
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear cookies: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// RemoveCookiesFile deletes the cookies JSON file; a missing file is not an error
+func (d *Database) RemoveCookiesFile(filePath string) error {
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cookies file: %w", err)
+	}
+	return nil
+}
+
 // ==============================================================================
 // Search History Operations
 // ==============================================================================
 
-// SaveSearchHistory saves a search query
-func (d *Database) SaveSearchHistory(query, jobTitle, company, location string, keywords []string, resultsCount int) error {
+// SaveSearchHistory saves a search query and returns its ID
+func (d *Database) SaveSearchHistory(query, jobTitle, company, location string, keywords []string, resultsCount int) (int64, error) {
 	keywordsJSON, _ := json.Marshal(keywords)
 
 	insertQuery := `
@@ -631,11 +797,105 @@ func (d *Database) SaveSearchHistory(query, jobTitle, company, location string,
 		VALUES (?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := d.db.Exec(insertQuery, query, jobTitle, company, location, string(keywordsJSON), resultsCount)
+	// Save the search and update daily stats together
+	var id int64
+	err := d.withTx(func(tx *sql.Tx) error {
+		result, err := tx.Exec(insertQuery, query, jobTitle, company, location, string(keywordsJSON), resultsCount)
+		if err != nil {
+			return err
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return err
+		}
+		return incrementDailyStat(tx, "searches_performed")
+	})
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("failed to save search history: %w", err)
 	}
+	return id, nil
+}
 
-	d.incrementDailyStat("searches_performed")
+// SaveSearchCursor records the last fully collected result page of the search
+// with the given signature
+func (d *Database) SaveSearchCursor(signature string, page int) error {
+	query := `
+		INSERT INTO search_cursors (signature, page, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(signature) DO UPDATE SET page = excluded.page, updated_at = CURRENT_TIMESTAMP
+	`
+	if _, err := d.db.Exec(query, signature, page); err != nil {
+		return fmt.Errorf("failed to save search cursor: %w", err)
+	}
 	return nil
 }
+
+// GetSearchCursor returns the last fully collected result page of a search,
+// or 0 if none was recorded
+func (d *Database) GetSearchCursor(signature string) (int, error) {
+	var page int
+	err := d.db.QueryRow(`SELECT page FROM search_cursors WHERE signature = ?`, signature).Scan(&page)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return page, err
+}
+
+// ClearSearchCursor forgets a search's cursor so it starts from page 1 again
+func (d *Database) ClearSearchCursor(signature string) error {
+	if _, err := d.db.Exec(`DELETE FROM search_cursors WHERE signature = ?`, signature); err != nil {
+		return fmt.Errorf("failed to clear search cursor: %w", err)
+	}
+	return nil
+}
+
+// ==============================================================================
+// Rate Limit Block Operations
+// ==============================================================================
+
+// SaveBlockedUntil records that LinkedIn refuses an action type until the given time
+func (d *Database) SaveBlockedUntil(actionType string, until time.Time) error {
+	query := `
+		INSERT INTO rate_limit_blocks (action_type, blocked_until, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(action_type) DO UPDATE SET blocked_until = excluded.blocked_until, updated_at = CURRENT_TIMESTAMP
+	`
+	if _, err := d.db.Exec(query, actionType, until); err != nil {
+		return fmt.Errorf("failed to save rate limit block: %w", err)
+	}
+	return nil
+}
+
+// GetBlockedUntil returns when a stored block on an action type ends, or the
+// zero time if none was recorded
+func (d *Database) GetBlockedUntil(actionType string) (time.Time, error) {
+	var until time.Time
+	err := d.db.QueryRow(`SELECT blocked_until FROM rate_limit_blocks WHERE action_type = ?`, actionType).Scan(&until)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return until, err
+}
+
+// ==============================================================================
+// Page Fingerprint Operations
+// ==============================================================================
+
+// SavePageFingerprint records the layout fingerprint of a page
+func (d *Database) SavePageFingerprint(pageKey, fingerprint string) error {
+	_, err := d.db.Exec(`INSERT INTO page_fingerprints (page_key, fingerprint) VALUES (?, ?)`, pageKey, fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to save page fingerprint: %w", err)
+	}
+	return nil
+}
+
+// GetLatestPageFingerprint returns the most recent fingerprint of a page, or
+// an empty string if none was recorded yet
+func (d *Database) GetLatestPageFingerprint(pageKey string) (string, error) {
+	var fingerprint string
+	err := d.db.QueryRow(`SELECT fingerprint FROM page_fingerprints WHERE page_key = ? ORDER BY id DESC LIMIT 1`, pageKey).Scan(&fingerprint)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return fingerprint, err
+}
@@ -0,0 +1,728 @@
+// Package storage - Tests for database operations
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBlockedUntil(t *testing.T) {
+	db := newTestDatabase(t)
+
+	until, err := db.GetBlockedUntil("connection")
+	if err != nil || !until.IsZero() {
+		t.Fatalf("Expected no block, got %v, %v", until, err)
+	}
+
+	want := time.Now().Add(72 * time.Hour).Truncate(time.Second)
+	if err := db.SaveBlockedUntil("connection", want.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveBlockedUntil("connection", want); err != nil {
+		t.Fatal(err)
+	}
+
+	until, err = db.GetBlockedUntil("connection")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !until.Equal(want) {
+		t.Errorf("Expected block until %v, got %v", want, until)
+	}
+}
+
+func TestSearchCursor(t *testing.T) {
+	db := newTestDatabase(t)
+
+	if page, err := db.GetSearchCursor("abc"); err != nil || page != 0 {
+		t.Fatalf("Expected no cursor, got %d, %v", page, err)
+	}
+
+	for _, page := range []int{1, 3} {
+		if err := db.SaveSearchCursor("abc", page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if page, _ := db.GetSearchCursor("abc"); page != 3 {
+		t.Errorf("Expected cursor at page 3, got %d", page)
+	}
+	if page, _ := db.GetSearchCursor("other"); page != 0 {
+		t.Errorf("Cursors must be per signature, got %d", page)
+	}
+
+	if err := db.ClearSearchCursor("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if page, _ := db.GetSearchCursor("abc"); page != 0 {
+		t.Errorf("Expected a cleared cursor, got %d", page)
+	}
+}
+
+func TestAcceptanceLatencyStats(t *testing.T) {
+	db := newTestDatabase(t)
+
+	stats, err := db.GetAcceptanceLatencyStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Count != 0 {
+		t.Fatalf("Expected no samples, got %d", stats.Count)
+	}
+
+	// Accepted after 1..10 days, plus one pending request that must be ignored
+	sentAt := time.Now().Add(-30 * 24 * time.Hour)
+	for i := 1; i <= 10; i++ {
+		url := fmt.Sprintf("https://www.linkedin.com/in/p%d/", i)
+		id, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: url, Status: "accepted"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		acceptedAt := sentAt.Add(time.Duration(i) * 24 * time.Hour)
+		if _, err := db.db.Exec(`UPDATE connection_requests SET sent_at = ?, accepted_at = ? WHERE id = ?`, sentAt, acceptedAt, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: "https://www.linkedin.com/in/pending/", Status: "pending"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err = db.GetAcceptanceLatencyStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	near := func(got, want float64) bool { return math.Abs(got-want) < 0.01 }
+	if stats.Count != 10 {
+		t.Errorf("Expected 10 samples, got %d", stats.Count)
+	}
+	if !near(stats.Mean, 5.5) || !near(stats.Median, 5.5) || !near(stats.P90, 9.1) {
+		t.Errorf("Unexpected stats: mean %.2f, median %.2f, p90 %.2f", stats.Mean, stats.Median, stats.P90)
+	}
+	if !near(stats.Min, 1) || !near(stats.Max, 10) {
+		t.Errorf("Unexpected range: %.2f - %.2f", stats.Min, stats.Max)
+	}
+}
+
+func TestGetPendingConnectionRequestsByCompany(t *testing.T) {
+	db := newTestDatabase(t)
+
+	profiles := map[string]string{
+		"https://www.linkedin.com/in/a/": "Acme Corp",
+		"https://www.linkedin.com/in/b/": "acme corp ",
+		"https://www.linkedin.com/in/c/": "Globex",
+	}
+	for url, company := range profiles {
+		if _, err := db.SaveProfile(&Profile{ProfileURL: url, Company: company}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for url, status := range map[string]string{
+		"https://www.linkedin.com/in/a/": "pending",
+		"https://www.linkedin.com/in/b/": "accepted",
+		"https://www.linkedin.com/in/c/": "pending",
+	} {
+		if _, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: url, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: "https://www.linkedin.com/in/unknown/", Status: "pending"}); err != nil {
+		t.Fatal(err)
+	}
+
+	requests, err := db.GetPendingConnectionRequestsByCompany("ACME corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 || requests[0].ProfileURL != "https://www.linkedin.com/in/a/" {
+		t.Fatalf("Expected only the pending Acme request, got %d", len(requests))
+	}
+}
+
+func TestMessageRequestStatus(t *testing.T) {
+	db := newTestDatabase(t)
+	url := "https://www.linkedin.com/in/a/"
+
+	if _, err := db.SaveMessage(&Message{ProfileURL: url, Content: "hi", MessageType: "message_request", Status: "pending_approval"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SaveMessage(&Message{ProfileURL: url, Content: "hi", MessageType: "direct"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := db.GetMessages(MessageFilter{Status: "pending_approval"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].MessageType != "message_request" {
+		t.Fatalf("Expected one pending message request, got %d", len(pending))
+	}
+
+	sent, err := db.GetMessages(MessageFilter{Status: "sent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("Messages default to sent, got %d sent", len(sent))
+	}
+
+	stats, err := db.GetTodayStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.MessagesSent != 1 {
+		t.Errorf("Only delivered messages should count as sent, got %d", stats.MessagesSent)
+	}
+
+	// A queued follow-up must not be sent again
+	if hasSent, _ := db.HasSentFollowUpMessage(url); !hasSent {
+		t.Error("A message request should count as a sent follow-up")
+	}
+}
+
+func TestProfileTags(t *testing.T) {
+	db := newTestDatabase(t)
+	a, b := "https://www.linkedin.com/in/a/", "https://www.linkedin.com/in/b/"
+	for _, url := range []string{a, b} {
+		if _, err := db.SaveProfile(&Profile{ProfileURL: url, Name: url}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.AddTag(a, " Warm Lead "); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddTag(a, "warm lead"); err != nil {
+		t.Fatalf("Adding a tag twice should be a no-op: %v", err)
+	}
+	if err := db.AddTag(b, "met at conf"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddTag("https://www.linkedin.com/in/unknown/", "x"); err == nil {
+		t.Error("Tagging an unknown profile should fail")
+	}
+
+	profiles, err := db.GetProfilesByTag("WARM LEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].ProfileURL != a {
+		t.Fatalf("Expected only profile a to be tagged, got %d", len(profiles))
+	}
+
+	if err := db.RemoveTag(a, "warm lead"); err != nil {
+		t.Fatal(err)
+	}
+	if tags, _ := db.GetTags(a); len(tags) != 0 {
+		t.Errorf("Expected no tags after removal, got %v", tags)
+	}
+
+	if err := db.SetProfileNotes(b, "Spoke about Go"); err != nil {
+		t.Fatal(err)
+	}
+	profile, err := db.GetProfile(b)
+	if err != nil || profile.Notes != "Spoke about Go" {
+		t.Errorf("Expected notes to be stored, got %+v (%v)", profile, err)
+	}
+
+	// Saving a profile again from a search keeps its notes
+	if _, err := db.SaveProfile(&Profile{ProfileURL: b, Name: "B"}); err != nil {
+		t.Fatal(err)
+	}
+	if profile, _ := db.GetProfile(b); profile.Notes != "Spoke about Go" {
+		t.Error("Re-saving a profile should not clear its notes")
+	}
+}
+
+func TestExperimentResults(t *testing.T) {
+	db := newTestDatabase(t)
+
+	seed := []struct {
+		variant string
+		status  string
+	}{
+		{"short", "accepted"},
+		{"short", "pending"},
+		{"short", "accepted"},
+		{"long", "pending"},
+		{"no_note", "declined"},
+	}
+	for i, s := range seed {
+		url := fmt.Sprintf("https://www.linkedin.com/in/p%d/", i)
+		id, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: url, Status: s.status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.RecordExperimentVariant(id, url, s.variant); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := db.GetExperimentResults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 variants, got %d", len(results))
+	}
+
+	short := results["short"]
+	if short.Sent != 3 || short.Accepted != 2 || math.Abs(short.AcceptanceRate-2.0/3) > 1e-9 {
+		t.Errorf("Unexpected short variant stats: %+v", short)
+	}
+	if long := results["long"]; long.Sent != 1 || long.Accepted != 0 || long.AcceptanceRate != 0 {
+		t.Errorf("Unexpected long variant stats: %+v", long)
+	}
+}
+
+func TestIncrementDailyStatConcurrent(t *testing.T) {
+	db := newTestDatabase(t)
+
+	const workers, perWorker = 20, 25
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				if err := incrementDailyStat(db.db, "profiles_viewed"); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: "https://www.linkedin.com/in/x/", Status: "pending"}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats, err := db.GetTodayStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ProfilesViewed != workers*perWorker {
+		t.Errorf("Expected %d profile views, got %d", workers*perWorker, stats.ProfilesViewed)
+	}
+	if stats.ConnectionsSent != workers*perWorker {
+		t.Errorf("Expected %d connections sent, got %d", workers*perWorker, stats.ConnectionsSent)
+	}
+}
+
+func TestGetDailyStatsRange(t *testing.T) {
+	db := newTestDatabase(t)
+
+	if err := db.IncrementProfileViews("explicit"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.IncrementProfileViews("incidental"); err != nil {
+		t.Fatal(err)
+	}
+
+	today := time.Now()
+	days, err := db.GetDailyStatsRange(today.AddDate(0, 0, -2), today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 3 {
+		t.Fatalf("Expected 3 days, got %d", len(days))
+	}
+	if days[0].Date != today.AddDate(0, 0, -2).Format("2006-01-02") || days[0].ProfilesViewed != 0 {
+		t.Errorf("Unexpected first day: %+v", days[0])
+	}
+	if days[2].Date != today.Format("2006-01-02") || days[2].ProfilesViewed != 1 || days[2].IncidentalProfileViews != 1 {
+		t.Errorf("Unexpected last day: %+v", days[2])
+	}
+}
+
+func TestConnectionsSnapshotDiff(t *testing.T) {
+	db := newTestDatabase(t)
+
+	added, err := db.DiffConnections([]string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 2 {
+		t.Fatalf("Expected every connection to be new on an empty snapshot, got %v", added)
+	}
+
+	if err := db.SaveConnectionsSnapshot([]string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveConnectionsSnapshot([]string{"b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	added, err = db.DiffConnections([]string{"c", "a", "d", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(added) != "[c d]" {
+		t.Errorf("Expected [c d], got %v", added)
+	}
+}
+
+func TestGetProfilesBySearch(t *testing.T) {
+	db := newTestDatabase(t)
+
+	first, err := db.SaveSearchHistory("q1", "Engineer", "", "", []string{"go"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := db.SaveSearchHistory("q2", "Recruiter", "", "", nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == 0 || second == first {
+		t.Fatalf("Expected distinct search IDs, got %d and %d", first, second)
+	}
+
+	link := func(searchID int64, url string) {
+		profileID, err := db.SaveProfile(&Profile{ProfileURL: url, Name: url})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.LinkSearchResult(searchID, profileID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	link(first, "https://www.linkedin.com/in/a")
+	link(first, "https://www.linkedin.com/in/b")
+	link(first, "https://www.linkedin.com/in/b")
+	link(second, "https://www.linkedin.com/in/b")
+
+	profiles, err := db.GetProfilesBySearch(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 || profiles[0].Name != "https://www.linkedin.com/in/a" {
+		t.Errorf("Expected profiles a and b for the first search, got %d", len(profiles))
+	}
+
+	profiles, err = db.GetProfilesBySearch(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "https://www.linkedin.com/in/b" {
+		t.Errorf("Expected only profile b for the second search, got %d", len(profiles))
+	}
+}
+
+func TestGetWeeklyConnectionCount(t *testing.T) {
+	db := newTestDatabase(t)
+
+	for _, url := range []string{"https://www.linkedin.com/in/a", "https://www.linkedin.com/in/b"} {
+		if _, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: url, Status: "pending"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().AddDate(0, 0, -8)
+	if _, err := db.db.Exec(`INSERT INTO connection_requests (profile_url, status, sent_at) VALUES (?, 'pending', ?)`, "https://www.linkedin.com/in/old", old); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := db.GetWeeklyConnectionCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 connections in the last 7 days, got %d", count)
+	}
+}
+
+func TestMarkConnectionsDeclined(t *testing.T) {
+	db := newTestDatabase(t)
+
+	for url, status := range map[string]string{
+		"https://www.linkedin.com/in/a/": "pending",
+		"https://www.linkedin.com/in/b/": "pending",
+		"https://www.linkedin.com/in/c/": "accepted",
+	} {
+		if _, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: url, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	marked, err := db.MarkConnectionsDeclined([]string{
+		"https://www.linkedin.com/in/a/",
+		"https://www.linkedin.com/in/c/",
+		"https://www.linkedin.com/in/unknown/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marked != 1 {
+		t.Errorf("Expected only the pending request to be marked, got %d", marked)
+	}
+
+	declined, err := db.GetConnectionRequests(ConnectionFilter{Status: "declined"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(declined) != 1 || declined[0].ProfileURL != "https://www.linkedin.com/in/a/" {
+		t.Errorf("Expected a to be declined, got %d requests", len(declined))
+	}
+
+	// Declined profiles still count as contacted, so they are never invited again
+	if sent, _ := db.HasSentConnectionRequest("https://www.linkedin.com/in/a/"); !sent {
+		t.Error("Declined profile should be excluded from future targeting")
+	}
+}
+
+func TestTaskQueue(t *testing.T) {
+	db := newTestDatabase(t)
+
+	past := time.Now().Add(-time.Hour)
+	connectID, err := db.EnqueueTask(TaskConnect, "https://www.linkedin.com/in/a/", past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.EnqueueTask(TaskView, "https://www.linkedin.com/in/b/", past.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.EnqueueTask(TaskMessage, "https://www.linkedin.com/in/c/", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.EnqueueTask("poke", "https://www.linkedin.com/in/d/", past); err == nil {
+		t.Error("Expected an error for an unknown task type")
+	}
+
+	// Queueing the same pending task again returns the existing one
+	again, err := db.EnqueueTask(TaskConnect, "https://www.linkedin.com/in/a/", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != connectID {
+		t.Errorf("Expected duplicate task to reuse ID %d, got %d", connectID, again)
+	}
+
+	task, err := db.NextDueTask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task == nil || task.ID != connectID {
+		t.Fatalf("Expected the oldest due task %d, got %+v", connectID, task)
+	}
+
+	// Filtering by type skips tasks that can't run right now
+	task, err = db.NextDueTask(TaskView, TaskMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task == nil || task.TaskType != TaskView {
+		t.Fatalf("Expected the due view task, got %+v", task)
+	}
+
+	if err := db.CompleteTask(task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if task, _ := db.NextDueTask(TaskView, TaskMessage); task != nil {
+		t.Errorf("Expected no due view or message task, got %+v", task)
+	}
+
+	// Failures are retried until MaxTaskAttempts, then the task is failed
+	for i := 0; i < MaxTaskAttempts; i++ {
+		if err := db.FailTask(connectID, "button not found", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if task, _ := db.NextDueTask(); task != nil {
+		t.Errorf("Expected no due task after repeated failures, got %+v", task)
+	}
+	failed, err := db.CountTasks(TaskFailed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed != 1 {
+		t.Errorf("Expected 1 failed task, got %d", failed)
+	}
+
+	// Errors a retry won't fix fail the task on the first attempt
+	viewID, err := db.EnqueueTask(TaskView, "https://www.linkedin.com/in/d/", past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AbandonTask(viewID, "already sent"); err != nil {
+		t.Fatal(err)
+	}
+	if failed, _ := db.CountTasks(TaskFailed); failed != 2 {
+		t.Errorf("Expected the abandoned task to be failed, got %d failed", failed)
+	}
+}
+
+func TestKnownProfileURLs(t *testing.T) {
+	db := newTestDatabase(t)
+
+	if _, err := db.SaveProfile(&Profile{ProfileURL: "https://www.linkedin.com/in/a/", Name: "A"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, url := range []string{"https://www.linkedin.com/in/a/", "https://www.linkedin.com/in/b/"} {
+		if _, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: url, Status: "pending"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	urls, err := db.KnownProfileURLs(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 2 {
+		t.Errorf("Expected 2 distinct known URLs, got %v", urls)
+	}
+
+	urls, err = db.KnownProfileURLs(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 1 {
+		t.Errorf("Expected the limit to apply, got %v", urls)
+	}
+}
+
+func TestGetProfilesByFilter(t *testing.T) {
+	db := newTestDatabase(t)
+	profiles := []*Profile{
+		{ProfileURL: "https://www.linkedin.com/in/a/", Company: "Acme Corp", ConnectionDegree: "1st"},
+		{ProfileURL: "https://www.linkedin.com/in/b/", Company: "Acme Corp", ConnectionDegree: "2nd"},
+		{ProfileURL: "https://www.linkedin.com/in/c/", Company: "Globex", ConnectionDegree: "1st"},
+	}
+	for _, p := range profiles {
+		if _, err := db.SaveProfile(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.AddTag(profiles[0].ProfileURL, "lead")
+	db.AddTag(profiles[2].ProfileURL, "lead")
+
+	filter, err := ParseProfileFilter("tag:Lead, degree:1st")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.GetProfiles(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 first-degree leads, got %d", len(got))
+	}
+
+	got, _ = db.GetProfiles(ProfileFilter{Company: "acme", ConnectionDegree: "1st"})
+	if len(got) != 1 || got[0].ProfileURL != profiles[0].ProfileURL {
+		t.Errorf("Expected only profile a for acme/1st, got %d", len(got))
+	}
+
+	for _, spec := range []string{"", "tag", "title:cto", "company:"} {
+		if _, err := ParseProfileFilter(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+
+	db.SaveMessage(&Message{ProfileURL: profiles[0].ProfileURL, Content: "hi", MessageType: "direct"})
+	if recent, _ := db.HasMessagedSince(profiles[0].ProfileURL, time.Now().Add(-time.Hour)); !recent {
+		t.Error("Expected the message just sent to count as recent")
+	}
+	if recent, _ := db.HasMessagedSince(profiles[2].ProfileURL, time.Now().Add(-time.Hour)); recent {
+		t.Error("Profile c was never messaged")
+	}
+}
+
+func TestExportConnectionsCSV(t *testing.T) {
+	db := newTestDatabase(t)
+	a, b := "https://www.linkedin.com/in/a/", "https://www.linkedin.com/in/b/"
+	db.SaveProfile(&Profile{ProfileURL: a, Name: "Ann Lee", Company: "Acme, Inc."})
+	db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: a, Note: "Hi \"Ann\"\nlet's connect", HasNote: true, Status: "pending"})
+	db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: b, Status: "pending"})
+	if err := db.UpdateConnectionStatus(a, "accepted"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := db.ExportConnectionsCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got %d records", len(records))
+	}
+	first := records[1]
+	if first[0] != a || first[1] != "Ann Lee" || first[2] != "Acme, Inc." || first[3] != "Hi \"Ann\"\nlet's connect" {
+		t.Errorf("Unexpected first row %q", first)
+	}
+	if first[4] != "accepted" || first[6] == "" {
+		t.Errorf("Expected an accepted row with accepted_at, got %q", first)
+	}
+	if second := records[2]; second[1] != "" || second[4] != "pending" || second[6] != "" {
+		t.Errorf("Expected a pending row without profile details, got %q", second)
+	}
+}
+
+func TestExportConnectionsCSVEscapesFormulas(t *testing.T) {
+	db := newTestDatabase(t)
+	url := "https://www.linkedin.com/in/mallory/"
+	db.SaveProfile(&Profile{ProfileURL: url, Name: "=HYPERLINK(\"http://evil\")", Company: "@Acme"})
+	db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: url, Note: "-1+2", HasNote: true, Status: "pending"})
+
+	var buf bytes.Buffer
+	if err := db.ExportConnectionsCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	row := records[1]
+	if row[1] != "'=HYPERLINK(\"http://evil\")" || row[2] != "'@Acme" || row[3] != "'-1+2" {
+		t.Errorf("Expected formula-like cells to be quoted, got %q", row)
+	}
+	if row[0] != url {
+		t.Errorf("Plain cells should be left alone, got %q", row[0])
+	}
+}
+
+func TestClearCookies(t *testing.T) {
+	db := newTestDatabase(t)
+	cookies := []*SessionCookie{
+		{Name: "li_at", Value: "secret", Domain: ".linkedin.com", Path: "/", Expires: time.Now().Add(time.Hour).Unix()},
+		{Name: "JSESSIONID", Value: "ajax", Domain: ".www.linkedin.com", Path: "/"},
+	}
+	if err := db.SaveCookies(cookies); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	if err := db.SaveCookiesToFile(cookies, path); err != nil {
+		t.Fatal(err)
+	}
+
+	cleared, err := db.ClearCookies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cleared != 2 {
+		t.Errorf("Expected 2 cookies cleared, got %d", cleared)
+	}
+	if stored, _ := db.LoadCookies(); len(stored) != 0 {
+		t.Errorf("Expected no cookies left in the database, got %d", len(stored))
+	}
+
+	if err := db.RemoveCookiesFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the cookies file to be removed, got %v", err)
+	}
+	if err := db.RemoveCookiesFile(path); err != nil {
+		t.Errorf("Removing a missing cookies file should succeed, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+// Package storage - experiments.go handles A/B tracking of connection note variants
+package storage
+
+import "fmt"
+
+// ExperimentStats summarizes the requests sent with one note variant
+type ExperimentStats struct {
+	Sent           int     `json:"sent"`
+	Accepted       int     `json:"accepted"`
+	AcceptanceRate float64 `json:"acceptance_rate"` // accepted / sent, 0-1
+}
+
+// RecordExperimentVariant stores the note variant used for a connection request
+func (d *Database) RecordExperimentVariant(requestID int64, profileURL, variant string) error {
+	_, err := d.db.Exec(`INSERT INTO experiments (request_id, profile_url, variant) VALUES (?, ?, ?)`, requestID, profileURL, variant)
+	if err != nil {
+		return fmt.Errorf("failed to record experiment variant: %w", err)
+	}
+	return nil
+}
+
+// GetExperimentResults returns the sample size and acceptance rate of each
+// note variant, keyed by variant ID
+func (d *Database) GetExperimentResults() (map[string]ExperimentStats, error) {
+	query := `
+		SELECT e.variant, COUNT(*), COALESCE(SUM(CASE WHEN cr.status = 'accepted' THEN 1 ELSE 0 END), 0)
+		FROM experiments e
+		JOIN connection_requests cr ON cr.id = e.request_id
+		GROUP BY e.variant
+	`
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query experiment results: %w", err)
+	}
+	defer rows.Close()
+
+	results := make(map[string]ExperimentStats)
+	for rows.Next() {
+		var variant string
+		var stats ExperimentStats
+		if err := rows.Scan(&variant, &stats.Sent, &stats.Accepted); err != nil {
+			return nil, err
+		}
+		if stats.Sent > 0 {
+			stats.AcceptanceRate = float64(stats.Accepted) / float64(stats.Sent)
+		}
+		results[variant] = stats
+	}
+
+	return results, rows.Err()
+}
@@ -0,0 +1,64 @@
+// Package storage - export.go handles CSV exports of outreach history
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// exportTimeFormat is spreadsheet-friendly and sorts as text
+const exportTimeFormat = "2006-01-02 15:04:05"
+
+// csvText makes scraped text safe to open in a spreadsheet: a cell starting
+// with =, +, - or @ would run as a formula, so it gets a leading quote
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ExportConnectionsCSV writes every connection request, oldest first, with
+// the invited profile's name and company and whether it was accepted
+func (d *Database) ExportConnectionsCSV(w io.Writer) error {
+	query := `
+		SELECT cr.profile_url, COALESCE(p.name, ''), COALESCE(p.company, ''),
+			COALESCE(cr.note, ''), cr.status, cr.sent_at, cr.accepted_at
+		FROM connection_requests cr
+		LEFT JOIN profiles p ON p.profile_url = cr.profile_url
+		ORDER BY cr.sent_at ASC, cr.id ASC
+	`
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to query connection requests: %w", err)
+	}
+	defer rows.Close()
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"profile_url", "name", "company", "note", "status", "sent_at", "accepted_at"})
+
+	for rows.Next() {
+		var url, name, company, note, status string
+		var sentAt time.Time
+		var acceptedAt *time.Time
+		if err := rows.Scan(&url, &name, &company, &note, &status, &sentAt, &acceptedAt); err != nil {
+			return err
+		}
+
+		accepted := ""
+		if acceptedAt != nil {
+			accepted = acceptedAt.Local().Format(exportTimeFormat)
+		}
+		out.Write([]string{csvText(url), csvText(name), csvText(company), csvText(note), status, sentAt.Local().Format(exportTimeFormat), accepted})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,391 @@
+// Package storage - migrations.go handles versioned schema migrations
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migration is a single ordered schema change
+type migration struct {
+	version int
+	name    string
+	apply   func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in order. Append new entries with the
+// next version number; never edit or reorder migrations that have shipped.
+var migrations = []migration{
+	{1, "initial schema", migrateInitialSchema},
+	{2, "connection request has_note", migrateConnectionRequestHasNote},
+	{3, "profile mutual_connections", migrateProfileMutualConnections},
+	{4, "page fingerprints", migratePageFingerprints},
+	{5, "rate limit blocks", migrateRateLimitBlocks},
+	{6, "message status", migrateMessageStatus},
+	{7, "profile tags and notes", migrateProfileTags},
+	{8, "note experiments", migrateExperiments},
+	{9, "connections snapshot", migrateConnectionsSnapshot},
+	{10, "search result provenance", migrateSearchResults},
+	{11, "incidental profile views", migrateIncidentalProfileViews},
+	{12, "task queue", migrateTasks},
+	{13, "search cursors", migrateSearchCursors},
+}
+
+// Migrate applies all pending migrations, recording each in schema_migrations
+func (d *Database) Migrate() error {
+	_, err := d.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	current, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		tx, err := d.db.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+		}
+
+		if err := m.apply(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s) failed: %w", m.version, m.name, err)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, m.version, m.name); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+		}
+
+		d.logger.WithFields(map[string]interface{}{
+			"version": m.version,
+			"name":    m.name,
+		}).Info("Applied schema migration")
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the highest applied migration version
+func (d *Database) SchemaVersion() (int, error) {
+	var version int
+	err := d.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// LatestSchemaVersion returns the version the schema is migrated to by this build
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// CheckIntegrity runs SQLite's integrity check and verifies the schema is current
+func (d *Database) CheckIntegrity() error {
+	var result string
+	if err := d.db.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("database integrity check failed: %s", result)
+	}
+
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version != LatestSchemaVersion() {
+		return fmt.Errorf("schema version %d does not match expected version %d", version, LatestSchemaVersion())
+	}
+
+	return nil
+}
+
+// columnExists reports whether a table already has the given column
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
+
+// addColumn adds a column unless a pre-migration database already has it
+func addColumn(tx *sql.Tx, table, column, definition string) error {
+	exists, err := columnExists(tx, table, column)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+// migrateInitialSchema creates the original tables. It uses IF NOT EXISTS so
+// databases created before migrations were introduced adopt it cleanly.
+func migrateInitialSchema(tx *sql.Tx) error {
+	schema := `
+	-- Profiles table
+	CREATE TABLE IF NOT EXISTS profiles (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_url TEXT UNIQUE NOT NULL,
+		name TEXT,
+		first_name TEXT,
+		last_name TEXT,
+		headline TEXT,
+		company TEXT,
+		location TEXT,
+		connection_degree TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Connection requests table
+	CREATE TABLE IF NOT EXISTS connection_requests (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_id INTEGER,
+		profile_url TEXT NOT NULL,
+		note TEXT,
+		status TEXT DEFAULT 'pending',
+		sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		accepted_at DATETIME,
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	-- Messages table
+	CREATE TABLE IF NOT EXISTS messages (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_id INTEGER,
+		profile_url TEXT NOT NULL,
+		content TEXT NOT NULL,
+		template TEXT,
+		message_type TEXT DEFAULT 'direct',
+		sent_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+
+	-- Daily stats table
+	CREATE TABLE IF NOT EXISTS daily_stats (
+		date TEXT PRIMARY KEY,
+		connections_sent INTEGER DEFAULT 0,
+		connections_accepted INTEGER DEFAULT 0,
+		messages_sent INTEGER DEFAULT 0,
+		profiles_viewed INTEGER DEFAULT 0,
+		searches_performed INTEGER DEFAULT 0
+	);
+
+	-- Session cookies table
+	CREATE TABLE IF NOT EXISTS session_cookies (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL,
+		domain TEXT,
+		path TEXT,
+		expires INTEGER,
+		http_only BOOLEAN,
+		secure BOOLEAN,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Search history table
+	CREATE TABLE IF NOT EXISTS search_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		query TEXT NOT NULL,
+		job_title TEXT,
+		company TEXT,
+		location TEXT,
+		keywords TEXT,
+		results_count INTEGER,
+		searched_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Create indexes
+	CREATE INDEX IF NOT EXISTS idx_profiles_url ON profiles(profile_url);
+	CREATE INDEX IF NOT EXISTS idx_connection_requests_status ON connection_requests(status);
+	CREATE INDEX IF NOT EXISTS idx_connection_requests_sent_at ON connection_requests(sent_at);
+	CREATE INDEX IF NOT EXISTS idx_messages_sent_at ON messages(sent_at);
+	`
+
+	_, err := tx.Exec(schema)
+	return err
+}
+
+// migrateConnectionRequestHasNote records whether a note was attached to a request
+func migrateConnectionRequestHasNote(tx *sql.Tx) error {
+	return addColumn(tx, "connection_requests", "has_note", "BOOLEAN DEFAULT 1")
+}
+
+// migrateProfileMutualConnections stores the mutual connection count scraped from search
+func migrateProfileMutualConnections(tx *sql.Tx) error {
+	return addColumn(tx, "profiles", "mutual_connections", "INTEGER DEFAULT 0")
+}
+
+// migratePageFingerprints stores DOM layout fingerprints of key pages
+func migratePageFingerprints(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS page_fingerprints (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		page_key TEXT NOT NULL,
+		fingerprint TEXT NOT NULL,
+		captured_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_page_fingerprints_key ON page_fingerprints(page_key, id);
+	`)
+	return err
+}
+
+// migrateRateLimitBlocks stores limits reported by LinkedIn itself, so later
+// runs respect them
+func migrateRateLimitBlocks(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS rate_limit_blocks (
+		action_type TEXT PRIMARY KEY,
+		blocked_until DATETIME NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)
+	`)
+	return err
+}
+
+// migrateMessageStatus records whether a message was delivered or queued as
+// a message request awaiting the recipient's approval
+func migrateMessageStatus(tx *sql.Tx) error {
+	return addColumn(tx, "messages", "status", "TEXT DEFAULT 'sent'")
+}
+
+// migrateProfileTags adds freeform tags and notes for CRM-style tracking
+func migrateProfileTags(tx *sql.Tx) error {
+	if err := addColumn(tx, "profiles", "notes", "TEXT DEFAULT ''"); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS profile_tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		profile_url TEXT NOT NULL,
+		tag TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(profile_url, tag)
+	);
+	CREATE INDEX IF NOT EXISTS idx_profile_tags_tag ON profile_tags(tag);
+	`)
+	return err
+}
+
+// migrateExperiments records which note variant each connection request used
+func migrateExperiments(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS experiments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		request_id INTEGER NOT NULL,
+		profile_url TEXT NOT NULL,
+		variant TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (request_id) REFERENCES connection_requests(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_experiments_variant ON experiments(variant);
+	`)
+	return err
+}
+
+// migrateConnectionsSnapshot stores the 1st-degree connections seen so far so
+// new acceptances can be found as a set difference
+func migrateConnectionsSnapshot(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS connections_snapshot (
+		profile_url TEXT PRIMARY KEY,
+		first_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`)
+	return err
+}
+
+// migrateSearchResults links each search to the profiles it surfaced
+func migrateSearchResults(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS search_results (
+		search_id INTEGER NOT NULL,
+		profile_id INTEGER NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (search_id, profile_id),
+		FOREIGN KEY (search_id) REFERENCES search_history(id),
+		FOREIGN KEY (profile_id) REFERENCES profiles(id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_search_results_profile ON search_results(profile_id);
+	`)
+	return err
+}
+
+// migrateIncidentalProfileViews counts profile visits made while connecting
+// or messaging separately from explicit profile views
+func migrateIncidentalProfileViews(tx *sql.Tx) error {
+	return addColumn(tx, "daily_stats", "incidental_profile_views", "INTEGER DEFAULT 0")
+}
+
+// migrateTasks adds the persistent queue of pending outreach tasks
+func migrateTasks(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_type TEXT NOT NULL,
+		target_url TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		scheduled_for TEXT NOT NULL,
+		attempts INTEGER DEFAULT 0,
+		last_error TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_tasks_due ON tasks(status, scheduled_for);
+	`)
+	return err
+}
+
+// migrateSearchCursors adds the last collected result page per search, so a
+// large search can be worked through across runs
+func migrateSearchCursors(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS search_cursors (
+		signature TEXT PRIMARY KEY,
+		page INTEGER NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)
+	`)
+	return err
+}
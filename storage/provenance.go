@@ -0,0 +1,37 @@
+// Package storage - provenance.go handles which search surfaced which profile
+package storage
+
+import "fmt"
+
+// LinkSearchResult records that a search surfaced a profile. Linking the same
+// pair twice is a no-op.
+func (d *Database) LinkSearchResult(searchID, profileID int64) error {
+	_, err := d.db.Exec(`INSERT OR IGNORE INTO search_results (search_id, profile_id) VALUES (?, ?)`, searchID, profileID)
+	if err != nil {
+		return fmt.Errorf("failed to link search result: %w", err)
+	}
+	return nil
+}
+
+// GetProfilesBySearch returns the profiles a search surfaced, oldest profile first
+func (d *Database) GetProfilesBySearch(searchID int64) ([]*Profile, error) {
+	query := `SELECT ` + profileColumns + ` FROM profiles
+		WHERE id IN (SELECT profile_id FROM search_results WHERE search_id = ?)
+		ORDER BY id`
+
+	rows, err := d.db.Query(query, searchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profiles by search: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []*Profile
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
@@ -0,0 +1,246 @@
+// Package storage - queries.go handles filtered, paginated reads of historical events
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConnectionFilter selects connection requests. Zero values mean "no filter".
+type ConnectionFilter struct {
+	Status     string    // pending, accepted, declined, withdrawn
+	ProfileURL string    // exact profile URL
+	Since      time.Time // sent at or after
+	Until      time.Time // sent before
+	OrderBy    string    // sent_at (default), accepted_at, id
+	Ascending  bool      // oldest first instead of newest first
+	Limit      int       // page size (0 = all)
+	Offset     int       // rows to skip
+}
+
+// MessageFilter selects sent messages. Zero values mean "no filter".
+type MessageFilter struct {
+	MessageType string    // connection_note, follow_up, direct, message_request
+	Status      string    // sent, pending_approval
+	ProfileURL  string    // exact profile URL
+	Since       time.Time // sent at or after
+	Until       time.Time // sent before
+	OrderBy     string    // sent_at (default), id
+	Ascending   bool      // oldest first instead of newest first
+	Limit       int       // page size (0 = all)
+	Offset      int       // rows to skip
+}
+
+// ProfileFilter selects stored profiles. Zero values mean "no filter".
+type ProfileFilter struct {
+	Tag              string // carries this tag
+	Company          string // company contains this, case-insensitive
+	ConnectionDegree string // 1st, 2nd, 3rd
+	Limit            int    // page size (0 = all)
+	Offset           int    // rows to skip
+}
+
+// ParseProfileFilter parses "tag:lead,degree:1st,company:Acme" into a
+// ProfileFilter; at least one key must be given
+func ParseProfileFilter(spec string) (ProfileFilter, error) {
+	var filter ProfileFilter
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, ":")
+		value = strings.TrimSpace(value)
+		if !ok || value == "" {
+			return filter, fmt.Errorf("invalid profile filter %q (want key:value)", part)
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "tag":
+			filter.Tag = value
+		case "company":
+			filter.Company = value
+		case "degree":
+			filter.ConnectionDegree = value
+		default:
+			return filter, fmt.Errorf("unknown profile filter key %q (allowed: tag, company, degree)", key)
+		}
+	}
+	if filter == (ProfileFilter{}) {
+		return filter, fmt.Errorf("profile filter is empty")
+	}
+	return filter, nil
+}
+
+// queryBuilder accumulates WHERE conditions and their arguments
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (q *queryBuilder) where(condition string, arg interface{}) {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, arg)
+}
+
+// build appends the WHERE, ORDER BY and LIMIT clauses to base
+func (q *queryBuilder) build(base, orderBy string, ascending bool, limit, offset int) string {
+	query := base
+	if len(q.conditions) > 0 {
+		query += " WHERE " + strings.Join(q.conditions, " AND ")
+	}
+
+	direction := "DESC"
+	if ascending {
+		direction = "ASC"
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", orderBy, direction, direction)
+
+	if limit > 0 || offset > 0 {
+		if limit <= 0 {
+			limit = -1 // SQLite: no limit
+		}
+		query += " LIMIT ? OFFSET ?"
+		q.args = append(q.args, limit, offset)
+	}
+
+	return query
+}
+
+// orderColumn validates a requested sort column against the allowed set
+func orderColumn(requested string, allowed ...string) (string, error) {
+	if requested == "" {
+		return allowed[0], nil
+	}
+	for _, column := range allowed {
+		if requested == column {
+			return column, nil
+		}
+	}
+	return "", fmt.Errorf("invalid order column %q (allowed: %s)", requested, strings.Join(allowed, ", "))
+}
+
+// GetConnectionRequests returns connection requests matching the filter
+func (d *Database) GetConnectionRequests(filter ConnectionFilter) ([]*ConnectionRequest, error) {
+	orderBy, err := orderColumn(filter.OrderBy, "sent_at", "accepted_at", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	q := &queryBuilder{}
+	if filter.Status != "" {
+		q.where("status = ?", filter.Status)
+	}
+	if filter.ProfileURL != "" {
+		q.where("profile_url = ?", filter.ProfileURL)
+	}
+	if !filter.Since.IsZero() {
+		q.where("sent_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		q.where("sent_at < ?", filter.Until)
+	}
+
+	query := q.build(
+		`SELECT id, profile_id, profile_url, note, has_note, status, sent_at, accepted_at FROM connection_requests`,
+		orderBy, filter.Ascending, filter.Limit, filter.Offset,
+	)
+
+	rows, err := d.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connection requests: %w", err)
+	}
+	defer rows.Close()
+
+	var requests []*ConnectionRequest
+	for rows.Next() {
+		req := &ConnectionRequest{}
+		err := rows.Scan(&req.ID, &req.ProfileID, &req.ProfileURL, &req.Note, &req.HasNote, &req.Status, &req.SentAt, &req.AcceptedAt)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+// GetProfiles returns stored profiles matching the filter, newest first
+func (d *Database) GetProfiles(filter ProfileFilter) ([]*Profile, error) {
+	q := &queryBuilder{}
+	if filter.Tag != "" {
+		q.where("profile_url IN (SELECT profile_url FROM profile_tags WHERE tag = ?)", normalizeTag(filter.Tag))
+	}
+	if filter.Company != "" {
+		q.where("LOWER(company) LIKE ?", "%"+strings.ToLower(filter.Company)+"%")
+	}
+	if filter.ConnectionDegree != "" {
+		q.where("connection_degree = ?", filter.ConnectionDegree)
+	}
+
+	query := q.build(`SELECT `+profileColumns+` FROM profiles`, "created_at", false, filter.Limit, filter.Offset)
+
+	rows, err := d.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []*Profile
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+// GetMessages returns sent messages matching the filter
+func (d *Database) GetMessages(filter MessageFilter) ([]*Message, error) {
+	orderBy, err := orderColumn(filter.OrderBy, "sent_at", "id")
+	if err != nil {
+		return nil, err
+	}
+
+	q := &queryBuilder{}
+	if filter.MessageType != "" {
+		q.where("message_type = ?", filter.MessageType)
+	}
+	if filter.Status != "" {
+		q.where("status = ?", filter.Status)
+	}
+	if filter.ProfileURL != "" {
+		q.where("profile_url = ?", filter.ProfileURL)
+	}
+	if !filter.Since.IsZero() {
+		q.where("sent_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		q.where("sent_at < ?", filter.Until)
+	}
+
+	query := q.build(
+		`SELECT id, profile_id, profile_url, content, template, message_type, status, sent_at FROM messages`,
+		orderBy, filter.Ascending, filter.Limit, filter.Offset,
+	)
+
+	rows, err := d.db.Query(query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*Message
+	for rows.Next() {
+		msg := &Message{}
+		err := rows.Scan(&msg.ID, &msg.ProfileID, &msg.ProfileURL, &msg.Content, &msg.Template, &msg.MessageType, &msg.Status, &msg.SentAt)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
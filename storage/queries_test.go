@@ -0,0 +1,149 @@
+// Package storage - Tests for filtered history queries
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nikshitha/linkedin-automation-poc/logger"
+)
+
+func newTestDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	log, _ := logger.New(logger.Config{Level: "error"})
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "test.db"), log)
+	if err != nil {
+		t.Fatalf("NewDatabase failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// seedHistory inserts three requests and three messages sent 3, 2 and 1 days ago
+func seedHistory(t *testing.T, db *Database) time.Time {
+	t.Helper()
+
+	now := time.Now()
+	statuses := []string{"pending", "accepted", "pending"}
+	types := []string{"connection_note", "follow_up", "follow_up"}
+	urls := []string{"https://www.linkedin.com/in/a/", "https://www.linkedin.com/in/b/", "https://www.linkedin.com/in/c/"}
+
+	for i := range urls {
+		sentAt := now.Add(-time.Duration(3-i) * 24 * time.Hour)
+
+		id, err := db.SaveConnectionRequest(&ConnectionRequest{ProfileURL: urls[i], Status: statuses[i]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.db.Exec(`UPDATE connection_requests SET sent_at = ? WHERE id = ?`, sentAt, id); err != nil {
+			t.Fatal(err)
+		}
+
+		id, err = db.SaveMessage(&Message{ProfileURL: urls[i], Content: "hi", MessageType: types[i]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.db.Exec(`UPDATE messages SET sent_at = ? WHERE id = ?`, sentAt, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return now
+}
+
+func TestGetConnectionRequestsFilter(t *testing.T) {
+	db := newTestDatabase(t)
+	now := seedHistory(t, db)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		filter ConnectionFilter
+		want   []string
+	}{
+		{"all newest first", ConnectionFilter{}, []string{"c", "b", "a"}},
+		{"status", ConnectionFilter{Status: "pending"}, []string{"c", "a"}},
+		{"profile url", ConnectionFilter{ProfileURL: "https://www.linkedin.com/in/b/"}, []string{"b"}},
+		{"since", ConnectionFilter{Since: now.Add(-2*day - time.Hour)}, []string{"c", "b"}},
+		{"until", ConnectionFilter{Until: now.Add(-2*day + time.Hour)}, []string{"b", "a"}},
+		{"date range", ConnectionFilter{Since: now.Add(-2*day - time.Hour), Until: now.Add(-2*day + time.Hour)}, []string{"b"}},
+		{"ascending", ConnectionFilter{Ascending: true}, []string{"a", "b", "c"}},
+		{"order by id", ConnectionFilter{OrderBy: "id", Ascending: true}, []string{"a", "b", "c"}},
+		{"limit", ConnectionFilter{Limit: 2}, []string{"c", "b"}},
+		{"offset", ConnectionFilter{Limit: 2, Offset: 2}, []string{"a"}},
+		{"offset without limit", ConnectionFilter{Offset: 1}, []string{"b", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests, err := db.GetConnectionRequests(tt.filter)
+			if err != nil {
+				t.Fatalf("GetConnectionRequests failed: %v", err)
+			}
+			var got []string
+			for _, req := range requests {
+				got = append(got, req.ProfileURL[len(req.ProfileURL)-2:len(req.ProfileURL)-1])
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := db.GetConnectionRequests(ConnectionFilter{OrderBy: "note; DROP TABLE profiles"}); err == nil {
+		t.Error("Expected an error for an invalid order column")
+	}
+}
+
+func TestGetMessagesFilter(t *testing.T) {
+	db := newTestDatabase(t)
+	now := seedHistory(t, db)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		filter MessageFilter
+		want   []string
+	}{
+		{"all newest first", MessageFilter{}, []string{"c", "b", "a"}},
+		{"message type", MessageFilter{MessageType: "follow_up"}, []string{"c", "b"}},
+		{"profile url", MessageFilter{ProfileURL: "https://www.linkedin.com/in/a/"}, []string{"a"}},
+		{"since", MessageFilter{Since: now.Add(-time.Hour - day)}, []string{"c"}},
+		{"until", MessageFilter{Until: now.Add(-2*day - time.Hour)}, []string{"a"}},
+		{"ascending", MessageFilter{Ascending: true}, []string{"a", "b", "c"}},
+		{"order by id", MessageFilter{OrderBy: "id"}, []string{"c", "b", "a"}},
+		{"limit and offset", MessageFilter{Limit: 1, Offset: 1}, []string{"b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := db.GetMessages(tt.filter)
+			if err != nil {
+				t.Fatalf("GetMessages failed: %v", err)
+			}
+			var got []string
+			for _, msg := range messages {
+				got = append(got, msg.ProfileURL[len(msg.ProfileURL)-2:len(msg.ProfileURL)-1])
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := db.GetMessages(MessageFilter{OrderBy: "content"}); err == nil {
+		t.Error("Expected an error for an invalid order column")
+	}
+}
@@ -0,0 +1,55 @@
+// Package storage - snapshot.go handles the known set of 1st-degree connections
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SaveConnectionsSnapshot adds profile URLs to the set of known 1st-degree
+// connections. URLs already in the snapshot keep their first-seen time.
+func (d *Database) SaveConnectionsSnapshot(profileURLs []string) error {
+	err := d.withTx(func(tx *sql.Tx) error {
+		for _, url := range profileURLs {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO connections_snapshot (profile_url) VALUES (?)`, url); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save connections snapshot: %w", err)
+	}
+	return nil
+}
+
+// DiffConnections returns the URLs in current that are not yet in the
+// connections snapshot, in the order given
+func (d *Database) DiffConnections(current []string) ([]string, error) {
+	rows, err := d.db.Query(`SELECT profile_url FROM connections_snapshot`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read connections snapshot: %w", err)
+	}
+	defer rows.Close()
+
+	known := make(map[string]bool)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		known[url] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for _, url := range current {
+		if !known[url] {
+			known[url] = true
+			added = append(added, url)
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,87 @@
+// Package storage - stats.go handles aggregate statistics over stored activity
+package storage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// LatencyStats summarizes how long accepted invitations took, in days
+type LatencyStats struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean_days"`
+	Median float64 `json:"median_days"`
+	P90    float64 `json:"p90_days"`
+	Min    float64 `json:"min_days"`
+	Max    float64 `json:"max_days"`
+}
+
+// GetAcceptanceLatencyStats computes the distribution of days from sent_at to
+// accepted_at across accepted connection requests. Count is 0 when none exist.
+func (d *Database) GetAcceptanceLatencyStats() (*LatencyStats, error) {
+	query := `
+		SELECT sent_at, accepted_at FROM connection_requests
+		WHERE status = 'accepted' AND accepted_at IS NOT NULL
+	`
+
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query accepted requests: %w", err)
+	}
+	defer rows.Close()
+
+	var days []float64
+	for rows.Next() {
+		req := &ConnectionRequest{}
+		if err := rows.Scan(&req.SentAt, &req.AcceptedAt); err != nil {
+			return nil, err
+		}
+		latency := req.AcceptedAt.Sub(req.SentAt).Hours() / 24
+		if latency < 0 {
+			continue
+		}
+		days = append(days, latency)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return latencyStats(days), nil
+}
+
+// latencyStats aggregates latencies given in days
+func latencyStats(days []float64) *LatencyStats {
+	stats := &LatencyStats{Count: len(days)}
+	if len(days) == 0 {
+		return stats
+	}
+
+	sort.Float64s(days)
+
+	var sum float64
+	for _, d := range days {
+		sum += d
+	}
+
+	stats.Mean = sum / float64(len(days))
+	stats.Median = percentile(days, 50)
+	stats.P90 = percentile(days, 90)
+	stats.Min = days[0]
+	stats.Max = days[len(days)-1]
+	return stats
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating
+// linearly between the closest ranks
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	weight := rank - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
@@ -0,0 +1,97 @@
+// Package storage - tags.go handles freeform tags and notes on profiles
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// normalizeTag makes tags case-insensitive and trims surrounding space
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// AddTag attaches a tag to a stored profile. Adding an existing tag is a no-op.
+func (d *Database) AddTag(profileURL, tag string) error {
+	tag = normalizeTag(tag)
+	if tag == "" {
+		return fmt.Errorf("tag must not be empty")
+	}
+
+	exists, err := d.ProfileExists(profileURL)
+	if err != nil {
+		return fmt.Errorf("failed to look up profile: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("profile not found: %s", profileURL)
+	}
+
+	_, err = d.db.Exec(`INSERT OR IGNORE INTO profile_tags (profile_url, tag) VALUES (?, ?)`, profileURL, tag)
+	if err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+	return nil
+}
+
+// RemoveTag detaches a tag from a profile
+func (d *Database) RemoveTag(profileURL, tag string) error {
+	_, err := d.db.Exec(`DELETE FROM profile_tags WHERE profile_url = ? AND tag = ?`, profileURL, normalizeTag(tag))
+	if err != nil {
+		return fmt.Errorf("failed to remove tag: %w", err)
+	}
+	return nil
+}
+
+// GetTags returns a profile's tags in alphabetical order
+func (d *Database) GetTags(profileURL string) ([]string, error) {
+	rows, err := d.db.Query(`SELECT tag FROM profile_tags WHERE profile_url = ? ORDER BY tag`, profileURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// GetProfilesByTag returns the stored profiles carrying a tag, newest first
+func (d *Database) GetProfilesByTag(tag string) ([]*Profile, error) {
+	query := `SELECT ` + profileColumns + ` FROM profiles
+		WHERE profile_url IN (SELECT profile_url FROM profile_tags WHERE tag = ?)
+		ORDER BY created_at DESC`
+
+	rows, err := d.db.Query(query, normalizeTag(tag))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tagged profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []*Profile
+	for rows.Next() {
+		profile, err := scanProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+// SetProfileNotes replaces the freeform notes on a stored profile
+func (d *Database) SetProfileNotes(profileURL, notes string) error {
+	result, err := d.db.Exec(`UPDATE profiles SET notes = ?, updated_at = CURRENT_TIMESTAMP WHERE profile_url = ?`, notes, profileURL)
+	if err != nil {
+		return fmt.Errorf("failed to set notes: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("profile not found: %s", profileURL)
+	}
+	return nil
+}
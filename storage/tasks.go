@@ -0,0 +1,157 @@
+// Package storage - tasks.go handles the persistent queue of pending outreach tasks
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Task types
+const (
+	TaskConnect = "connect"
+	TaskMessage = "message"
+	TaskView    = "view"
+)
+
+// Task statuses
+const (
+	TaskPending = "pending"
+	TaskDone    = "done"
+	TaskFailed  = "failed"
+)
+
+// MaxTaskAttempts is how often a task is tried before it is marked failed
+const MaxTaskAttempts = 3
+
+// taskTimeFormat is how scheduled_for is stored, in UTC, so it sorts and
+// compares as text
+const taskTimeFormat = "2006-01-02 15:04:05"
+
+// Task is a queued outreach action
+type Task struct {
+	ID           int64
+	TaskType     string
+	TargetURL    string
+	Status       string
+	ScheduledFor time.Time
+	Attempts     int
+	LastError    string
+	CreatedAt    time.Time
+}
+
+// IsTaskType reports whether t is a known task type
+func IsTaskType(t string) bool {
+	return t == TaskConnect || t == TaskMessage || t == TaskView
+}
+
+// EnqueueTask queues a task to run at or after scheduledFor. If the same
+// task is already pending, its ID is returned instead of queueing it twice.
+func (d *Database) EnqueueTask(taskType, targetURL string, scheduledFor time.Time) (int64, error) {
+	if !IsTaskType(taskType) {
+		return 0, fmt.Errorf("unknown task type: %s", taskType)
+	}
+
+	var id int64
+	err := d.db.QueryRow(`SELECT id FROM tasks WHERE task_type = ? AND target_url = ? AND status = ?`,
+		taskType, targetURL, TaskPending).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to check queued tasks: %w", err)
+	}
+
+	result, err := d.db.Exec(`INSERT INTO tasks (task_type, target_url, status, scheduled_for) VALUES (?, ?, ?, ?)`,
+		taskType, targetURL, TaskPending, scheduledFor.UTC().Format(taskTimeFormat))
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue task: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// NextDueTask returns the oldest pending task that is due, limited to the
+// given task types (all types when none are given). It returns nil when
+// nothing is due. The task stays pending until CompleteTask or FailTask, so
+// a task interrupted by a crash is picked up again on the next run.
+func (d *Database) NextDueTask(taskTypes ...string) (*Task, error) {
+	query := `SELECT id, task_type, target_url, status, scheduled_for, attempts, COALESCE(last_error, ''), created_at
+		FROM tasks WHERE status = ? AND scheduled_for <= ?`
+	args := []interface{}{TaskPending, time.Now().UTC().Format(taskTimeFormat)}
+	if len(taskTypes) > 0 {
+		query += ` AND task_type IN (?` + strings.Repeat(", ?", len(taskTypes)-1) + `)`
+		for _, t := range taskTypes {
+			args = append(args, t)
+		}
+	}
+	query += ` ORDER BY scheduled_for, id LIMIT 1`
+
+	task := &Task{}
+	var scheduledFor string
+	err := d.db.QueryRow(query, args...).Scan(&task.ID, &task.TaskType, &task.TargetURL, &task.Status,
+		&scheduledFor, &task.Attempts, &task.LastError, &task.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next task: %w", err)
+	}
+	task.ScheduledFor, _ = time.ParseInLocation(taskTimeFormat, scheduledFor, time.UTC)
+	return task, nil
+}
+
+// CompleteTask marks a task as done
+func (d *Database) CompleteTask(id int64) error {
+	_, err := d.db.Exec(`UPDATE tasks SET status = ?, attempts = attempts + 1, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		TaskDone, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete task: %w", err)
+	}
+	return nil
+}
+
+// FailTask records a failed attempt. The task is retried after retryAfter
+// until it has been tried MaxTaskAttempts times, then it is marked failed.
+func (d *Database) FailTask(id int64, reason string, retryAfter time.Duration) error {
+	retryAt := time.Now().Add(retryAfter).UTC().Format(taskTimeFormat)
+	_, err := d.db.Exec(`
+		UPDATE tasks SET
+			attempts = attempts + 1,
+			last_error = ?,
+			status = CASE WHEN attempts + 1 >= ? THEN ? ELSE status END,
+			scheduled_for = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`,
+		reason, MaxTaskAttempts, TaskFailed, retryAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to record task failure: %w", err)
+	}
+	return nil
+}
+
+// AbandonTask records a failed attempt and marks the task failed right
+// away, for errors another attempt wouldn't fix
+func (d *Database) AbandonTask(id int64, reason string) error {
+	_, err := d.db.Exec(`
+		UPDATE tasks SET
+			attempts = attempts + 1,
+			last_error = ?,
+			status = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`,
+		reason, TaskFailed, id)
+	if err != nil {
+		return fmt.Errorf("failed to record task failure: %w", err)
+	}
+	return nil
+}
+
+// CountTasks returns how many tasks have the given status
+func (d *Database) CountTasks(status string) (int, error) {
+	var count int
+	if err := d.db.QueryRow(`SELECT COUNT(*) FROM tasks WHERE status = ?`, status).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return count, nil
+}